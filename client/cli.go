@@ -2,8 +2,10 @@ package client
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -35,25 +37,7 @@ func HandleResponse(c *Client, resp *http.Response, pretty bool) {
 		}
 		fmt.Printf("error: %d%s", resp.StatusCode, sbody)
 	} else if !c.Dump && len(body) > 0 {
-		var out string
-		if pretty {
-			var jbody interface{}
-			err = json.Unmarshal(body, &jbody)
-			if err != nil {
-				out = string(body)
-			} else {
-				var b []byte
-				b, err = json.MarshalIndent(jbody, "", "    ")
-				if err == nil {
-					out = string(b)
-				} else {
-					out = string(body)
-				}
-			}
-		} else {
-			out = string(body)
-		}
-		fmt.Print(out)
+		fmt.Print(formatBody(body, pretty))
 	}
 
 	// Figure out exit code
@@ -73,6 +57,73 @@ func HandleResponse(c *Client, resp *http.Response, pretty bool) {
 	os.Exit(exitStatus)
 }
 
+// HandlePages prints the body of each page of results returned by it on a separate line until
+// all the pages have been retrieved. It calls HandleResponse, which exits the process, with the
+// first response whose status code is not 2xx.
+func HandlePages(ctx context.Context, c *Client, it PageIterator, pretty bool) error {
+	for {
+		resp, err := it.Next(ctx)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			HandleResponse(c, resp, pretty)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if !c.Dump && len(body) > 0 {
+			fmt.Println(formatBody(body, pretty))
+		}
+	}
+}
+
+// formatBody returns the given response body indented if pretty is true and it contains JSON.
+func formatBody(body []byte, pretty bool) string {
+	if !pretty {
+		return string(body)
+	}
+	var jbody interface{}
+	if err := json.Unmarshal(body, &jbody); err != nil {
+		return string(body)
+	}
+	b, err := json.MarshalIndent(jbody, "", "    ")
+	if err != nil {
+		return string(body)
+	}
+	return string(b)
+}
+
+// HandleEvents prints the data of each event read from r on a separate line until the server closes
+// the stream. The data is indented if pretty is true and it contains JSON.
+func HandleEvents(r *EventReader, pretty bool) error {
+	defer r.Close()
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		out := e.Data
+		if pretty {
+			var v interface{}
+			if err := json.Unmarshal(e.Data, &v); err == nil {
+				if b, err := json.MarshalIndent(v, "", "    "); err == nil {
+					out = b
+				}
+			}
+		}
+		fmt.Println(string(out))
+	}
+}
+
 // WSWrite sends STDIN lines to a websocket server.
 func WSWrite(ws *websocket.Conn) {
 	scanner := bufio.NewScanner(os.Stdin)
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Conditions lists the preconditions of a conditional request as defined in RFC 7232. The
+// generated clients of cacheable actions and of unsafe actions that use entity tags accept
+// conditions and set the corresponding request headers. Zero fields are not sent.
+type Conditions struct {
+	// IfMatch lists the entity tags the current representation must match, e.g. `"v1"` or
+	// `*`.
+	IfMatch string
+	// IfNoneMatch lists the entity tags of the representations cached by the client.
+	IfNoneMatch string
+	// IfModifiedSince is the last modification date of the representation cached by the
+	// client.
+	IfModifiedSince time.Time
+	// IfUnmodifiedSince is the date after which the resource must not have been modified.
+	IfUnmodifiedSince time.Time
+}
+
+// ParseConditions returns the conditions corresponding to the given header values. Dates use
+// the HTTP date format, empty values are ignored.
+func ParseConditions(ifMatch, ifNoneMatch, ifModifiedSince, ifUnmodifiedSince string) (*Conditions, error) {
+	c := &Conditions{IfMatch: ifMatch, IfNoneMatch: ifNoneMatch}
+	if ifModifiedSince != "" {
+		t, err := http.ParseTime(ifModifiedSince)
+		if err != nil {
+			return nil, fmt.Errorf("invalid If-Modified-Since date %q", ifModifiedSince)
+		}
+		c.IfModifiedSince = t
+	}
+	if ifUnmodifiedSince != "" {
+		t, err := http.ParseTime(ifUnmodifiedSince)
+		if err != nil {
+			return nil, fmt.Errorf("invalid If-Unmodified-Since date %q", ifUnmodifiedSince)
+		}
+		c.IfUnmodifiedSince = t
+	}
+	return c, nil
+}
+
+// SetHeaders sets the conditional request headers corresponding to the conditions.
+func (c *Conditions) SetHeaders(header http.Header) {
+	if c.IfMatch != "" {
+		header.Set("If-Match", c.IfMatch)
+	}
+	if c.IfNoneMatch != "" {
+		header.Set("If-None-Match", c.IfNoneMatch)
+	}
+	if !c.IfModifiedSince.IsZero() {
+		header.Set("If-Modified-Since", c.IfModifiedSince.UTC().Format(http.TimeFormat))
+	}
+	if !c.IfUnmodifiedSince.IsZero() {
+		header.Set("If-Unmodified-Since", c.IfUnmodifiedSince.UTC().Format(http.TimeFormat))
+	}
+}
@@ -0,0 +1,35 @@
+package client_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Conditions", func() {
+	modified := time.Date(2017, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	It("sets the conditional request headers", func() {
+		header := make(http.Header)
+		c := &client.Conditions{IfNoneMatch: `"v1"`, IfModifiedSince: modified}
+		c.SetHeaders(header)
+		Ω(header).Should(Equal(http.Header{
+			"If-None-Match":     []string{`"v1"`},
+			"If-Modified-Since": []string{"Wed, 01 Mar 2017 10:00:00 GMT"},
+		}))
+	})
+
+	It("parses the header values", func() {
+		c, err := client.ParseConditions(`"v1"`, "", "", "Wed, 01 Mar 2017 10:00:00 GMT")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(c.IfMatch).Should(Equal(`"v1"`))
+		Ω(c.IfUnmodifiedSince.Equal(modified)).Should(BeTrue())
+
+		_, err = client.ParseConditions("", "", "yesterday", "")
+		Ω(err).Should(HaveOccurred())
+	})
+})
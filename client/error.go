@@ -0,0 +1,46 @@
+package client
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/goadesign/goa"
+)
+
+// DecodeError decodes the body of an error response into a goa error. It understands both the
+// goa error media type (application/vnd.goa.error) and RFC 7807 problem details
+// (application/problem+json). Other content types are decoded with decoder, a JSON decoder is
+// used if decoder is nil.
+func DecodeError(decoder *goa.HTTPDecoder, resp *http.Response) (*goa.ErrorResponse, error) {
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	if mediaType == goa.ProblemMediaIdentifier || strings.HasSuffix(mediaType, "/problem+json") {
+		var p goa.ProblemDetails
+		if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+			return nil, err
+		}
+		e := p.ErrorResponse()
+		if e.Status == 0 {
+			e.Status = resp.StatusCode
+		}
+		return e, nil
+	}
+	var e goa.ErrorResponse
+	if decoder == nil {
+		err = json.NewDecoder(resp.Body).Decode(&e)
+	} else {
+		err = decoder.Decode(&e, resp.Body, contentType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if e.Status == 0 {
+		e.Status = resp.StatusCode
+	}
+	return &e, nil
+}
@@ -0,0 +1,64 @@
+package client_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DecodeError", func() {
+	var contentType, body string
+	var gerr *goa.ErrorResponse
+	var err error
+
+	JustBeforeEach(func() {
+		resp := &http.Response{
+			StatusCode: 400,
+			Header:     http.Header{"Content-Type": []string{contentType}},
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+		}
+		gerr, err = client.DecodeError(nil, resp)
+	})
+
+	Context("with a goa error", func() {
+		BeforeEach(func() {
+			contentType = goa.ErrorMediaIdentifier
+			body = `{"id":"foo","code":"invalid_request","detail":"bar","meta":{"param":"baz"}}`
+		})
+
+		It("decodes the error", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gerr).To(Equal(&goa.ErrorResponse{
+				ID:     "foo",
+				Code:   "invalid_request",
+				Status: 400,
+				Detail: "bar",
+				Meta:   map[string]interface{}{"param": "baz"},
+			}))
+		})
+	})
+
+	Context("with problem details", func() {
+		BeforeEach(func() {
+			contentType = "application/problem+json; charset=utf-8"
+			body = `{"type":"about:blank","title":"Bad Request","status":400,"detail":"bar","instance":"foo","code":"invalid_request","param":"baz"}`
+		})
+
+		It("decodes the error", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gerr).To(Equal(&goa.ErrorResponse{
+				ID:     "foo",
+				Code:   "invalid_request",
+				Status: 400,
+				Detail: "bar",
+				Meta:   map[string]interface{}{"param": "baz"},
+			}))
+		})
+	})
+})
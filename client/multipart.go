@@ -0,0 +1,67 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+)
+
+// maxMemory is the maximum number of bytes of file content kept in memory by NewFileHeader, the
+// rest is stored in temporary files.
+const maxMemory = 32 << 20
+
+// NewFileHeader returns a multipart file header for the file with the given path. The header can
+// be used to initialize the file fields of multipart payloads.
+func NewFileHeader(path string) (*multipart.FileHeader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return NewFileHeaderFromReader(filepath.Base(path), f)
+}
+
+// NewFileHeaderFromReader returns a multipart file header with the given filename whose content
+// is read from r.
+func NewFileHeaderFromReader(filename string, r io.Reader) (*multipart.FileHeader, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	fw, err := w.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(fw, r); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(maxMemory)
+	if err != nil {
+		return nil, err
+	}
+	files := form.File["file"]
+	if len(files) == 0 {
+		return nil, fmt.Errorf("failed to read content of %s", filename)
+	}
+	return files[0], nil
+}
+
+// WriteFileField writes the content of the file described by fh to the multipart form w using
+// the given field name.
+func WriteFileField(w *multipart.Writer, fieldName string, fh *multipart.FileHeader) error {
+	f, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fw, err := w.CreateFormFile(fieldName, fh.Filename)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, f)
+	return err
+}
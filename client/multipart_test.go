@@ -0,0 +1,76 @@
+package client_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("multipart", func() {
+	const content = "file content"
+
+	Context("NewFileHeaderFromReader", func() {
+		It("creates a file header with the given content", func() {
+			fh, err := client.NewFileHeaderFromReader("foo.txt", bytes.NewBufferString(content))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fh.Filename).To(Equal("foo.txt"))
+			f, err := fh.Open()
+			Expect(err).ToNot(HaveOccurred())
+			defer f.Close()
+			b, err := ioutil.ReadAll(f)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(b)).To(Equal(content))
+		})
+	})
+
+	Context("NewFileHeader", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "goa-multipart")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("creates a file header from a file on disk", func() {
+			path := filepath.Join(dir, "bar.txt")
+			Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+			fh, err := client.NewFileHeader(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fh.Filename).To(Equal("bar.txt"))
+			Expect(fh.Size).To(BeEquivalentTo(len(content)))
+		})
+
+		It("fails if the file does not exist", func() {
+			_, err := client.NewFileHeader(filepath.Join(dir, "missing"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("WriteFileField", func() {
+		It("writes the file content as a form file", func() {
+			fh, err := client.NewFileHeaderFromReader("foo.txt", bytes.NewBufferString(content))
+			Expect(err).ToNot(HaveOccurred())
+			var body bytes.Buffer
+			w := multipart.NewWriter(&body)
+			Expect(client.WriteFileField(w, "upload", fh)).To(Succeed())
+			Expect(w.Close()).To(Succeed())
+
+			form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1024)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(form.File).To(HaveKey("upload"))
+			Expect(form.File["upload"][0].Filename).To(Equal("foo.txt"))
+		})
+	})
+})
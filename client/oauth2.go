@@ -0,0 +1,380 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultExpiryDelta is the default duration before the expiry of an access token at which the
+// OAuth2 token sources request a new token.
+const DefaultExpiryDelta = 10 * time.Second
+
+// DefaultTokenTimeout is the default maximum duration of the requests made to the token endpoint.
+const DefaultTokenTimeout = 30 * time.Second
+
+type (
+	// OAuth2Config describes the OAuth2 token endpoint and client credentials used by the token
+	// sources that request access tokens.
+	OAuth2Config struct {
+		// TokenURL is the URL of the token endpoint.
+		TokenURL string
+		// ClientID is the client identifier.
+		ClientID string
+		// ClientSecret is the client secret. The client credentials are sent using HTTP basic
+		// authentication if set, the client identifier is sent in the request body otherwise.
+		ClientSecret string
+		// Scopes lists the requested scopes.
+		Scopes []string
+		// HTTPClient is the client used to make requests to the token endpoint,
+		// http.DefaultClient is used if nil.
+		HTTPClient *http.Client
+		// ExpiryDelta is the duration before the expiry of the access token at which a new
+		// token is requested, DefaultExpiryDelta is used if zero.
+		ExpiryDelta time.Duration
+		// Timeout is the maximum duration of a request to the token endpoint,
+		// DefaultTokenTimeout is used if zero.
+		Timeout time.Duration
+	}
+
+	// OAuth2Token is an access token returned by an OAuth2 token endpoint.
+	OAuth2Token struct {
+		// AccessToken is the token used to sign requests.
+		AccessToken string
+		// TokenType is the token type, defaults to "Bearer".
+		TokenType string
+		// RefreshToken is the token used to obtain a new access token if any.
+		RefreshToken string
+		// Expiry is the expiration time of the access token, zero if the token does not
+		// expire.
+		Expiry time.Time
+	}
+
+	// OAuth2Error is the error returned by the token sources when the token endpoint rejects
+	// a request.
+	OAuth2Error struct {
+		// Status is the HTTP status of the token endpoint response.
+		Status int
+		// Code is the OAuth2 error code, e.g. "invalid_grant".
+		Code string
+		// Description is the human readable error description if any.
+		Description string
+	}
+
+	// oauth2TokenSource is a TokenSource that caches the access token returned by the token
+	// endpoint and requests a new one when it is about to expire.
+	oauth2TokenSource struct {
+		config *OAuth2Config
+		// grant returns the parameters of the token request used when there is no
+		// refresh token.
+		grant func() url.Values
+		// now returns the current time.
+		now func() time.Time
+
+		mu    sync.Mutex
+		token *OAuth2Token
+		// call is the in-flight token request if any.
+		call *tokenCall
+	}
+
+	// tokenCall is a token request shared by the callers of a token source that need a new
+	// token at the same time.
+	tokenCall struct {
+		// done is closed once the request completes.
+		done  chan struct{}
+		token *OAuth2Token
+		err   error
+	}
+
+	// tokenResponse is the body of a successful token endpoint response.
+	tokenResponse struct {
+		AccessToken  string      `json:"access_token"`
+		TokenType    string      `json:"token_type"`
+		RefreshToken string      `json:"refresh_token"`
+		ExpiresIn    json.Number `json:"expires_in"`
+	}
+
+	// errorResponse is the body of an error token endpoint response.
+	errorResponse struct {
+		Code        string `json:"error"`
+		Description string `json:"error_description"`
+	}
+)
+
+// ClientCredentialsTokenSource returns a token source that requests access tokens using the client
+// credentials grant.
+func (c *OAuth2Config) ClientCredentialsTokenSource() TokenSource {
+	return c.tokenSource(nil, func() url.Values {
+		return url.Values{"grant_type": {"client_credentials"}}
+	})
+}
+
+// PasswordTokenSource returns a token source that requests access tokens using the resource owner
+// password credentials grant. The refresh token returned by the token endpoint if any is used to
+// request the following tokens.
+func (c *OAuth2Config) PasswordTokenSource(username, password string) TokenSource {
+	return c.tokenSource(nil, func() url.Values {
+		return url.Values{
+			"grant_type": {"password"},
+			"username":   {username},
+			"password":   {password},
+		}
+	})
+}
+
+// RefreshTokenSource returns a token source that requests access tokens using the refresh token
+// grant starting with the given refresh token. Refresh tokens rotated by the token endpoint
+// replace the previous one.
+func (c *OAuth2Config) RefreshTokenSource(refreshToken string) TokenSource {
+	return c.tokenSource(&OAuth2Token{RefreshToken: refreshToken}, nil)
+}
+
+// tokenSource creates a token source with the given initial token and grant.
+func (c *OAuth2Config) tokenSource(token *OAuth2Token, grant func() url.Values) *oauth2TokenSource {
+	return &oauth2TokenSource{config: c, grant: grant, now: time.Now, token: token}
+}
+
+// Token returns the cached access token if it does not expire within the configured delta,
+// a new access token otherwise. See TokenContext.
+func (s *oauth2TokenSource) Token() (Token, error) {
+	return s.TokenContext(context.Background())
+}
+
+// TokenContext returns the cached access token if it does not expire within the configured delta,
+// a new access token otherwise. Concurrent callers share a single request to the token endpoint
+// which does not hold the lock of the token source, TokenContext returns ctx.Err() if ctx is done
+// before the request completes.
+func (s *oauth2TokenSource) TokenContext(ctx context.Context) (Token, error) {
+	s.mu.Lock()
+	if s.token != nil && s.token.AccessToken != "" && !s.expiring(s.token) {
+		token := s.token
+		s.mu.Unlock()
+		return token, nil
+	}
+	call := s.call
+	if call == nil {
+		call = &tokenCall{done: make(chan struct{})}
+		s.call = call
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), s.config.timeout())
+			defer cancel()
+			call.token, call.err = s.refresh(ctx)
+			s.mu.Lock()
+			s.call = nil
+			s.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return nil, call.err
+		}
+		return call.token, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// refresh requests a new access token and caches it. A refresh token rejected by the token
+// endpoint is discarded and the grant of the token source, if any, is used instead. refresh is
+// called by one goroutine at a time.
+func (s *oauth2TokenSource) refresh(ctx context.Context) (*OAuth2Token, error) {
+	var refreshToken string
+	s.mu.Lock()
+	if s.token != nil {
+		refreshToken = s.token.RefreshToken
+	}
+	s.mu.Unlock()
+
+	var (
+		token *OAuth2Token
+		err   error
+	)
+	if refreshToken != "" {
+		params := url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {refreshToken},
+		}
+		token, err = s.config.requestToken(ctx, params, s.now())
+		if err != nil {
+			_, rejected := err.(*OAuth2Error)
+			if rejected {
+				// Drop the rejected refresh token so that the grant is used instead.
+				refreshToken = ""
+				s.mu.Lock()
+				t := *s.token
+				t.RefreshToken = ""
+				s.token = &t
+				s.mu.Unlock()
+			}
+			if !rejected || s.grant == nil {
+				return nil, err
+			}
+		}
+	}
+	if token == nil {
+		if s.grant == nil {
+			return nil, fmt.Errorf("oauth2: no refresh token")
+		}
+		token, err = s.config.requestToken(ctx, s.grant(), s.now())
+		if err != nil {
+			return nil, err
+		}
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	s.mu.Lock()
+	s.token = token
+	s.mu.Unlock()
+	return token, nil
+}
+
+// expiring returns true if the token expires within the expiry delta.
+func (s *oauth2TokenSource) expiring(t *OAuth2Token) bool {
+	if t.Expiry.IsZero() {
+		return false
+	}
+	delta := s.config.ExpiryDelta
+	if delta == 0 {
+		delta = DefaultExpiryDelta
+	}
+	return !s.now().Add(delta).Before(t.Expiry)
+}
+
+// timeout returns the maximum duration of a request to the token endpoint.
+func (c *OAuth2Config) timeout() time.Duration {
+	if c.Timeout == 0 {
+		return DefaultTokenTimeout
+	}
+	return c.Timeout
+}
+
+// requestToken makes a request to the token endpoint with the given parameters. now is used to
+// compute the expiry of the returned token.
+func (c *OAuth2Config) requestToken(ctx context.Context, params url.Values, now time.Time) (*OAuth2Token, error) {
+	if len(c.Scopes) > 0 {
+		params.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	if c.ClientSecret == "" && c.ClientID != "" {
+		params.Set("client_id", c.ClientID)
+	}
+	req, err := http.NewRequest("POST", c.TokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+	}
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: %s", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: %s", err)
+	}
+	form := isFormResponse(resp)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		e := &OAuth2Error{Status: resp.StatusCode}
+		if form {
+			if vals, err := url.ParseQuery(string(body)); err == nil {
+				e.Code, e.Description = vals.Get("error"), vals.Get("error_description")
+			}
+		} else {
+			var er errorResponse
+			if err := json.Unmarshal(body, &er); err == nil {
+				e.Code, e.Description = er.Code, er.Description
+			}
+		}
+		return nil, e
+	}
+
+	var tr tokenResponse
+	if form {
+		vals, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("oauth2: invalid token response: %s", err)
+		}
+		tr = tokenResponse{
+			AccessToken:  vals.Get("access_token"),
+			TokenType:    vals.Get("token_type"),
+			RefreshToken: vals.Get("refresh_token"),
+			ExpiresIn:    json.Number(vals.Get("expires_in")),
+		}
+	} else if err := json.Unmarshal(body, &tr); err != nil {
+		return nil, fmt.Errorf("oauth2: invalid token response: %s", err)
+	}
+	if tr.AccessToken == "" {
+		return nil, fmt.Errorf("oauth2: token response is missing access_token")
+	}
+	token := &OAuth2Token{
+		AccessToken:  tr.AccessToken,
+		TokenType:    tr.TokenType,
+		RefreshToken: tr.RefreshToken,
+	}
+	if tr.ExpiresIn != "" {
+		secs, err := strconv.ParseInt(string(tr.ExpiresIn), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("oauth2: invalid expires_in value %q", tr.ExpiresIn)
+		}
+		if secs > 0 {
+			token.Expiry = now.Add(time.Duration(secs) * time.Second)
+		}
+	}
+	return token, nil
+}
+
+// isFormResponse returns true if the token endpoint response body is URL encoded rather than
+// JSON encoded as done by some providers.
+func isFormResponse(resp *http.Response) bool {
+	mt, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return err == nil && mt == "application/x-www-form-urlencoded"
+}
+
+// SetAuthHeader sets the Authorization header to r.
+func (t *OAuth2Token) SetAuthHeader(r *http.Request) {
+	typ := t.TokenType
+	if typ == "" || strings.EqualFold(typ, "bearer") {
+		typ = "Bearer"
+	}
+	r.Header.Set("Authorization", typ+" "+t.AccessToken)
+}
+
+// Valid reports whether the token has an access token that is not expired.
+func (t *OAuth2Token) Valid() bool {
+	return t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Before(t.Expiry))
+}
+
+// Error returns the error message.
+func (e *OAuth2Error) Error() string {
+	msg := fmt.Sprintf("oauth2: token endpoint returned %d", e.Status)
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	if e.Description != "" {
+		msg += ": " + e.Description
+	}
+	return msg
+}
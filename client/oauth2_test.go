@@ -0,0 +1,248 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OAuth2Config", func() {
+	var (
+		expiresIn int
+		rotate    bool
+		fail      bool
+		revoked   bool
+		hang      chan struct{}
+		mu        sync.Mutex
+		requests  []*http.Request
+		server    *httptest.Server
+		config    *client.OAuth2Config
+	)
+
+	BeforeEach(func() {
+		expiresIn = 3600
+		rotate = false
+		fail = false
+		revoked = false
+		hang = nil
+		requests = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			mu.Lock()
+			requests = append(requests, r)
+			n := len(requests)
+			mu.Unlock()
+			if hang != nil {
+				<-hang
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if fail || revoked && r.PostForm.Get("grant_type") == "refresh_token" {
+				w.WriteHeader(400)
+				fmt.Fprint(w, `{"error":"invalid_grant","error_description":"bad credentials"}`)
+				return
+			}
+			refresh := "refresh"
+			if rotate {
+				refresh = fmt.Sprintf("refresh%d", n)
+			}
+			fmt.Fprintf(w, `{"access_token":"token%d","token_type":"bearer","refresh_token":%q,"expires_in":%d}`, n, refresh, expiresIn)
+		}))
+		config = &client.OAuth2Config{
+			TokenURL:     server.URL,
+			ClientID:     "id",
+			ClientSecret: "secret",
+			Scopes:       []string{"read", "write"},
+		}
+	})
+
+	AfterEach(func() {
+		if hang != nil {
+			close(hang)
+		}
+		server.Close()
+	})
+
+	authHeader := func(source client.TokenSource) string {
+		token, err := source.Token()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(token.Valid()).Should(BeTrue())
+		req, _ := http.NewRequest("GET", "/", nil)
+		token.SetAuthHeader(req)
+		return req.Header.Get("Authorization")
+	}
+
+	Context("with the client credentials grant", func() {
+		It("requests and caches the token", func() {
+			source := config.ClientCredentialsTokenSource()
+			Ω(authHeader(source)).Should(Equal("Bearer token1"))
+			Ω(authHeader(source)).Should(Equal("Bearer token1"))
+			Ω(requests).Should(HaveLen(1))
+			r := requests[0]
+			Ω(r.Method).Should(Equal("POST"))
+			Ω(r.PostForm.Get("grant_type")).Should(Equal("client_credentials"))
+			Ω(r.PostForm.Get("scope")).Should(Equal("read write"))
+			user, pass, ok := r.BasicAuth()
+			Ω(ok).Should(BeTrue())
+			Ω(user).Should(Equal("id"))
+			Ω(pass).Should(Equal("secret"))
+		})
+
+		It("sends the client ID in the body when there is no secret", func() {
+			config.ClientSecret = ""
+			authHeader(config.ClientCredentialsTokenSource())
+			_, _, ok := requests[0].BasicAuth()
+			Ω(ok).Should(BeFalse())
+			Ω(requests[0].PostForm.Get("client_id")).Should(Equal("id"))
+		})
+
+		It("is safe for concurrent use", func() {
+			source := config.ClientCredentialsTokenSource()
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					_, err := source.Token()
+					Ω(err).ShouldNot(HaveOccurred())
+				}()
+			}
+			wg.Wait()
+			Ω(requests).Should(HaveLen(1))
+		})
+	})
+
+	Context("with a token about to expire", func() {
+		BeforeEach(func() {
+			expiresIn = 5
+		})
+
+		It("refreshes the token using the refresh token", func() {
+			source := config.PasswordTokenSource("user", "pass")
+			Ω(authHeader(source)).Should(Equal("Bearer token1"))
+			Ω(requests[0].PostForm.Get("grant_type")).Should(Equal("password"))
+			Ω(requests[0].PostForm.Get("username")).Should(Equal("user"))
+			Ω(requests[0].PostForm.Get("password")).Should(Equal("pass"))
+
+			Ω(authHeader(source)).Should(Equal("Bearer token2"))
+			Ω(requests).Should(HaveLen(2))
+			Ω(requests[1].PostForm.Get("grant_type")).Should(Equal("refresh_token"))
+			Ω(requests[1].PostForm.Get("refresh_token")).Should(Equal("refresh"))
+		})
+
+		It("honors the expiry delta", func() {
+			config.ExpiryDelta = time.Second
+			source := config.ClientCredentialsTokenSource()
+			authHeader(source)
+			authHeader(source)
+			Ω(requests).Should(HaveLen(1))
+		})
+	})
+
+	Context("with a revoked refresh token", func() {
+		BeforeEach(func() {
+			expiresIn = 5
+		})
+
+		It("falls back to the grant of the token source", func() {
+			source := config.PasswordTokenSource("user", "pass")
+			Ω(authHeader(source)).Should(Equal("Bearer token1"))
+
+			revoked = true
+			Ω(authHeader(source)).Should(Equal("Bearer token3"))
+			Ω(requests).Should(HaveLen(3))
+			Ω(requests[1].PostForm.Get("grant_type")).Should(Equal("refresh_token"))
+			Ω(requests[2].PostForm.Get("grant_type")).Should(Equal("password"))
+		})
+
+		It("returns the error when the token source has no grant", func() {
+			revoked = true
+			source := config.RefreshTokenSource("initial")
+			_, err := source.Token()
+			Ω(err).Should(BeAssignableToTypeOf(&client.OAuth2Error{}))
+			_, err = source.Token()
+			Ω(err).Should(MatchError("oauth2: no refresh token"))
+			Ω(requests).Should(HaveLen(1))
+		})
+	})
+
+	Context("with the refresh token grant", func() {
+		BeforeEach(func() {
+			expiresIn = 5
+			rotate = true
+		})
+
+		It("uses the rotated refresh tokens", func() {
+			source := config.RefreshTokenSource("initial")
+			Ω(authHeader(source)).Should(Equal("Bearer token1"))
+			Ω(authHeader(source)).Should(Equal("Bearer token2"))
+			Ω(requests[0].PostForm.Get("grant_type")).Should(Equal("refresh_token"))
+			Ω(requests[0].PostForm.Get("refresh_token")).Should(Equal("initial"))
+			Ω(requests[1].PostForm.Get("refresh_token")).Should(Equal("refresh1"))
+		})
+	})
+
+	Context("with a hanging token endpoint", func() {
+		BeforeEach(func() {
+			hang = make(chan struct{})
+		})
+
+		It("times out the token request", func() {
+			config.Timeout = 50 * time.Millisecond
+			_, err := config.ClientCredentialsTokenSource().Token()
+			Ω(err).Should(MatchError(ContainSubstring("context deadline exceeded")))
+		})
+
+		It("returns when the context of the caller is done", func() {
+			source := config.ClientCredentialsTokenSource().(client.ContextTokenSource)
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			_, err := source.TokenContext(ctx)
+			Ω(err).Should(Equal(context.DeadlineExceeded))
+
+			// The other callers are not blocked by the pending request.
+			ctx, cancel = context.WithCancel(context.Background())
+			cancel()
+			_, err = source.TokenContext(ctx)
+			Ω(err).Should(Equal(context.Canceled))
+			Eventually(func() int {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(requests)
+			}).Should(Equal(1))
+		})
+
+		It("stops waiting when the request to sign is canceled", func() {
+			signer := &client.OAuth2Signer{TokenSource: config.ClientCredentialsTokenSource()}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			req, _ := http.NewRequest("GET", "/", nil)
+			err := signer.Sign(req.WithContext(ctx))
+			Ω(err).Should(Equal(context.Canceled))
+		})
+	})
+
+	Context("with a token endpoint returning an error", func() {
+		BeforeEach(func() {
+			fail = true
+		})
+
+		It("returns the OAuth2 error", func() {
+			_, err := config.ClientCredentialsTokenSource().Token()
+			Ω(err).Should(HaveOccurred())
+			oerr, ok := err.(*client.OAuth2Error)
+			Ω(ok).Should(BeTrue())
+			Ω(oerr.Status).Should(Equal(400))
+			Ω(oerr.Code).Should(Equal("invalid_grant"))
+			Ω(oerr.Description).Should(Equal("bad credentials"))
+		})
+	})
+})
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PageIterator is implemented by the generated iterators over the pages of results returned
+// by paginated actions.
+type PageIterator interface {
+	// Next makes the request for the next page and returns its response. It returns
+	// io.EOF once all the pages have been retrieved.
+	Next(ctx context.Context) (*http.Response, error)
+}
+
+// NextPageParam returns the value of the query string parameter param in the URL of the next page
+// given by the Link header of resp. It returns an empty string if there is no next page.
+func NextPageParam(resp *http.Response, param string) string {
+	for _, link := range resp.Header["Link"] {
+		for _, l := range strings.Split(link, ",") {
+			parts := strings.Split(l, ";")
+			if !isNextRel(parts[1:]) {
+				continue
+			}
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			u, err := url.Parse(target[1 : len(target)-1])
+			if err != nil {
+				continue
+			}
+			return u.Query().Get(param)
+		}
+	}
+	return ""
+}
+
+// isNextRel returns true if the given link parameters contain the relation type "next".
+func isNextRel(params []string) bool {
+	for _, p := range params {
+		kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+		if len(kv) != 2 || !strings.EqualFold(kv[0], "rel") {
+			continue
+		}
+		for _, rel := range strings.Fields(strings.Trim(kv[1], `"`)) {
+			if strings.EqualFold(rel, "next") {
+				return true
+			}
+		}
+	}
+	return false
+}
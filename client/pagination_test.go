@@ -0,0 +1,52 @@
+package client_test
+
+import (
+	"net/http"
+
+	"github.com/goadesign/goa/client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NextPageParam", func() {
+	var link string
+	var param string
+
+	JustBeforeEach(func() {
+		resp := &http.Response{Header: make(http.Header)}
+		if link != "" {
+			resp.Header.Set("Link", link)
+		}
+		param = client.NextPageParam(resp, "cursor")
+	})
+
+	Context("with a next link", func() {
+		BeforeEach(func() {
+			link = `</bottles?cursor=abc&limit=10>; rel="prev", </bottles?cursor=def&limit=10>; rel="next"`
+		})
+
+		It("returns the parameter of the next page URL", func() {
+			Ω(param).Should(Equal("def"))
+		})
+	})
+
+	Context("without a next link", func() {
+		BeforeEach(func() {
+			link = `</bottles?cursor=abc>; rel="prev"`
+		})
+
+		It("returns an empty string", func() {
+			Ω(param).Should(BeEmpty())
+		})
+	})
+
+	Context("without a Link header", func() {
+		BeforeEach(func() {
+			link = ""
+		})
+
+		It("returns an empty string", func() {
+			Ω(param).Should(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,282 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is the error returned by the circuit breaker Doer when requests to the host are
+// not allowed.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type (
+	// RetryPolicy configures the Doer returned by Retry.
+	RetryPolicy struct {
+		// MaxAttempts is the maximum number of attempts including the first one.
+		MaxAttempts int
+		// MinBackoff is the delay before the first retry, it doubles with each retry.
+		MinBackoff time.Duration
+		// MaxBackoff caps the delay between two attempts, it does not apply to delays given
+		// by Retry-After headers. Zero means no cap.
+		MaxBackoff time.Duration
+		// RetryNonIdempotent enables retrying requests whose method is not idempotent and
+		// that do not carry an Idempotency-Key header.
+		RetryNonIdempotent bool
+		// Statuses lists the response status codes that cause a retry,
+		// DefaultRetryStatuses is used if nil.
+		Statuses []int
+	}
+
+	// CircuitBreaker is a Doer that stops sending requests to a host after a number of
+	// consecutive failures. Once the cooldown elapses a single probe request is let through:
+	// the circuit closes if it succeeds and opens again otherwise. Failures are transport
+	// errors and responses with a 5xx status code.
+	CircuitBreaker struct {
+		// Doer is the wrapped Doer.
+		Doer
+		// Threshold is the number of consecutive failures that opens the circuit.
+		Threshold int
+		// Cooldown is the duration during which the circuit stays open.
+		Cooldown time.Duration
+
+		mu    sync.Mutex
+		hosts map[string]*circuit
+	}
+
+	// circuit is the state of the circuit of a host.
+	circuit struct {
+		failures int
+		openedAt time.Time
+		probing  bool
+	}
+
+	// hostLimiter is a Doer that limits the number of concurrent requests per host.
+	hostLimiter struct {
+		Doer
+		max   int
+		mu    sync.Mutex
+		hosts map[string]chan struct{}
+	}
+)
+
+// DefaultRetryStatuses lists the response status codes retried by default.
+var DefaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// Retry returns a Doer that retries the requests sent with d that fail with a transport error or
+// a retryable status according to p. Retries are delayed using exponential backoff with jitter
+// or by the duration given in the Retry-After response header if any. Requests bodies are
+// rewound using Request.GetBody when set and buffered in memory otherwise.
+func Retry(d Doer, p *RetryPolicy) Doer {
+	return doFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+		if p.MaxAttempts < 2 || (!p.RetryNonIdempotent && !isIdempotent(req)) {
+			return d.Do(ctx, req)
+		}
+		if err := rewindable(req); err != nil {
+			return nil, err
+		}
+		for attempt := 1; ; attempt++ {
+			if attempt > 1 && req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				req.Body = body
+			}
+			resp, err := d.Do(ctx, req)
+			if attempt == p.MaxAttempts || err == ErrCircuitOpen || ctx.Err() != nil {
+				return resp, err
+			}
+			if err == nil && !p.retryable(resp.StatusCode) {
+				return resp, nil
+			}
+			wait := p.backoff(attempt)
+			if err == nil {
+				if after, ok := retryAfter(resp); ok {
+					wait = after
+				}
+				io.Copy(ioutil.Discard, resp.Body)
+				resp.Body.Close()
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+	})
+}
+
+// retryable returns true if responses with the given status code should be retried.
+func (p *RetryPolicy) retryable(status int) bool {
+	statuses := p.Statuses
+	if statuses == nil {
+		statuses = DefaultRetryStatuses
+	}
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before the retry following the given attempt. The delay is picked
+// randomly between half and all of the exponential backoff.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt && (p.MaxBackoff == 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryAfter returns the delay given by the Retry-After header of resp if any.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	h := resp.Header.Get("Retry-After")
+	if h == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(h); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// isIdempotent returns true if the request may be sent more than once, that is if its method is
+// idempotent or if it carries an Idempotency-Key header.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE":
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// rewindable makes sure the body of req can be read more than once by setting GetBody.
+func rewindable(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+	b, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
+
+// NewCircuitBreaker returns a circuit breaker that wraps d, opens the circuit of a host after
+// threshold consecutive failures and keeps it open for cooldown.
+func NewCircuitBreaker(d Doer, threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{Doer: d, Threshold: threshold, Cooldown: cooldown}
+}
+
+// Do sends the request with the wrapped Doer unless the circuit of the request host is open in
+// which case it returns ErrCircuitOpen.
+func (cb *CircuitBreaker) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if cb.Threshold < 1 {
+		return cb.Doer.Do(ctx, req)
+	}
+	c, probe, ok := cb.allow(req.URL.Host)
+	if !ok {
+		return nil, ErrCircuitOpen
+	}
+	resp, err := cb.Doer.Do(ctx, req)
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if probe {
+		c.probing = false
+	}
+	if err != nil || resp.StatusCode >= 500 {
+		c.failures++
+		if probe || c.failures >= cb.Threshold {
+			c.openedAt = time.Now()
+		}
+	} else {
+		c.failures = 0
+		c.openedAt = time.Time{}
+	}
+	return resp, err
+}
+
+// allow returns the circuit of the given host and whether a request may be sent to it. probe is
+// true if the request is the half-open probe.
+func (cb *CircuitBreaker) allow(host string) (c *circuit, probe, ok bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.hosts == nil {
+		cb.hosts = make(map[string]*circuit)
+	}
+	c, found := cb.hosts[host]
+	if !found {
+		c = &circuit{}
+		cb.hosts[host] = c
+	}
+	if c.openedAt.IsZero() {
+		return c, false, true
+	}
+	if c.probing || time.Since(c.openedAt) < cb.Cooldown {
+		return c, false, false
+	}
+	c.probing = true
+	return c, true, true
+}
+
+// LimitHost returns a Doer that sends at most max concurrent requests per host with d. Requests
+// over the limit wait for a slot until the context is done. A slot is released as soon as the
+// response headers are received.
+func LimitHost(d Doer, max int) Doer {
+	if max < 1 {
+		return d
+	}
+	return &hostLimiter{Doer: d, max: max, hosts: make(map[string]chan struct{})}
+}
+
+// Do sends the request once a slot for its host is available.
+func (l *hostLimiter) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	l.mu.Lock()
+	sem, ok := l.hosts[req.URL.Host]
+	if !ok {
+		sem = make(chan struct{}, l.max)
+		l.hosts[req.URL.Host] = sem
+	}
+	l.mu.Unlock()
+
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-sem }()
+	return l.Doer.Do(ctx, req)
+}
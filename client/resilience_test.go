@@ -0,0 +1,227 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// doerFunc implements client.Doer with a function.
+type doerFunc func(context.Context, *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return f(ctx, req)
+}
+
+// response builds a response with the given status and headers.
+func response(status int, headers ...string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(""))}
+	for i := 0; i < len(headers); i += 2 {
+		resp.Header.Set(headers[i], headers[i+1])
+	}
+	return resp
+}
+
+var _ = Describe("Retry", func() {
+	var (
+		statuses []int
+		bodies   []string
+		doer     client.Doer
+		policy   *client.RetryPolicy
+		req      *http.Request
+		resp     *http.Response
+		err      error
+		elapsed  time.Duration
+	)
+
+	BeforeEach(func() {
+		statuses = []int{503, 503, 200}
+		bodies = nil
+		policy = &client.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}
+		req, _ = http.NewRequest("GET", "http://example.com/", nil)
+		doer = doerFunc(func(_ context.Context, r *http.Request) (*http.Response, error) {
+			if r.Body != nil {
+				b, _ := ioutil.ReadAll(r.Body)
+				bodies = append(bodies, string(b))
+			}
+			status := statuses[0]
+			statuses = statuses[1:]
+			return response(status), nil
+		})
+	})
+
+	JustBeforeEach(func() {
+		start := time.Now()
+		resp, err = client.Retry(doer, policy).Do(context.Background(), req)
+		elapsed = time.Since(start)
+	})
+
+	It("retries until success", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(200))
+		Ω(statuses).Should(BeEmpty())
+	})
+
+	Context("with too few attempts", func() {
+		BeforeEach(func() {
+			policy.MaxAttempts = 2
+		})
+
+		It("returns the last response", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(resp.StatusCode).Should(Equal(503))
+			Ω(statuses).Should(HaveLen(1))
+		})
+	})
+
+	Context("with a non idempotent request", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("POST", "http://example.com/", ioutil.NopCloser(strings.NewReader("body")))
+		})
+
+		It("does not retry", func() {
+			Ω(resp.StatusCode).Should(Equal(503))
+			Ω(bodies).Should(Equal([]string{"body"}))
+		})
+
+		Context("with an Idempotency-Key header", func() {
+			BeforeEach(func() {
+				req.Header.Set("Idempotency-Key", "key")
+			})
+
+			It("retries and rewinds the body", func() {
+				Ω(resp.StatusCode).Should(Equal(200))
+				Ω(bodies).Should(Equal([]string{"body", "body", "body"}))
+			})
+		})
+
+		Context("with retries of non idempotent requests enabled", func() {
+			BeforeEach(func() {
+				policy.RetryNonIdempotent = true
+			})
+
+			It("retries", func() {
+				Ω(resp.StatusCode).Should(Equal(200))
+				Ω(bodies).Should(HaveLen(3))
+			})
+		})
+	})
+
+	Context("with a Retry-After header", func() {
+		BeforeEach(func() {
+			policy.MaxAttempts = 2
+			calls := 0
+			doer = doerFunc(func(context.Context, *http.Request) (*http.Response, error) {
+				calls++
+				if calls == 1 {
+					return response(429, "Retry-After", "1"), nil
+				}
+				return response(200), nil
+			})
+		})
+
+		It("waits for the given delay", func() {
+			Ω(resp.StatusCode).Should(Equal(200))
+			Ω(elapsed).Should(BeNumerically(">=", time.Second))
+		})
+	})
+})
+
+var _ = Describe("CircuitBreaker", func() {
+	var (
+		fail    bool
+		calls   int
+		breaker *client.CircuitBreaker
+		req     *http.Request
+	)
+
+	BeforeEach(func() {
+		fail = true
+		calls = 0
+		breaker = client.NewCircuitBreaker(doerFunc(func(context.Context, *http.Request) (*http.Response, error) {
+			calls++
+			if fail {
+				return nil, errors.New("boom")
+			}
+			return response(200), nil
+		}), 2, 50*time.Millisecond)
+		req, _ = http.NewRequest("GET", "http://example.com/", nil)
+	})
+
+	It("opens after consecutive failures and probes once the cooldown elapses", func() {
+		for i := 0; i < 2; i++ {
+			_, err := breaker.Do(context.Background(), req)
+			Ω(err).Should(MatchError("boom"))
+		}
+		_, err := breaker.Do(context.Background(), req)
+		Ω(err).Should(Equal(client.ErrCircuitOpen))
+		Ω(calls).Should(Equal(2))
+
+		time.Sleep(60 * time.Millisecond)
+		_, err = breaker.Do(context.Background(), req)
+		Ω(err).Should(MatchError("boom"))
+		_, err = breaker.Do(context.Background(), req)
+		Ω(err).Should(Equal(client.ErrCircuitOpen))
+
+		fail = false
+		time.Sleep(60 * time.Millisecond)
+		resp, err := breaker.Do(context.Background(), req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(200))
+		_, err = breaker.Do(context.Background(), req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(calls).Should(Equal(5))
+	})
+
+	It("keeps the circuits of other hosts closed", func() {
+		for i := 0; i < 2; i++ {
+			breaker.Do(context.Background(), req)
+		}
+		fail = false
+		other, _ := http.NewRequest("GET", "http://other.com/", nil)
+		_, err := breaker.Do(context.Background(), other)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+})
+
+var _ = Describe("LimitHost", func() {
+	It("limits the number of concurrent requests per host", func() {
+		var current, peak int32
+		doer := client.LimitHost(doerFunc(func(context.Context, *http.Request) (*http.Response, error) {
+			n := atomic.AddInt32(&current, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&current, -1)
+			return response(200), nil
+		}), 2)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				req, _ := http.NewRequest("GET", "http://example.com/", nil)
+				_, err := doer.Do(context.Background(), req)
+				Ω(err).ShouldNot(HaveOccurred())
+			}()
+		}
+		wg.Wait()
+		Ω(atomic.LoadInt32(&peak)).Should(Equal(int32(2)))
+	})
+})
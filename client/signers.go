@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
@@ -67,6 +68,14 @@ type (
 		Token() (Token, error)
 	}
 
+	// A ContextTokenSource is a TokenSource that may stop waiting for a token when a context
+	// is done. The signers use the request context with the token sources that implement it.
+	ContextTokenSource interface {
+		TokenSource
+		// TokenContext returns a token or an error, ctx.Err() if ctx is done first.
+		TokenContext(ctx context.Context) (Token, error)
+	}
+
 	// StaticTokenSource implements a token source that always returns the same token.
 	StaticTokenSource struct {
 		StaticToken *StaticToken
@@ -122,7 +131,15 @@ func (s *OAuth2Signer) Sign(req *http.Request) error {
 
 // signFromSource generates a token using the given source and uses it to sign the request.
 func signFromSource(source TokenSource, req *http.Request) error {
-	token, err := source.Token()
+	var (
+		token Token
+		err   error
+	)
+	if cs, ok := source.(ContextTokenSource); ok {
+		token, err = cs.TokenContext(req.Context())
+	} else {
+		token, err = source.Token()
+	}
 	if err != nil {
 		return err
 	}
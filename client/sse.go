@@ -0,0 +1,104 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// Event is a server-sent event read from an event stream.
+	Event struct {
+		// ID is the ID of the event or of the last event that had one.
+		ID string
+		// Event is the event type, "message" if the server did not specify one.
+		Event string
+		// Data is the event data.
+		Data []byte
+		// Retry is the reconnection time requested by the server if any.
+		Retry time.Duration
+	}
+
+	// EventReader reads the server-sent events of a response body. See
+	// https://html.spec.whatwg.org/multipage/server-sent-events.html for details on the
+	// protocol.
+	EventReader struct {
+		body        io.ReadCloser
+		r           *bufio.Reader
+		lastEventID string
+	}
+)
+
+// NewEventReader returns a reader that reads the events from the given response body.
+func NewEventReader(body io.ReadCloser) *EventReader {
+	return &EventReader{body: body, r: bufio.NewReader(body)}
+}
+
+// Next reads and returns the next event. It returns io.EOF once the server closes the stream, any
+// incomplete event is discarded.
+func (r *EventReader) Next() (*Event, error) {
+	var (
+		e       Event
+		data    bytes.Buffer
+		hasData bool
+	)
+	for {
+		line, err := r.r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return nil, err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if line == "" {
+			if !hasData {
+				// Nothing to dispatch, keep the reconnection time for the next event.
+				e = Event{Retry: e.Retry}
+				continue
+			}
+			e.ID = r.lastEventID
+			if e.Event == "" {
+				e.Event = "message"
+			}
+			e.Data = data.Bytes()
+			return &e, nil
+		}
+		if line[0] == ':' {
+			continue // comment
+		}
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "event":
+			e.Event = value
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				r.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+				e.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// LastEventID returns the ID of the last event read. Clients should send it in the Last-Event-ID
+// header when reconnecting.
+func (r *EventReader) LastEventID() string {
+	return r.lastEventID
+}
+
+// Close closes the underlying response body.
+func (r *EventReader) Close() error {
+	return r.body.Close()
+}
@@ -0,0 +1,47 @@
+package client_test
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EventReader", func() {
+	var body string
+	var reader *client.EventReader
+
+	JustBeforeEach(func() {
+		reader = client.NewEventReader(ioutil.NopCloser(strings.NewReader(body)))
+	})
+
+	Context("with a stream of events", func() {
+		BeforeEach(func() {
+			body = ": comment\n\nid: 1\nevent: update\nretry: 1500\ndata: foo\ndata:bar\n\r\ndata: baz\n\nid: 3\ndata: incomplete"
+		})
+
+		It("reads the events", func() {
+			e, err := reader.Next()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(e.ID).To(Equal("1"))
+			Expect(e.Event).To(Equal("update"))
+			Expect(e.Retry).To(Equal(1500 * time.Millisecond))
+			Expect(string(e.Data)).To(Equal("foo\nbar"))
+
+			e, err = reader.Next()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(e.ID).To(Equal("1"))
+			Expect(e.Event).To(Equal("message"))
+			Expect(string(e.Data)).To(Equal("baz"))
+
+			_, err = reader.Next()
+			Expect(err).To(Equal(io.EOF))
+			Expect(reader.LastEventID()).To(Equal("3"))
+		})
+	})
+})
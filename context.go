@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"context"
 )
@@ -168,3 +170,39 @@ func (r *ResponseData) Write(b []byte) (int, error) {
 	r.Length += len(b)
 	return r.ResponseWriter.Write(b)
 }
+
+// SetETag sets the ETag header of the response to the strong entity tag built from the given
+// opaque value. The value is quoted if needed.
+func (r *ResponseData) SetETag(tag string) {
+	r.Header().Set("ETag", quoteETag(tag))
+}
+
+// SetWeakETag sets the ETag header of the response to the weak entity tag built from the given
+// opaque value. Weak entity tags indicate that representations are semantically equivalent but
+// not byte for byte identical.
+func (r *ResponseData) SetWeakETag(tag string) {
+	r.Header().Set("ETag", "W/"+quoteETag(tag))
+}
+
+// SetLastModified sets the Last-Modified header of the response to the given time.
+func (r *ResponseData) SetLastModified(t time.Time) {
+	r.Header().Set("Last-Modified", t.UTC().Format(http.TimeFormat))
+}
+
+// SetNextPage sets the Link response header to the URL of the next page of results with relation
+// type "next". The URL is the URL of req with the query string parameter param set to value.
+func (r *ResponseData) SetNextPage(req *http.Request, param, value string) {
+	u := *req.URL
+	q := u.Query()
+	q.Set(param, value)
+	u.RawQuery = q.Encode()
+	r.Header().Set("Link", "<"+u.RequestURI()+">; rel=\"next\"")
+}
+
+// quoteETag returns the given entity tag value surrounded with double quotes unless it already is.
+func quoteETag(tag string) string {
+	if len(tag) >= 2 && strings.HasPrefix(tag, `"`) && strings.HasSuffix(tag, `"`) {
+		return tag
+	}
+	return `"` + tag + `"`
+}
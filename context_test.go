@@ -3,6 +3,7 @@ package goa_test
 import (
 	"net/http"
 	"net/url"
+	"time"
 
 	"context"
 
@@ -21,7 +22,7 @@ var _ = Describe("ResponseData", func() {
 		var err error
 		req, err = http.NewRequest("GET", "google.com", nil)
 		Ω(err).ShouldNot(HaveOccurred())
-		rw = &TestResponseWriter{Status: 42}
+		rw = &TestResponseWriter{Status: 42, ParentHeader: make(http.Header)}
 		params = url.Values{"query": []string{"value"}}
 		ctx := goa.NewContext(context.Background(), rw, req, params)
 		data = goa.ContextResponse(ctx)
@@ -55,4 +56,36 @@ var _ = Describe("ResponseData", func() {
 			Ω(data.Status).Should(Equal(status))
 		})
 	})
+
+	Context("SetETag", func() {
+		It("sets a quoted strong entity tag", func() {
+			data.SetETag("xyz")
+			Ω(rw.Header().Get("ETag")).Should(Equal(`"xyz"`))
+			data.SetETag(`"abc"`)
+			Ω(rw.Header().Get("ETag")).Should(Equal(`"abc"`))
+		})
+	})
+
+	Context("SetWeakETag", func() {
+		It("sets a weak entity tag", func() {
+			data.SetWeakETag("xyz")
+			Ω(rw.Header().Get("ETag")).Should(Equal(`W/"xyz"`))
+		})
+	})
+
+	Context("SetLastModified", func() {
+		It("sets the header using the HTTP date format", func() {
+			t := time.Date(2017, 3, 1, 10, 30, 0, 0, time.FixedZone("PST", -8*3600))
+			data.SetLastModified(t)
+			Ω(rw.Header().Get("Last-Modified")).Should(Equal("Wed, 01 Mar 2017 18:30:00 GMT"))
+		})
+	})
+
+	Context("SetNextPage", func() {
+		It("sets the Link header to the URL of the next page", func() {
+			req, _ := http.NewRequest("GET", "http://example.com/bottles?limit=10&cursor=a", nil)
+			data.SetNextPage(req, "cursor", "b c")
+			Ω(rw.Header().Get("Link")).Should(Equal(`</bottles?cursor=b+c&limit=10>; rel="next"`))
+		})
+	})
 })
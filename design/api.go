@@ -185,6 +185,70 @@ var (
 		AttributeDefinition: &AttributeDefinition{Type: errorMediaType},
 		Name:                "default",
 	}
+
+	// ProblemMediaIdentifier is the media type identifier used for RFC 7807 problem details
+	// error responses.
+	ProblemMediaIdentifier = "application/problem+json"
+
+	// ProblemMedia is the built-in media type for RFC 7807 problem details error responses.
+	// Use it in place of ErrorMedia in Response for services that use goa.ProblemErrorFormatter.
+	ProblemMedia = &MediaTypeDefinition{
+		UserTypeDefinition: &UserTypeDefinition{
+			AttributeDefinition: &AttributeDefinition{
+				Type:        problemMediaType,
+				Description: "RFC 7807 problem details error response media type",
+				Example: map[string]interface{}{
+					"type":     "about:blank",
+					"title":    "Bad Request",
+					"status":   400,
+					"detail":   "Value of ID must be an integer",
+					"instance": "3F1FKVRR",
+					"code":     "invalid_value",
+				},
+			},
+			TypeName: "problem",
+		},
+		Identifier: ProblemMediaIdentifier,
+		Views:      map[string]*ViewDefinition{"default": problemMediaView},
+	}
+
+	problemMediaType = Object{
+		"type": &AttributeDefinition{
+			Type:        String,
+			Description: "a URI reference that identifies the problem type.",
+			Example:     "about:blank",
+		},
+		"title": &AttributeDefinition{
+			Type:        String,
+			Description: "a short, human-readable summary of the problem type.",
+			Example:     "Bad Request",
+		},
+		"status": &AttributeDefinition{
+			Type:        Integer,
+			Description: "the HTTP status code applicable to this problem.",
+			Example:     400,
+		},
+		"detail": &AttributeDefinition{
+			Type:        String,
+			Description: "a human-readable explanation specific to this occurrence of the problem.",
+			Example:     "Value of ID must be an integer",
+		},
+		"instance": &AttributeDefinition{
+			Type:        String,
+			Description: "a URI reference that identifies the specific occurrence of the problem.",
+			Example:     "3F1FKVRR",
+		},
+		"code": &AttributeDefinition{
+			Type:        String,
+			Description: "an application-specific error code, expressed as a string value.",
+			Example:     "invalid_value",
+		},
+	}
+
+	problemMediaView = &ViewDefinition{
+		AttributeDefinition: &AttributeDefinition{Type: problemMediaType},
+		Name:                "default",
+	}
 )
 
 func init() {
@@ -200,6 +264,7 @@ func init() {
 		{MIMETypes: GobContentTypes, PackagePath: goa, Function: "NewGobDecoder"},
 	}
 	errorMediaView.Parent = ErrorMedia
+	problemMediaView.Parent = ProblemMedia
 }
 
 // CanonicalIdentifier returns the media type identifier sans suffix
@@ -418,6 +418,115 @@ func OptionalPayload(p interface{}, dsls ...func()) {
 	payload(true, p, dsls...)
 }
 
+// MultipartForm can be used in: Action
+//
+// MultipartForm implements the action multipart form DSL. An action multipart form indicates that
+// the HTTP requests made by the user agent initiating the action must use the multipart form
+// encoding ("multipart/form-data"). Each payload attribute is encoded as a form field, attributes
+// of type File are encoded as file parts. The payload must be an object whose attributes are
+// primitives or arrays of primitives. Example:
+//
+//	Action("upload", func() {
+//		Routing(POST("/upload"))
+//		MultipartForm()
+//		Payload(func() {
+//			Member("file", File, "File to upload")
+//			Member("description", String)
+//			Required("file")
+//		})
+//		Response(NoContent)
+//	})
+//
+func MultipartForm() {
+	if a, ok := actionDefinition(); ok {
+		a.PayloadMultipart = true
+	}
+}
+
+// ServerSentEvents can be used in: Action
+//
+// ServerSentEvents indicates that the action streams server-sent events to the client (see
+// https://html.spec.whatwg.org/multipage/server-sent-events.html). The function accepts the type of
+// the event data, a DSL that describes it or both using the same syntax as Payload. Each event
+// data is encoded as JSON, inline event types are named after the action and resource, e.g.
+// "WatchBottleEvent". The action OK response defaults to a response with the
+// "text/event-stream" media type. Example:
+//
+//	Action("watch", func() {
+//		Routing(GET("/:id/watch"))
+//		ServerSentEvents(BottleEvent)	// Each event data is a BottleEvent
+//	})
+//
+// The generated action context exposes a Stream method that writes the response headers and
+// returns the stream used to send the events, the generated client exposes a method that returns
+// an event reader.
+func ServerSentEvents(t interface{}, dsls ...func()) {
+	if len(dsls) > 1 {
+		dslengine.ReportError("too many arguments given to ServerSentEvents")
+		return
+	}
+	if a, ok := actionDefinition(); ok {
+		var att *design.AttributeDefinition
+		var dsl func()
+		switch actual := t.(type) {
+		case func():
+			dsl = actual
+			att = &design.AttributeDefinition{Type: design.Object{}}
+		case *design.AttributeDefinition:
+			att = design.DupAtt(actual)
+		case *design.UserTypeDefinition:
+			if len(dsls) == 0 {
+				att = &design.AttributeDefinition{Type: actual}
+				break
+			}
+			att = design.DupAtt(actual.Definition())
+		case *design.MediaTypeDefinition:
+			if len(dsls) == 0 {
+				att = &design.AttributeDefinition{Type: actual}
+				break
+			}
+			att = design.DupAtt(actual.AttributeDefinition)
+		case string:
+			ut, ok := design.Design.Types[actual]
+			if !ok {
+				dslengine.ReportError("unknown event type %s", actual)
+				return
+			}
+			att = &design.AttributeDefinition{Type: ut}
+		case design.DataType:
+			att = &design.AttributeDefinition{Type: actual}
+		default:
+			dslengine.ReportError("invalid ServerSentEvents argument, must be a type, a media type or a DSL building a type")
+			return
+		}
+		if len(dsls) == 1 {
+			if dsl != nil {
+				dslengine.ReportError("invalid arguments in ServerSentEvents call, must be (type), (dsl) or (type, dsl)")
+			}
+			dsl = dsls[0]
+		}
+		if dsl != nil {
+			dslengine.Execute(dsl, att)
+		}
+		if _, ok := att.Type.(design.Object); ok {
+			// Inline event types are added to the API types so that code is generated
+			// for them.
+			name := fmt.Sprintf("%s%sEvent", camelize(a.Name), camelize(a.Parent.Name))
+			if _, ok := design.Design.Types[name]; ok {
+				dslengine.ReportError("event type %s conflicts with an existing type", name)
+				return
+			}
+			ut := &design.UserTypeDefinition{AttributeDefinition: att, TypeName: name}
+			if design.Design.Types == nil {
+				design.Design.Types = make(map[string]*design.UserTypeDefinition)
+			}
+			design.Design.Types[name] = ut
+			att = &design.AttributeDefinition{Type: ut}
+		}
+		a.EventStream = att
+	}
+}
+
 func payload(isOptional bool, p interface{}, dsls ...func()) {
 	if len(dsls) > 1 {
 		dslengine.ReportError("too many arguments given to Payload")
@@ -112,6 +112,121 @@ var _ = Describe("Action", func() {
 		})
 	})
 
+	Context("with a multipart payload", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(POST("/upload"))
+				MultipartForm()
+				Payload(func() {
+					Member("file", File)
+					Member("description", String)
+					Required("file")
+				})
+			}
+		})
+
+		It("produces a valid multipart action", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action).ShouldNot(BeNil())
+			Ω(action.Validate()).ShouldNot(HaveOccurred())
+			Ω(action.PayloadMultipart).Should(BeTrue())
+			Ω(action.Payload.ToObject()).Should(HaveKey("file"))
+			Ω(action.Payload.ToObject()["file"].Type).Should(Equal(File))
+		})
+	})
+
+	Context("with a file payload attribute and no multipart form", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(POST("/upload"))
+				Payload(func() {
+					Member("file", File)
+				})
+			}
+		})
+
+		It("produces an invalid action", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a multipart payload containing an object", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(POST("/upload"))
+				MultipartForm()
+				Payload(func() {
+					Member("file", File)
+					Member("meta", func() {
+						Attribute("name")
+					})
+				})
+			}
+		})
+
+		It("produces an invalid action", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with server-sent events", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(GET("/events"))
+				ServerSentEvents(func() {
+					Attribute("id", Integer)
+					Attribute("name")
+					Required("id")
+				})
+			}
+		})
+
+		It("produces a valid action with an event stream response", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action).ShouldNot(BeNil())
+			Ω(action.Validate()).ShouldNot(HaveOccurred())
+			Ω(action.ServerSentEvents()).Should(BeTrue())
+			Ω(action.EventStream.Type.ToObject()).Should(HaveKey("id"))
+			Ω(Design.Types).Should(HaveKey("FooResEvent"))
+			Ω(action.EventStream.Type).Should(Equal(Design.Types["FooResEvent"]))
+			Ω(action.Responses).Should(HaveKey(OK))
+			Ω(action.Responses[OK].MediaType).Should(Equal("text/event-stream"))
+		})
+	})
+
+	Context("with server-sent events and a conflicting OK response", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(GET("/events"))
+				ServerSentEvents(String)
+				Response(OK, "application/json")
+			}
+		})
+
+		It("produces an invalid action", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with server-sent events containing a file", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(GET("/events"))
+				ServerSentEvents(File)
+			}
+		})
+
+		It("produces an invalid action", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
 	Context("with a name and DSL defining a description, route, headers, payload and responses", func() {
 		const typeName = "typeName"
 		const description = "description"
@@ -64,6 +64,7 @@ import (
 //			MediaType(arg2)
 //		})
 //              NoExample()                             // Prevent automatic generation of examples
+//		ErrorMediaType(ProblemMedia)		// Use RFC 7807 problem details for errors
 //		Trait("Authenticated", func() {		// Traits define DSL that can be run anywhere
 //			Headers(func() {
 //				Header("header")
@@ -248,6 +249,33 @@ func TermsOfService(terms string) {
 	}
 }
 
+// ErrorMediaType can be used in: API
+//
+// ErrorMediaType sets the media type of the errors returned by the API. The value must be one of
+// the built-in ErrorMedia (application/vnd.goa.error, the default) or ProblemMedia
+// (application/problem+json, RFC 7807 problem details). Generators use it to describe the error
+// responses and the generated main configures the service error formatter accordingly. Example:
+//
+//	API("cellar", func() {
+//		ErrorMediaType(ProblemMedia)
+//	})
+//
+// Error responses using the other built-in media type are rewritten to use it, so that
+//
+//	Response(BadRequest, ErrorMedia)
+//
+// is described as returning application/problem+json in the example above.
+//
+func ErrorMediaType(mt *design.MediaTypeDefinition) {
+	if mt != design.ErrorMedia && mt != design.ProblemMedia {
+		dslengine.ReportError("invalid error media type, must be ErrorMedia or ProblemMedia")
+		return
+	}
+	if a, ok := apiDefinition(); ok {
+		a.ErrorMediaType = mt
+	}
+}
+
 // Regular expression used to validate RFC1035 hostnames*/
 var hostnameRegex = regexp.MustCompile(`^[[:alnum:]][[:alnum:]\-]{0,61}[[:alnum:]]|[[:alpha:]]$`)
 
@@ -118,6 +118,45 @@ var _ = Describe("API", func() {
 			})
 		})
 
+		Context("with an error media type", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					ErrorMediaType(ProblemMedia)
+				}
+			})
+
+			It("sets the API error media type", func() {
+				Ω(Design.ErrorMediaType).Should(Equal(ProblemMedia))
+				Ω(Design.ErrorMedia()).Should(Equal(ProblemMedia))
+			})
+
+			It("records the error media type", func() {
+				Ω(Design.MediaTypes).Should(HaveKey(CanonicalIdentifier(ProblemMediaIdentifier)))
+			})
+
+			Context("and actions returning ErrorMedia", func() {
+				BeforeEach(func() {
+					Resource("bottle", func() {
+						Response(NotFound, ErrorMedia)
+						Action("show", func() {
+							Routing(GET("/:id"))
+							Response(BadRequest, ErrorMedia)
+						})
+					})
+				})
+
+				It("uses the error media type in the responses", func() {
+					Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+					r := Design.Resources["bottle"]
+					Ω(r.Responses[NotFound].MediaType).Should(Equal(ProblemMediaIdentifier))
+					resp := r.Actions["show"].Responses[BadRequest]
+					Ω(resp.MediaType).Should(Equal(ProblemMediaIdentifier))
+					Ω(resp.Type).Should(Equal(ProblemMedia))
+					Ω(Design.MediaTypes).ShouldNot(HaveKey(CanonicalIdentifier(ErrorMediaIdentifier)))
+				})
+			})
+		})
+
 		Context("with contact information", func() {
 			const contactName = "contactName"
 			const contactEmail = "contactEmail"
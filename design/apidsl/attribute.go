@@ -11,7 +11,7 @@ import (
 	"github.com/goadesign/goa/dslengine"
 )
 
-// Attribute can be used in: View, Type, Attribute, Attributes
+// Attribute can be used in: View, Type, Attribute, Attributes, OneOf
 //
 // Attribute implements the attribute definition DSL. An attribute describes a data structure
 // recursively. Attributes are used for describing request headers, parameters and payloads -
@@ -99,6 +99,9 @@ func Attribute(name string, args ...interface{}) {
 			def.Params = new(design.AttributeDefinition)
 		}
 		parent = def.Params
+	case *design.Union:
+		unionValue(def, name, args...)
+		return
 	default:
 		dslengine.IncompatibleDSL()
 	}
@@ -139,6 +142,22 @@ func Attribute(name string, args ...interface{}) {
 	}
 }
 
+// unionValue adds a value to the union being defined with OneOf.
+func unionValue(u *design.Union, name string, args ...interface{}) {
+	dataType, description, dsl := parseAttributeArgs(nil, args...)
+	att := &design.AttributeDefinition{
+		Type:        dataType,
+		Description: description,
+	}
+	if dsl != nil {
+		dslengine.Execute(dsl, att)
+	}
+	if att.Type == nil {
+		att.Type = design.String
+	}
+	u.Values = append(u.Values, &design.UnionValue{Name: name, AttributeDefinition: att})
+}
+
 // attributeFromRef returns a base attribute given a reference data type.
 // It takes care of running the DSL on the reference type if it hasn't run yet.
 func attributeFromRef(name string, ref design.DataType) *design.AttributeDefinition {
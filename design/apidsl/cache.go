@@ -0,0 +1,45 @@
+package apidsl
+
+// Cacheable can be used in: Action
+//
+// Cacheable indicates that the action responses carry validators (ETag and/or Last-Modified
+// headers) that clients may use to revalidate cached representations. Cacheable adds the optional
+// If-None-Match and If-Modified-Since headers to the action and the NotModified (304) response.
+// The validators are set by the action implementation using the SetETag and SetLastModified
+// methods of goa.ResponseData and the conditional requests are answered by the
+// middleware/conditional package.
+// Example:
+//
+//	Action("show", func() {
+//		Routing(GET("/:id"))
+//		Cacheable()
+//		Response(OK, BottleMedia)
+//	})
+//
+func Cacheable() {
+	if a, ok := actionDefinition(); ok {
+		a.Cacheable = true
+	}
+}
+
+// ETag can be used in: Action
+//
+// ETag indicates that the action responses carry an entity tag. Actions that modify the resource
+// (i.e. use a method other than GET, HEAD, OPTIONS or TRACE) accept the optional If-Match and
+// If-Unmodified-Since headers and respond with PreconditionFailed (412) when the preconditions
+// do not hold, making it possible for clients to avoid lost updates. ETag adds the headers and
+// the response to the action.
+// Example:
+//
+//	Action("update", func() {
+//		Routing(PUT("/:id"))
+//		ETag()
+//		Payload(BottlePayload)
+//		Response(NoContent)
+//	})
+//
+func ETag() {
+	if a, ok := actionDefinition(); ok {
+		a.ETag = true
+	}
+}
@@ -0,0 +1,81 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cacheable", func() {
+	var action *ActionDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		API("test", func() {})
+		Resource("res", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Cacheable()
+				Response(OK)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		action = Design.Resources["res"].Actions["show"]
+	})
+
+	It("adds the conditional headers", func() {
+		Ω(action.Cacheable).Should(BeTrue())
+		headers := action.Headers.Type.ToObject()
+		Ω(headers).Should(HaveKey("If-None-Match"))
+		Ω(headers).Should(HaveKey("If-Modified-Since"))
+		Ω(headers).ShouldNot(HaveKey("If-Match"))
+		Ω(action.Headers.IsRequired("If-None-Match")).Should(BeFalse())
+	})
+
+	It("adds the NotModified response", func() {
+		Ω(action.Responses).Should(HaveKey(NotModified))
+		Ω(action.Responses[NotModified].Status).Should(Equal(304))
+	})
+})
+
+var _ = Describe("ETag", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+		API("test", func() {})
+		Resource("res", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				ETag()
+				Response(OK)
+			})
+			Action("update", func() {
+				Routing(PUT("/:id"))
+				ETag()
+				Headers(func() {
+					Header("If-Match", String, "Custom description")
+				})
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	})
+
+	It("does not add preconditions to safe actions", func() {
+		show := Design.Resources["res"].Actions["show"]
+		Ω(show.ETag).Should(BeTrue())
+		Ω(show.Headers).Should(BeNil())
+		Ω(show.Responses).ShouldNot(HaveKey(PreconditionFailed))
+	})
+
+	It("adds the preconditions to unsafe actions", func() {
+		update := Design.Resources["res"].Actions["update"]
+		headers := update.Headers.Type.ToObject()
+		Ω(headers).Should(HaveKey("If-Unmodified-Since"))
+		Ω(headers["If-Match"].Description).Should(Equal("Custom description"))
+		Ω(update.Responses).Should(HaveKey(PreconditionFailed))
+		Ω(update.Responses[PreconditionFailed].Status).Should(Equal(412))
+		Ω(update.Responses[PreconditionFailed].MediaType).Should(Equal(ErrorMedia.Identifier))
+	})
+})
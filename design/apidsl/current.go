@@ -131,3 +131,13 @@ func responseDefinition() (*design.ResponseDefinition, bool) {
 	}
 	return r, ok
 }
+
+// paginationDefinition returns true and current context if it is a PaginationDefinition,
+// nil and false otherwise.
+func paginationDefinition() (*design.PaginationDefinition, bool) {
+	p, ok := dslengine.CurrentDefinition().(*design.PaginationDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return p, ok
+}
@@ -0,0 +1,26 @@
+package apidsl
+
+// Idempotent can be used in: Action
+//
+// Idempotent indicates that clients may safely retry the requests made to the action by sending
+// the same Idempotency-Key header: repeated requests are not handled again and get the response
+// of the first request. Idempotent adds the optional Idempotency-Key header as well as the
+// Conflict (409) response returned while the first request is being handled and the
+// UnprocessableEntity (422) response returned when a key is reused with a different request to
+// actions that use methods other than GET, HEAD, OPTIONS or TRACE. The generated code runs the
+// middleware mounted with UseIdempotencyMiddleware, e.g. the middleware/idempotency package, for
+// the idempotent actions.
+// Example:
+//
+//	Action("create", func() {
+//		Routing(POST(""))
+//		Idempotent()
+//		Payload(OrderPayload)
+//		Response(Created)
+//	})
+//
+func Idempotent() {
+	if a, ok := actionDefinition(); ok {
+		a.Idempotent = true
+	}
+}
@@ -0,0 +1,46 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Idempotent", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+		API("test", func() {})
+		Resource("res", func() {
+			Action("create", func() {
+				Routing(POST(""))
+				Idempotent()
+				Response(Created)
+			})
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Idempotent()
+				Response(OK)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	})
+
+	It("adds the Idempotency-Key header and the error responses", func() {
+		create := Design.Resources["res"].Actions["create"]
+		Ω(create.Idempotent).Should(BeTrue())
+		header := create.Headers.Type.ToObject()["Idempotency-Key"]
+		Ω(header).ShouldNot(BeNil())
+		Ω(*header.Validation.MaxLength).Should(Equal(255))
+		Ω(create.Headers.IsRequired("Idempotency-Key")).Should(BeFalse())
+		Ω(create.Responses[Conflict].Status).Should(Equal(409))
+		Ω(create.Responses[UnprocessableEntity].Status).Should(Equal(422))
+	})
+
+	It("leaves safe actions untouched", func() {
+		show := Design.Resources["res"].Actions["show"]
+		Ω(show.Headers).Should(BeNil())
+		Ω(show.Responses).ShouldNot(HaveKey(Conflict))
+	})
+})
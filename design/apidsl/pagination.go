@@ -0,0 +1,115 @@
+package apidsl
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+const (
+	// defaultPageSize is the default page size of paginated actions.
+	defaultPageSize = 20
+	// defaultMaxPageSize is the default maximum page size of paginated actions.
+	defaultMaxPageSize = 100
+)
+
+// Paginated can be used in: Action
+//
+// Paginated indicates that the action splits its results into pages. The optional DSL uses Cursor
+// or Offset to define how pages are identified, cursor pagination is used by default, and
+// PageSize to define the default and maximum number of results per page (20 and 100 by
+// default). Paginated adds the page and the "limit" page size query string parameters to the
+// action as well as the Link and X-Next-Cursor headers to its OK response. The generated context
+// exposes a SetNextPage method used to set these headers, the generated client exposes an
+// iterator that follows the pages and the generated CLI an --all flag.
+// Example:
+//
+//	Action("list", func() {
+//		Routing(GET(""))
+//		Paginated(func() {
+//			Cursor("cursor")
+//			PageSize(20, 100)
+//		})
+//		Response(OK, CollectionOf(BottleMedia))
+//	})
+//
+func Paginated(dsl ...func()) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	if len(dsl) > 1 {
+		dslengine.ReportError("too many arguments given to Paginated")
+		return
+	}
+	p := &design.PaginationDefinition{
+		Parent:      a,
+		SizeParam:   "limit",
+		DefaultSize: defaultPageSize,
+		MaxSize:     defaultMaxPageSize,
+	}
+	if len(dsl) == 1 {
+		if !dslengine.Execute(dsl[0], p) {
+			return
+		}
+	}
+	if p.CursorParam == "" && p.OffsetParam == "" {
+		p.CursorParam = "cursor"
+	}
+	a.Pagination = p
+}
+
+// Cursor can be used in: Paginated
+//
+// Cursor indicates that pages are identified by an opaque cursor given in the query string
+// parameter with the given name, "cursor" by default. The cursor of the next page is returned in
+// the X-Next-Cursor response header.
+func Cursor(name ...string) {
+	if p, ok := paginationDefinition(); ok {
+		if p.OffsetParam != "" {
+			dslengine.ReportError("Cursor and Offset cannot be used together")
+			return
+		}
+		p.CursorParam = paramName(name, "cursor")
+	}
+}
+
+// Offset can be used in: Paginated
+//
+// Offset indicates that pages are identified by the index of their first result given in the
+// query string parameter with the given name, "offset" by default.
+func Offset(name ...string) {
+	if p, ok := paginationDefinition(); ok {
+		if p.CursorParam != "" {
+			dslengine.ReportError("Cursor and Offset cannot be used together")
+			return
+		}
+		p.OffsetParam = paramName(name, "offset")
+	}
+}
+
+// PageSize can be used in: Paginated
+//
+// PageSize defines the number of results returned in a page when the request does not specify
+// one and the maximum number of results a request may ask for. The optional name overrides the
+// name of the page size query string parameter, "limit" by default.
+func PageSize(defaultSize, maxSize int, name ...string) {
+	p, ok := paginationDefinition()
+	if !ok {
+		return
+	}
+	if defaultSize <= 0 || maxSize < defaultSize {
+		dslengine.ReportError("invalid page size %d and maximum %d, size must be greater than 0 and not exceed the maximum", defaultSize, maxSize)
+		return
+	}
+	p.DefaultSize = defaultSize
+	p.MaxSize = maxSize
+	p.SizeParam = paramName(name, "limit")
+}
+
+// paramName returns the name given to a pagination DSL function or def if there is none.
+func paramName(name []string, def string) string {
+	if len(name) > 0 && name[0] != "" {
+		return name[0]
+	}
+	return def
+}
@@ -0,0 +1,97 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Paginated", func() {
+	var dsl func()
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dsl = nil
+	})
+
+	JustBeforeEach(func() {
+		API("test", func() {})
+		Resource("res", func() {
+			Action("list", func() {
+				Routing(GET(""))
+				if dsl != nil {
+					Paginated(dsl)
+				} else {
+					Paginated()
+				}
+				Response(OK)
+			})
+		})
+		dslengine.Run()
+	})
+
+	Context("with no DSL", func() {
+		It("uses cursor pagination", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			list := Design.Resources["res"].Actions["list"]
+			Ω(list.Pagination.CursorParam).Should(Equal("cursor"))
+			params := list.QueryParams.Type.ToObject()
+			Ω(params).Should(HaveKey("cursor"))
+			Ω(params["limit"].DefaultValue).Should(Equal(20))
+			Ω(*params["limit"].Validation.Minimum).Should(Equal(1.0))
+			Ω(*params["limit"].Validation.Maximum).Should(Equal(100.0))
+			headers := list.Responses[OK].Headers.Type.ToObject()
+			Ω(headers).Should(HaveKey("Link"))
+			Ω(headers).Should(HaveKey("X-Next-Cursor"))
+		})
+	})
+
+	Context("with offset pagination", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Offset("start")
+				PageSize(10, 50, "size")
+			}
+		})
+
+		It("adds the offset and page size parameters", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			list := Design.Resources["res"].Actions["list"]
+			params := list.QueryParams.Type.ToObject()
+			Ω(params).Should(HaveKey("start"))
+			Ω(params).ShouldNot(HaveKey("cursor"))
+			Ω(params["size"].DefaultValue).Should(Equal(10))
+			Ω(*params["size"].Validation.Maximum).Should(Equal(50.0))
+			headers := list.Responses[OK].Headers.Type.ToObject()
+			Ω(headers).Should(HaveKey("Link"))
+			Ω(headers).ShouldNot(HaveKey("X-Next-Cursor"))
+		})
+	})
+
+	Context("with both cursor and offset", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Cursor()
+				Offset()
+			}
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with an invalid page size", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				PageSize(50, 10)
+			}
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
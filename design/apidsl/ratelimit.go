@@ -0,0 +1,70 @@
+package apidsl
+
+import (
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// RateLimit can be used in: API, Resource, Action
+//
+// RateLimit documents the rate limit applied to the requests. The limit allows requests requests
+// per period with an optional burst size. Actions inherit the rate limit of their resource or of
+// the API if they don't define one. The limit is described in the generated Swagger specification
+// via the RateLimit-* response headers and the 429 (Too Many Requests) response. Note that the
+// limit must be enforced by the service, for example using the middleware/ratelimit package.
+// Example:
+//
+//	Resource("bottle", func() {
+//		RateLimit(100, time.Minute)
+//		Action("create", func() {
+//			RateLimit(10, time.Minute, 5) // 10 requests per minute, bursts of 5
+//		})
+//	})
+//
+func RateLimit(requests int, period time.Duration, burst ...int) {
+	if requests <= 0 {
+		dslengine.ReportError("invalid rate limit requests %d, must be greater than 0", requests)
+		return
+	}
+	if period <= 0 {
+		dslengine.ReportError("invalid rate limit period %s, must be greater than 0", period)
+		return
+	}
+	def := &design.RateLimitDefinition{Requests: requests, Period: period}
+	if len(burst) > 0 {
+		if len(burst) > 1 || burst[0] <= 0 {
+			dslengine.ReportError("invalid rate limit burst, must be a single value greater than 0")
+			return
+		}
+		def.Burst = burst[0]
+	}
+	setRateLimit(def)
+}
+
+// NoRateLimit can be used in: Resource, Action
+//
+// NoRateLimit documents that the requests made to a Resource or an Action are not rate limited.
+// It prevents fallback to Resource or API-defined RateLimit.
+func NoRateLimit() {
+	if _, ok := dslengine.CurrentDefinition().(*design.APIDefinition); ok {
+		dslengine.IncompatibleDSL()
+		return
+	}
+	setRateLimit(&design.RateLimitDefinition{})
+}
+
+// setRateLimit sets the rate limit of the current definition.
+func setRateLimit(def *design.RateLimitDefinition) {
+	switch parent := dslengine.CurrentDefinition().(type) {
+	case *design.ActionDefinition:
+		parent.RateLimit = def
+	case *design.ResourceDefinition:
+		parent.RateLimit = def
+	case *design.APIDefinition:
+		parent.RateLimit = def
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
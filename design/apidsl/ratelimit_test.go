@@ -0,0 +1,82 @@
+package apidsl_test
+
+import (
+	"time"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RateLimit", func() {
+	var action *ActionDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		action = nil
+	})
+
+	Context("with a rate limit defined on the API", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				RateLimit(100, time.Minute)
+			})
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(GET("/"))
+				})
+				Action("exempt", func() {
+					Routing(GET("/exempt"))
+					NoRateLimit()
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			action = Design.Resources["res"].Actions["act"]
+		})
+
+		It("is inherited by the actions", func() {
+			Ω(action.RateLimit).ShouldNot(BeNil())
+			Ω(action.RateLimit.Requests).Should(Equal(100))
+			Ω(action.RateLimit.Period).Should(Equal(time.Minute))
+		})
+
+		It("can be disabled", func() {
+			Ω(Design.Resources["res"].Actions["exempt"].RateLimit).Should(BeNil())
+		})
+	})
+
+	Context("with a rate limit defined on the action", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				RateLimit(100, time.Minute)
+			})
+			Resource("res", func() {
+				RateLimit(50, time.Minute)
+				Action("act", func() {
+					Routing(GET("/"))
+					RateLimit(10, time.Second, 5)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			action = Design.Resources["res"].Actions["act"]
+		})
+
+		It("overrides the parent rate limit", func() {
+			Ω(action.RateLimit).Should(Equal(&RateLimitDefinition{Requests: 10, Period: time.Second, Burst: 5}))
+		})
+	})
+
+	Context("with an invalid rate limit", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				RateLimit(0, time.Minute)
+			})
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Run()).Should(HaveOccurred())
+		})
+	})
+})
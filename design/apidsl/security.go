@@ -1,6 +1,8 @@
 package apidsl
 
 import (
+	"time"
+
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
 )
@@ -237,6 +239,17 @@ func OAuth2Security(name string, dsl ...func()) *design.SecuritySchemeDefinition
 //        Scope("my_system:read", "Read anything in there")
 //    })
 //
+// The claims of the JWTs may also be validated by the JWT middleware, e.g.:
+//
+//    JWTSecurity("jwt", func() {
+//        Header("Authorization")
+//        Issuer("https://example.com")
+//        Audience("my-api")
+//        Leeway(30 * time.Second)
+//        Algorithms("RS256", "ES256")
+//        RequiredClaims("sub")
+//    })
+//
 func JWTSecurity(name string, dsl ...func()) *design.SecuritySchemeDefinition {
 	switch dslengine.CurrentDefinition().(type) {
 	case *design.APIDefinition, *dslengine.TopLevelDefinition:
@@ -401,3 +414,68 @@ func TokenURL(tokenURL string) {
 	}
 	dslengine.IncompatibleDSL()
 }
+
+// Issuer can be used in: JWTSecurity
+//
+// Issuer defines the expected value of the "iss" claim of the JWTs. Requests made with tokens
+// issued by a different issuer are rejected by the JWT middleware.
+func Issuer(iss string) {
+	if parent, ok := jwtSecurityDefinition(); ok {
+		parent.Issuer = iss
+	}
+}
+
+// Audience can be used in: JWTSecurity
+//
+// Audience defines the accepted values of the "aud" claim of the JWTs. Requests made with tokens
+// whose audience does not contain any of the values are rejected by the JWT middleware.
+func Audience(aud ...string) {
+	if parent, ok := jwtSecurityDefinition(); ok {
+		parent.Audience = append(parent.Audience, aud...)
+	}
+}
+
+// Leeway can be used in: JWTSecurity
+//
+// Leeway defines the clock skew tolerated by the JWT middleware when validating the "exp", "nbf"
+// and "iat" claims of the JWTs.
+func Leeway(d time.Duration) {
+	if parent, ok := jwtSecurityDefinition(); ok {
+		if d < 0 {
+			dslengine.ReportError("leeway must not be negative")
+			return
+		}
+		parent.Leeway = d
+	}
+}
+
+// Algorithms can be used in: JWTSecurity
+//
+// Algorithms defines the signing algorithms accepted by the JWT middleware, e.g. "RS256". All the
+// algorithms supported by the keys are accepted by default.
+func Algorithms(algs ...string) {
+	if parent, ok := jwtSecurityDefinition(); ok {
+		parent.Algorithms = append(parent.Algorithms, algs...)
+	}
+}
+
+// RequiredClaims can be used in: JWTSecurity
+//
+// RequiredClaims defines the names of the claims the JWTs must define.
+func RequiredClaims(names ...string) {
+	if parent, ok := jwtSecurityDefinition(); ok {
+		parent.RequiredClaims = append(parent.RequiredClaims, names...)
+	}
+}
+
+// jwtSecurityDefinition returns the JWT security scheme being defined and true if the current
+// definition is a JWT security scheme, it reports an incompatible DSL error otherwise.
+func jwtSecurityDefinition() (*design.SecuritySchemeDefinition, bool) {
+	if parent, ok := dslengine.CurrentDefinition().(*design.SecuritySchemeDefinition); ok {
+		if parent.Kind == design.JWTSecurityKind {
+			return parent, true
+		}
+	}
+	dslengine.IncompatibleDSL()
+	return nil, false
+}
@@ -165,3 +165,65 @@ func HashOf(k, v design.DataType, dsls ...func()) *design.Hash {
 	}
 	return &design.Hash{KeyType: &kat, ElemType: &vat}
 }
+
+// OneOf can be used in: Type
+//
+// OneOf defines the type as a union: values of the type are one of the types listed in the OneOf
+// DSL. The DSL lists the union values using Attribute and may specify a discriminator using
+// Discriminator. Example:
+//
+//	var Shape = Type("Shape", func() {
+//		Description("Shape is either a circle or a square")
+//		OneOf(func() {
+//			Discriminator("kind")
+//			Attribute("circle", Circle)
+//			Attribute("square", Square)
+//		})
+//	})
+//
+// The generated code represents unions with a struct whose Value field holds one of the
+// structs generated for each union value. When a discriminator is defined the JSON
+// representation of a union value is the JSON representation of the value type (which must
+// be an object) with an additional attribute named after the discriminator whose value is the
+// name of the union value (e.g. {"kind":"circle","radius":1}). Without discriminator the JSON
+// representation of a union value is the JSON representation of the value type and decoding
+// attempts the value types in the order they are listed in the DSL. A union without
+// discriminator may list at most one object or hash type as these cannot be told apart.
+func OneOf(dsl func()) {
+	a, ok := attributeDefinition()
+	if !ok {
+		return
+	}
+	var isType bool
+	for _, ut := range design.Design.Types {
+		if ut.AttributeDefinition == a {
+			isType = true
+			break
+		}
+	}
+	if !isType {
+		dslengine.IncompatibleDSL()
+		return
+	}
+	if o, ok := a.Type.(design.Object); a.Type != nil && (!ok || len(o) > 0) {
+		dslengine.ReportError("OneOf cannot be used in a type that defines attributes")
+		return
+	}
+	u := &design.Union{}
+	if !dslengine.Execute(dsl, u) {
+		return
+	}
+	a.Type = u
+}
+
+// Discriminator can be used in: OneOf
+//
+// Discriminator sets the name of the attribute that identifies the type of union values in
+// their JSON representation, see OneOf.
+func Discriminator(name string) {
+	if u, ok := dslengine.CurrentDefinition().(*design.Union); ok {
+		u.Discriminator = name
+		return
+	}
+	dslengine.IncompatibleDSL()
+}
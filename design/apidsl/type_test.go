@@ -289,3 +289,125 @@ var _ = Describe("HashOf", func() {
 		})
 	})
 })
+
+var _ = Describe("OneOf", func() {
+	var dsl func()
+	var ut *UserTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dsl = nil
+	})
+
+	JustBeforeEach(func() {
+		Type("circle", func() {
+			Attribute("radius", Integer)
+		})
+		Type("shape", dsl)
+		dslengine.Run()
+		ut, _ = Design.Types["shape"]
+	})
+
+	Context("with values", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				OneOf(func() {
+					Attribute("circle", "circle")
+					Attribute("name", String, "description")
+				})
+			}
+		})
+
+		It("produces a union type", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(ut).ShouldNot(BeNil())
+			Ω(ut.IsUnion()).Should(BeTrue())
+			u := ut.ToUnion()
+			Ω(u.Discriminator).Should(BeEmpty())
+			Ω(u.Values).Should(HaveLen(2))
+			Ω(u.Values[0].Name).Should(Equal("circle"))
+			Ω(u.Values[0].Type).Should(Equal(Design.Types["circle"]))
+			Ω(u.Values[1].Name).Should(Equal("name"))
+			Ω(u.Values[1].Type).Should(Equal(String))
+			Ω(u.Values[1].Description).Should(Equal("description"))
+		})
+	})
+
+	Context("with a discriminator", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				OneOf(func() {
+					Discriminator("kind")
+					Attribute("circle", "circle")
+				})
+			}
+		})
+
+		It("sets the discriminator", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(ut.ToUnion().Discriminator).Should(Equal("kind"))
+		})
+	})
+
+	Context("with several object or hash values and no discriminator", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				OneOf(func() {
+					Attribute("circle", "circle")
+					Attribute("tags", HashOf(String, String))
+				})
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("must define a discriminator"))
+		})
+	})
+
+	Context("with a discriminator and values that are not objects", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				OneOf(func() {
+					Discriminator("kind")
+					Attribute("name", String)
+				})
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a type that defines attributes", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Attribute("name")
+				OneOf(func() {
+					Attribute("circle", "circle")
+				})
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("used in an attribute", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Attribute("shape", func() {
+					OneOf(func() {
+						Attribute("circle", "circle")
+					})
+				})
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
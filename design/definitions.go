@@ -7,6 +7,7 @@ import (
 	"path"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/dimfeld/httppath"
 	"github.com/goadesign/goa/dslengine"
@@ -74,6 +75,12 @@ type (
 		Security *SecurityDefinition
 		// NoExamples indicates whether to bypass automatic example generation.
 		NoExamples bool
+		// RateLimit defines the rate limit applied to all the resources and actions,
+		// unless overridden by Resource or Action-level RateLimit() calls.
+		RateLimit *RateLimitDefinition
+		// ErrorMediaType is the media type of the errors returned by the API, one of
+		// ErrorMedia or ProblemMedia. ErrorMedia is used if nil.
+		ErrorMediaType *MediaTypeDefinition
 
 		// rand is the random generator used to generate examples.
 		rand *RandomGenerator
@@ -144,6 +151,9 @@ type (
 		// Security defines security requirements for the Resource,
 		// for actions that don't define one themselves.
 		Security *SecurityDefinition
+		// RateLimit defines the rate limit applied to the resource actions that don't
+		// define one themselves.
+		RateLimit *RateLimitDefinition
 	}
 
 	// CORSDefinition contains the definition for a specific origin CORS policy.
@@ -166,6 +176,35 @@ type (
 		Regexp bool
 	}
 
+	// RateLimitDefinition describes the rate limit applied to the requests made to an API,
+	// resource or action.
+	RateLimitDefinition struct {
+		// Requests is the maximum number of requests allowed per Period.
+		Requests int
+		// Period is the duration of the rate limit window.
+		Period time.Duration
+		// Burst is the maximum number of requests that can be made at once.
+		Burst int
+	}
+
+	// PaginationDefinition describes how the results of an action are split into pages.
+	PaginationDefinition struct {
+		// Parent is the paginated action.
+		Parent *ActionDefinition
+		// CursorParam is the name of the query string parameter that carries the opaque
+		// cursor identifying the page, empty if the action uses offset pagination.
+		CursorParam string
+		// OffsetParam is the name of the query string parameter that carries the index of
+		// the first result of the page, empty if the action uses cursor pagination.
+		OffsetParam string
+		// SizeParam is the name of the query string parameter that carries the page size.
+		SizeParam string
+		// DefaultSize is the page size used when the request does not specify one.
+		DefaultSize int
+		// MaxSize is the maximum page size.
+		MaxSize int
+	}
+
 	// EncodingDefinition defines an encoder supported by the API.
 	EncodingDefinition struct {
 		// MIMETypes is the set of possible MIME types for the content being encoded or decoded.
@@ -245,12 +284,30 @@ type (
 		Payload *UserTypeDefinition
 		// PayloadOptional is true if the request payload is optional, false otherwise.
 		PayloadOptional bool
+		// PayloadMultipart is true if the request payload is multipart, false otherwise.
+		PayloadMultipart bool
+		// EventStream describes the data of the server-sent events streamed in the
+		// response if any.
+		EventStream *AttributeDefinition
 		// Request headers that need to be made available to action
 		Headers *AttributeDefinition
 		// Metadata is a list of key/value pairs
 		Metadata dslengine.MetadataDefinition
 		// Security defines security requirements for the action
 		Security *SecurityDefinition
+		// RateLimit defines the rate limit applied to the action requests
+		RateLimit *RateLimitDefinition
+		// Cacheable is true if the action responses carry validators (ETag or
+		// Last-Modified headers) and the action answers conditional requests.
+		Cacheable bool
+		// ETag is true if the action responses carry an entity tag and the action
+		// enforces If-Match preconditions when it modifies the resource.
+		ETag bool
+		// Idempotent is true if repeated requests made with the same Idempotency-Key
+		// header are handled once and get the response of the first request.
+		Idempotent bool
+		// Pagination describes how the action results are split into pages if any.
+		Pagination *PaginationDefinition
 	}
 
 	// FileServerDefinition defines an endpoint that servers static assets.
@@ -610,6 +667,15 @@ func (a *APIDefinition) MediaTypeWithIdentifier(id string) *MediaTypeDefinition
 	return nil
 }
 
+// ErrorMedia returns the media type of the errors returned by the API: the media type set with
+// the ErrorMediaType DSL or ErrorMedia if none.
+func (a *APIDefinition) ErrorMedia() *MediaTypeDefinition {
+	if a.ErrorMediaType != nil {
+		return a.ErrorMediaType
+	}
+	return ErrorMedia
+}
+
 // IterateResources calls the given iterator passing in each resource sorted in alphabetical order.
 // Iteration stops if an iterator returns an error and in this case IterateResources returns that
 // error.
@@ -635,7 +701,8 @@ func (a *APIDefinition) DSL() func() {
 }
 
 // Finalize sets the Consumes and Produces fields to the defaults if empty.
-// Also it records built-in media types that are used by the user design.
+// Also it rewrites the error responses to use the media type set with ErrorMediaType and records
+// built-in media types that are used by the user design.
 func (a *APIDefinition) Finalize() {
 	if len(a.Consumes) == 0 {
 		a.Consumes = DefaultDecoders
@@ -643,16 +710,38 @@ func (a *APIDefinition) Finalize() {
 	if len(a.Produces) == 0 {
 		a.Produces = DefaultEncoders
 	}
+	if a.ErrorMediaType != nil {
+		a.IterateResponses(a.useErrorMedia)
+		a.IterateResources(func(r *ResourceDefinition) error {
+			for _, resp := range r.Responses {
+				a.useErrorMedia(resp)
+			}
+			return r.IterateActions(func(action *ActionDefinition) error {
+				return action.IterateResponses(a.useErrorMedia)
+			})
+		})
+	}
+	builtins := map[string]*MediaTypeDefinition{
+		ErrorMediaIdentifier:   ErrorMedia,
+		ProblemMediaIdentifier: ProblemMedia,
+	}
+	if a.ErrorMediaType != nil {
+		if a.MediaTypes == nil {
+			a.MediaTypes = make(map[string]*MediaTypeDefinition)
+		}
+		a.MediaTypes[CanonicalIdentifier(a.ErrorMediaType.Identifier)] = a.ErrorMediaType
+		delete(builtins, a.ErrorMediaType.Identifier)
+	}
 	a.IterateResources(func(r *ResourceDefinition) error {
 		returnsError := func(resp *ResponseDefinition) bool {
-			if resp.MediaType == ErrorMediaIdentifier {
+			if mt, ok := builtins[resp.MediaType]; ok {
 				if a.MediaTypes == nil {
 					a.MediaTypes = make(map[string]*MediaTypeDefinition)
 				}
-				a.MediaTypes[CanonicalIdentifier(ErrorMediaIdentifier)] = ErrorMedia
-				return true
+				a.MediaTypes[CanonicalIdentifier(mt.Identifier)] = mt
+				delete(builtins, resp.MediaType)
 			}
-			return false
+			return len(builtins) == 0
 		}
 		for _, resp := range a.Responses {
 			if returnsError(resp) {
@@ -675,6 +764,20 @@ func (a *APIDefinition) Finalize() {
 	})
 }
 
+// useErrorMedia makes the given response use the API error media type if it uses the other
+// built-in error media type.
+func (a *APIDefinition) useErrorMedia(resp *ResponseDefinition) error {
+	id := CanonicalIdentifier(resp.MediaType)
+	if id != ErrorMediaIdentifier && id != ProblemMediaIdentifier {
+		return nil
+	}
+	resp.MediaType = a.ErrorMediaType.Identifier
+	if mt, ok := resp.Type.(*MediaTypeDefinition); ok && mt.IsError() {
+		resp.Type = a.ErrorMediaType
+	}
+	return nil
+}
+
 // NewResourceDefinition creates a resource definition but does not
 // execute the DSL.
 func NewResourceDefinition(name string, dsl func()) *ResourceDefinition {
@@ -928,6 +1031,23 @@ func (cors *CORSDefinition) Context() string {
 	return fmt.Sprintf("CORS policy for resource %s origin %s", cors.Parent.Context(), cors.Origin)
 }
 
+// Context returns the generic definition name used in error messages.
+func (p *PaginationDefinition) Context() string {
+	if p.Parent == nil {
+		return "pagination"
+	}
+	return fmt.Sprintf("pagination of %s", p.Parent.Context())
+}
+
+// PageParam returns the name of the query string parameter that identifies the page, i.e. the
+// cursor or the offset parameter.
+func (p *PaginationDefinition) PageParam() string {
+	if p.CursorParam != "" {
+		return p.CursorParam
+	}
+	return p.OffsetParam
+}
+
 // Context returns the generic definition name used in error messages.
 func (enc *EncodingDefinition) Context() string {
 	return fmt.Sprintf("encoding for %s", strings.Join(enc.MIMETypes, ", "))
@@ -1033,6 +1153,10 @@ func (a *AttributeDefinition) IsPrimitivePointer(attName string) bool {
 		return false
 	}
 	if att.Type.IsPrimitive() {
+		if att.Type.Kind() == FileKind {
+			// Files are always represented with *multipart.FileHeader.
+			return false
+		}
 		return !a.IsRequired(attName) && !a.HasDefaultValue(attName) && !a.IsNonZero(attName)
 	}
 	return false
@@ -1497,7 +1621,13 @@ func (a *ActionDefinition) WebSocket() bool {
 	return true
 }
 
-// Finalize inherits security scheme and action responses from parent and top level design.
+// ServerSentEvents returns true if the action streams server-sent events in its response.
+func (a *ActionDefinition) ServerSentEvents() bool {
+	return a.EventStream != nil
+}
+
+// Finalize inherits security scheme, rate limit and action responses from parent and top level
+// design.
 func (a *ActionDefinition) Finalize() {
 	// Inherit security scheme
 	if a.Security == nil {
@@ -1511,11 +1641,40 @@ func (a *ActionDefinition) Finalize() {
 		a.Security = nil
 	}
 
+	// Inherit rate limit
+	if a.RateLimit == nil {
+		a.RateLimit = a.Parent.RateLimit
+		if a.RateLimit == nil {
+			a.RateLimit = Design.RateLimit
+		}
+	}
+	if a.RateLimit != nil && a.RateLimit.Requests == 0 {
+		a.RateLimit = nil
+	}
+
+	a.initConditional()
+	a.initIdempotency()
+
 	if a.Payload != nil {
 		a.Payload.Finalize()
 	}
 
+	if a.EventStream != nil {
+		if a.Responses == nil {
+			a.Responses = make(map[string]*ResponseDefinition)
+		}
+		if _, ok := a.Responses[OK]; !ok {
+			a.Responses[OK] = &ResponseDefinition{
+				Name:      OK,
+				Status:    200,
+				MediaType: "text/event-stream",
+				Parent:    a,
+			}
+		}
+	}
+
 	a.mergeResponses()
+	a.initPagination()
 	a.initImplicitParams()
 	a.initQueryParams()
 }
@@ -1527,6 +1686,9 @@ func (a *ActionDefinition) UserTypes() map[string]*UserTypeDefinition {
 	if a.Payload != nil {
 		allp["__payload__"] = &AttributeDefinition{Type: a.Payload}
 	}
+	if a.EventStream != nil {
+		allp["__events__"] = a.EventStream
+	}
 	for n, ut := range UserTypes(allp) {
 		types[n] = ut
 	}
@@ -1602,6 +1764,174 @@ func (a *ActionDefinition) mergeResponses() {
 	}
 }
 
+// initConditional adds the conditional request headers and the 304 (Not Modified) and 412
+// (Precondition Failed) responses to cacheable actions and to unsafe actions that use entity
+// tags. Headers and responses defined explicitly in the design are left untouched.
+func (a *ActionDefinition) initConditional() {
+	headers := make(Object)
+	if a.Cacheable {
+		headers["If-None-Match"] = &AttributeDefinition{
+			Type:        String,
+			Description: "Entity tags of the representations cached by the client",
+		}
+		headers["If-Modified-Since"] = &AttributeDefinition{
+			Type:        String,
+			Description: "Last modification date of the representation cached by the client",
+		}
+		a.addResponse(&ResponseDefinition{Name: NotModified, Status: 304})
+	}
+	if a.ETag && a.IsUnsafe() {
+		headers["If-Match"] = &AttributeDefinition{
+			Type:        String,
+			Description: "Entity tags the current representation must match for the request to proceed",
+		}
+		headers["If-Unmodified-Since"] = &AttributeDefinition{
+			Type:        String,
+			Description: "Date after which the resource must not have been modified for the request to proceed",
+		}
+		a.addResponse(&ResponseDefinition{
+			Name:      PreconditionFailed,
+			Status:    412,
+			MediaType: Design.ErrorMedia().Identifier,
+		})
+	}
+	if len(headers) == 0 {
+		return
+	}
+	if a.Headers == nil {
+		a.Headers = &AttributeDefinition{Type: Object{}}
+	}
+	obj := a.Headers.Type.ToObject()
+	if obj == nil {
+		return
+	}
+	for n, h := range headers {
+		if _, ok := obj[n]; !ok {
+			obj[n] = h
+		}
+	}
+}
+
+// maxIdempotencyKeyLength is the maximum length of the Idempotency-Key header.
+const maxIdempotencyKeyLength = 255
+
+// initIdempotency adds the Idempotency-Key header and the 409 (Conflict) and 422 (Unprocessable
+// Entity) responses to idempotent actions that use unsafe methods.
+func (a *ActionDefinition) initIdempotency() {
+	if !a.Idempotent || !a.IsUnsafe() {
+		return
+	}
+	if a.Headers == nil {
+		a.Headers = &AttributeDefinition{Type: Object{}}
+	}
+	if obj := a.Headers.Type.ToObject(); obj != nil {
+		if _, ok := obj["Idempotency-Key"]; !ok {
+			maxLength := maxIdempotencyKeyLength
+			obj["Idempotency-Key"] = &AttributeDefinition{
+				Type:        String,
+				Description: "Unique key used to make retries of the request safe, repeated requests with the same key get the response of the first request",
+				Validation:  &dslengine.ValidationDefinition{MaxLength: &maxLength},
+			}
+		}
+	}
+	errorMedia := Design.ErrorMedia().Identifier
+	a.addResponse(&ResponseDefinition{Name: Conflict, Status: 409, MediaType: errorMedia})
+	a.addResponse(&ResponseDefinition{Name: UnprocessableEntity, Status: 422, MediaType: errorMedia})
+}
+
+// initPagination adds the page and page size query string parameters to paginated actions as
+// well as the Link and X-Next-Cursor headers to their OK (200) response. Parameters and headers
+// defined explicitly in the design are left untouched.
+func (a *ActionDefinition) initPagination() {
+	p := a.Pagination
+	if p == nil {
+		return
+	}
+	if a.Params == nil {
+		a.Params = &AttributeDefinition{Type: Object{}}
+	}
+	params := a.Params.Type.ToObject()
+	if params == nil {
+		return
+	}
+	if _, ok := params[p.SizeParam]; !ok {
+		minSize, maxSize := 1.0, float64(p.MaxSize)
+		params[p.SizeParam] = &AttributeDefinition{
+			Type:         Integer,
+			Description:  "Maximum number of results in the page",
+			DefaultValue: p.DefaultSize,
+			Validation:   &dslengine.ValidationDefinition{Minimum: &minSize, Maximum: &maxSize},
+		}
+	}
+	if p.CursorParam != "" {
+		if _, ok := params[p.CursorParam]; !ok {
+			params[p.CursorParam] = &AttributeDefinition{
+				Type:        String,
+				Description: "Cursor of the page as given by the X-Next-Cursor header of the previous page",
+			}
+		}
+	} else if _, ok := params[p.OffsetParam]; !ok {
+		minOffset := 0.0
+		params[p.OffsetParam] = &AttributeDefinition{
+			Type:         Integer,
+			Description:  "Index of the first result of the page",
+			DefaultValue: 0,
+			Validation:   &dslengine.ValidationDefinition{Minimum: &minOffset},
+		}
+	}
+	resp, ok := a.Responses[OK]
+	if !ok {
+		return
+	}
+	if resp.Headers == nil {
+		resp.Headers = &AttributeDefinition{Type: Object{}}
+	}
+	headers := resp.Headers.Type.ToObject()
+	if headers == nil {
+		return
+	}
+	if _, ok := headers["Link"]; !ok {
+		headers["Link"] = &AttributeDefinition{
+			Type:        String,
+			Description: "URL of the next page with relation type \"next\", absent on the last page",
+		}
+	}
+	if p.CursorParam != "" {
+		if _, ok := headers["X-Next-Cursor"]; !ok {
+			headers["X-Next-Cursor"] = &AttributeDefinition{
+				Type:        String,
+				Description: "Cursor of the next page, absent on the last page",
+			}
+		}
+	}
+}
+
+// addResponse adds the given response to the action unless the action already defines a
+// response with the same name.
+func (a *ActionDefinition) addResponse(resp *ResponseDefinition) {
+	if a.Responses == nil {
+		a.Responses = make(map[string]*ResponseDefinition)
+	}
+	if _, ok := a.Responses[resp.Name]; ok {
+		return
+	}
+	resp.Parent = a
+	a.Responses[resp.Name] = resp
+}
+
+// IsUnsafe returns true if any of the action routes uses a method that is not safe as defined in
+// RFC 7231 section 4.2.1, that is a method other than GET, HEAD, OPTIONS or TRACE.
+func (a *ActionDefinition) IsUnsafe() bool {
+	for _, r := range a.Routes {
+		switch r.Verb {
+		case "GET", "HEAD", "OPTIONS", "TRACE":
+		default:
+			return true
+		}
+	}
+	return false
+}
+
 // initImplicitParams creates params for path segments that don't have one.
 func (a *ActionDefinition) initImplicitParams() {
 	for _, ro := range a.Routes {
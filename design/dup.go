@@ -72,6 +72,15 @@ func (d *dupper) DupType(t DataType) DataType {
 			KeyType:  d.DupAttribute(actual.KeyType),
 			ElemType: d.DupAttribute(actual.ElemType),
 		}
+	case *Union:
+		res := &Union{Discriminator: actual.Discriminator}
+		for _, v := range actual.Values {
+			res.Values = append(res.Values, &UnionValue{
+				Name:                v.Name,
+				AttributeDefinition: d.DupAttribute(v.AttributeDefinition),
+			})
+		}
+		return res
 	case *UserTypeDefinition:
 		if u, ok := d.dts[actual.TypeName]; ok {
 			return u
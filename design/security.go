@@ -3,6 +3,7 @@ package design
 import (
 	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/goadesign/goa/dslengine"
 )
@@ -69,6 +70,16 @@ type SecuritySchemeDefinition struct {
 	TokenURL string `json:"token_url,omitempty"`
 	// AuthorizationURL holds URL for retrieving authorization codes with oauth2
 	AuthorizationURL string `json:"authorization_url,omitempty"`
+	// Issuer is the expected value of the "iss" claim of JWTs.
+	Issuer string `json:"issuer,omitempty"`
+	// Audience lists the accepted values of the "aud" claim of JWTs.
+	Audience []string `json:"audience,omitempty"`
+	// Leeway is the clock skew tolerated when validating the time based claims of JWTs.
+	Leeway time.Duration `json:"leeway,omitempty"`
+	// Algorithms lists the signing algorithms accepted for JWTs.
+	Algorithms []string `json:"algorithms,omitempty"`
+	// RequiredClaims lists the names of the claims JWTs must define.
+	RequiredClaims []string `json:"required_claims,omitempty"`
 	// Metadata is a list of key/value pairs
 	Metadata dslengine.MetadataDefinition
 }
@@ -58,6 +58,12 @@ type (
 		// ToHash returns the underlying hash map if any (i.e. if IsHash returns true),
 		// nil otherwise.
 		ToHash() *Hash
+		// IsUnion returns true if the underlying type is a union or a user type which
+		// is a union.
+		IsUnion() bool
+		// ToUnion returns the underlying union if any (i.e. if IsUnion returns true),
+		// nil otherwise.
+		ToUnion() *Union
 		// CanHaveDefault returns whether the data type can have a default value.
 		CanHaveDefault() bool
 		// IsCompatible checks whether val has a Go type that is
@@ -106,6 +112,24 @@ type (
 	// HashVal is the value of a hash used to specify the default value.
 	HashVal map[interface{}]interface{}
 
+	// Union is the type for a value that may be one of a list of types.
+	Union struct {
+		// Discriminator is the name of the attribute that holds the name of the
+		// union value type in the JSON representation. Union values may only be
+		// objects when a discriminator is defined.
+		Discriminator string
+		// Values lists the union values in the order they were defined.
+		Values []*UnionValue
+	}
+
+	// UnionValue is one of the types listed in a union.
+	UnionValue struct {
+		// Name of the value, used in generated code and as discriminator value.
+		Name string
+		// A union value is an attribute definition.
+		*AttributeDefinition
+	}
+
 	// UserTypeDefinition is the type for user defined types that are not media types
 	// (e.g. payload types).
 	UserTypeDefinition struct {
@@ -163,6 +187,10 @@ const (
 	UserTypeKind
 	// MediaTypeKind represents a media type.
 	MediaTypeKind
+	// FileKind represents a file uploaded in a multipart form.
+	FileKind
+	// UnionKind represents a value that may be one of a list of types.
+	UnionKind
 )
 
 const (
@@ -188,6 +216,10 @@ const (
 
 	// Any is the type for an arbitrary JSON value (interface{} in Go).
 	Any = Primitive(AnyKind)
+
+	// File is the type for a file uploaded in a multipart form (*multipart.FileHeader in Go).
+	// File may only be used in the payload of actions that use MultipartForm.
+	File = Primitive(FileKind)
 )
 
 // DataType implementation
@@ -208,6 +240,8 @@ func (p Primitive) Name() string {
 		return "string"
 	case Any:
 		return "any"
+	case File:
+		return "file"
 	default:
 		panic("unknown primitive type") // bug
 	}
@@ -237,6 +271,12 @@ func (p Primitive) ToArray() *Array { return nil }
 // ToHash returns nil.
 func (p Primitive) ToHash() *Hash { return nil }
 
+// IsUnion returns false.
+func (p Primitive) IsUnion() bool { return false }
+
+// ToUnion returns nil.
+func (p Primitive) ToUnion() *Union { return nil }
+
 // CanHaveDefault returns whether the primitive can have a default value.
 func (p Primitive) CanHaveDefault() (ok bool) {
 	switch p {
@@ -248,7 +288,7 @@ func (p Primitive) CanHaveDefault() (ok bool) {
 
 // IsCompatible returns true if val is compatible with p.
 func (p Primitive) IsCompatible(val interface{}) bool {
-	if p != Boolean && p != Integer && p != Number && p != String && p != DateTime && p != UUID && p != Any {
+	if p != Boolean && p != Integer && p != Number && p != String && p != DateTime && p != UUID && p != Any && p != File {
 		panic("unknown primitive type") // bug
 	}
 	if p == Any {
@@ -297,6 +337,8 @@ func (p Primitive) GenerateExample(r *RandomGenerator, seen []string) interface{
 	case Any:
 		// to not make it too complicated, pick one of the primitive types
 		return anyPrimitive[r.Int()%len(anyPrimitive)].GenerateExample(r, seen)
+	case File:
+		return r.String() + ".txt"
 	default:
 		panic("unknown primitive type") // bug
 	}
@@ -336,6 +378,12 @@ func (a *Array) ToArray() *Array { return a }
 // ToHash returns nil.
 func (a *Array) ToHash() *Hash { return nil }
 
+// IsUnion returns false.
+func (a *Array) IsUnion() bool { return false }
+
+// ToUnion returns nil.
+func (a *Array) ToUnion() *Union { return nil }
+
 // CanHaveDefault returns true if the array type can have a default value.
 // The array type can have a default value only if the element type can
 // have a default value.
@@ -409,6 +457,12 @@ func (o Object) ToArray() *Array { return nil }
 // ToHash returns nil.
 func (o Object) ToHash() *Hash { return nil }
 
+// IsUnion returns false.
+func (o Object) IsUnion() bool { return false }
+
+// ToUnion returns nil.
+func (o Object) ToUnion() *Union { return nil }
+
 // CanHaveDefault returns false.
 func (o Object) CanHaveDefault() bool { return false }
 
@@ -475,6 +529,12 @@ func (h *Hash) ToArray() *Array { return nil }
 // ToHash returns the underlying hash map.
 func (h *Hash) ToHash() *Hash { return h }
 
+// IsUnion returns false.
+func (h *Hash) IsUnion() bool { return false }
+
+// ToUnion returns nil.
+func (h *Hash) ToUnion() *Union { return nil }
+
 // CanHaveDefault returns true if the hash type can have a default value.
 // The hash type can have a default value only if both the key type and
 // the element type can have a default value.
@@ -519,6 +579,91 @@ func (h *Hash) MakeMap(m map[interface{}]interface{}) interface{} {
 	return hash.Interface()
 }
 
+// Kind implements DataKind.
+func (u *Union) Kind() Kind { return UnionKind }
+
+// Name returns the type name.
+func (u *Union) Name() string { return "union" }
+
+// Context returns the generic definition name used in error messages.
+func (u *Union) Context() string { return "union" }
+
+// IsPrimitive returns false.
+func (u *Union) IsPrimitive() bool { return false }
+
+// HasAttributes returns false, union values are opaque to the code that manipulates the
+// attributes of data structures.
+func (u *Union) HasAttributes() bool { return false }
+
+// IsObject returns false.
+func (u *Union) IsObject() bool { return false }
+
+// IsArray returns false.
+func (u *Union) IsArray() bool { return false }
+
+// IsHash returns false.
+func (u *Union) IsHash() bool { return false }
+
+// ToObject returns nil.
+func (u *Union) ToObject() Object { return nil }
+
+// ToArray returns nil.
+func (u *Union) ToArray() *Array { return nil }
+
+// ToHash returns nil.
+func (u *Union) ToHash() *Hash { return nil }
+
+// IsUnion returns true.
+func (u *Union) IsUnion() bool { return true }
+
+// ToUnion returns the underlying union.
+func (u *Union) ToUnion() *Union { return u }
+
+// CanHaveDefault returns false.
+func (u *Union) CanHaveDefault() bool { return false }
+
+// IsCompatible returns true if val is compatible with one of the union values.
+func (u *Union) IsCompatible(val interface{}) bool {
+	for _, v := range u.Values {
+		if v.Type.IsCompatible(val) {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateExample returns a random value for one of the union values. The value includes the
+// discriminator attribute if the union defines one.
+func (u *Union) GenerateExample(r *RandomGenerator, seen []string) interface{} {
+	if len(u.Values) == 0 {
+		return nil
+	}
+	v := u.Values[r.Int()%len(u.Values)]
+	ex := v.GenerateExample(r, seen)
+	if u.Discriminator != "" {
+		if m, ok := ex.(map[string]interface{}); ok {
+			// copy so the discriminator does not leak into the example of the value type
+			res := make(map[string]interface{}, len(m)+1)
+			for k, val := range m {
+				res[k] = val
+			}
+			res[u.Discriminator] = v.Name
+			return res
+		}
+	}
+	return ex
+}
+
+// Value returns the union value with the given name, nil if there isn't one.
+func (u *Union) Value(name string) *UnionValue {
+	for _, v := range u.Values {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
 // AttributeIterator is the type of the function given to IterateAttributes.
 type AttributeIterator func(string, *AttributeDefinition) error
 
@@ -578,6 +723,15 @@ func UserTypes(dt DataType) map[string]*UserTypeDefinition {
 			return nil
 		}
 		return types
+	case *Union:
+		types := make(map[string]*UserTypeDefinition)
+		for _, v := range actual.Values {
+			v.Walk(collect(types))
+		}
+		if len(types) == 0 {
+			return nil
+		}
+		return types
 	case *UserTypeDefinition:
 		types := map[string]*UserTypeDefinition{actual.TypeName: actual}
 		actual.Walk(collect(types))
@@ -662,6 +816,12 @@ func (u *UserTypeDefinition) ToArray() *Array { return u.Type.ToArray() }
 // ToHash calls ToHash on the user type underlying data type.
 func (u *UserTypeDefinition) ToHash() *Hash { return u.Type.ToHash() }
 
+// IsUnion calls IsUnion on the user type underlying data type.
+func (u *UserTypeDefinition) IsUnion() bool { return u.Type != nil && u.Type.IsUnion() }
+
+// ToUnion calls ToUnion on the user type underlying data type.
+func (u *UserTypeDefinition) ToUnion() *Union { return u.Type.ToUnion() }
+
 // CanHaveDefault calls CanHaveDefault on the user type underlying data type.
 func (u *UserTypeDefinition) CanHaveDefault() bool { return u.Type.CanHaveDefault() }
 
@@ -703,7 +863,8 @@ func (m *MediaTypeDefinition) IsError() bool {
 		panic("invalid media type identifier " + m.Identifier) // bug
 	}
 	delete(params, "view")
-	return mime.FormatMediaType(base, params) == ErrorMedia.Identifier
+	id := mime.FormatMediaType(base, params)
+	return id == ErrorMedia.Identifier || id == ProblemMedia.Identifier
 }
 
 // ComputeViews returns the media type views recursing as necessary if the media type is a
@@ -1010,6 +1171,12 @@ func walk(at *AttributeDefinition, walker func(*AttributeDefinition) error, seen
 				return err
 			}
 		}
+	case *Union:
+		for _, v := range actual.Values {
+			if err := walk(v.AttributeDefinition, walker, seen); err != nil {
+				return err
+			}
+		}
 	case *UserTypeDefinition:
 		return walkUt(actual)
 	case *MediaTypeDefinition:
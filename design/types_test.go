@@ -397,6 +397,33 @@ var _ = Describe("UserTypes", func() {
 			Ω(userTypes[childut.TypeName]).Should(Equal(childut))
 		})
 	})
+
+	Context("with an object with an attribute using a union of user types", func() {
+		var ut, valueut *UserTypeDefinition
+
+		BeforeEach(func() {
+			valueut = &UserTypeDefinition{
+				TypeName:            "value",
+				AttributeDefinition: &AttributeDefinition{Type: Object{}},
+			}
+			union := &Union{Values: []*UnionValue{
+				{Name: "value", AttributeDefinition: &AttributeDefinition{Type: valueut}},
+				{Name: "name", AttributeDefinition: &AttributeDefinition{Type: String}},
+			}}
+			ut = &UserTypeDefinition{
+				TypeName:            "union",
+				AttributeDefinition: &AttributeDefinition{Type: union},
+			}
+
+			o = Object{"foo": &AttributeDefinition{Type: ut}}
+		})
+
+		It("returns the user types", func() {
+			Ω(userTypes).Should(HaveLen(2))
+			Ω(userTypes[ut.TypeName]).Should(Equal(ut))
+			Ω(userTypes[valueut.TypeName]).Should(Equal(valueut))
+		})
+	})
 })
 
 var _ = Describe("MediaTypeDefinition", func() {
@@ -602,4 +629,23 @@ var _ = Describe("GenerateExample", func() {
 			Ω(h.GenerateExample(rand, nil)).Should(BeAssignableToTypeOf(map[string]string{"foo": "bar"}))
 		})
 	})
+
+	Context("Given a union with a discriminator", func() {
+		var value *AttributeDefinition
+		var u *Union
+		BeforeEach(func() {
+			value = &AttributeDefinition{Type: Object{"foo": &AttributeDefinition{Type: String}}}
+			u = &Union{
+				Discriminator: "kind",
+				Values:        []*UnionValue{{Name: "value", AttributeDefinition: value}},
+			}
+		})
+		It("generates an example that includes the discriminator", func() {
+			rand := NewRandomGenerator("foo")
+			ex := u.GenerateExample(rand, nil)
+			Ω(ex).Should(HaveKeyWithValue("kind", "value"))
+			Ω(ex).Should(HaveKey("foo"))
+			Ω(value.Example).ShouldNot(HaveKey("kind"))
+		})
+	})
 })
@@ -331,12 +331,34 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	verr.Merge(a.ValidateParams())
 	if a.Payload != nil {
 		verr.Merge(a.Payload.Validate("action payload", a))
+		verr.Merge(a.validateMultipartPayload())
+		if a.Payload.IsUnion() && Design.Types[a.Payload.TypeName] != a.Payload {
+			verr.Add(a, "union payload type must be used as is, it cannot be extended with a DSL")
+		}
+	} else if a.PayloadMultipart {
+		verr.Add(a, "MultipartForm requires the action to define a payload")
+	}
+	if a.EventStream != nil {
+		verr.Merge(a.EventStream.Validate("event stream", a))
+		if hasFile(a.EventStream) {
+			verr.Add(a, "event stream data cannot contain files")
+		}
+		if a.WebSocket() {
+			verr.Add(a, "server-sent events cannot be used with websocket actions")
+		}
+		if r, ok := a.Responses[OK]; ok && r.MediaType != "text/event-stream" {
+			verr.Add(a, "OK response of action streaming server-sent events must use the text/event-stream media type")
+		}
 	}
 	if a.Parent == nil {
 		verr.Add(a, "missing parent resource")
 	}
 	if a.Params != nil {
 		for n, p := range a.Params.Type.ToObject() {
+			if p.Type.Kind() == FileKind {
+				verr.Add(a, "Param %s has an invalid type, files may only be used in multipart payloads", n)
+				continue
+			}
 			if p.Type.IsPrimitive() {
 				continue
 			}
@@ -352,6 +374,45 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	return verr.AsError()
 }
 
+// validateMultipartPayload checks that multipart payloads are objects whose attributes can be
+// encoded in a multipart form and that files are only used in multipart payloads.
+func (a *ActionDefinition) validateMultipartPayload() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if !a.PayloadMultipart {
+		if hasFile(a.Payload.AttributeDefinition) {
+			verr.Add(a, "payload has file attributes but the action does not use MultipartForm")
+		}
+		return verr.AsError()
+	}
+	obj := a.Payload.ToObject()
+	if obj == nil {
+		verr.Add(a, "multipart payload must be an object")
+		return verr.AsError()
+	}
+	for n, att := range obj {
+		if att.Type.IsPrimitive() {
+			continue
+		}
+		if arr := att.Type.ToArray(); arr != nil && arr.ElemType.Type.IsPrimitive() {
+			continue
+		}
+		verr.Add(a, "multipart payload attribute %s has an invalid type, must be a primitive or an array of primitives", n)
+	}
+	return verr.AsError()
+}
+
+// hasFile returns true if the attribute or any of its children is a file.
+func hasFile(att *AttributeDefinition) bool {
+	found := false
+	att.Walk(func(a *AttributeDefinition) error {
+		if a.Type.Kind() == FileKind {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
 // Validate checks the file server is properly initialized.
 func (f *FileServerDefinition) Validate() *dslengine.ValidationErrors {
 	verr := new(dslengine.ValidationErrors)
@@ -478,6 +539,8 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 			ctx = fmt.Sprintf("field %s", n)
 			verr.Merge(att.Validate(ctx, parent))
 		}
+	} else if u := a.Type.ToUnion(); u != nil {
+		verr.Merge(u.validate(ctx, parent))
 	} else {
 		if a.Type.IsArray() {
 			elemType := a.Type.ToArray().ElemType
@@ -488,6 +551,47 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 	return verr.AsError()
 }
 
+// validate checks that the union defines at least one value, that the value names are unique
+// and that all the values are objects that do not define the discriminator attribute if the
+// union has a discriminator. Unions without discriminator may define at most one object or hash
+// value as decoding could not tell these values apart.
+func (u *Union) validate(ctx string, parent dslengine.Definition) *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if len(u.Values) == 0 {
+		verr.Add(parent, "%sunion must define at least one value", ctx)
+	}
+	names := make(map[string]bool)
+	var objects []string
+	for _, v := range u.Values {
+		if names[v.Name] {
+			verr.Add(parent, "%sunion value %#v is defined twice", ctx, v.Name)
+		}
+		names[v.Name] = true
+		if v.Type == nil {
+			verr.Add(parent, "%stype of union value %#v is nil", ctx, v.Name)
+			continue
+		}
+		if v.Type.Kind() == FileKind {
+			verr.Add(parent, "%sunion value %#v cannot be a file", ctx, v.Name)
+		}
+		if v.Type.IsObject() || v.Type.IsHash() {
+			objects = append(objects, v.Name)
+		}
+		if u.Discriminator != "" {
+			if !v.Type.IsObject() {
+				verr.Add(parent, "%sunion value %#v must be an object as the union defines a discriminator", ctx, v.Name)
+			} else if _, ok := v.Type.ToObject()[u.Discriminator]; ok {
+				verr.Add(parent, "%sunion value %#v cannot define the discriminator attribute %#v", ctx, v.Name, u.Discriminator)
+			}
+		}
+		verr.Merge(v.Validate(fmt.Sprintf("union value %s", v.Name), parent))
+	}
+	if u.Discriminator == "" && len(objects) > 1 {
+		verr.Add(parent, "%sunion values %s are objects, the union must define a discriminator", ctx, strings.Join(objects, ", "))
+	}
+	return verr.AsError()
+}
+
 // Validate checks that the response definition is consistent: its status is set and the media
 // type definition if any is valid.
 func (r *ResponseDefinition) Validate() *dslengine.ValidationErrors {
@@ -50,6 +50,10 @@ func AttributeImports(att *design.AttributeDefinition, imports []*ImportSpec, se
 	}
 
 	switch t := att.Type.(type) {
+	case design.Primitive:
+		if t.Kind() == design.FileKind {
+			return appendImports(imports, []*ImportSpec{SimpleImport("mime/multipart")})
+		}
 	case *design.UserTypeDefinition:
 		return appendImports(imports, AttributeImports(t.AttributeDefinition, imports, seen))
 	case *design.MediaTypeDefinition:
@@ -65,6 +69,12 @@ func AttributeImports(att *design.AttributeDefinition, imports []*ImportSpec, se
 	case *design.Hash:
 		imports = appendImports(imports, AttributeImports(t.KeyType, imports, seen))
 		return appendImports(imports, AttributeImports(t.ElemType, imports, seen))
+	case *design.Union:
+		imports = appendImports(imports, []*ImportSpec{SimpleImport("encoding/json")})
+		for _, v := range t.Values {
+			imports = appendImports(imports, AttributeImports(v.AttributeDefinition, imports, seen))
+		}
+		return imports
 	}
 
 	return imports
@@ -57,7 +57,7 @@ func RecursivePublicizer(att *design.AttributeDefinition, source, target string,
 				catt,
 				fmt.Sprintf("%s.%s", source, Goify(n, true)),
 				fmt.Sprintf("%s.%s", target, Goify(n, true)),
-				catt.Type.IsPrimitive() && !att.IsPrimitivePointer(n),
+				catt.Type.IsPrimitive() && catt.Type.Kind() != design.FileKind && !att.IsPrimitivePointer(n),
 				depth+1,
 				false,
 			)
@@ -82,7 +82,8 @@ func Publicizer(att *design.AttributeDefinition, sourceField, targetField string
 		"init":        init,
 	}
 	switch {
-	case att.Type.IsPrimitive():
+	case att.Type.IsPrimitive(), att.Type.IsUnion():
+		// Union types have no private version.
 		publication = RunTemplate(simplePublicizeT, data)
 	case att.Type.IsObject():
 		if _, ok := att.Type.(*design.MediaTypeDefinition); ok {
@@ -76,17 +76,17 @@ func GoTypeDef(ds design.DataStructure, tabs int, jsonTags, private bool) string
 		return GoTypeName(t, nil, tabs, private)
 	case *design.Array:
 		d := GoTypeDef(actual.ElemType, tabs, jsonTags, private)
-		if actual.ElemType.Type.IsObject() {
+		if actual.ElemType.Type.IsObject() || actual.ElemType.Type.IsUnion() {
 			d = "*" + d
 		}
 		return "[]" + d
 	case *design.Hash:
 		keyDef := GoTypeDef(actual.KeyType, tabs, jsonTags, private)
-		if actual.KeyType.Type.IsObject() {
+		if actual.KeyType.Type.IsObject() || actual.KeyType.Type.IsUnion() {
 			keyDef = "*" + keyDef
 		}
 		elemDef := GoTypeDef(actual.ElemType, tabs, jsonTags, private)
-		if actual.ElemType.Type.IsObject() {
+		if actual.ElemType.Type.IsObject() || actual.ElemType.Type.IsUnion() {
 			elemDef = "*" + elemDef
 		}
 		return fmt.Sprintf("map[%s]%s", keyDef, elemDef)
@@ -116,7 +116,7 @@ func goTypeDefObject(obj design.Object, def *design.AttributeDefinition, tabs in
 		WriteTabs(&buffer, tabs+1)
 		field := obj[name]
 		typedef := GoTypeDef(field, tabs+1, jsonTags, private)
-		if (field.Type.IsPrimitive() && private) || field.Type.IsObject() || def.IsPrimitivePointer(name) {
+		if (field.Type.IsPrimitive() && field.Type.Kind() != design.FileKind && private) || field.Type.IsObject() || field.Type.IsUnion() || def.IsPrimitivePointer(name) {
 			typedef = "*" + typedef
 		}
 		fname := GoifyAtt(field, name, true)
@@ -179,7 +179,7 @@ func GoTypeRef(t design.DataType, required []string, tabs int, private bool) str
 			return "error"
 		}
 	}
-	if t.IsObject() {
+	if t.IsObject() || t.IsUnion() {
 		return "*" + tname
 	}
 	return tname
@@ -200,8 +200,7 @@ func GoTypeName(t design.DataType, required []string, tabs int, private bool) st
 	case design.Object:
 		att := &design.AttributeDefinition{Type: actual}
 		if len(required) > 0 {
-			requiredVal := &dslengine.ValidationDefinition{Required: required}
-			att.Validation.Merge(requiredVal)
+			att.Validation = &dslengine.ValidationDefinition{Required: required}
 		}
 		return GoTypeDef(att, tabs, false, private)
 	case *design.Hash:
@@ -211,6 +210,10 @@ func GoTypeName(t design.DataType, required []string, tabs int, private bool) st
 			GoTypeRef(actual.ElemType.Type, actual.ElemType.AllRequired(), tabs+1, private),
 		)
 	case *design.UserTypeDefinition:
+		if actual.IsUnion() {
+			// There is no private version of union types.
+			return Goify(actual.TypeName, true)
+		}
 		return Goify(actual.TypeName, !private)
 	case *design.MediaTypeDefinition:
 		if actual.IsError() {
@@ -241,6 +244,8 @@ func GoNativeType(t design.DataType) string {
 			return "uuid.UUID"
 		case design.AnyKind:
 			return "interface{}"
+		case design.FileKind:
+			return "*multipart.FileHeader"
 		default:
 			panic(fmt.Sprintf("goa bug: unknown primitive type %#v", actual))
 		}
@@ -250,6 +255,8 @@ func GoNativeType(t design.DataType) string {
 		return "map[string]interface{}"
 	case *design.Hash:
 		return fmt.Sprintf("map[%s]%s", GoNativeType(actual.KeyType.Type), GoNativeType(actual.ElemType.Type))
+	case *design.Union:
+		return "interface{}"
 	case *design.MediaTypeDefinition:
 		return GoNativeType(actual.Type)
 	case *design.UserTypeDefinition:
@@ -259,6 +266,12 @@ func GoNativeType(t design.DataType) string {
 	}
 }
 
+// GoUnionValueTypeName returns the name of the Go struct generated for the given value of the
+// union user type ut.
+func GoUnionValueTypeName(ut *design.UserTypeDefinition, v *design.UnionValue) string {
+	return Goify(ut.TypeName, true) + Goify(v.Name, true)
+}
+
 // GoTypeDesc returns the description of a type.  If no description is defined
 // for the type, one will be generated.
 func GoTypeDesc(t design.DataType, upper bool) string {
@@ -212,6 +212,43 @@ var _ = Describe("code generation", func() {
 					Ω(st).Should(Equal(expected))
 				})
 
+				Context("with a file", func() {
+					BeforeEach(func() {
+						object["file"] = &AttributeDefinition{Type: File}
+						required = &dslengine.ValidationDefinition{Required: []string{"file"}}
+					})
+
+					AfterEach(func() {
+						delete(object, "file")
+					})
+
+					It("uses a multipart file header pointer", func() {
+						Ω(st).Should(ContainSubstring("	File *multipart.FileHeader `form:\"file\" json:\"file\" xml:\"file\"`\n"))
+					})
+				})
+
+				Context("with a union", func() {
+					BeforeEach(func() {
+						union := &Union{Values: []*UnionValue{
+							{Name: "name", AttributeDefinition: &AttributeDefinition{Type: String}},
+							{Name: "count", AttributeDefinition: &AttributeDefinition{Type: Integer}},
+						}}
+						object["shape"] = &AttributeDefinition{Type: &UserTypeDefinition{
+							TypeName:            "Shape",
+							AttributeDefinition: &AttributeDefinition{Type: union},
+						}}
+						required = &dslengine.ValidationDefinition{Required: []string{"shape"}}
+					})
+
+					AfterEach(func() {
+						delete(object, "shape")
+					})
+
+					It("uses a pointer to the union type", func() {
+						Ω(st).Should(ContainSubstring("	Shape *Shape `form:\"shape\" json:\"shape\" xml:\"shape\"`\n"))
+					})
+				})
+
 				Context("using struct tags metadata", func() {
 					tn1 := "struct:tag:foo"
 					tv11 := "bar"
@@ -56,6 +56,7 @@ type Validator struct {
 	arrayValT *template.Template
 	hashValT  *template.Template
 	userValT  *template.Template
+	unionValT *template.Template
 	seen      map[string]*bytes.Buffer
 }
 
@@ -86,6 +87,10 @@ func NewValidator() *Validator {
 	if err != nil {
 		panic(err)
 	}
+	v.unionValT, err = template.New("union").Funcs(fm).Parse(unionValTmpl)
+	if err != nil {
+		panic(err)
+	}
 	return v
 }
 
@@ -246,6 +251,8 @@ func (v *Validator) recurse(att *design.AttributeDefinition, nonzero, required,
 		buf.Write(v.arrayValCode(att, nonzero, required, hasDefault, target, context, depth, private))
 	} else if h := att.Type.ToHash(); h != nil {
 		buf.Write(v.hashValCode(att, nonzero, required, hasDefault, target, context, depth, private))
+	} else if att.Type.IsUnion() {
+		buf.Write(v.unionValCode(att, target, context, depth))
 	} else {
 		validation := ValidationChecker(att, nonzero, required, hasDefault, target, context, depth, private)
 		if validation != "" {
@@ -258,38 +265,7 @@ func (v *Validator) recurse(att *design.AttributeDefinition, nonzero, required,
 func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, target, context string, depth int, private bool) string {
 	var validation string
 	if ds, ok := catt.Type.(design.DataStructure); ok {
-		// We need to check empirically whether there are validations to be
-		// generated, we can't just generate and check whether something was
-		// generated to avoid infinite recursions.
-		hasValidations := false
-		done := errors.New("done")
-		ds.Walk(func(a *design.AttributeDefinition) error {
-			if a.Validation != nil {
-				if private {
-					hasValidations = true
-					return done
-				}
-				// For public data structures there is a case where
-				// there is validation but no actual validation
-				// code: if the validation is a required validation
-				// that applies to attributes that cannot be nil or
-				// empty string i.e. primitive types other than
-				// string.
-				if !a.Validation.HasRequiredOnly() {
-					hasValidations = true
-					return done
-				}
-				for _, name := range a.Validation.Required {
-					att := a.Type.ToObject()[name]
-					if att != nil && (!att.Type.IsPrimitive() || att.Type.Kind() == design.StringKind) {
-						hasValidations = true
-						return done
-					}
-				}
-			}
-			return nil
-		})
-		if hasValidations {
+		if hasValidations(ds, private) {
 			validation = RunTemplate(v.userValT, map[string]interface{}{
 				"depth":  depth,
 				"target": fmt.Sprintf("%s.%s", target, GoifyAtt(catt, n, true)),
@@ -312,7 +288,7 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 		).String()
 	}
 	if validation != "" {
-		if catt.Type.IsObject() {
+		if catt.Type.IsObject() || catt.Type.IsUnion() {
 			validation = fmt.Sprintf("%sif %s.%s != nil {\n%s\n%s}",
 				Tabs(depth), target, GoifyAtt(catt, n, true), validation, Tabs(depth))
 		}
@@ -320,6 +296,82 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 	return validation
 }
 
+// unionValCode produces the code that validates the value of a union user type. The code
+// switches on the actual type of the value and validates it.
+func (v *Validator) unionValCode(att *design.AttributeDefinition, target, context string, depth int) []byte {
+	ut, ok := att.Type.(*design.UserTypeDefinition)
+	if !ok {
+		return nil
+	}
+	var cases []map[string]interface{}
+	for _, uv := range ut.ToUnion().Values {
+		var validation string
+		if ds, ok := uv.Type.(design.DataStructure); ok {
+			if hasValidations(ds, false) {
+				validation = RunTemplate(v.userValT, map[string]interface{}{
+					"depth":  depth + 2,
+					"target": "v.Value",
+				})
+				validation = fmt.Sprintf("%sif v.Value != nil {\n%s\n%s}", Tabs(depth+1), validation, Tabs(depth+1))
+			}
+		} else {
+			validation = v.recurse(uv.AttributeDefinition, false, true, false, "v.Value", context+"."+uv.Name, depth+1, false).String()
+			if validation != "" && uv.Type.IsObject() {
+				validation = fmt.Sprintf("%sif v.Value != nil {\n%s\n%s}", Tabs(depth+1), validation, Tabs(depth+1))
+			}
+		}
+		if validation != "" {
+			cases = append(cases, map[string]interface{}{
+				"typeName":   GoUnionValueTypeName(ut, uv),
+				"validation": validation,
+			})
+		}
+	}
+	if len(cases) == 0 {
+		return nil
+	}
+	return []byte(RunTemplate(v.unionValT, map[string]interface{}{
+		"depth":  depth,
+		"target": target,
+		"cases":  cases,
+	}))
+}
+
+// hasValidations checks empirically whether there are validations to be generated for the given
+// data structure, we can't just generate and check whether something was generated to avoid
+// infinite recursions.
+func hasValidations(ds design.DataStructure, private bool) bool {
+	res := false
+	done := errors.New("done")
+	ds.Walk(func(a *design.AttributeDefinition) error {
+		if a.Validation != nil {
+			if private {
+				res = true
+				return done
+			}
+			// For public data structures there is a case where
+			// there is validation but no actual validation
+			// code: if the validation is a required validation
+			// that applies to attributes that cannot be nil or
+			// empty string i.e. primitive types other than
+			// string.
+			if !a.Validation.HasRequiredOnly() {
+				res = true
+				return done
+			}
+			for _, name := range a.Validation.Required {
+				att := a.Type.ToObject()[name]
+				if att != nil && (!att.Type.IsPrimitive() || att.Type.Kind() == design.StringKind) {
+					res = true
+					return done
+				}
+			}
+		}
+		return nil
+	})
+	return res
+}
+
 // ValidationChecker produces Go code that runs the validation defined in the given attribute
 // definition against the content of the variable named target recursively.
 // context is used to keep track of recursion to produce helpful error messages in case of type
@@ -470,6 +522,12 @@ const (
 
 	userValTmpl = `{{ tabs .depth }}if err2 := {{ .target }}.Validate(); err2 != nil {
 {{ tabs .depth }}	err = goa.MergeErrors(err, err2)
+{{ tabs .depth }}}`
+
+	unionValTmpl = `{{ tabs .depth }}switch v := {{ .target }}.Value.(type) {
+{{- range .cases }}
+{{ tabs $.depth }}case *{{ .typeName }}:
+{{ .validation }}{{ end }}
 {{ tabs .depth }}}`
 
 	enumValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
@@ -511,7 +569,7 @@ const (
 	requiredValTmpl = `{{ $att := index $.attribute.Type.ToObject .required }}{{/*
 */}}{{ if and (not $.private) (eq $att.Type.Kind 4) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == "" {
 {{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{  .required  }}"))
-{{ tabs $.depth }}}{{ else if or $.private (not $att.Type.IsPrimitive) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == nil {
+{{ tabs $.depth }}}{{ else if or $.private (not $att.Type.IsPrimitive) (eq $att.Type.Kind 13) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == nil {
 {{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{ .required }}"))
 {{ tabs $.depth }}}{{ end }}`
 )
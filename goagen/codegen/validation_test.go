@@ -303,6 +303,66 @@ var _ = Describe("validation code generation", func() {
 				})
 			})
 
+			Context("of union user type", func() {
+				BeforeEach(func() {
+					min := 0.0
+					minLength := 2
+					circle := &design.UserTypeDefinition{
+						TypeName: "Circle",
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: design.Object{
+								"radius": &design.AttributeDefinition{
+									Type:       design.Integer,
+									Validation: &dslengine.ValidationDefinition{Minimum: &min},
+								},
+							},
+						},
+					}
+					attType = &design.UserTypeDefinition{
+						TypeName: "Shape",
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: &design.Union{
+								Values: []*design.UnionValue{
+									{Name: "circle", AttributeDefinition: &design.AttributeDefinition{Type: circle}},
+									{Name: "name", AttributeDefinition: &design.AttributeDefinition{
+										Type:       design.String,
+										Validation: &dslengine.ValidationDefinition{MinLength: &minLength},
+									}},
+									{Name: "count", AttributeDefinition: &design.AttributeDefinition{Type: design.Integer}},
+								},
+							},
+						},
+					}
+					validation = nil
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(unionValCode))
+				})
+
+				Context("with a required non-string attribute in a value", func() {
+					var circle *design.UserTypeDefinition
+
+					BeforeEach(func() {
+						circle = &design.UserTypeDefinition{
+							TypeName: "Circle",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"radius": &design.AttributeDefinition{Type: design.Number},
+								},
+								Validation: &dslengine.ValidationDefinition{Required: []string{"radius"}},
+							},
+						}
+						attType.(*design.UserTypeDefinition).Type.(*design.Union).Values[0].Type = circle
+					})
+
+					It("checks the attribute when validating the decoded value", func() {
+						valCode := codegen.NewValidator().Code(circle.AttributeDefinition, false, false, false, "ut", "response", 1, true)
+						Ω(valCode).Should(Equal(unionRequiredValCode))
+					})
+				})
+			})
+
 			Context("with a custom type metadata", func() {
 				JustBeforeEach(func() {
 					att.Metadata = map[string][]string{"struct:field:type": {"foo"}}
@@ -325,6 +385,23 @@ var _ = Describe("validation code generation", func() {
 })
 
 const (
+	unionValCode = `	switch v := val.Value.(type) {
+	case *ShapeCircle:
+		if v.Value != nil {
+			if err2 := v.Value.Validate(); err2 != nil {
+				err = goa.MergeErrors(err, err2)
+			}
+		}
+	case *ShapeName:
+			if utf8.RuneCountInString(v.Value) < 2 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`context.name`" + `, v.Value, utf8.RuneCountInString(v.Value), 2, true))
+		}
+	}`
+
+	unionRequiredValCode = `	if ut.Radius == nil {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`response`" + `, "radius"))
+	}`
+
 	enumValCode = `	if val != nil {
 		if !(*val == 1 || *val == 2 || *val == 3) {
 			err = goa.MergeErrors(err, goa.InvalidEnumValueError(` + "`context`" + `, *val, []interface{}{1, 2, 3}))
@@ -0,0 +1,428 @@
+package genapp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+type (
+	// ContractTest is the data used to render the contract test of an action.
+	ContractTest struct {
+		Name      string
+		FuncName  string
+		Method    string
+		Path      string
+		Headers   []*ContractHeader
+		Body      string
+		Responses []*ContractResponse
+	}
+
+	// ContractHeader is a request header of a contract test.
+	ContractHeader struct {
+		Name   string
+		Values []string
+	}
+
+	// ContractResponse is a response declared for the action of a contract test.
+	ContractResponse struct {
+		Status      int
+		Headers     []string
+		ContentType string
+		Views       []*ContractView
+	}
+
+	// ContractView is a view of the media type of a contract test response.
+	ContractView struct {
+		Name        string
+		Attributes  []string
+		Type        string
+		Validatable bool
+	}
+)
+
+// generateContractTest generates the contract tests of the API actions in the test directory.
+// The tests drive a http.Handler with requests built from the examples of the action parameters
+// and payload and check the responses against the design.
+func (g *Generator) generateContractTest() error {
+	if len(g.API.Resources) == 0 {
+		return nil
+	}
+	tmpl := template.Must(template.New("contract").Parse(contractTmpl))
+	outDir := filepath.Join(g.OutDir, "test")
+	appPkg, err := codegen.PackagePath(g.OutDir)
+	if err != nil {
+		return err
+	}
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport(appPkg),
+		codegen.SimpleImport("github.com/goadesign/goa/goatest"),
+	}
+
+	return g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		filename := filepath.Join(outDir, codegen.SnakeCase(res.Name)+"_contract.go")
+		file, err := codegen.SourceFileFor(filename)
+		if err != nil {
+			return err
+		}
+		title := fmt.Sprintf("%s: %s Contract Tests", g.API.Context(), res.Name)
+		if err := file.WriteHeader(title, "test", imports); err != nil {
+			return err
+		}
+
+		var tests []*ContractTest
+		err = res.IterateActions(func(action *design.ActionDefinition) error {
+			// Websocket and server-sent events actions do not complete a response.
+			if action.WebSocket() || action.ServerSentEvents() {
+				return nil
+			}
+			test, err := g.createContractTest(res, action)
+			if err != nil {
+				return fmt.Errorf("%s: %s", action.Context(), err)
+			}
+			tests = append(tests, test)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		g.genfiles = append(g.genfiles, filename)
+		data := map[string]interface{}{
+			"Resource": res.Name,
+			"RunName":  fmt.Sprintf("Run%sContract", codegen.Goify(res.Name, true)),
+			"Tests":    tests,
+		}
+		if err := tmpl.Execute(file, data); err != nil {
+			return err
+		}
+		return file.FormatCode()
+	})
+}
+
+// createContractTest builds the contract test of the given action. The request uses the first
+// route of the action, the required parameters and headers and those that define an example.
+func (g *Generator) createContractTest(res *design.ResourceDefinition, action *design.ActionDefinition) (*ContractTest, error) {
+	rand := g.API.RandomGenerator()
+	route := action.Routes[0]
+	test := &ContractTest{
+		Name:     fmt.Sprintf("%s %s", res.Name, action.Name),
+		FuncName: fmt.Sprintf("%s%sContract", codegen.Goify(action.Name, true), codegen.Goify(res.Name, true)),
+		Method:   route.Verb,
+	}
+
+	// Path and query string
+	var params design.Object
+	if action.Params != nil {
+		params = action.Params.Type.ToObject()
+	}
+	path := design.WildcardRegex.ReplaceAllStringFunc(route.FullPath(), func(w string) string {
+		name := design.WildcardRegex.FindStringSubmatch(w)[1]
+		var values []string
+		if att, ok := params[name]; ok {
+			values = exampleValues(att, rand)
+		}
+		return "/" + url.PathEscape(strings.Join(values, ","))
+	})
+	if action.QueryParams != nil {
+		var names []string
+		for name := range action.QueryParams.Type.ToObject() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		query := url.Values{}
+		for _, name := range names {
+			att := action.QueryParams.Type.ToObject()[name]
+			if att.Example == nil && !action.Params.IsRequired(name) {
+				continue
+			}
+			if values := exampleValues(att, rand); len(values) > 0 {
+				query[name] = values
+			}
+		}
+		if len(query) > 0 {
+			path += "?" + query.Encode()
+		}
+	}
+	test.Path = path
+
+	// Headers
+	hds := &design.AttributeDefinition{Type: design.Object{}}
+	for _, h := range []*design.AttributeDefinition{res.Headers, action.Headers} {
+		if h != nil {
+			hds.Merge(h)
+			hds.Validation = h.Validation
+		}
+	}
+	var names []string
+	for name := range hds.Type.ToObject() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		att := hds.Type.ToObject()[name]
+		if att.Example == nil && !hds.IsRequired(name) {
+			continue
+		}
+		if values := exampleValues(att, rand); len(values) > 0 {
+			test.Headers = append(test.Headers, &ContractHeader{Name: http.CanonicalHeaderKey(name), Values: values})
+		}
+	}
+
+	// Payload
+	if action.Payload != nil {
+		ex := action.Payload.GenerateExample(rand, nil)
+		if ex != nil && action.PayloadMultipart {
+			body, contentType, err := multipartBody(action.Payload.AttributeDefinition, ex)
+			if err != nil {
+				return nil, err
+			}
+			test.Body = body
+			test.Headers = append(test.Headers, &ContractHeader{Name: "Content-Type", Values: []string{contentType}})
+		} else if ex != nil {
+			b, err := json.Marshal(toStringMap(ex))
+			if err != nil {
+				return nil, err
+			}
+			test.Body = string(b)
+		}
+	}
+
+	// Responses
+	err := action.IterateResponses(func(resp *design.ResponseDefinition) error {
+		r, err := g.createContractResponse(resp)
+		if err != nil {
+			return err
+		}
+		test.Responses = append(test.Responses, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(test.Responses, func(i, j int) bool { return test.Responses[i].Status < test.Responses[j].Status })
+
+	return test, nil
+}
+
+// createContractResponse builds the contract of the given response. The body of responses whose
+// media type does not define a view must be rendered using one of the media type views.
+func (g *Generator) createContractResponse(resp *design.ResponseDefinition) (*ContractResponse, error) {
+	r := &ContractResponse{Status: resp.Status, ContentType: resp.MediaType}
+	if resp.Headers != nil {
+		for name := range resp.Headers.Type.ToObject() {
+			if resp.Headers.IsRequired(name) {
+				r.Headers = append(r.Headers, http.CanonicalHeaderKey(name))
+			}
+		}
+		sort.Strings(r.Headers)
+	}
+	mt := design.Design.MediaTypeWithIdentifier(resp.MediaType)
+	if mt == nil {
+		return r, nil
+	}
+	if mt.ContentType != "" {
+		r.ContentType = mt.ContentType
+	}
+	var views []string
+	if resp.ViewName != "" {
+		views = []string{resp.ViewName}
+	} else {
+		mt.IterateViews(func(v *design.ViewDefinition) error {
+			views = append(views, v.Name)
+			return nil
+		})
+	}
+	for _, view := range views {
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return nil, err
+		}
+		v := &ContractView{Name: view}
+		obj := p.Type.ToObject()
+		if p.IsArray() {
+			obj = p.Type.ToArray().ElemType.Type.ToObject()
+		}
+		if obj != nil {
+			v.Attributes = []string{}
+			for name := range obj {
+				v.Attributes = append(v.Attributes, name)
+			}
+			sort.Strings(v.Attributes)
+		}
+		if !p.IsError() {
+			v.Type = fmt.Sprintf("%s.%s", g.Target, codegen.GoTypeName(p, nil, 0, false))
+			v.Validatable = g.validator.Code(p.AttributeDefinition, false, false, false, "payload", "raw", 1, false) != ""
+		}
+		r.Views = append(r.Views, v)
+	}
+	return r, nil
+}
+
+// multipartBody returns the multipart/form-data request body built from the example of the
+// given multipart payload and the corresponding Content-Type header value. Files are sent with the
+// example file name and no content. The parts are written in a fixed order and with a fixed
+// boundary so that the generated code is stable.
+func multipartBody(payload *design.AttributeDefinition, ex interface{}) (string, string, error) {
+	values, _ := toStringMap(ex).(map[string]interface{})
+	var names []string
+	for name := range payload.Type.ToObject() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if err := mw.SetBoundary("goa-contract-boundary"); err != nil {
+		return "", "", err
+	}
+	for _, name := range names {
+		att := payload.Type.ToObject()[name]
+		v := values[name]
+		if v == nil {
+			continue
+		}
+		elems := []interface{}{v}
+		if att.Type.IsArray() {
+			att = att.Type.ToArray().ElemType
+			rv := reflect.ValueOf(v)
+			if rv.Kind() != reflect.Slice {
+				continue
+			}
+			elems = make([]interface{}, rv.Len())
+			for i := range elems {
+				elems[i] = rv.Index(i).Interface()
+			}
+		}
+		if !att.Type.IsPrimitive() {
+			continue
+		}
+		for _, elem := range elems {
+			if att.Type.Kind() == design.FileKind {
+				if _, err := mw.CreateFormFile(name, exampleString(elem)); err != nil {
+					return "", "", err
+				}
+				continue
+			}
+			if err := mw.WriteField(name, exampleString(elem)); err != nil {
+				return "", "", err
+			}
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return "", "", err
+	}
+	return buf.String(), mw.FormDataContentType(), nil
+}
+
+// exampleValues returns the string representations of the example of the given parameter or
+// header attribute, one per element for arrays.
+func exampleValues(att *design.AttributeDefinition, rand *design.RandomGenerator) []string {
+	ex := att.GenerateExample(rand, nil)
+	if ex == nil {
+		return nil
+	}
+	v := reflect.ValueOf(ex)
+	if v.Kind() != reflect.Slice {
+		return []string{exampleString(ex)}
+	}
+	values := make([]string, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		values[i] = exampleString(v.Index(i).Interface())
+	}
+	return values
+}
+
+// exampleString returns the string representation of a primitive example value.
+func exampleString(ex interface{}) string {
+	switch actual := ex.(type) {
+	case time.Time:
+		return actual.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(actual, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", actual)
+	}
+}
+
+// toStringMap converts map[interface{}]interface{} to a map[string]interface{} when possible.
+func toStringMap(val interface{}) interface{} {
+	switch actual := val.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{})
+		for k, v := range actual {
+			m[fmt.Sprintf("%v", k)] = toStringMap(v)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{})
+		for k, v := range actual {
+			m[k] = toStringMap(v)
+		}
+		return m
+	case []interface{}:
+		mapSlice := make([]interface{}, len(actual))
+		for i, e := range actual {
+			mapSlice[i] = toStringMap(e)
+		}
+		return mapSlice
+	default:
+		return actual
+	}
+}
+
+const contractTmpl = `{{ $resource := .Resource }}{{ range .Tests }}
+// {{ .FuncName }} returns the contract test of the {{ .Name }} action. The request is built from
+// the examples of the action parameters and payload, the responses are the ones declared in the
+// design.
+func {{ .FuncName }}() *goatest.ContractTest {
+	return &goatest.ContractTest{
+		Name:   {{ printf "%q" .Name }},
+		Method: {{ printf "%q" .Method }},
+		Path:   {{ printf "%q" .Path }},
+{{ if .Headers }}		Header: http.Header{
+{{ range .Headers }}			{{ printf "%q" .Name }}: { {{ range $i, $v := .Values }}{{ if $i }}, {{ end }}{{ printf "%q" $v }}{{ end }} },
+{{ end }}		},
+{{ end }}{{ if .Body }}		Body: []byte({{ printf "%q" .Body }}),
+{{ end }}		Responses: []*goatest.ContractResponse{
+{{ range .Responses }}			{
+				Status: {{ .Status }},
+{{ if .Headers }}				Headers: []string{ {{ range $i, $h := .Headers }}{{ if $i }}, {{ end }}{{ printf "%q" $h }}{{ end }} },
+{{ end }}{{ if .ContentType }}				ContentType: {{ printf "%q" .ContentType }},
+{{ end }}{{ if .Views }}				Views: []*goatest.ContractView{
+{{ range .Views }}					{
+						Name: {{ printf "%q" .Name }},
+{{ if .Attributes }}						Attributes: []string{ {{ range $i, $a := .Attributes }}{{ if $i }}, {{ end }}{{ printf "%q" $a }}{{ end }} },
+{{ end }}{{ if .Type }}						Validate: func(body []byte) error {
+							var mt {{ .Type }}
+							{{ if .Validatable }}if err := json.Unmarshal(body, &mt); err != nil {
+								return err
+							}
+							return mt.Validate(){{ else }}return json.Unmarshal(body, &mt){{ end }}
+						},
+{{ end }}					},
+{{ end }}				},
+{{ end }}			},
+{{ end }}		},
+	}
+}
+{{ end }}
+// {{ .RunName }} runs the contract tests of the {{ $resource }} actions against the given handler,
+// typically the mux of a service whose controllers are mounted.
+func {{ .RunName }}(t goatest.TInterface, handler http.Handler) {
+{{ range .Tests }}	goatest.RunContract(t, handler, {{ .FuncName }}())
+{{ end }}}
+`
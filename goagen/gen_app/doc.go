@@ -1,5 +1,12 @@
 /*
 Package genapp provides the generator for the handlers, context data structures and tests of a goa
 application. It generates the glue between user code and the low level router.
+
+The generated test package contains helpers that call the controller actions in-process as well as
+contract tests that send requests built from the design examples to a http.Handler - typically the
+service mux - and check that the responses match the status, headers and media type views declared
+in the design. Native Go fuzz targets are also generated in the app package for each action
+payload, they feed arbitrary request bodies through the registered decoders and the generated
+finalize, validate and publicize code using the design examples as seed corpus.
 */
 package genapp
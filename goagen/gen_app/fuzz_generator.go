@@ -0,0 +1,159 @@
+package genapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// FuzzTarget is the data used to render the fuzz target of an action payload.
+type FuzzTarget struct {
+	Name      string
+	Resource  string
+	Action    string
+	Unmarshal string
+	Seeds     []string
+}
+
+// generateFuzzTest generates native Go fuzz targets for the payloads of the API actions. The
+// targets live in the app package so that they may call the generated unmarshal functions which
+// run the registered decoders, the finalizers, the validations and the publicizers.
+func (g *Generator) generateFuzzTest() error {
+	var targets []*FuzzTarget
+	err := g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload == nil || a.PayloadMultipart {
+				return nil
+			}
+			target := &FuzzTarget{
+				Name:      fmt.Sprintf("Fuzz%s%sPayload", codegen.Goify(a.Name, true), codegen.Goify(res.Name, true)),
+				Resource:  res.Name,
+				Action:    a.Name,
+				Unmarshal: fmt.Sprintf("unmarshal%s%sPayload", codegen.Goify(a.Name, true), codegen.Goify(res.Name, true)),
+			}
+			if ex := a.Payload.GenerateExample(g.API.RandomGenerator(), nil); ex != nil {
+				b, err := json.Marshal(toStringMap(ex))
+				if err != nil {
+					return fmt.Errorf("%s: %s", a.Context(), err)
+				}
+				target.Seeds = append(target.Seeds, string(b))
+			}
+			if a.Payload.IsObject() {
+				target.Seeds = append(target.Seeds, "{}")
+			}
+			targets = append(targets, target)
+			return nil
+		})
+	})
+	if err != nil || len(targets) == 0 {
+		return err
+	}
+
+	decoders, err := BuildEncoders(g.API.Consumes, false)
+	if err != nil {
+		return err
+	}
+	var contentTypes []string
+	for _, d := range decoders {
+		contentTypes = append(contentTypes, d.MIMETypes[0])
+	}
+	if len(contentTypes) == 0 {
+		contentTypes = []string{"application/json"}
+	}
+
+	filename := filepath.Join(g.OutDir, "fuzz_test.go")
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, filename)
+	// Native fuzzing requires Go 1.18
+	if _, err := file.Write([]byte("//go:build go1.18\n// +build go1.18\n\n")); err != nil {
+		return err
+	}
+	title := fmt.Sprintf("%s: Payload Fuzz Tests", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("bytes"),
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("net/http/httptest"),
+		codegen.SimpleImport("net/url"),
+		codegen.SimpleImport("testing"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	if err := file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	data := map[string]interface{}{
+		"ContentTypes": contentTypes,
+		"Targets":      targets,
+	}
+	if err := file.ExecuteTemplate("fuzz", fuzzT, nil, data); err != nil {
+		return err
+	}
+	return file.FormatCode()
+}
+
+// fuzzT generates the fuzz targets of the action payloads.
+// template input: map[string]interface{}
+const fuzzT = `
+// fuzzContentTypes lists the content types of the request bodies sent to each fuzz target, one
+// per decoder registered by initService.
+var fuzzContentTypes = []string{ {{ range $i, $ct := .ContentTypes }}{{ if $i }}, {{ end }}{{ printf "%q" $ct }}{{ end }} }
+
+// fuzzPayload runs unmarshal with a request whose body is the given data. The fuzzing engine
+// reports the panics, fuzzPayload checks that the JSON encoding of a payload that unmarshals
+// successfully unmarshals again.
+func fuzzPayload(t *testing.T, service *goa.Service, contentType string, body []byte, unmarshal func(context.Context, *goa.Service, *http.Request) error) {
+	payload, err := fuzzUnmarshal(t, service, contentType, body, unmarshal)
+	if err != nil {
+		return
+	}
+	if payload == nil {
+		t.Fatalf("%s: no payload after successful unmarshal", contentType)
+	}
+	if contentType != "application/json" {
+		return
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("%s: failed to encode payload: %s", contentType, err)
+	}
+	if _, err := fuzzUnmarshal(t, service, contentType, b, unmarshal); err != nil {
+		t.Errorf("%s: encoded payload %s does not unmarshal: %s", contentType, b, err)
+	}
+}
+
+// fuzzUnmarshal runs unmarshal with a request whose body is the given data and returns the
+// resulting payload.
+func fuzzUnmarshal(t *testing.T, service *goa.Service, contentType string, body []byte, unmarshal func(context.Context, *goa.Service, *http.Request) error) (interface{}, error) {
+	req, err := http.NewRequest("POST", "/", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	ctx := goa.NewContext(context.Background(), httptest.NewRecorder(), req, url.Values{})
+	if err := unmarshal(ctx, service, req); err != nil {
+		return nil, err
+	}
+	return goa.ContextRequest(ctx).Payload, nil
+}
+{{ range .Targets }}
+// {{ .Name }} feeds arbitrary request bodies to the decoders, validations and
+// publicizer of the {{ .Resource }} {{ .Action }} action payload. The seed corpus is derived from
+// the design examples.
+func {{ .Name }}(f *testing.F) {
+{{ range .Seeds }}	f.Add([]byte({{ printf "%q" . }}))
+{{ end }}	service := goa.New("fuzz")
+	initService(service)
+	f.Fuzz(func(t *testing.T, body []byte) {
+		for _, ct := range fuzzContentTypes {
+			fuzzPayload(t, service, ct, body, {{ .Unmarshal }})
+		}
+	})
+}
+{{ end }}`
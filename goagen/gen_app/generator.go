@@ -107,6 +107,12 @@ func (g *Generator) Generate() (_ []string, err error) {
 		if err := g.generateResourceTest(); err != nil {
 			return nil, err
 		}
+		if err := g.generateContractTest(); err != nil {
+			return nil, err
+		}
+		if err := g.generateFuzzTest(); err != nil {
+			return nil, err
+		}
 	}
 
 	return g.genfiles, nil
@@ -131,6 +137,7 @@ func (g *Generator) generateContexts() error {
 	}
 	title := fmt.Sprintf("%s: Application Contexts", g.API.Context())
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("strconv"),
@@ -146,6 +153,9 @@ func (g *Generator) generateContexts() error {
 			if a.Payload != nil {
 				imports = codegen.AttributeImports(a.Payload.AttributeDefinition, imports, nil)
 			}
+			if a.EventStream != nil {
+				imports = codegen.AttributeImports(a.EventStream, imports, nil)
+			}
 			return nil
 		})
 	})
@@ -192,6 +202,8 @@ func (g *Generator) generateContexts() error {
 				API:          g.API,
 				DefaultPkg:   g.Target,
 				Security:     a.Security,
+				Events:       a.EventStream,
+				Pagination:   a.Pagination,
 			}
 			return ctxWr.Execute(&ctxData)
 		})
@@ -218,6 +230,9 @@ func (g *Generator) generateControllers() error {
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.SimpleImport("github.com/goadesign/goa/cors"),
 		codegen.SimpleImport("regexp"),
+		codegen.SimpleImport("strconv"),
+		codegen.SimpleImport("time"),
+		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
 	}
 	encoders, err := BuildEncoders(g.API.Produces, true)
 	if err != nil {
@@ -247,7 +262,10 @@ func (g *Generator) generateControllers() error {
 	ctlWr.WriteHeader(title, g.Target, imports)
 	ctlWr.WriteInitService(encoders, decoders)
 
-	var controllersData []*ControllerTemplateData
+	var (
+		controllersData []*ControllerTemplateData
+		idempotent      bool
+	)
 	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
 		// Create file servers for all directory file servers that serve index.html.
 		fileServers := r.FileServers
@@ -276,14 +294,19 @@ func (g *Generator) generateControllers() error {
 			context := fmt.Sprintf("%s%sContext", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
 			unmarshal := fmt.Sprintf("unmarshal%s%sPayload", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
 			action := map[string]interface{}{
-				"Name":            codegen.Goify(a.Name, true),
-				"DesignName":      a.Name,
-				"Routes":          a.Routes,
-				"Context":         context,
-				"Unmarshal":       unmarshal,
-				"Payload":         a.Payload,
-				"PayloadOptional": a.PayloadOptional,
-				"Security":        a.Security,
+				"Name":             codegen.Goify(a.Name, true),
+				"DesignName":       a.Name,
+				"Routes":           a.Routes,
+				"Context":          context,
+				"Unmarshal":        unmarshal,
+				"Payload":          a.Payload,
+				"PayloadOptional":  a.PayloadOptional,
+				"PayloadMultipart": a.PayloadMultipart,
+				"Security":         a.Security,
+				"Idempotent":       a.Idempotent,
+			}
+			if a.Idempotent {
+				idempotent = true
 			}
 			data.Actions = append(data.Actions, action)
 			return nil
@@ -306,6 +329,11 @@ func (g *Generator) generateControllers() error {
 	if err = ctlWr.Execute(controllersData); err != nil {
 		return err
 	}
+	if idempotent {
+		if err = ctlWr.WriteIdempotency(); err != nil {
+			return err
+		}
+	}
 	return ctlWr.FormatCode()
 }
 
@@ -327,6 +355,7 @@ func (g *Generator) generateSecurity() error {
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("errors"),
 		codegen.SimpleImport("context"),
+		codegen.SimpleImport("time"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
 	}
 	secWr.WriteHeader(title, g.Target, imports)
@@ -190,7 +190,7 @@ var _ = Describe("Generate", func() {
 
 			It("generates the corresponding code", func() {
 				Ω(genErr).Should(BeNil())
-				Ω(files).Should(HaveLen(8))
+				Ω(files).Should(HaveLen(9))
 
 				isSource("contexts.go", contextsCode)
 				isSource("controllers.go", controllersCode)
@@ -172,6 +172,33 @@ var _ = Describe("Generate", func() {
 									},
 								},
 							},
+							"upload": {
+								Name: "upload",
+								Params: &design.AttributeDefinition{Type: design.Object{}},
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "POST",
+										Path: "upload",
+									},
+								},
+								Payload: &design.UserTypeDefinition{
+									AttributeDefinition: &design.AttributeDefinition{
+										Type: design.Object{
+											"file": &design.AttributeDefinition{Type: design.File},
+											"name": &design.AttributeDefinition{Type: design.String, Example: "bottle"},
+										},
+										Validation: &dslengine.ValidationDefinition{Required: []string{"file", "name"}},
+									},
+									TypeName: "UploadPayload",
+								},
+								PayloadMultipart: true,
+								Responses: map[string]*design.ResponseDefinition{
+									"noContent": {
+										Name:   "noContent",
+										Status: 204,
+									},
+								},
+							},
 						},
 					},
 				},
@@ -185,7 +212,7 @@ var _ = Describe("Generate", func() {
 
 		It("does not call Validate on the resulting media type when it does not exist", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(8))
+			Ω(files).Should(HaveLen(10))
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 
@@ -194,7 +221,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates the ActionRouteResponse test methods ", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(8))
+			Ω(files).Should(HaveLen(10))
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 
@@ -257,10 +284,119 @@ var _ = Describe("Generate", func() {
 			Ω(content).Should(ContainSubstring(", payload app.CustomName)"))
 		})
 
+		It("generates the contract tests", func() {
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_contract.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(content).Should(ContainSubstring("func ShowFooContract() *goatest.ContractTest {"))
+			Ω(content).Should(ContainSubstring(`Name:   "foo show",`))
+			Ω(content).Should(ContainSubstring(`Method: "GET",`))
+			Ω(content).Should(MatchRegexp(`Path:   "/p/-?\d+/u/[0-9a-f-]+/[^"/]+\?required=[^"&]+",`))
+			Ω(content).Should(ContainSubstring(`"Requiredheader":`))
+			Ω(content).Should(ContainSubstring(`"Requiredresourceheader":`))
+			Ω(content).ShouldNot(ContainSubstring(`"Optionalheader":`))
+			Ω(content).Should(ContainSubstring(`ContentType: "application/vnd.goa.test.int",`))
+			Ω(content).Should(ContainSubstring(`Attributes: []string{"foo"},`))
+			Ω(content).Should(ContainSubstring("var mt app.IntContainer"))
+			Ω(content).Should(ContainSubstring("return json.Unmarshal(body, &mt)"))
+			Ω(content).ShouldNot(ContainSubstring("return mt.Validate()"))
+			Ω(content).Should(ContainSubstring("func GetFooContract() *goatest.ContractTest {"))
+			Ω(content).Should(ContainSubstring("Body:"))
+			Ω(content).Should(ContainSubstring("func UploadFooContract() *goatest.ContractTest {"))
+			Ω(content).Should(ContainSubstring(`"Content-Type":           {"multipart/form-data; boundary=goa-contract-boundary"},`))
+			Ω(content).Should(MatchRegexp(`Content-Disposition: form-data; name=\\"file\\"; filename=\\"[^"\\]+\.txt\\"`))
+			Ω(content).Should(ContainSubstring(`Content-Disposition: form-data; name=\"name\"\r\n\r\nbottle\r\n`))
+			Ω(content).Should(ContainSubstring("func RunFooContract(t goatest.TInterface, handler http.Handler) {"))
+			Ω(content).Should(ContainSubstring("goatest.RunContract(t, handler, ShowFooContract())"))
+		})
+
+		It("generates the payload fuzz targets", func() {
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "fuzz_test.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(string(content)).Should(HavePrefix("//go:build go1.18\n"))
+			Ω(content).Should(ContainSubstring("package app"))
+			Ω(content).Should(ContainSubstring(`var fuzzContentTypes = []string{"application/json"}`))
+			Ω(content).Should(ContainSubstring("func FuzzGetFooPayload(f *testing.F) {"))
+			Ω(content).Should(ContainSubstring("initService(service)"))
+			Ω(content).Should(ContainSubstring("fuzzPayload(t, service, ct, body, unmarshalGetFooPayload)"))
+			Ω(content).Should(ContainSubstring("b, err := json.Marshal(payload)"))
+			Ω(content).ShouldNot(ContainSubstring("Validate()"))
+			Ω(content).ShouldNot(ContainSubstring("FuzzShowFooPayload"))
+			Ω(content).ShouldNot(ContainSubstring("FuzzUploadFooPayload"))
+		})
+
 		It("generates header compliant with https://github.com/golang/go/issues/13560", func() {
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(strings.Split(string(content), "\n")).Should(ContainElement(MatchRegexp(`^// Code generated .* DO NOT EDIT\.$`)))
 		})
 	})
+
+	Context("with a response media type that has validations", func() {
+		BeforeEach(func() {
+			min := 1.0
+			countAttr := &design.AttributeDefinition{
+				Type: design.Object{
+					"count": &design.AttributeDefinition{
+						Type:       design.Integer,
+						Validation: &dslengine.ValidationDefinition{Minimum: &min},
+					},
+				},
+			}
+
+			countMedia := &design.MediaTypeDefinition{
+				Identifier: "application/vnd.goa.test.count",
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: countAttr,
+					TypeName:            "CountContainer",
+				},
+			}
+			countMedia.Views = map[string]*design.ViewDefinition{
+				"default": {AttributeDefinition: countAttr, Name: "default", Parent: countMedia},
+			}
+
+			design.Design = &design.APIDefinition{
+				Name: "testapi",
+				MediaTypes: map[string]*design.MediaTypeDefinition{
+					design.ErrorMedia.Identifier: design.ErrorMedia,
+					countMedia.Identifier:        countMedia,
+				},
+				Resources: map[string]*design.ResourceDefinition{
+					"bar": {
+						Name: "bar",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name:   "show",
+								Params: &design.AttributeDefinition{Type: design.Object{}},
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: ""}},
+								Responses: map[string]*design.ResponseDefinition{
+									"ok": {
+										Name:      "ok",
+										Status:    200,
+										MediaType: countMedia.Identifier,
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			barRes := design.Design.Resources["bar"]
+			for _, a := range barRes.Actions {
+				a.Parent = barRes
+				a.Routes[0].Parent = a
+			}
+		})
+
+		It("validates the response bodies in the contract tests", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "bar_contract.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(content).Should(ContainSubstring("var mt app.CountContainer"))
+			Ω(content).Should(ContainSubstring("if err := json.Unmarshal(body, &mt); err != nil {"))
+			Ω(content).Should(ContainSubstring("return mt.Validate()"))
+		})
+	})
 })
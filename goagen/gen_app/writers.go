@@ -85,6 +85,8 @@ type (
 		API          *design.APIDefinition
 		DefaultPkg   string
 		Security     *design.SecurityDefinition
+		Events       *design.AttributeDefinition
+		Pagination   *design.PaginationDefinition
 	}
 
 	// ControllerTemplateData contains the information required to generate an action handler.
@@ -237,6 +239,29 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			}
 		}
 	}
+	if data.Events != nil {
+		events := data.Events
+		if mt, ok := events.Type.(*design.MediaTypeDefinition); ok {
+			projected, _, err := mt.Project(design.DefaultView)
+			if err != nil {
+				return err
+			}
+			events = &design.AttributeDefinition{Type: projected}
+		}
+		streamData := map[string]interface{}{
+			"Context":    data,
+			"StreamName": codegen.Goify(data.ActionName, true) + codegen.Goify(data.ResourceName, true) + "Stream",
+			"Events":     events,
+		}
+		if err := w.ExecuteTemplate("stream", ctxStreamT, nil, streamData); err != nil {
+			return err
+		}
+	}
+	if data.Pagination != nil {
+		if err := w.ExecuteTemplate("page", ctxPageT, nil, data); err != nil {
+			return err
+		}
+	}
 	return data.IterateResponses(func(resp *design.ResponseDefinition) error {
 		respData := map[string]interface{}{
 			"Context":  data,
@@ -338,6 +363,8 @@ func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
 		fn := template.FuncMap{
 			"finalizeCode":   w.Finalizer.Code,
 			"validationCode": w.Validator.Code,
+			"newCoerceData":  newCoerceData,
+			"arrayAttribute": arrayAttribute,
 		}
 		if err := w.ExecuteTemplate("unmarshal", unmarshalT, fn, d); err != nil {
 			return err
@@ -346,6 +373,12 @@ func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
 	return nil
 }
 
+// WriteIdempotency writes the code that runs the idempotency middleware for the idempotent
+// actions.
+func (w *ControllersWriter) WriteIdempotency() error {
+	return w.ExecuteTemplate("idempotency", idempotencyT, nil, nil)
+}
+
 // NewSecurityWriter returns a security functionality code writer.
 // Those functionalities are there to support action-middleware related to security.
 func NewSecurityWriter(filename string) (*SecurityWriter, error) {
@@ -433,8 +466,16 @@ func NewUserTypesWriter(filename string) (*UserTypesWriter, error) {
 // Execute writes the code for the context types to the writer.
 func (w *UserTypesWriter) Execute(t *design.UserTypeDefinition) error {
 	fn := template.FuncMap{
-		"finalizeCode":   w.Finalizer.Code,
-		"validationCode": w.Validator.Code,
+		"finalizeCode":       w.Finalizer.Code,
+		"validationCode":     w.Validator.Code,
+		"unionValueTypeName": codegen.GoUnionValueTypeName,
+		"unionPrivateType":   unionPrivateType,
+	}
+	if t.IsUnion() {
+		fn["unionValidationCode"] = func(t *design.UserTypeDefinition) string {
+			return w.Validator.Code(&design.AttributeDefinition{Type: t}, false, false, false, "u", "response", 1, false)
+		}
+		return w.ExecuteTemplate("union", unionT, fn, t)
 	}
 	return w.ExecuteTemplate("types", userTypeT, fn, t)
 }
@@ -451,6 +492,17 @@ func newCoerceData(name string, att *design.AttributeDefinition, pointer bool, p
 	}
 }
 
+// unionPrivateType returns the user type of the union value if the generated code defines a
+// private type for it, nil otherwise. Union values of such types are decoded into the private
+// type so that the required attributes can be validated before the value is publicized.
+func unionPrivateType(v *design.UnionValue) *design.UserTypeDefinition {
+	ut, ok := v.Type.(*design.UserTypeDefinition)
+	if !ok || !ut.IsObject() {
+		return nil
+	}
+	return ut
+}
+
 // arrayAttribute returns the array element attribute definition.
 func arrayAttribute(a *design.AttributeDefinition) *design.AttributeDefinition {
 	return a.Type.(*design.Array).ElemType
@@ -630,6 +682,48 @@ func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}({{ if .Response.
 }
 `
 
+	// ctxStreamT generates the server-sent events stream type and the context method that
+	// creates it.
+	// template input: map[string]interface{}
+	ctxStreamT = `// {{ .StreamName }} streams the server-sent events of the {{ .Context.ResourceName }} {{ .Context.ActionName }} action.
+type {{ .StreamName }} struct {
+	*goa.EventStream
+}
+
+// Stream writes the response headers and returns the stream used to send the events.
+func (ctx *{{ .Context.Name }}) Stream() (*{{ .StreamName }}, error) {
+	s, err := goa.NewEventStream(ctx.Context, ctx.ResponseData, ctx.RequestData)
+	if err != nil {
+		return nil, err
+	}
+	return &{{ .StreamName }}{EventStream: s}, nil
+}
+
+// Send encodes v as JSON and sends it in an event with the given ID, no ID is sent if id is empty.
+func (s *{{ .StreamName }}) Send(id string, v {{ gotyperef .Events.Type .Events.AllRequired 0 false }}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.EventStream.Send(&goa.ServerSentEvent{ID: id, Data: data})
+}
+`
+
+	// ctxPageT generates the helper used by paginated actions to link to the next page.
+	// template input: *ContextTemplateData
+	ctxPageT = `{{ if .Pagination.CursorParam }}// SetNextPage sets the Link and X-Next-Cursor response headers so that clients may request the
+// page of results identified by cursor next. It must not be called for the last page.
+func (ctx *{{ .Name }}) SetNextPage(cursor string) {
+	ctx.ResponseData.Header().Set("X-Next-Cursor", cursor)
+	ctx.ResponseData.SetNextPage(ctx.RequestData.Request, {{ printf "%q" .Pagination.CursorParam }}, cursor)
+}
+{{ else }}// SetNextPage sets the Link response header so that clients may request the page of results
+// starting at offset next. It must not be called for the last page.
+func (ctx *{{ .Name }}) SetNextPage(offset int) {
+	ctx.ResponseData.SetNextPage(ctx.RequestData.Request, {{ printf "%q" .Pagination.OffsetParam }}, strconv.Itoa(offset))
+}
+{{ end }}`
+
 	// payloadT generates the payload type definition GoGenerator
 	// template input: *ContextTemplateData
 	payloadT = `{{ $payload := .Payload }}{{ if .Payload.IsObject }}// {{ gotypename .Payload nil 0 true }} is the {{ .ResourceName }} {{ .ActionName }} action payload.{{/*
@@ -721,7 +815,8 @@ func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Co
 {{ end }}		}
 {{ end }}		return ctrl.{{ .Name }}(rctx)
 	}
-{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
+{{ if .Idempotent }}	h = handleIdempotency(h)
+{{ end }}{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
 {{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
 {{ end }}{{ range .Routes }}	service.Mux.Handle("{{ .Verb }}", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}))
 	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" $action.Name }}, "route", {{ printf "%q" (printf "%s %s" .Verb .FullPath) }}{{ with $action.Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
@@ -732,6 +827,35 @@ func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Co
 {{ end }}	service.Mux.Handle("GET", "{{ .RequestPath }}", ctrl.MuxHandler("serve", h, nil))
 	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "files", {{ printf "%q" .FilePath }}, "route", {{ printf "%q" (printf "GET %s" .RequestPath) }}{{ with .Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
 {{ end }}}
+`
+
+	// idempotencyT generates the code that runs the idempotency middleware for the actions
+	// defined with the Idempotent DSL.
+	// template input: nil
+	idempotencyT = `
+type (
+	// Private type used to store the idempotency middleware in the service context
+	idempotencyMiddlewareKey struct{}
+)
+
+// UseIdempotencyMiddleware mounts the middleware that handles the Idempotency-Key header of the
+// requests made to idempotent actions onto the service. It must be called before the
+// controllers are created.
+func UseIdempotencyMiddleware(service *goa.Service, middleware goa.Middleware) {
+	service.Context = context.WithValue(service.Context, idempotencyMiddlewareKey{}, middleware)
+}
+
+// handleIdempotency creates a handler that runs the idempotency middleware mounted with
+// UseIdempotencyMiddleware if any.
+func handleIdempotency(h goa.Handler) goa.Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		middleware, ok := ctx.Value(idempotencyMiddlewareKey{}).(goa.Middleware)
+		if !ok {
+			return h(ctx, rw, req)
+		}
+		return middleware(h)(ctx, rw, req)
+	}
+}
 `
 
 	// handleCORST generates the code that checks whether a CORS request is authorized
@@ -768,17 +892,56 @@ func handle{{ .Resource }}Origin(h goa.Handler) goa.Handler {
 
 	// unmarshalT generates the code for an action payload unmarshal function.
 	// template input: *ControllerTemplateData
-	unmarshalT = `{{ range .Actions }}{{ if .Payload }}
+	unmarshalT = `{{ define "Coerce" }}` + coerceT + `{{ end }}` + `{{ range .Actions }}{{ if .Payload }}
 // {{ .Unmarshal }} unmarshals the request body into the context request data Payload field.
 func {{ .Unmarshal }}(ctx context.Context, service *goa.Service, req *http.Request) error {
-	{{ if .Payload.IsObject }}payload := &{{ gotypename .Payload nil 1 true }}{}
+	{{ if .PayloadMultipart }}if err := req.ParseMultipartForm(32 << 20); err != nil {
+		return err
+	}
+	var err error
+	payload := &{{ gotypename .Payload nil 1 true }}{}
+{{ range $name, $att := .Payload.ToObject }}{{ if eq $att.Type.Kind 13 }}{{/*
+
+// FILE
+*/}}	if files := req.MultipartForm.File["{{ $name }}"]; len(files) > 0 {
+		payload.{{ goifyatt $att $name true }} = files[0]
+	}
+{{ else if $att.Type.IsArray }}{{ if eq (arrayAttribute $att).Type.Kind 13 }}{{/*
+
+// ARRAY OF FILES
+*/}}	if files := req.MultipartForm.File["{{ $name }}"]; len(files) > 0 {
+		payload.{{ goifyatt $att $name true }} = files
+	}
+{{ else }}{{/*
+
+// ARRAY
+*/}}	if values := req.MultipartForm.Value["{{ $name }}"]; len(values) > 0 {
+{{ if eq (arrayAttribute $att).Type.Kind 4 }}		payload.{{ goifyatt $att $name true }} = values
+{{ else }}		elems := make({{ gotyperef $att.Type nil 2 true }}, len(values))
+		for i, raw{{ goify $name true }} := range values {
+{{ template "Coerce" (newCoerceData $name (arrayAttribute $att) false "elems[i]" 3) }}		}
+		payload.{{ goifyatt $att $name true }} = elems
+{{ end }}	}
+{{ end }}{{ else }}{{/*
+
+// PRIMITIVE
+*/}}	if values := req.MultipartForm.Value["{{ $name }}"]; len(values) > 0 {
+		raw{{ goify $name true }} := values[0]
+{{ template "Coerce" (newCoerceData $name $att true (printf "payload.%s" (goifyatt $att $name true)) 2) }}	}
+{{ end }}{{ end }}	if err != nil {
+		return err
+	}{{ $assignment := finalizeCode .Payload.AttributeDefinition "payload" 1 }}{{ if $assignment }}
+	payload.Finalize(){{ end }}{{ else if .Payload.IsObject }}payload := &{{ gotypename .Payload nil 1 true }}{}
 	if err := service.DecodeRequest(req, payload); err != nil {
 		return err
 	}{{ $assignment := finalizeCode .Payload.AttributeDefinition "payload" 1 }}{{ if $assignment }}
-	payload.Finalize(){{ end }}{{ else }}var payload {{ gotypename .Payload nil 1 false }}
+	payload.Finalize(){{ end }}{{ else if .Payload.IsUnion }}payload := &{{ gotypename .Payload nil 1 false }}{}
+	if err := service.DecodeRequest(req, payload); err != nil {
+		return err
+	}{{ else }}var payload {{ gotypename .Payload nil 1 false }}
 	if err := service.DecodeRequest(req, &payload); err != nil {
 		return err
-	}{{ end }}{{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 false }}{{ if $validation }}
+	}{{ end }}{{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 false }}{{ if or $validation .Payload.IsUnion }}
 	if err := payload.Validate(); err != nil {
 		// Initialize payload with private data structure so it can be logged
 		goa.ContextRequest(ctx).Payload = payload
@@ -854,6 +1017,107 @@ func (ut {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
 }{{ end }}
+`
+
+	// unionT generates the code for a union user type.
+	// template input: UserTypeTemplateData
+	unionT = `{{ $typeName := gotypename . nil 0 false }}{{ $union := .ToUnion }}// {{ gotypedesc . true }}
+type {{ $typeName }} struct {
+	// Value is one of{{ range $i, $v := $union.Values }}{{ if $i }},{{ end }} *{{ unionValueTypeName $ $v }}{{ end }}.
+	Value {{ $typeName }}Value
+}
+
+// {{ $typeName }}Value is the interface implemented by the {{ $typeName }} values.
+type {{ $typeName }}Value interface {
+	is{{ $typeName }}Value()
+}
+{{ range $union.Values }}{{ $valueTypeName := unionValueTypeName $ . }}
+// {{ $valueTypeName }} is the {{ printf "%q" .Name }} {{ $typeName }} value.
+type {{ $valueTypeName }} struct {
+	Value {{ gotyperef .Type .AllRequired 1 false }}
+}
+
+func (*{{ $valueTypeName }}) is{{ $typeName }}Value() {}
+{{ end }}
+// MarshalJSON encodes the actual value of the union.
+func (u {{ $typeName }}) MarshalJSON() ([]byte, error) {
+	switch v := u.Value.(type) {
+{{- range $union.Values }}
+	case *{{ unionValueTypeName $ . }}:
+{{- if $union.Discriminator }}
+		return goa.MarshalUnionValue(v.Value, {{ printf "%q" $union.Discriminator }}, {{ printf "%q" .Name }})
+{{- else }}
+		return json.Marshal(v.Value)
+{{- end }}{{ end }}
+	}
+	return []byte("null"), nil
+}
+
+// UnmarshalJSON decodes the union value.
+func (u *{{ $typeName }}) UnmarshalJSON(data []byte) error {
+{{- if $union.Discriminator }}
+	name, err := goa.UnionDiscriminator(data, {{ printf "%q" $union.Discriminator }})
+	if err != nil {
+		return err
+	}
+	switch name {
+{{- range $union.Values }}
+	case {{ printf "%q" .Name }}:
+{{- $ut := unionPrivateType . }}{{ if $ut }}
+		var v {{ gotypename $ut $ut.AllRequired 2 true }}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}{{ if finalizeCode $ut.AttributeDefinition "ut" 1 }}
+		v.Finalize(){{ end }}{{ if validationCode $ut.AttributeDefinition false false false "ut" "response" 1 true }}
+		if err := v.Validate(); err != nil {
+			return err
+		}{{ end }}
+		u.Value = &{{ unionValueTypeName $ . }}{Value: v.Publicize()}
+{{- else }}
+		var v {{ gotyperef .Type .AllRequired 2 false }}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		u.Value = &{{ unionValueTypeName $ . }}{Value: v}
+{{- end }}{{ end }}
+	default:
+		return fmt.Errorf("invalid {{ $typeName }} {{ $union.Discriminator }} %q", name)
+	}
+	return nil
+{{- else }}{{ range $union.Values }}{{ $ut := unionPrivateType . }}{{ if $ut }}
+	{
+		var v {{ gotypename $ut $ut.AllRequired 2 true }}
+		err := json.Unmarshal(data, &v){{ $finalize := finalizeCode $ut.AttributeDefinition "ut" 1 }}{{ $validation := validationCode $ut.AttributeDefinition false false false "ut" "response" 1 true }}{{ if or $finalize $validation }}
+		if err == nil {
+{{- if $finalize }}
+			v.Finalize()
+{{- end }}{{ if $validation }}
+			err = v.Validate()
+{{- end }}
+		}{{ end }}
+		if err == nil {
+			u.Value = &{{ unionValueTypeName $ . }}{Value: v.Publicize()}
+			return nil
+		}
+	}
+{{- else }}
+	{
+		var v {{ gotyperef .Type .AllRequired 2 false }}
+		if err := json.Unmarshal(data, &v); err == nil {
+			u.Value = &{{ unionValueTypeName $ . }}{Value: v}
+			return nil
+		}
+	}
+{{- end }}{{ end }}
+	return fmt.Errorf("value does not match any of the {{ $typeName }} types")
+{{- end }}
+}
+
+// Validate validates the {{ $typeName }} union value.
+func (u *{{ $typeName }}) Validate() (err error) {
+{{ unionValidationCode . }}
+	return
+}
 `
 
 	// securitySchemesT generates the code for the security module.
@@ -892,7 +1156,12 @@ func {{ $funcName }}() *goa.{{ .Context }} {
 		Scopes: map[string]string{
 {{ range $k, $v := . }}			{{ printf "%q" $k }}: {{ printf "%q" $v }},
 {{ end }}{{/*
-*/}}		},{{ end }}
+*/}}		},{{ end }}{{ if .Issuer }}
+		Issuer: {{ printf "%q" .Issuer }},{{ end }}{{ with .Audience }}
+		Audience: []string{ {{ range $i, $a := . }}{{ if $i }}, {{ end }}{{ printf "%q" $a }}{{ end }} },{{ end }}{{ if .Leeway }}
+		Leeway: time.Duration({{ printf "%d" .Leeway }}), // {{ .Leeway }}{{ end }}{{ with .Algorithms }}
+		Algorithms: []string{ {{ range $i, $a := . }}{{ if $i }}, {{ end }}{{ printf "%q" $a }}{{ end }} },{{ end }}{{ with .RequiredClaims }}
+		RequiredClaims: []string{ {{ range $i, $c := . }}{{ if $i }}, {{ end }}{{ printf "%q" $c }}{{ end }} },{{ end }}
 {{ end }}{{/*
 */}}	}
 {{ if .Description }} def.Description = {{ printf "%q" .Description }}
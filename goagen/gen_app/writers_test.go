@@ -3,6 +3,7 @@ package genapp_test
 import (
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/design/apidsl"
@@ -48,16 +49,19 @@ var _ = Describe("ContextsWriter", func() {
 		})
 
 		Context("with data", func() {
-			var params, headers *design.AttributeDefinition
+			var params, headers, events *design.AttributeDefinition
 			var payload *design.UserTypeDefinition
 			var responses map[string]*design.ResponseDefinition
 			var routes []*design.RouteDefinition
+			var pagination *design.PaginationDefinition
 
 			var data *genapp.ContextTemplateData
 
 			BeforeEach(func() {
 				params = nil
 				headers = nil
+				events = nil
+				pagination = nil
 				payload = nil
 				responses = nil
 				routes = nil
@@ -76,6 +80,8 @@ var _ = Describe("ContextsWriter", func() {
 					Routes:       routes,
 					API:          design.Design,
 					DefaultPkg:   "",
+					Events:       events,
+					Pagination:   pagination,
 				}
 			})
 
@@ -835,6 +841,63 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with server-sent events", func() {
+				BeforeEach(func() {
+					design.Design = new(design.APIDefinition)
+					events = &design.AttributeDefinition{
+						Type: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{"id": {Type: design.Integer}},
+							},
+							TypeName: "BottleEvent",
+						},
+					}
+				})
+
+				It("writes the stream code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(streamContext))
+				})
+			})
+
+			Context("with cursor pagination", func() {
+				BeforeEach(func() {
+					pagination = &design.PaginationDefinition{CursorParam: "cursor", SizeParam: "limit"}
+				})
+
+				It("writes the next page helper", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("func (ctx *ListBottleContext) SetNextPage(cursor string) {"))
+					Ω(written).Should(ContainSubstring(`ctx.ResponseData.Header().Set("X-Next-Cursor", cursor)`))
+					Ω(written).Should(ContainSubstring(`ctx.ResponseData.SetNextPage(ctx.RequestData.Request, "cursor", cursor)`))
+				})
+			})
+
+			Context("with offset pagination", func() {
+				BeforeEach(func() {
+					pagination = &design.PaginationDefinition{OffsetParam: "offset", SizeParam: "limit"}
+				})
+
+				It("writes the next page helper", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("func (ctx *ListBottleContext) SetNextPage(offset int) {"))
+					Ω(written).Should(ContainSubstring(`ctx.ResponseData.SetNextPage(ctx.RequestData.Request, "offset", strconv.Itoa(offset))`))
+				})
+			})
+
 			Context("with a object payload", func() {
 				BeforeEach(func() {
 					design.Design = new(design.APIDefinition)
@@ -994,10 +1057,13 @@ var _ = Describe("ControllersWriter", func() {
 			var payloads []*design.UserTypeDefinition
 			var encoders, decoders []*genapp.EncoderTemplateData
 			var origins []*design.CORSDefinition
+			var multipart, idempotent bool
 
 			var data []*genapp.ControllerTemplateData
 
 			BeforeEach(func() {
+				multipart = false
+				idempotent = false
 				actions = nil
 				verbs = nil
 				paths = nil
@@ -1034,9 +1100,11 @@ var _ = Describe("ControllersWriter", func() {
 								Verb: verbs[i],
 								Path: paths[i],
 							}},
-						"Context":   contexts[i],
-						"Unmarshal": unmarshal,
-						"Payload":   payload,
+						"Context":          contexts[i],
+						"Unmarshal":        unmarshal,
+						"Payload":          payload,
+						"PayloadMultipart": multipart,
+						"Idempotent":       idempotent,
 					}
 				}
 				if len(as) > 0 {
@@ -1146,6 +1214,84 @@ var _ = Describe("ControllersWriter", func() {
 				})
 			})
 
+			Context("with actions that take a multipart payload", func() {
+				BeforeEach(func() {
+					actions = []string{"upload"}
+					verbs = []string{"POST"}
+					paths = []string{"/accounts/:accountID/bottles/upload"}
+					contexts = []string{"UploadBottleContext"}
+					unmarshals = []string{"unmarshalUploadBottlePayload"}
+					multipart = true
+					payloads = []*design.UserTypeDefinition{
+						{
+							TypeName: "UploadBottlePayload",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"file": &design.AttributeDefinition{
+										Type: design.File,
+									},
+								},
+							},
+						},
+					}
+				})
+
+				It("writes the multipart payload unmarshal function", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("func unmarshalUploadBottlePayload("))
+					Ω(written).Should(ContainSubstring("req.ParseMultipartForm(32 << 20)"))
+					Ω(written).Should(ContainSubstring(`req.MultipartForm.File["file"]`))
+					Ω(written).ShouldNot(ContainSubstring("service.DecodeRequest(req, payload)"))
+				})
+
+				Context("with default values", func() {
+					BeforeEach(func() {
+						minLength := 1
+						payloads[0].Type.(design.Object)["name"] = &design.AttributeDefinition{
+							Type:         design.String,
+							DefaultValue: "bottle",
+							Validation:   &dslengine.ValidationDefinition{MinLength: &minLength},
+						}
+					})
+
+					It("finalizes the payload before validating it", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring("payload.Finalize()\n\tif err := payload.Validate(); err != nil {"))
+					})
+				})
+			})
+
+			Context("with idempotent actions", func() {
+				BeforeEach(func() {
+					actions = []string{"create"}
+					verbs = []string{"POST"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"CreateBottleContext"}
+					idempotent = true
+				})
+
+				It("runs the idempotency middleware", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					err = writer.WriteIdempotency()
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("h = handleIdempotency(h)"))
+					Ω(written).Should(ContainSubstring("func UseIdempotencyMiddleware(service *goa.Service, middleware goa.Middleware)"))
+					Ω(written).Should(ContainSubstring("func handleIdempotency(h goa.Handler) goa.Handler"))
+				})
+			})
+
 			Context("with multiple controllers", func() {
 				BeforeEach(func() {
 					actions = []string{"list", "show"}
@@ -1274,6 +1420,61 @@ var _ = Describe("ControllersWriter", func() {
 	})
 })
 
+var _ = Describe("SecurityWriter", func() {
+	var writer *genapp.SecurityWriter
+	var workspace *codegen.Workspace
+	var filename string
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		pkg, err := workspace.NewPackage("app")
+		Ω(err).ShouldNot(HaveOccurred())
+		src := pkg.CreateSourceFile("security.go")
+		filename = src.Abs()
+		writer, err = genapp.NewSecurityWriter(filename)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with a JWT security scheme declaring claims", func() {
+		var scheme *design.SecuritySchemeDefinition
+
+		BeforeEach(func() {
+			design.Design = design.NewAPIDefinition()
+			Ω(dslengine.Execute(func() {
+				scheme = apidsl.JWTSecurity("jwt", func() {
+					apidsl.Header("Authorization")
+					apidsl.Issuer("https://auth.example.com")
+					apidsl.Audience("api", "admin")
+					apidsl.Leeway(30 * time.Second)
+					apidsl.Algorithms("RS256")
+					apidsl.RequiredClaims("sub", "exp")
+				})
+			}, design.Design)).Should(BeTrue())
+			Ω(dslengine.Execute(scheme.DSLFunc, scheme)).Should(BeTrue())
+		})
+
+		It("writes the claims in the security definition", func() {
+			err := writer.Execute([]*design.SecuritySchemeDefinition{scheme})
+			Ω(err).ShouldNot(HaveOccurred())
+			b, err := ioutil.ReadFile(filename)
+			Ω(err).ShouldNot(HaveOccurred())
+			written := string(b)
+			Ω(written).Should(ContainSubstring("func NewJWTSecurity() *goa.JWTSecurity {"))
+			Ω(written).Should(ContainSubstring(`Issuer: "https://auth.example.com",`))
+			Ω(written).Should(ContainSubstring(`Audience: []string{ "api", "admin" },`))
+			Ω(written).Should(ContainSubstring(`Leeway: time.Duration(30000000000), // 30s`))
+			Ω(written).Should(ContainSubstring(`Algorithms: []string{ "RS256" },`))
+			Ω(written).Should(ContainSubstring(`RequiredClaims: []string{ "sub", "exp" },`))
+		})
+	})
+})
+
 var _ = Describe("HrefWriter", func() {
 	var writer *genapp.ResourcesWriter
 	var workspace *codegen.Workspace
@@ -1513,6 +1714,75 @@ var _ = Describe("UserTypesWriter", func() {
 					Ω(written).Should(ContainSubstring(userTypeIncludingHash))
 				})
 			})
+
+			Context("with a union user type", func() {
+				var discriminator string
+				var value *design.UnionValue
+
+				BeforeEach(func() {
+					discriminator = "kind"
+					square := &design.UserTypeDefinition{
+						TypeName: "Square",
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: design.Object{
+								"kind": &design.AttributeDefinition{Type: design.String},
+								"side": &design.AttributeDefinition{Type: design.Integer},
+							},
+						},
+					}
+					value = &design.UnionValue{Name: "square", AttributeDefinition: &design.AttributeDefinition{Type: square}}
+				})
+
+				JustBeforeEach(func() {
+					circle := &design.UserTypeDefinition{
+						TypeName: "Circle",
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: design.Object{
+								"kind":   &design.AttributeDefinition{Type: design.String},
+								"radius": &design.AttributeDefinition{Type: design.Number},
+							},
+							Validation: &dslengine.ValidationDefinition{Required: []string{"radius"}},
+						},
+					}
+					data = &design.UserTypeDefinition{
+						TypeName: "Shape",
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: &design.Union{
+								Discriminator: discriminator,
+								Values: []*design.UnionValue{
+									{Name: "circle", AttributeDefinition: &design.AttributeDefinition{Type: circle}},
+									value,
+								},
+							},
+						},
+					}
+				})
+
+				It("validates the object values decoded from JSON", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(unionDiscriminatorUnmarshal))
+				})
+
+				Context("without a discriminator", func() {
+					BeforeEach(func() {
+						discriminator = ""
+						value = &design.UnionValue{Name: "name", AttributeDefinition: &design.AttributeDefinition{Type: design.String}}
+					})
+
+					It("tries the next value when the object value is invalid", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(unionUnmarshal))
+					})
+				})
+			})
 		})
 	})
 })
@@ -2498,6 +2768,49 @@ type SimplePayload struct {
 }
 `
 
+	unionDiscriminatorUnmarshal = `
+	switch name {
+	case "circle":
+		var v circle
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		if err := v.Validate(); err != nil {
+			return err
+		}
+		u.Value = &ShapeCircle{Value: v.Publicize()}
+	case "square":
+		var v square
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		u.Value = &ShapeSquare{Value: v.Publicize()}
+	default:
+		return fmt.Errorf("invalid Shape kind %q", name)
+	}
+`
+
+	unionUnmarshal = `
+	{
+		var v circle
+		err := json.Unmarshal(data, &v)
+		if err == nil {
+			err = v.Validate()
+		}
+		if err == nil {
+			u.Value = &ShapeCircle{Value: v.Publicize()}
+			return nil
+		}
+	}
+	{
+		var v string
+		if err := json.Unmarshal(data, &v); err == nil {
+			u.Value = &ShapeName{Value: v}
+			return nil
+		}
+	}
+`
+
 	userTypeIncludingHash = `// complexPayload user type.
 type complexPayload struct {
 	Misc map[int]*miscPayload ` + "`" + `form:"misc,omitempty" json:"misc,omitempty" xml:"misc,omitempty"` + "`" + `
@@ -2533,3 +2846,28 @@ type ComplexPayload struct {
 }
 `
 )
+
+const streamContext = `
+// ListBottlesStream streams the server-sent events of the bottles list action.
+type ListBottlesStream struct {
+	*goa.EventStream
+}
+
+// Stream writes the response headers and returns the stream used to send the events.
+func (ctx *ListBottleContext) Stream() (*ListBottlesStream, error) {
+	s, err := goa.NewEventStream(ctx.Context, ctx.ResponseData, ctx.RequestData)
+	if err != nil {
+		return nil, err
+	}
+	return &ListBottlesStream{EventStream: s}, nil
+}
+
+// Send encodes v as JSON and sends it in an event with the given ID, no ID is sent if id is empty.
+func (s *ListBottlesStream) Send(id string, v *BottleEvent) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.EventStream.Send(&goa.ServerSentEvent{ID: id, Data: data})
+}
+`
@@ -52,6 +52,8 @@ func (g *Generator) generateMain(mainFile string, clientPkg, cliPkg string, func
 	hasBasicAuthSigners := false
 	hasAPIKeySigners := false
 	hasTokenSigners := false
+	hasOAuth2Signers := false
+	tokenURL := ""
 	for _, s := range g.API.SecuritySchemes {
 		if signerType(s) != "" {
 			hasSigners = true
@@ -60,8 +62,14 @@ func (g *Generator) generateMain(mainFile string, clientPkg, cliPkg string, func
 				hasBasicAuthSigners = true
 			case "apiKey":
 				hasAPIKeySigners = true
-			case "jwt", "oauth2":
+			case "jwt":
 				hasTokenSigners = true
+			case "oauth2":
+				hasTokenSigners = true
+				hasOAuth2Signers = true
+				if tokenURL == "" {
+					tokenURL = s.TokenURL
+				}
 			}
 		}
 	}
@@ -74,6 +82,8 @@ func (g *Generator) generateMain(mainFile string, clientPkg, cliPkg string, func
 		HasBasicAuthSigners bool
 		HasAPIKeySigners    bool
 		HasTokenSigners     bool
+		HasOAuth2Signers    bool
+		TokenURL            string
 	}{
 		API:                 g.API,
 		Version:             version,
@@ -82,6 +92,8 @@ func (g *Generator) generateMain(mainFile string, clientPkg, cliPkg string, func
 		HasBasicAuthSigners: hasBasicAuthSigners,
 		HasAPIKeySigners:    hasAPIKeySigners,
 		HasTokenSigners:     hasTokenSigners,
+		HasOAuth2Signers:    hasOAuth2Signers,
+		TokenURL:            tokenURL,
 	}
 	if err := file.ExecuteTemplate("main", mainTmpl, funcs, data); err != nil {
 		return err
@@ -99,12 +111,15 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 	funcs["defaultRouteParams"] = defaultRouteParams
 	funcs["defaultRouteTemplate"] = defaultRouteTemplate
 	funcs["joinNames"] = joinNames
+	funcs["clientHeaders"] = clientHeaders
+	funcs["conditionArgs"] = conditionArgs
 	funcs["joinRouteParams"] = joinRouteParams
 	funcs["routes"] = routes
 	funcs["flagType"] = flagType
 	funcs["cmdFieldType"] = cmdFieldTypeString
 	funcs["formatExample"] = formatExample
 	funcs["shouldAddExample"] = shouldAddExample
+	funcs["unionPayloadUsage"] = unionPayloadUsage
 	funcs["kebabCase"] = codegen.KebabCase
 
 	commandTypesTmpl := template.Must(template.New("commandTypes").Funcs(funcs).Parse(commandTypesTmpl))
@@ -391,6 +406,33 @@ type specialTypeResult struct {
 	Output string
 }
 
+// clientHeaders returns the headers of the action that are given to the client as individual
+// parameters, see splitConditionalHeaders.
+func clientHeaders(action *design.ActionDefinition) *design.AttributeDefinition {
+	headers, _ := splitConditionalHeaders(action)
+	return headers
+}
+
+// conditionArgs returns the arguments given to goaclient.ParseConditions to build the
+// conditions of the action request from the command flags, the empty string if the action
+// client does not accept conditions.
+func conditionArgs(action *design.ActionDefinition) string {
+	_, conditions := splitConditionalHeaders(action)
+	if len(conditions) == 0 {
+		return ""
+	}
+	args := make([]string, len(conditionalHeaders))
+	for i, n := range conditionalHeaders {
+		args[i] = `""`
+		for _, c := range conditions {
+			if c == n {
+				args[i] = "cmd." + codegen.Goify(n, true)
+			}
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
 // generate the relation and output of specially typed Params that need
 // custom convertion from String Flags to Rich objects in Client action
 //
@@ -581,6 +623,20 @@ func flagType(att *design.AttributeDefinition) string {
 	}
 }
 
+// unionPayloadUsage returns the usage of the payload flag for actions whose payload is a union.
+func unionPayloadUsage(ut *design.UserTypeDefinition) string {
+	u := ut.ToUnion()
+	names := make([]string, len(u.Values))
+	for i, v := range u.Values {
+		names[i] = v.Name
+	}
+	usage := fmt.Sprintf("Request body encoded in JSON, one of %s", strings.Join(names, ", "))
+	if u.Discriminator != "" {
+		usage += fmt.Sprintf(" identified by the %q attribute", u.Discriminator)
+	}
+	return usage
+}
+
 func shouldAddExample(ut *design.UserTypeDefinition) bool {
 	if ut == nil {
 		return false
@@ -614,8 +670,35 @@ func main() {
 	app.PersistentFlags().DurationVarP(&httpClient.Timeout, "timeout", "t", time.Duration(20) * time.Second, "Set the request timeout")
 	app.PersistentFlags().BoolVar(&c.Dump, "dump", false, "Dump HTTP request and response.")
 
+	// Register resilience flags and wrap the HTTP client accordingly once the flags are parsed
+	var retries, breakerThreshold, maxConns int
+	var retryBackoff, retryMaxBackoff, breakerCooldown time.Duration
+	var retryAll bool
+	app.PersistentFlags().IntVar(&retries, "retries", 0, "Maximum number of retries of failed requests")
+	app.PersistentFlags().DurationVar(&retryBackoff, "retry-backoff", 100*time.Millisecond, "Delay before the first retry, doubled for each retry")
+	app.PersistentFlags().DurationVar(&retryMaxBackoff, "retry-max-backoff", 10*time.Second, "Maximum delay between two retries")
+	app.PersistentFlags().BoolVar(&retryAll, "retry-all", false, "Retry requests whose method is not idempotent")
+	app.PersistentFlags().IntVar(&breakerThreshold, "breaker-threshold", 0, "Number of consecutive failures that opens the circuit breaker, 0 disables it")
+	app.PersistentFlags().DurationVar(&breakerCooldown, "breaker-cooldown", 30*time.Second, "Duration during which the circuit breaker stays open")
+	app.PersistentFlags().IntVar(&maxConns, "max-conns-per-host", 0, "Maximum number of concurrent requests per host, 0 means no limit")
+	app.PersistentPreRun = func(*cobra.Command, []string) {
+		doer := goaclient.LimitHost(goaclient.HTTPClientDoer(httpClient), maxConns)
+		if breakerThreshold > 0 {
+			doer = goaclient.NewCircuitBreaker(doer, breakerThreshold, breakerCooldown)
+		}
+		if retries > 0 {
+			doer = goaclient.Retry(doer, &goaclient.RetryPolicy{
+				MaxAttempts:        retries + 1,
+				MinBackoff:         retryBackoff,
+				MaxBackoff:         retryMaxBackoff,
+				RetryNonIdempotent: retryAll,
+			})
+		}
+		c.Doer = doer
+	}
+
 {{ if .HasSigners }}	// Register signer flags
-{{ if .HasBasicAuthSigners }} var user, pass string
+{{ if or .HasBasicAuthSigners .HasOAuth2Signers }} var user, pass string
 	app.PersistentFlags().StringVar(&user, "user", "", "Username used for authentication")
 	app.PersistentFlags().StringVar(&pass, "pass", "", "Password used for authentication")
 {{ end }}{{ if .HasAPIKeySigners }} var key, format string
@@ -624,12 +707,40 @@ func main() {
 {{ end }}{{ if .HasTokenSigners }} var token, typ string
 	app.PersistentFlags().StringVar(&token, "token", "", "Token used for authentication")
 	app.PersistentFlags().StringVar(&typ, "token-type", "Bearer", "Token type used for authentication")
+{{ end }}{{ if .HasOAuth2Signers }} var grant, tokenURL, clientID, clientSecret, refreshToken string
+	var scopes []string
+	app.PersistentFlags().StringVar(&grant, "grant", "", "OAuth2 grant used to retrieve tokens: client_credentials, password or refresh_token")
+	app.PersistentFlags().StringVar(&tokenURL, "token-url", {{ printf "%q" .TokenURL }}, "OAuth2 token endpoint URL")
+	app.PersistentFlags().StringVar(&clientID, "client-id", "", "OAuth2 client ID")
+	app.PersistentFlags().StringVar(&clientSecret, "client-secret", "", "OAuth2 client secret")
+	app.PersistentFlags().StringVar(&refreshToken, "refresh-token", "", "OAuth2 refresh token used with the refresh_token grant")
+	app.PersistentFlags().StringSliceVar(&scopes, "scope", nil, "OAuth2 scopes requested with the token")
 {{ end }}
 	// Parse flags and setup signers
 	app.ParseFlags(os.Args)
-{{ if .HasTokenSigners }}	source := &goaclient.StaticTokenSource{
+{{ if .HasTokenSigners }}	var source goaclient.TokenSource = &goaclient.StaticTokenSource{
 		StaticToken: &goaclient.StaticToken{Type: typ, Value: token},
 	}
+{{ end }}{{ if .HasOAuth2Signers }}	if grant != "" {
+		config := &goaclient.OAuth2Config{
+			TokenURL:     tokenURL,
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Scopes:       scopes,
+			HTTPClient:   httpClient,
+		}
+		switch grant {
+		case "client_credentials":
+			source = config.ClientCredentialsTokenSource()
+		case "password":
+			source = config.PasswordTokenSource(user, pass)
+		case "refresh_token":
+			source = config.RefreshTokenSource(refreshToken)
+		default:
+			fmt.Fprintf(os.Stderr, "invalid --grant value %q, must be one of client_credentials, password or refresh_token\n", grant)
+			os.Exit(-1)
+		}
+	}
 {{ end }}{{ end }}{{ range $security := .API.SecuritySchemes }}{{ $signer := signerType $security }}{{ if $signer }}{{/*
 */}}	{{ goify $security.SchemeName false }}Signer := new{{ goify $security.SchemeName true }}Signer({{ signerArgs $security }}){{ end }}
 {{ end }}
@@ -685,13 +796,19 @@ const commandTypesTmpl = `{{ $cmdName := goify (printf "%s%sCommand" .Name (titl
 	{{ $cmdName }} struct {
 {{ if .Payload }}		Payload string
 		ContentType string
-{{ end }}{{ $params := defaultRouteParams . }}{{ if $params }}{{ range $name, $att := $params.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
+{{ if .PayloadMultipart }}{{ range $name, $att := .Payload.ToObject }}{{ if eq $att.Type.Kind 13 }}		// {{ goify $name true }}File is the path to the file uploaded in the {{ $name }} form field.
+		{{ goify $name true }}File string
+{{ end }}{{ end }}{{ end }}{{ end }}{{ $params := defaultRouteParams . }}{{ if $params }}{{ range $name, $att := $params.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
 {{ end }}		{{ goify $name true }} {{ cmdFieldType $att.Type false }}
 {{ end }}{{ end }}{{ $params := .QueryParams }}{{ if $params }}{{ range $name, $att := $params.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
 {{ end }}		{{ goify $name true }} {{ cmdFieldType $att.Type false}}
 {{ end }}{{ end }}{{ $headers := .Headers }}{{ if $headers }}{{ range $name, $att := $headers.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
 {{ end }}		{{ goify $name true }} {{ cmdFieldType $att.Type false}}
-{{ end }}{{ end }}		PrettyPrint bool
+{{ end }}{{ end }}{{ if .EventStream }}		// LastEventID is the ID of the last event received, used to resume the event stream.
+		LastEventID string
+{{ end }}{{ if .Pagination }}		// All is true if the command follows the pages of results until the last one.
+		All bool
+{{ end }}		PrettyPrint bool
 	}
 
 `
@@ -715,9 +832,14 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 {{ else }}{{ $pparams := defaultRouteParams .Action }}	path = fmt.Sprintf({{ printf "%q" (defaultRouteTemplate .Action)}}, {{ joinRouteParams .Action $pparams }})
 {{ end }}	}
 	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
-	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Action.QueryParams .Action.Headers }}{{ $specialTypeResult.Output }}
+	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Action.QueryParams (clientHeaders .Action) }}{{ $specialTypeResult.Output }}{{ $conditionArgs := conditionArgs .Action }}{{ if $conditionArgs }}
+	conditions, err := goaclient.ParseConditions({{ $conditionArgs }})
+	if err != nil {
+		goa.LogError(ctx, "invalid conditional request headers", "err", err)
+		return err
+	}{{ end }}
 	ws, err := c.{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}(ctx, path{{/*
-	*/}}{{ $params := joinNames true .Action.QueryParams .Action.Headers }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }})
+	*/}}{{ $params := joinNames true .Action.QueryParams (clientHeaders .Action) }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }}{{ if conditionArgs .Action }}, conditions{{ end }})
 	if err != nil {
 		goa.LogError(ctx, "failed", "err", err)
 		return err
@@ -780,8 +902,12 @@ found:
 
 const registerTmpl = `{{ $cmdName := goify (printf "%s%sCommand" .Action.Name (title (kebabCase .Resource.Name))) true }}// RegisterFlags registers the command flags with the command line.
 func (cmd *{{ $cmdName }}) RegisterFlags(cc *cobra.Command, c *{{ .Package }}.Client) {
-{{ if .Action.Payload }}	cc.Flags().StringVar(&cmd.Payload, "payload", "", "Request body encoded in JSON")
-	cc.Flags().StringVar(&cmd.ContentType, "content", "", "Request content type override, e.g. 'application/x-www-form-urlencoded'")
+{{ if .Action.Payload }}	cc.Flags().StringVar(&cmd.Payload, "payload", "", {{ if .Action.Payload.IsUnion }}{{ printf "%q" (unionPayloadUsage .Action.Payload) }}{{ else }}"Request body encoded in JSON"{{ end }})
+{{ if .Action.PayloadMultipart }}{{ range $name, $att := .Action.Payload.ToObject }}{{ if eq $att.Type.Kind 13 }}{{/*
+*/}}	cc.Flags().StringVar(&cmd.{{ goify $name true }}File, "{{ $name }}", "", "Path to the file uploaded in the {{ $name }} form field")
+{{ end }}{{ end }}{{ else }}	cc.Flags().StringVar(&cmd.ContentType, "content", "", "Request content type override, e.g. 'application/x-www-form-urlencoded'")
+{{ end }}{{ end }}{{ if .Action.EventStream }}	cc.Flags().StringVar(&cmd.LastEventID, "last-event-id", "", "ID of the last event received, the server resumes the event stream after it")
+{{ end }}{{ if .Action.Pagination }}	cc.Flags().BoolVar(&cmd.All, "all", false, "Follow the pages of results and print each page until the last one")
 {{ end }}{{ $pparams := defaultRouteParams .Action }}{{ if $pparams }}{{ range $pname, $pparam := $pparams.Type.ToObject }}{{ $tmp := goify $pname false }}{{/*
 */}}{{ if not $pparam.DefaultValue }}	var {{ $tmp }} {{ cmdFieldType $pparam.Type false }}
 {{ end }}	cc.Flags().{{ flagType $pparam }}Var(&cmd.{{ goify $pname true }}, "{{ $pname }}", {{/*
@@ -813,20 +939,46 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 {{ else }}			return fmt.Errorf("failed to deserialize payload: %s", err)
 {{ end }}		}
 	}
-{{ end }}	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
-	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Action.QueryParams .Action.Headers }}{{ $specialTypeResult.Output }}
-	resp, err := c.{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}(ctx, path{{ if .Action.Payload }}, {{/*
-	*/}}{{ if or .Action.Payload.Type.IsObject .Action.Payload.IsPrimitive }}&{{ end }}payload{{ else }}{{ end }}{{/*
-	*/}}{{ $params := joinNames true .Action.QueryParams .Action.Headers }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }}{{/*
-	*/}}{{ if and .Action.Payload .HasMultiContent }}, cmd.ContentType{{ end }})
+{{ if .Action.PayloadMultipart }}{{ range $name, $att := .Action.Payload.ToObject }}{{ if eq $att.Type.Kind 13 }}{{/*
+*/}}	if cmd.{{ goify $name true }}File != "" {
+		fh, err := goaclient.NewFileHeader(cmd.{{ goify $name true }}File)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %s", cmd.{{ goify $name true }}File, err)
+		}
+		payload.{{ goifyatt $att $name true }} = fh
+	}
+{{ end }}{{ end }}{{ end }}{{ end }}	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
+	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Action.QueryParams (clientHeaders .Action) }}{{ $specialTypeResult.Output }}{{ $conditionArgs := conditionArgs .Action }}{{ if $conditionArgs }}
+	conditions, err := goaclient.ParseConditions({{ $conditionArgs }})
+	if err != nil {
+		goa.LogError(ctx, "invalid conditional request headers", "err", err)
+		return err
+	}{{ end }}
+{{ if .Action.Pagination }}	if cmd.All {
+		it := c.New{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}Iterator(path{{ if .Action.Payload }}, {{/*
+		*/}}{{ if or .Action.Payload.Type.IsObject .Action.Payload.IsPrimitive .Action.Payload.IsUnion }}&{{ end }}payload{{ end }}{{/*
+		*/}}{{ $params := joinNames true .Action.QueryParams (clientHeaders .Action) }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }}{{ if conditionArgs .Action }}, conditions{{ end }}{{/*
+		*/}}{{ if and .Action.Payload .HasMultiContent (not .Action.PayloadMultipart) }}, cmd.ContentType{{ end }})
+		if err := goaclient.HandlePages(ctx, c.Client, it, cmd.PrettyPrint); err != nil {
+			goa.LogError(ctx, "failed", "err", err)
+			return err
+		}
+		return nil
+	}
+{{ end }}	{{ if .Action.EventStream }}stream, err := c.Stream{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}(ctx, path, cmd.LastEventID{{/*
+	*/}}{{ else }}resp, err := c.{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}(ctx, path{{ end }}{{ if .Action.Payload }}, {{/*
+	*/}}{{ if or .Action.Payload.Type.IsObject .Action.Payload.IsPrimitive .Action.Payload.IsUnion }}&{{ end }}payload{{ else }}{{ end }}{{/*
+	*/}}{{ $params := joinNames true .Action.QueryParams (clientHeaders .Action) }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }}{{ if conditionArgs .Action }}, conditions{{ end }}{{/*
+	*/}}{{ if and .Action.Payload .HasMultiContent (not .Action.PayloadMultipart) }}, cmd.ContentType{{ end }})
 	if err != nil {
 		goa.LogError(ctx, "failed", "err", err)
 		return err
 	}
 
-	goaclient.HandleResponse(c.Client, resp, cmd.PrettyPrint)
+{{ if .Action.EventStream }}	return goaclient.HandleEvents(stream.EventReader, cmd.PrettyPrint)
+{{ else }}	goaclient.HandleResponse(c.Client, resp, cmd.PrettyPrint)
 	return nil
-}
+{{ end }}}
 `
 
 // Takes map[string][]*design.ActionDefinition as input
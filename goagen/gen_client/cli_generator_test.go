@@ -63,6 +63,18 @@ var _ = Describe("Generate", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 		})
 
+		It("registers the resilience flags", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "testapi-cli", "main.go"))
+			content := string(c)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`IntVar(&retries, "retries", 0`))
+			Ω(content).Should(ContainSubstring(`IntVar(&breakerThreshold, "breaker-threshold", 0`))
+			Ω(content).Should(ContainSubstring(`IntVar(&maxConns, "max-conns-per-host", 0`))
+			Ω(content).Should(ContainSubstring("doer = goaclient.Retry(doer, &goaclient.RetryPolicy{"))
+			Ω(content).Should(ContainSubstring("c.Doer = doer"))
+		})
+
 		Context("generated commands.go", func() {
 			var commandHeader string
 
@@ -491,4 +503,71 @@ var _ = Describe("Generate", func() {
 			Ω(content).Should(ContainSubstring("c.SetJWT1Signer(jwt1Signer)"))
 		})
 	})
+
+	Context("with paginated actions", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.Design = &design.APIDefinition{
+				Name:        "testapi",
+				Title:       "dummy API with paginated actions",
+				Description: "I told you it's dummy",
+				Consumes:    design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name: "list",
+								QueryParams: &design.AttributeDefinition{
+									Type: design.Object{
+										"offset": &design.AttributeDefinition{Type: design.Integer},
+										"limit":  &design.AttributeDefinition{Type: design.Integer},
+									},
+								},
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+								Pagination: &design.PaginationDefinition{
+									OffsetParam: "offset",
+									SizeParam:   "limit",
+								},
+							},
+							"browse": {
+								Name: "browse",
+								QueryParams: &design.AttributeDefinition{
+									Type: design.Object{
+										"cursor": &design.AttributeDefinition{Type: design.String},
+									},
+								},
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "/browse",
+									},
+								},
+								Pagination: &design.PaginationDefinition{
+									CursorParam: "cursor",
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			for _, a := range fooRes.Actions {
+				a.Parent = fooRes
+				a.Routes[0].Parent = a
+				a.Pagination.Parent = a
+			}
+		})
+
+		It("generates a client that compiles", func() {
+			Ω(genErr).Should(BeNil())
+			_, err := gexec.Build(filepath.Join(testgenPackagePath, "tool", "testapi-cli"))
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
 })
@@ -146,6 +146,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 			"defaultPath":        defaultPath,
 			"escapeBackticks":    escapeBackticks,
 			"goify":              codegen.Goify,
+			"goifyatt":           codegen.GoifyAtt,
 			"gotypedef":          codegen.GoTypeDef,
 			"gotypedesc":         codegen.GoTypeDesc,
 			"gotypename":         codegen.GoTypeName,
@@ -321,6 +322,7 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("io"),
 		codegen.SimpleImport("io/ioutil"),
+		codegen.SimpleImport("mime/multipart"),
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("net/url"),
 		codegen.SimpleImport("os"),
@@ -331,6 +333,7 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 		codegen.SimpleImport("context"),
 		codegen.SimpleImport("golang.org/x/net/websocket"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
 	}
 	title := fmt.Sprintf("%s: %s Resource Client", g.API.Context(), res.Name)
 	if err := file.WriteHeader(title, g.Target, imports); err != nil {
@@ -440,14 +443,18 @@ func (g *Generator) generateFileServer(file *codegen.SourceFile, fs *design.File
 
 func (g *Generator) generateActionClient(action *design.ActionDefinition, file *codegen.SourceFile, funcs template.FuncMap) error {
 	var (
-		params        []string
-		names         []string
-		queryParams   []*paramData
-		headers       []*paramData
-		signer        string
-		clientsTmpl   = template.Must(template.New("clients").Funcs(funcs).Parse(clientsTmpl))
-		requestsTmpl  = template.Must(template.New("requests").Funcs(funcs).Parse(requestsTmpl))
-		clientsWSTmpl = template.Must(template.New("clientsws").Funcs(funcs).Parse(clientsWSTmpl))
+		params         []string
+		names          []string
+		queryParams    []*paramData
+		headers        []*paramData
+		signer         string
+		clientsTmpl    = template.Must(template.New("clients").Funcs(funcs).Parse(clientsTmpl))
+		requestsTmpl   = template.Must(template.New("requests").Funcs(funcs).Parse(requestsTmpl))
+		clientsWSTmpl  = template.Must(template.New("clientsws").Funcs(funcs).Parse(clientsWSTmpl))
+		clientsSSETmpl = template.Must(template.New("clientssse").Funcs(funcs).Parse(clientsSSETmpl))
+		iteratorTmpl   = template.Must(template.New("iterator").Funcs(funcs).Parse(iteratorTmpl))
+		eventsType     string
+		page           *paramData
 	)
 	if action.Payload != nil {
 		params = append(params, "payload "+codegen.GoTypeRef(action.Payload, action.Payload.AllRequired(), 1, false))
@@ -472,11 +479,34 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		return append(reqData, optData...)
 	}
 	queryParams = initParamsScoped(action.QueryParams)
-	headers = initParamsScoped(action.Headers)
+	clientHeaders, conditions := splitConditionalHeaders(action)
+	headers = initParamsScoped(clientHeaders)
+	if len(conditions) > 0 {
+		params = append(params, "conditions *goaclient.Conditions")
+		names = append(names, "conditions")
+	}
+	if action.Pagination != nil {
+		for _, p := range queryParams {
+			if p.Name == action.Pagination.PageParam() {
+				page = p
+				break
+			}
+		}
+	}
 
 	if action.Security != nil {
 		signer = codegen.Goify(action.Security.Scheme.SchemeName, true)
 	}
+	if events := action.EventStream; events != nil {
+		if mt, ok := events.Type.(*design.MediaTypeDefinition); ok {
+			projected, _, err := mt.Project(design.DefaultView)
+			if err != nil {
+				return err
+			}
+			events = &design.AttributeDefinition{Type: projected}
+		}
+		eventsType = codegen.GoTypeRef(events.Type, events.AllRequired(), 1, false)
+	}
 	data := struct {
 		Name               string
 		ResourceName       string
@@ -484,6 +514,8 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Routes             []*design.RouteDefinition
 		HasPayload         bool
 		HasMultiContent    bool
+		PayloadMultipart   bool
+		MultipartFields    []*paramData
 		DefaultContentType string
 		Params             string
 		ParamNames         string
@@ -491,13 +523,18 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Signer             string
 		QueryParams        []*paramData
 		Headers            []*paramData
+		Conditional        bool
+		EventsType         string
+		Page               *paramData
 	}{
 		Name:               action.Name,
 		ResourceName:       action.Parent.Name,
 		Description:        action.Description,
 		Routes:             action.Routes,
 		HasPayload:         action.Payload != nil,
-		HasMultiContent:    len(design.Design.Consumes) > 1,
+		HasMultiContent:    len(design.Design.Consumes) > 1 && !action.PayloadMultipart,
+		PayloadMultipart:   action.Payload != nil && action.PayloadMultipart,
+		MultipartFields:    multipartParams(action),
 		DefaultContentType: design.Design.Consumes[0].MIMETypes[0],
 		Params:             strings.Join(params, ", "),
 		ParamNames:         strings.Join(names, ", "),
@@ -505,6 +542,9 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Signer:             signer,
 		QueryParams:        queryParams,
 		Headers:            headers,
+		Conditional:        len(conditions) > 0,
+		EventsType:         eventsType,
+		Page:               page,
 	}
 	if action.WebSocket() {
 		return clientsWSTmpl.Execute(file, data)
@@ -512,6 +552,16 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 	if err := clientsTmpl.Execute(file, data); err != nil {
 		return err
 	}
+	if action.ServerSentEvents() {
+		if err := clientsSSETmpl.Execute(file, data); err != nil {
+			return err
+		}
+	}
+	if page != nil {
+		if err := iteratorTmpl.Execute(file, data); err != nil {
+			return err
+		}
+	}
 	return requestsTmpl.Execute(file, data)
 }
 
@@ -564,17 +614,26 @@ func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) er
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
 	}
 	for _, v := range g.API.MediaTypes {
 		imports = codegen.AttributeImports(v.AttributeDefinition, imports, nil)
 	}
 	mtWr.WriteHeader(title, g.Target, imports)
+	errorDecoded := false
 	err = g.API.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
 		if (mt.Type.IsObject() || mt.Type.IsArray()) && !mt.IsError() {
 			if err := mtWr.Execute(mt); err != nil {
 				return err
 			}
 		}
+		if mt.IsError() {
+			// All error media types are decoded with the same DecodeErrorResponse method.
+			if errorDecoded {
+				return nil
+			}
+			errorDecoded = true
+		}
 		err := mt.IterateViews(func(view *design.ViewDefinition) error {
 			p, _, err := mt.Project(view.Name)
 			if err != nil {
@@ -828,6 +887,46 @@ func typeName(mt *design.MediaTypeDefinition) string {
 }
 
 // initParams returns required and optional paramData extracted from given attribute definition.
+// conditionalHeaders lists the conditional request headers in the order of the
+// goaclient.ParseConditions arguments.
+var conditionalHeaders = []string{"If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since"}
+
+// splitConditionalHeaders returns the headers of the action other than the conditional request
+// headers and the names of the conditional request headers of the action. The generated clients
+// of cacheable actions and of unsafe actions that use entity tags set the conditional request
+// headers using a goaclient.Conditions value rather than individual parameters.
+func splitConditionalHeaders(action *design.ActionDefinition) (*design.AttributeDefinition, []string) {
+	if action.Headers == nil || !action.Cacheable && !(action.ETag && action.IsUnsafe()) {
+		return action.Headers, nil
+	}
+	obj := action.Headers.Type.ToObject()
+	if obj == nil {
+		return action.Headers, nil
+	}
+	var conditions []string
+	for _, n := range conditionalHeaders {
+		if h, ok := obj[n]; ok && h.Type == design.String && !action.Headers.IsRequired(n) {
+			conditions = append(conditions, n)
+		}
+	}
+	if len(conditions) == 0 {
+		return action.Headers, nil
+	}
+	headers := make(design.Object, len(obj))
+	for n, h := range obj {
+		headers[n] = h
+	}
+	for _, n := range conditions {
+		delete(headers, n)
+	}
+	if len(headers) == 0 {
+		return nil, conditions
+	}
+	att := *action.Headers
+	att.Type = headers
+	return &att, conditions
+}
+
 func initParams(att *design.AttributeDefinition) ([]*paramData, []*paramData) {
 	if att == nil {
 		return nil, nil
@@ -871,6 +970,47 @@ func initParams(att *design.AttributeDefinition) ([]*paramData, []*paramData) {
 	return reqParamData, optParamData
 }
 
+// multipartParams returns the data needed to generate the code that encodes the fields of the
+// multipart payload of the given action, nil if the action payload is not multipart.
+func multipartParams(action *design.ActionDefinition) []*paramData {
+	if action.Payload == nil || !action.PayloadMultipart {
+		return nil
+	}
+	obj := action.Payload.ToObject()
+	if obj == nil {
+		return nil
+	}
+	def := action.Payload.Definition()
+	var params []*paramData
+	obj.IterateAttributes(func(n string, att *design.AttributeDefinition) error {
+		varName := "payload." + codegen.GoifyAtt(att, n, true)
+		param := &paramData{
+			Name:      n,
+			VarName:   varName,
+			ValueName: varName,
+			Attribute: att,
+		}
+		switch {
+		case att.Type.Kind() == design.FileKind:
+			param.IsFile = true
+			param.CheckNil = true
+		case att.Type.IsArray():
+			param.IsArray = true
+			param.ElemAttribute = att.Type.ToArray().ElemType
+			param.IsFile = param.ElemAttribute.Type.Kind() == design.FileKind
+		default:
+			param.MustToString = att.Type.Kind() != design.StringKind
+			if def.IsPrimitivePointer(n) {
+				param.ValueName = "*" + varName
+				param.CheckNil = true
+			}
+		}
+		params = append(params, param)
+		return nil
+	})
+	return params
+}
+
 // paramData is the data structure holding the information needed to generate query params and
 // headers handling code.
 type paramData struct {
@@ -881,6 +1021,7 @@ type paramData struct {
 	ElemAttribute *design.AttributeDefinition
 	MustToString  bool
 	IsArray       bool
+	IsFile        bool
 	CheckNil      bool
 }
 
@@ -904,10 +1045,11 @@ type {{ gotypename .Payload nil 1 false }} {{ gotypedef .Payload 0 true false }}
 
 	typeDecodeTmpl = `{{ $typeName := typeName . }}{{ $funcName := printf "Decode%s" $typeName }}// {{ $funcName }} decodes the {{ $typeName }} instance encoded in resp body.
 func (c *Client) {{ $funcName }}(resp *http.Response) ({{ decodegotyperef . .AllRequired 0 false }}, error) {
-	var decoded {{ decodegotypename . .AllRequired 0 false }}
+{{ if .IsError }}	return goaclient.DecodeError(c.Decoder, resp)
+{{ else }}	var decoded {{ decodegotypename . .AllRequired 0 false }}
 	err := c.Decoder.Decode(&decoded, resp.Body, resp.Header.Get("Content-Type"))
 	return {{ if .IsObject }}&{{ end }}decoded, err
-}
+{{ end }}}
 `
 
 	pathTmpl = `{{ $funcName := printf "%sPath%s" (goify (printf "%s%s" .Route.Parent.Name (title .Route.Parent.Parent.Name)) true) ((or (and .Index (add .Index 1)) "") | printf "%v") }}{{/*
@@ -968,6 +1110,93 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	cfg.Header["{{ $header.Name }}"] = []string{ {{ $tmp }} }
 {{ end }}	return websocket.DialConfig(cfg)
 }
+`
+
+	clientsSSETmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{/*
+*/}}// {{ $funcName }}Stream reads the server-sent events streamed by the {{ .Name }} action endpoint of the {{ .ResourceName }} resource.
+type {{ $funcName }}Stream struct {
+	*goaclient.EventReader
+}
+
+// Next reads the next event and decodes its data. It returns io.EOF once the server closes the stream.
+func (s *{{ $funcName }}Stream) Next() ({{ .EventsType }}, error) {
+	var v {{ .EventsType }}
+	e, err := s.EventReader.Next()
+	if err != nil {
+		return v, err
+	}
+	err = json.Unmarshal(e.Data, &v)
+	return v, err
+}
+
+// Stream{{ $funcName }} makes a request to the {{ .Name }} action endpoint of the {{ .ResourceName }} resource and
+// returns the stream of server-sent events. lastEventID is sent in the Last-Event-ID header if not empty.
+func (c *Client) Stream{{ $funcName }}(ctx context.Context, path string, lastEventID string{{ if .Params }}, {{ .Params }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType string{{ end }}) (*{{ $funcName }}Stream, error) {
+	req, err := c.New{{ $funcName }}Request(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType{{ end }})
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := c.Client.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		defer resp.Body.Close()
+		var body string
+		if b, err := ioutil.ReadAll(resp.Body); err == nil && len(b) > 0 {
+			body = ": " + string(b)
+		}
+		return nil, fmt.Errorf("%s%s", resp.Status, body)
+	}
+	return &{{ $funcName }}Stream{EventReader: goaclient.NewEventReader(resp.Body)}, nil
+}
+`
+
+	iteratorTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{/*
+*/}}// {{ $funcName }}Iterator iterates over the pages of results returned by the {{ .Name }} action endpoint of the {{ .ResourceName }} resource.
+type {{ $funcName }}Iterator struct {
+	next func(context.Context) (*http.Response, error)
+	done bool
+}
+
+// New{{ $funcName }}Iterator returns an iterator over the pages of results returned by the {{ .Name }} action endpoint of
+// the {{ .ResourceName }} resource starting with the page identified by the given parameters.
+func (c *Client) New{{ $funcName }}Iterator(path string{{ if .Params }}, {{ .Params }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType string{{ end }}) *{{ $funcName }}Iterator {
+	it := &{{ $funcName }}Iterator{}
+	it.next = func(ctx context.Context) (*http.Response, error) {
+		resp, err := c.{{ $funcName }}(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType{{ end }})
+		if err != nil {
+			return nil, err
+		}
+		nextPage := goaclient.NextPageParam(resp, "{{ .Page.Name }}")
+		if nextPage == "" {
+			it.done = true
+			return resp, nil
+		}
+{{ if eq .Page.Attribute.Type.Kind 2 }}		nextOffset, err := strconv.Atoi(nextPage)
+		if err != nil {
+			it.done = true
+			return resp, nil
+		}
+		{{ .Page.VarName }} = {{ if .Page.CheckNil }}&{{ end }}nextOffset
+{{ else }}		{{ .Page.VarName }} = {{ if .Page.CheckNil }}&{{ end }}nextPage
+{{ end }}		return resp, nil
+	}
+	return it
+}
+
+// Next makes the request for the next page and returns its response, the caller must close the
+// response body. It returns io.EOF once the last page has been retrieved.
+func (it *{{ $funcName }}Iterator) Next(ctx context.Context) (*http.Response, error) {
+	if it.done {
+		return nil, io.EOF
+	}
+	return it.next(ctx)
+}
 `
 
 	fsTmpl = `// {{ .Name }} downloads {{ if .DirName }}{{ .DirName }}files with the given filename{{ else }}{{ .FileName }}{{ end }} and writes it to the file dest.
@@ -1009,7 +1238,41 @@ func (c * Client) {{ .Name }}(ctx context.Context, {{ if .DirName }}filename, {{
 	requestsTmpl = `{{ $funcName := goify (printf "New%s%sRequest" (title .Name) (title .ResourceName)) true }}{{/*
 */}}// {{ $funcName }} create the request corresponding to the {{ .Name }} action endpoint of the {{ .ResourceName }} resource.
 func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}{{ if .HasPayload }}{{ if .HasMultiContent }}, contentType string{{ end }}{{ end }}) (*http.Request, error) {
-{{ if .HasPayload }}	var body bytes.Buffer
+{{ if .PayloadMultipart }}	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+{{ range .MultipartFields }}{{ if .CheckNil }}	if {{ .VarName }} != nil {
+{{ end }}{{/*
+
+// FILES
+*/}}{{ if .IsFile }}{{ if .IsArray }}	for _, fh := range {{ .VarName }} {
+		if err := goaclient.WriteFileField(w, "{{ .Name }}", fh); err != nil {
+			return nil, err
+		}
+	}
+{{ else }}	if err := goaclient.WriteFileField(w, "{{ .Name }}", {{ .VarName }}); err != nil {
+		return nil, err
+	}
+{{ end }}{{/*
+
+// ARRAY
+*/}}{{ else if .IsArray }}	for _, p := range {{ .VarName }} {
+		{{ $tmp := tempvar }}{{ toString "p" $tmp .ElemAttribute }}
+		if err := w.WriteField("{{ .Name }}", {{ $tmp }}); err != nil {
+			return nil, err
+		}
+	}
+{{/*
+
+// PRIMITIVE
+*/}}{{ else }}	{{ $tmp := tempvar }}{{ toString .ValueName $tmp .Attribute }}
+	if err := w.WriteField("{{ .Name }}", {{ $tmp }}); err != nil {
+		return nil, err
+	}
+{{ end }}{{ if .CheckNil }}	}
+{{ end }}{{ end }}	if err := w.Close(); err != nil {
+		return nil, err
+	}
+{{ else if .HasPayload }}	var body bytes.Buffer
 {{ if .HasMultiContent }}	if contentType == "" {
 		contentType = "*/*" // Use default encoder
 	}
@@ -1050,8 +1313,9 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 {{ end }}	if err != nil {
 		return nil, err
 	}
-{{ if or .HasPayload .Headers }}	header := req.Header
-{{ if .HasPayload }}{{ if .HasMultiContent }}	if contentType == "*/*" {
+{{ if or .HasPayload .Headers .Conditional }}	header := req.Header
+{{ if .PayloadMultipart }}	header.Set("Content-Type", w.FormDataContentType())
+{{ else if .HasPayload }}{{ if .HasMultiContent }}	if contentType == "*/*" {
 		header.Set("Content-Type", "{{ .DefaultContentType }}")
 	} else {
 		header.Set("Content-Type", contentType)
@@ -1062,6 +1326,9 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	header.Set("{{ .Name }}", {{ $tmp }}){{ else }}
 	header.Set("{{ .Name }}", {{ .ValueName }})
 {{ end }}{{ if .CheckNil }}	}{{ end }}
+{{ end }}{{ if .Conditional }}	if conditions != nil {
+		conditions.SetHeaders(header)
+	}
 {{ end }}{{ end }}{{ if .Signer }}	if c.{{ .Signer }}Signer != nil {
 		c.{{ .Signer }}Signer.Sign(req)
 	}
@@ -375,6 +375,226 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with an action streaming server-sent events", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"watch": {
+								Name: "watch",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "/watch",
+									},
+								},
+								EventStream: &design.AttributeDefinition{Type: design.String},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			watchAct := fooRes.Actions["watch"]
+			watchAct.Parent = fooRes
+			watchAct.Routes[0].Parent = watchAct
+		})
+
+		It("generates the stream client method", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (s *WatchFooStream) Next() (string, error) {"))
+			Ω(content).Should(ContainSubstring("func (c *Client) StreamWatchFoo(ctx context.Context, path string, lastEventID string) (*WatchFooStream, error) {"))
+			Ω(content).Should(ContainSubstring(`req.Header.Set("Last-Event-ID", lastEventID)`))
+		})
+
+		It("generates the command that prints the events", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("stream, err := c.StreamWatchFoo(ctx, path, cmd.LastEventID)"))
+			Ω(content).Should(ContainSubstring("return goaclient.HandleEvents(stream.EventReader, cmd.PrettyPrint)"))
+			Ω(content).Should(ContainSubstring(`cc.Flags().StringVar(&cmd.LastEventID, "last-event-id"`))
+		})
+	})
+
+	Context("with a paginated action", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name: "list",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "/foos",
+									},
+								},
+								QueryParams: &design.AttributeDefinition{
+									Type: design.Object{
+										"cursor": {Type: design.String},
+										"limit":  {Type: design.Integer},
+									},
+								},
+								Pagination: &design.PaginationDefinition{
+									CursorParam: "cursor",
+									SizeParam:   "limit",
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			listAct := fooRes.Actions["list"]
+			listAct.Parent = fooRes
+			listAct.Routes[0].Parent = listAct
+			listAct.Pagination.Parent = listAct
+		})
+
+		It("generates the page iterator", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (c *Client) NewListFooIterator(path string, cursor *string, limit *int) *ListFooIterator {"))
+			Ω(content).Should(ContainSubstring(`nextPage := goaclient.NextPageParam(resp, "cursor")`))
+			Ω(content).Should(ContainSubstring("cursor = &nextPage"))
+			Ω(content).Should(ContainSubstring("func (it *ListFooIterator) Next(ctx context.Context) (*http.Response, error) {"))
+		})
+
+		It("generates the --all flag", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring(`cc.Flags().BoolVar(&cmd.All, "all", false`))
+			Ω(content).Should(ContainSubstring("it := c.NewListFooIterator(path, "))
+			Ω(content).Should(ContainSubstring("goaclient.HandlePages(ctx, c.Client, it, cmd.PrettyPrint)"))
+		})
+	})
+
+	Context("with an offset paginated action", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name: "list",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "/foos",
+									},
+								},
+								QueryParams: &design.AttributeDefinition{
+									Type: design.Object{
+										"offset": {Type: design.Integer},
+										"limit":  {Type: design.Integer},
+									},
+								},
+								Pagination: &design.PaginationDefinition{
+									OffsetParam: "offset",
+									SizeParam:   "limit",
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			listAct := fooRes.Actions["list"]
+			listAct.Parent = fooRes
+			listAct.Routes[0].Parent = listAct
+			listAct.Pagination.Parent = listAct
+		})
+
+		It("parses the offset of the next page", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (c *Client) NewListFooIterator(path string, limit *int, offset *int) *ListFooIterator {"))
+			Ω(content).Should(ContainSubstring(`nextPage := goaclient.NextPageParam(resp, "offset")`))
+			Ω(content).Should(ContainSubstring("nextOffset, err := strconv.Atoi(nextPage)"))
+			Ω(content).Should(ContainSubstring("offset = &nextOffset"))
+		})
+	})
+
+	Context("with a cacheable action", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name: "show",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "/foos/1",
+									},
+								},
+								Headers: &design.AttributeDefinition{
+									Type: design.Object{
+										"If-None-Match":     {Type: design.String},
+										"If-Modified-Since": {Type: design.String},
+										"X-Trace":           {Type: design.String},
+									},
+								},
+								Cacheable: true,
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("generates a client that accepts conditions", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (c *Client) ShowFoo(ctx context.Context, path string, xTrace *string, conditions *goaclient.Conditions) (*http.Response, error) {"))
+			Ω(content).Should(ContainSubstring("conditions.SetHeaders(header)"))
+			Ω(content).Should(ContainSubstring(`header.Set("X-Trace", *xTrace)`))
+			Ω(content).ShouldNot(ContainSubstring(`header.Set("If-None-Match"`))
+		})
+
+		It("generates the command that builds the conditions from the flags", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring(`cc.Flags().StringVar(&cmd.IfNoneMatch, "If-None-Match"`))
+			Ω(content).Should(ContainSubstring(`conditions, err := goaclient.ParseConditions("", cmd.IfNoneMatch, cmd.IfModifiedSince, "")`))
+			Ω(content).Should(MatchRegexp(`resp, err := c\.ShowFoo\(ctx, path, .*, conditions\)`))
+		})
+	})
+
 	Context("with an action with multiple routes", func() {
 		BeforeEach(func() {
 			design.Design = &design.APIDefinition{
@@ -508,6 +728,39 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with an oauth2 security scheme", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			securitySchemeDef := &design.SecuritySchemeDefinition{
+				SchemeName: "oauth",
+				Kind:       design.OAuth2SecurityKind,
+				Type:       "oauth2",
+				TokenURL:   "http://example.com/token",
+			}
+			design.Design = &design.APIDefinition{
+				Name:        "testapi",
+				Title:       "dummy API with no resource",
+				Description: "I told you it's dummy",
+				Consumes:    design.DefaultEncoders,
+				SecuritySchemes: []*design.SecuritySchemeDefinition{
+					securitySchemeDef,
+				},
+			}
+		})
+
+		It("generates the OAuth2 grant flags", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "testapi-cli", "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`StringVar(&grant, "grant", ""`))
+			Ω(content).Should(ContainSubstring(`StringVar(&tokenURL, "token-url", "http://example.com/token"`))
+			Ω(content).Should(ContainSubstring(`StringVar(&user, "user", ""`))
+			Ω(content).Should(ContainSubstring("source = config.ClientCredentialsTokenSource()"))
+			Ω(content).Should(ContainSubstring("source = config.PasswordTokenSource(user, pass)"))
+			Ω(content).Should(ContainSubstring("source = config.RefreshTokenSource(refreshToken)"))
+		})
+	})
+
 	Context("with an action with a user type payload", func() {
 		BeforeEach(func() {
 			codegen.TempCount = 0
@@ -0,0 +1,11 @@
+/*
+Package gendesign provides a generator that serializes the design to JSON.
+The generated design.json file describes the API, its resources, actions, routes, user types,
+media types, views, links, security requirements, metadata and validations so that tools written
+in any language can consume the design without writing a Go generator.
+
+The representation is stable: lists are sorted and the same design always produces the same
+document. The "format" field holds the version of the representation (see FormatVersion), it is
+incremented when a change that is not backwards compatible is made to the representation.
+*/
+package gendesign
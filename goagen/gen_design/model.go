@@ -0,0 +1,653 @@
+package gendesign
+
+import (
+	"sort"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// FormatVersion is the version of the JSON representation produced by Build. It is incremented
+// each time a change that is not backwards compatible is made to the representation.
+const FormatVersion = 1
+
+type (
+	// Design is the JSON representation of an API design. Lists are sorted by name so that the
+	// representation of a given design is always the same.
+	Design struct {
+		// Format is the version of the representation, see FormatVersion.
+		Format int `json:"format"`
+		// API describes the API.
+		API *APIDef `json:"api"`
+		// Types lists the user types including the action payload types.
+		Types []*UserType `json:"types,omitempty"`
+		// MediaTypes lists the media types.
+		MediaTypes []*MediaType `json:"media_types,omitempty"`
+		// Resources lists the resources.
+		Resources []*Resource `json:"resources,omitempty"`
+	}
+
+	// APIDef is the JSON representation of the API definition.
+	APIDef struct {
+		Name            string                       `json:"name"`
+		Title           string                       `json:"title,omitempty"`
+		Description     string                       `json:"description,omitempty"`
+		Version         string                       `json:"version,omitempty"`
+		Host            string                       `json:"host,omitempty"`
+		Schemes         []string                     `json:"schemes,omitempty"`
+		BasePath        string                       `json:"base_path,omitempty"`
+		Params          *Attribute                   `json:"params,omitempty"`
+		Consumes        []*Encoding                  `json:"consumes,omitempty"`
+		Produces        []*Encoding                  `json:"produces,omitempty"`
+		Origins         []*CORS                      `json:"origins,omitempty"`
+		TermsOfService  string                       `json:"terms_of_service,omitempty"`
+		Contact         *design.ContactDefinition    `json:"contact,omitempty"`
+		License         *design.LicenseDefinition    `json:"license,omitempty"`
+		Docs            *design.DocsDefinition       `json:"docs,omitempty"`
+		SecuritySchemes []*SecurityScheme            `json:"security_schemes,omitempty"`
+		Security        *Security                    `json:"security,omitempty"`
+		RateLimit       *RateLimit                   `json:"rate_limit,omitempty"`
+		ErrorMediaType  string                       `json:"error_media_type,omitempty"`
+		Metadata        dslengine.MetadataDefinition `json:"metadata,omitempty"`
+	}
+
+	// Encoding is the JSON representation of an encoder or decoder definition.
+	Encoding struct {
+		MIMETypes   []string `json:"mime_types"`
+		PackagePath string   `json:"package_path,omitempty"`
+		Function    string   `json:"function,omitempty"`
+	}
+
+	// CORS is the JSON representation of a CORS policy.
+	CORS struct {
+		Origin      string   `json:"origin"`
+		Headers     []string `json:"headers,omitempty"`
+		Methods     []string `json:"methods,omitempty"`
+		Exposed     []string `json:"exposed,omitempty"`
+		MaxAge      uint     `json:"max_age,omitempty"`
+		Credentials bool     `json:"credentials,omitempty"`
+		Regexp      bool     `json:"regexp,omitempty"`
+	}
+
+	// SecurityScheme is the JSON representation of a security scheme.
+	SecurityScheme struct {
+		Scheme           string                       `json:"scheme"`
+		Kind             string                       `json:"kind"`
+		Type             string                       `json:"type"`
+		Description      string                       `json:"description,omitempty"`
+		In               string                       `json:"in,omitempty"`
+		Name             string                       `json:"name,omitempty"`
+		Scopes           map[string]string            `json:"scopes,omitempty"`
+		Flow             string                       `json:"flow,omitempty"`
+		TokenURL         string                       `json:"token_url,omitempty"`
+		AuthorizationURL string                       `json:"authorization_url,omitempty"`
+		Issuer           string                       `json:"issuer,omitempty"`
+		Audience         []string                     `json:"audience,omitempty"`
+		Leeway           string                       `json:"leeway,omitempty"`
+		Algorithms       []string                     `json:"algorithms,omitempty"`
+		RequiredClaims   []string                     `json:"required_claims,omitempty"`
+		Metadata         dslengine.MetadataDefinition `json:"metadata,omitempty"`
+	}
+
+	// Security is the JSON representation of the security requirements of an API, resource
+	// or action.
+	Security struct {
+		Scheme string   `json:"scheme"`
+		Scopes []string `json:"scopes,omitempty"`
+	}
+
+	// RateLimit is the JSON representation of a rate limit.
+	RateLimit struct {
+		Requests int    `json:"requests"`
+		Period   string `json:"period"`
+		Burst    int    `json:"burst,omitempty"`
+	}
+
+	// Pagination is the JSON representation of the pagination of an action.
+	Pagination struct {
+		CursorParam string `json:"cursor_param,omitempty"`
+		OffsetParam string `json:"offset_param,omitempty"`
+		SizeParam   string `json:"size_param"`
+		DefaultSize int    `json:"default_size"`
+		MaxSize     int    `json:"max_size"`
+	}
+
+	// UserType is the JSON representation of a user type.
+	UserType struct {
+		Name string `json:"name"`
+		*Attribute
+	}
+
+	// MediaType is the JSON representation of a media type.
+	MediaType struct {
+		Identifier  string `json:"identifier"`
+		Name        string `json:"name"`
+		ContentType string `json:"content_type,omitempty"`
+		*Attribute
+		Views    []*View `json:"views,omitempty"`
+		Links    []*Link `json:"links,omitempty"`
+		Resource string  `json:"resource,omitempty"`
+	}
+
+	// View is the JSON representation of a media type view.
+	View struct {
+		Name       string           `json:"name"`
+		Attributes []*ViewAttribute `json:"attributes"`
+	}
+
+	// ViewAttribute is an attribute rendered by a view.
+	ViewAttribute struct {
+		Name string `json:"name"`
+		// View is the view used to render the attribute if it is a media type.
+		View string `json:"view,omitempty"`
+	}
+
+	// Link is the JSON representation of a media type link.
+	Link struct {
+		Name        string `json:"name"`
+		View        string `json:"view,omitempty"`
+		URITemplate string `json:"uri_template,omitempty"`
+		MediaType   string `json:"media_type,omitempty"`
+	}
+
+	// Resource is the JSON representation of a resource.
+	Resource struct {
+		Name            string                       `json:"name"`
+		Description     string                       `json:"description,omitempty"`
+		Schemes         []string                     `json:"schemes,omitempty"`
+		BasePath        string                       `json:"base_path,omitempty"`
+		Parent          string                       `json:"parent,omitempty"`
+		MediaType       string                       `json:"media_type,omitempty"`
+		DefaultView     string                       `json:"default_view,omitempty"`
+		CanonicalAction string                       `json:"canonical_action,omitempty"`
+		Params          *Attribute                   `json:"params,omitempty"`
+		Headers         *Attribute                   `json:"headers,omitempty"`
+		Actions         []*Action                    `json:"actions,omitempty"`
+		FileServers     []*FileServer                `json:"file_servers,omitempty"`
+		Origins         []*CORS                      `json:"origins,omitempty"`
+		Security        *Security                    `json:"security,omitempty"`
+		RateLimit       *RateLimit                   `json:"rate_limit,omitempty"`
+		Metadata        dslengine.MetadataDefinition `json:"metadata,omitempty"`
+	}
+
+	// Action is the JSON representation of a resource action.
+	Action struct {
+		Name             string                       `json:"name"`
+		Description      string                       `json:"description,omitempty"`
+		Docs             *design.DocsDefinition       `json:"docs,omitempty"`
+		Schemes          []string                     `json:"schemes,omitempty"`
+		Routes           []*Route                     `json:"routes,omitempty"`
+		Params           *Attribute                   `json:"params,omitempty"`
+		Headers          *Attribute                   `json:"headers,omitempty"`
+		Payload          *Attribute                   `json:"payload,omitempty"`
+		PayloadOptional  bool                         `json:"payload_optional,omitempty"`
+		PayloadMultipart bool                         `json:"payload_multipart,omitempty"`
+		EventStream      *Attribute                   `json:"event_stream,omitempty"`
+		Responses        []*Response                  `json:"responses,omitempty"`
+		Security         *Security                    `json:"security,omitempty"`
+		RateLimit        *RateLimit                   `json:"rate_limit,omitempty"`
+		Cacheable        bool                         `json:"cacheable,omitempty"`
+		ETag             bool                         `json:"etag,omitempty"`
+		Pagination       *Pagination                  `json:"pagination,omitempty"`
+		Metadata         dslengine.MetadataDefinition `json:"metadata,omitempty"`
+	}
+
+	// Route is the JSON representation of an action route.
+	Route struct {
+		Verb     string                       `json:"verb"`
+		Path     string                       `json:"path"`
+		FullPath string                       `json:"full_path"`
+		Metadata dslengine.MetadataDefinition `json:"metadata,omitempty"`
+	}
+
+	// FileServer is the JSON representation of a file server.
+	FileServer struct {
+		RequestPath string                       `json:"request_path"`
+		FilePath    string                       `json:"file_path"`
+		Description string                       `json:"description,omitempty"`
+		Security    *Security                    `json:"security,omitempty"`
+		Metadata    dslengine.MetadataDefinition `json:"metadata,omitempty"`
+	}
+
+	// Response is the JSON representation of an action response.
+	Response struct {
+		Name        string                       `json:"name"`
+		Status      int                          `json:"status"`
+		Description string                       `json:"description,omitempty"`
+		MediaType   string                       `json:"media_type,omitempty"`
+		View        string                       `json:"view,omitempty"`
+		Type        *DataType                    `json:"type,omitempty"`
+		Headers     *Attribute                   `json:"headers,omitempty"`
+		Metadata    dslengine.MetadataDefinition `json:"metadata,omitempty"`
+	}
+
+	// Attribute is the JSON representation of an attribute.
+	Attribute struct {
+		Type        *DataType                    `json:"type"`
+		Description string                       `json:"description,omitempty"`
+		Validation  *Validation                  `json:"validation,omitempty"`
+		Default     interface{}                  `json:"default,omitempty"`
+		View        string                       `json:"view,omitempty"`
+		Metadata    dslengine.MetadataDefinition `json:"metadata,omitempty"`
+	}
+
+	// DataType is the JSON representation of a data type. User types and media types are
+	// referred to by name, their definitions are listed in the Types and MediaTypes fields of
+	// Design.
+	DataType struct {
+		// Kind is one of "boolean", "integer", "number", "string", "datetime", "uuid", "any",
+		// "file", "object", "array", "hash", "union", "user_type" or "media_type".
+		Kind string `json:"kind"`
+		// Name is the name of the user type or media type.
+		Name string `json:"name,omitempty"`
+		// Identifier is the identifier of the media type.
+		Identifier string `json:"identifier,omitempty"`
+		// Attributes lists the attributes of objects indexed by name.
+		Attributes map[string]*Attribute `json:"attributes,omitempty"`
+		// Key is the type of hash keys.
+		Key *Attribute `json:"key,omitempty"`
+		// Elem is the type of array elements and hash values.
+		Elem *Attribute `json:"elem,omitempty"`
+		// Discriminator is the name of the union discriminator attribute.
+		Discriminator string `json:"discriminator,omitempty"`
+		// Values lists the union values.
+		Values []*UnionValue `json:"values,omitempty"`
+	}
+
+	// UnionValue is the JSON representation of a union value.
+	UnionValue struct {
+		Name string `json:"name"`
+		*Attribute
+	}
+
+	// Validation is the JSON representation of attribute validations.
+	Validation struct {
+		Enum      []interface{} `json:"enum,omitempty"`
+		Format    string        `json:"format,omitempty"`
+		Pattern   string        `json:"pattern,omitempty"`
+		Minimum   *float64      `json:"minimum,omitempty"`
+		Maximum   *float64      `json:"maximum,omitempty"`
+		MinLength *int          `json:"min_length,omitempty"`
+		MaxLength *int          `json:"max_length,omitempty"`
+		Required  []string      `json:"required,omitempty"`
+	}
+)
+
+// Build returns the JSON representation of the given API design.
+func Build(api *design.APIDefinition) *Design {
+	d := &Design{
+		Format: FormatVersion,
+		API: &APIDef{
+			Name:           api.Name,
+			Title:          api.Title,
+			Description:    api.Description,
+			Version:        api.Version,
+			Host:           api.Host,
+			Schemes:        api.Schemes,
+			BasePath:       api.BasePath,
+			Params:         buildAttribute(api.Params),
+			Consumes:       buildEncodings(api.Consumes),
+			Produces:       buildEncodings(api.Produces),
+			Origins:        buildOrigins(api.Origins),
+			TermsOfService: api.TermsOfService,
+			Contact:        api.Contact,
+			License:        api.License,
+			Docs:           api.Docs,
+			Security:       buildSecurity(api.Security),
+			RateLimit:      buildRateLimit(api.RateLimit),
+			ErrorMediaType: api.ErrorMedia().Identifier,
+			Metadata:       api.Metadata,
+		},
+	}
+	for _, s := range api.SecuritySchemes {
+		var leeway string
+		if s.Leeway > 0 {
+			leeway = s.Leeway.String()
+		}
+		d.API.SecuritySchemes = append(d.API.SecuritySchemes, &SecurityScheme{
+			Scheme:           s.SchemeName,
+			Kind:             securityKind(s.Kind),
+			Type:             s.Type,
+			Description:      s.Description,
+			In:               s.In,
+			Name:             s.Name,
+			Scopes:           s.Scopes,
+			Flow:             s.Flow,
+			TokenURL:         s.TokenURL,
+			AuthorizationURL: s.AuthorizationURL,
+			Issuer:           s.Issuer,
+			Audience:         s.Audience,
+			Leeway:           leeway,
+			Algorithms:       s.Algorithms,
+			RequiredClaims:   s.RequiredClaims,
+			Metadata:         s.Metadata,
+		})
+	}
+
+	types := make(map[string]*design.UserTypeDefinition)
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		types[ut.TypeName] = ut
+		return nil
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		d.MediaTypes = append(d.MediaTypes, buildMediaType(mt))
+		return nil
+	})
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		r := buildResource(res)
+		res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload != nil {
+				if _, ok := types[a.Payload.TypeName]; !ok {
+					types[a.Payload.TypeName] = a.Payload
+				}
+			}
+			r.Actions = append(r.Actions, buildAction(a))
+			return nil
+		})
+		d.Resources = append(d.Resources, r)
+		return nil
+	})
+
+	names := make([]string, len(types))
+	i := 0
+	for n := range types {
+		names[i] = n
+		i++
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		d.Types = append(d.Types, &UserType{Name: n, Attribute: buildAttribute(types[n].AttributeDefinition)})
+	}
+
+	return d
+}
+
+// buildMediaType returns the JSON representation of the given media type.
+func buildMediaType(mt *design.MediaTypeDefinition) *MediaType {
+	m := &MediaType{
+		Identifier:  mt.Identifier,
+		Name:        mt.TypeName,
+		ContentType: mt.ContentType,
+		Attribute:   buildAttribute(mt.AttributeDefinition),
+	}
+	if m.ContentType == mt.Identifier {
+		m.ContentType = ""
+	}
+	if mt.Resource != nil {
+		m.Resource = mt.Resource.Name
+	}
+	mt.IterateViews(func(v *design.ViewDefinition) error {
+		view := &View{Name: v.Name, Attributes: []*ViewAttribute{}}
+		if obj := v.Type.ToObject(); obj != nil {
+			for _, n := range sortedNames(obj) {
+				view.Attributes = append(view.Attributes, &ViewAttribute{Name: n, View: obj[n].View})
+			}
+		}
+		m.Views = append(m.Views, view)
+		return nil
+	})
+	links := make([]string, len(mt.Links))
+	i := 0
+	for n := range mt.Links {
+		links[i] = n
+		i++
+	}
+	sort.Strings(links)
+	for _, n := range links {
+		l := mt.Links[n]
+		link := &Link{Name: l.Name, View: l.View, URITemplate: l.URITemplate}
+		if lmt := l.MediaType(); lmt != nil {
+			link.MediaType = lmt.Identifier
+		}
+		m.Links = append(m.Links, link)
+	}
+	return m
+}
+
+// buildResource returns the JSON representation of the given resource without its actions.
+func buildResource(res *design.ResourceDefinition) *Resource {
+	r := &Resource{
+		Name:            res.Name,
+		Description:     res.Description,
+		Schemes:         res.Schemes,
+		BasePath:        res.BasePath,
+		Parent:          res.ParentName,
+		MediaType:       res.MediaType,
+		DefaultView:     res.DefaultViewName,
+		CanonicalAction: res.CanonicalActionName,
+		Params:          buildAttribute(res.Params),
+		Headers:         buildAttribute(res.Headers),
+		Origins:         buildOrigins(res.Origins),
+		Security:        buildSecurity(res.Security),
+		RateLimit:       buildRateLimit(res.RateLimit),
+		Metadata:        res.Metadata,
+	}
+	res.IterateFileServers(func(fs *design.FileServerDefinition) error {
+		r.FileServers = append(r.FileServers, &FileServer{
+			RequestPath: fs.RequestPath,
+			FilePath:    fs.FilePath,
+			Description: fs.Description,
+			Security:    buildSecurity(fs.Security),
+			Metadata:    fs.Metadata,
+		})
+		return nil
+	})
+	return r
+}
+
+// buildAction returns the JSON representation of the given action.
+func buildAction(a *design.ActionDefinition) *Action {
+	act := &Action{
+		Name:             a.Name,
+		Description:      a.Description,
+		Docs:             a.Docs,
+		Schemes:          a.Schemes,
+		Params:           buildAttribute(a.Params),
+		Headers:          buildAttribute(a.Headers),
+		PayloadOptional:  a.PayloadOptional,
+		PayloadMultipart: a.PayloadMultipart,
+		EventStream:      buildAttribute(a.EventStream),
+		Security:         buildSecurity(a.Security),
+		RateLimit:        buildRateLimit(a.RateLimit),
+		Cacheable:        a.Cacheable,
+		ETag:             a.ETag,
+		Pagination:       buildPagination(a.Pagination),
+		Metadata:         a.Metadata,
+	}
+	for _, r := range a.Routes {
+		act.Routes = append(act.Routes, &Route{
+			Verb:     r.Verb,
+			Path:     r.Path,
+			FullPath: r.FullPath(),
+			Metadata: r.Metadata,
+		})
+	}
+	if a.Payload != nil {
+		act.Payload = buildAttribute(&design.AttributeDefinition{Type: a.Payload})
+	}
+	a.IterateResponses(func(r *design.ResponseDefinition) error {
+		resp := &Response{
+			Name:        r.Name,
+			Status:      r.Status,
+			Description: r.Description,
+			MediaType:   r.MediaType,
+			View:        r.ViewName,
+			Headers:     buildAttribute(r.Headers),
+			Metadata:    r.Metadata,
+		}
+		if r.Type != nil {
+			resp.Type = buildType(r.Type)
+		}
+		act.Responses = append(act.Responses, resp)
+		return nil
+	})
+	sort.SliceStable(act.Responses, func(i, j int) bool { return act.Responses[i].Status < act.Responses[j].Status })
+	return act
+}
+
+// buildAttribute returns the JSON representation of the given attribute.
+func buildAttribute(att *design.AttributeDefinition) *Attribute {
+	if att == nil || att.Type == nil {
+		return nil
+	}
+	a := &Attribute{
+		Type:        buildType(att.Type),
+		Description: att.Description,
+		Default:     att.DefaultValue,
+		View:        att.View,
+		Metadata:    att.Metadata,
+	}
+	if v := att.Validation; v != nil {
+		val := &Validation{
+			Enum:      v.Values,
+			Format:    v.Format,
+			Pattern:   v.Pattern,
+			Minimum:   v.Minimum,
+			Maximum:   v.Maximum,
+			MinLength: v.MinLength,
+			MaxLength: v.MaxLength,
+		}
+		if len(v.Required) > 0 {
+			val.Required = make([]string, len(v.Required))
+			copy(val.Required, v.Required)
+			sort.Strings(val.Required)
+		}
+		if len(val.Enum) > 0 || val.Format != "" || val.Pattern != "" || val.Minimum != nil ||
+			val.Maximum != nil || val.MinLength != nil || val.MaxLength != nil || len(val.Required) > 0 {
+			a.Validation = val
+		}
+	}
+	return a
+}
+
+// buildType returns the JSON representation of the given data type.
+func buildType(t design.DataType) *DataType {
+	switch actual := t.(type) {
+	case design.Primitive:
+		return &DataType{Kind: primitiveKind(actual)}
+	case design.Object:
+		o := &DataType{Kind: "object", Attributes: make(map[string]*Attribute, len(actual))}
+		for n, att := range actual {
+			o.Attributes[n] = buildAttribute(att)
+		}
+		return o
+	case *design.Array:
+		return &DataType{Kind: "array", Elem: buildAttribute(actual.ElemType)}
+	case *design.Hash:
+		return &DataType{Kind: "hash", Key: buildAttribute(actual.KeyType), Elem: buildAttribute(actual.ElemType)}
+	case *design.Union:
+		u := &DataType{Kind: "union", Discriminator: actual.Discriminator}
+		for _, v := range actual.Values {
+			u.Values = append(u.Values, &UnionValue{Name: v.Name, Attribute: buildAttribute(v.AttributeDefinition)})
+		}
+		return u
+	case *design.MediaTypeDefinition:
+		return &DataType{Kind: "media_type", Name: actual.TypeName, Identifier: actual.Identifier}
+	case *design.UserTypeDefinition:
+		return &DataType{Kind: "user_type", Name: actual.TypeName}
+	}
+	return nil
+}
+
+// primitiveKind returns the kind of the JSON representation of the given primitive type. Unlike
+// Primitive.Name it distinguishes date times and UUIDs from strings.
+func primitiveKind(p design.Primitive) string {
+	switch p.Kind() {
+	case design.DateTimeKind:
+		return "datetime"
+	case design.UUIDKind:
+		return "uuid"
+	}
+	return p.Name()
+}
+
+// buildEncodings returns the JSON representation of the given encoders or decoders.
+func buildEncodings(encs []*design.EncodingDefinition) []*Encoding {
+	var res []*Encoding
+	for _, e := range encs {
+		res = append(res, &Encoding{MIMETypes: e.MIMETypes, PackagePath: e.PackagePath, Function: e.Function})
+	}
+	return res
+}
+
+// buildOrigins returns the JSON representation of the given CORS policies sorted by origin.
+func buildOrigins(origins map[string]*design.CORSDefinition) []*CORS {
+	names := make([]string, len(origins))
+	i := 0
+	for n := range origins {
+		names[i] = n
+		i++
+	}
+	sort.Strings(names)
+	var res []*CORS
+	for _, n := range names {
+		o := origins[n]
+		res = append(res, &CORS{
+			Origin:      o.Origin,
+			Headers:     o.Headers,
+			Methods:     o.Methods,
+			Exposed:     o.Exposed,
+			MaxAge:      o.MaxAge,
+			Credentials: o.Credentials,
+			Regexp:      o.Regexp,
+		})
+	}
+	return res
+}
+
+// buildSecurity returns the JSON representation of the given security requirements.
+func buildSecurity(s *design.SecurityDefinition) *Security {
+	if s == nil || s.Scheme == nil {
+		return nil
+	}
+	return &Security{Scheme: s.Scheme.SchemeName, Scopes: s.Scopes}
+}
+
+// securityKind returns the name of the given security scheme kind.
+func securityKind(k design.SecuritySchemeKind) string {
+	switch k {
+	case design.OAuth2SecurityKind:
+		return "oauth2"
+	case design.BasicAuthSecurityKind:
+		return "basic"
+	case design.APIKeySecurityKind:
+		return "api_key"
+	case design.JWTSecurityKind:
+		return "jwt"
+	}
+	return "none"
+}
+
+// buildPagination returns the JSON representation of the given pagination.
+func buildPagination(p *design.PaginationDefinition) *Pagination {
+	if p == nil {
+		return nil
+	}
+	return &Pagination{
+		CursorParam: p.CursorParam,
+		OffsetParam: p.OffsetParam,
+		SizeParam:   p.SizeParam,
+		DefaultSize: p.DefaultSize,
+		MaxSize:     p.MaxSize,
+	}
+}
+
+// buildRateLimit returns the JSON representation of the given rate limit.
+func buildRateLimit(rl *design.RateLimitDefinition) *RateLimit {
+	if rl == nil {
+		return nil
+	}
+	return &RateLimit{Requests: rl.Requests, Period: rl.Period.String(), Burst: rl.Burst}
+}
+
+// sortedNames returns the names of the attributes of the given object sorted alphabetically.
+func sortedNames(obj design.Object) []string {
+	names := make([]string, len(obj))
+	i := 0
+	for n := range obj {
+		names[i] = n
+		i++
+	}
+	sort.Strings(names)
+	return names
+}
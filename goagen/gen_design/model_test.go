@@ -0,0 +1,160 @@
+package gendesign_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_design"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Build", func() {
+	var d *gendesign.Design
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		API("cellar", func() {
+			Version("1.0")
+			BasePath("/cellar")
+			Security(jwtScheme)
+		})
+		jwtScheme = JWTSecurity("jwt", func() {
+			Header("Authorization")
+			Scope("api:read")
+			Issuer("https://example.com")
+			Audience("cellar")
+			Leeway(30 * time.Second)
+		})
+		Type("BottlePayload", func() {
+			Attribute("name", String, func() {
+				MinLength(2)
+				Metadata("struct:tag:json", "name")
+			})
+			Attribute("color", String, func() {
+				Enum("red", "white")
+			})
+			Required("name", "color")
+		})
+		Bottle := MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				Attribute("id", Integer)
+				Attribute("name", String)
+				Attribute("created", DateTime)
+				Attribute("tags", ArrayOf(String))
+				Required("id")
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("name")
+			})
+			View("tiny", func() {
+				Attribute("id")
+			})
+		})
+		Resource("bottle", func() {
+			BasePath("/bottles")
+			RateLimit(10, time.Minute)
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Params(func() {
+					Param("id", Integer)
+				})
+				Response(OK, Bottle)
+				Response(NotFound)
+			})
+			Action("create", func() {
+				Routing(POST(""))
+				Payload("BottlePayload")
+				Response(Created)
+			})
+			Action("rate", func() {
+				Routing(PUT("/:id/rate"))
+				Payload(func() {
+					Member("rating", Integer)
+				})
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		d = gendesign.Build(Design)
+	})
+
+	It("builds the API", func() {
+		Ω(d.Format).Should(Equal(gendesign.FormatVersion))
+		Ω(d.API.Name).Should(Equal("cellar"))
+		Ω(d.API.Version).Should(Equal("1.0"))
+		Ω(d.API.Security).Should(Equal(&gendesign.Security{Scheme: "jwt", Scopes: nil}))
+		Ω(d.API.SecuritySchemes).Should(HaveLen(1))
+		Ω(d.API.SecuritySchemes[0].Kind).Should(Equal("jwt"))
+		Ω(d.API.SecuritySchemes[0].Type).Should(Equal("apiKey"))
+		Ω(d.API.SecuritySchemes[0].Issuer).Should(Equal("https://example.com"))
+		Ω(d.API.SecuritySchemes[0].Audience).Should(Equal([]string{"cellar"}))
+		Ω(d.API.SecuritySchemes[0].Leeway).Should(Equal("30s"))
+		Ω(d.API.ErrorMediaType).Should(Equal(ErrorMedia.Identifier))
+	})
+
+	It("builds the user types including the inline payloads", func() {
+		Ω(d.Types).Should(HaveLen(3))
+		Ω(d.Types[0].Name).Should(Equal("BottlePayload"))
+		Ω(d.Types[1].Name).Should(Equal("CreateBottlePayload"))
+		Ω(d.Types[2].Name).Should(Equal("RateBottlePayload"))
+		name := d.Types[0].Type.Attributes["name"]
+		Ω(name.Type.Kind).Should(Equal("string"))
+		Ω(*name.Validation.MinLength).Should(Equal(2))
+		Ω(name.Metadata).Should(HaveKeyWithValue("struct:tag:json", []string{"name"}))
+		Ω(d.Types[0].Type.Attributes["color"].Validation.Enum).Should(Equal([]interface{}{"red", "white"}))
+		Ω(d.Types[0].Validation.Required).Should(Equal([]string{"color", "name"}))
+	})
+
+	It("builds the media types", func() {
+		var bottle *gendesign.MediaType
+		for _, mt := range d.MediaTypes {
+			if mt.Identifier == "application/vnd.bottle" {
+				bottle = mt
+			}
+		}
+		Ω(bottle).ShouldNot(BeNil())
+		Ω(bottle.Type.Attributes["created"].Type.Kind).Should(Equal("datetime"))
+		Ω(bottle.Type.Attributes["tags"].Type.Elem.Type.Kind).Should(Equal("string"))
+		Ω(bottle.Views).Should(HaveLen(2))
+		Ω(bottle.Views[0].Name).Should(Equal("default"))
+		Ω(bottle.Views[0].Attributes).Should(HaveLen(2))
+		Ω(bottle.Views[1].Name).Should(Equal("tiny"))
+	})
+
+	It("builds the resources and actions", func() {
+		Ω(d.Resources).Should(HaveLen(1))
+		res := d.Resources[0]
+		Ω(res.RateLimit).Should(Equal(&gendesign.RateLimit{Requests: 10, Period: "1m0s"}))
+		Ω(res.Actions).Should(HaveLen(3))
+		create, rate, show := res.Actions[0], res.Actions[1], res.Actions[2]
+		Ω(create.Payload.Type).Should(Equal(&gendesign.DataType{Kind: "user_type", Name: "CreateBottlePayload"}))
+		Ω(rate.Payload.Type.Name).Should(Equal("RateBottlePayload"))
+		Ω(show.Routes).Should(Equal([]*gendesign.Route{{Verb: "GET", Path: "/:id", FullPath: "/cellar/bottles/:id"}}))
+		Ω(show.Params.Type.Attributes["id"].Type.Kind).Should(Equal("integer"))
+		Ω(show.Responses).Should(HaveLen(2))
+		Ω(show.Responses[0].Status).Should(Equal(200))
+		Ω(show.Responses[0].MediaType).Should(Equal("application/vnd.bottle"))
+		Ω(show.Responses[1].Status).Should(Equal(404))
+	})
+
+	It("is stable", func() {
+		js, err := json.Marshal(d)
+		Ω(err).ShouldNot(HaveOccurred())
+		for i := 0; i < 5; i++ {
+			other, err := json.Marshal(gendesign.Build(Design))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(other).Should(Equal(js))
+		}
+		var back gendesign.Design
+		Ω(json.Unmarshal(js, &back)).ShouldNot(HaveOccurred())
+		Ω(back.Resources[0].Actions[2].Name).Should(Equal("show"))
+	})
+})
+
+// jwtScheme is the security scheme used by the test design.
+var jwtScheme *SecuritySchemeDefinition
@@ -0,0 +1,754 @@
+package gendiff
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/gen_design"
+)
+
+type (
+	// Change describes a difference between two versions of a design.
+	Change struct {
+		// Breaking is true if the change may break existing clients.
+		Breaking bool
+		// Location describes the element of the design that changed.
+		Location string
+		// Message describes the change.
+		Message string
+	}
+
+	// direction indicates whether an attribute is sent by clients or by the service.
+	direction int
+
+	// comparer walks two versions of a design and records the differences.
+	comparer struct {
+		base, target *side
+		changes      []*Change
+		seen         map[string]bool
+	}
+
+	// side indexes the types of one of the compared designs.
+	side struct {
+		design     *gendesign.Design
+		types      map[string]*gendesign.Attribute
+		mediaTypes map[string]*gendesign.MediaType
+	}
+)
+
+const (
+	// request is the direction of attributes sent by clients: narrowing the set of accepted
+	// values breaks clients.
+	request direction = iota
+	// response is the direction of attributes sent by the service: widening the set of
+	// returned values breaks clients.
+	response
+)
+
+// wildcardRegex matches the wildcards of a route path.
+var wildcardRegex = regexp.MustCompile(`/(:|\*)[a-zA-Z0-9_]+`)
+
+// String returns a one line description of the change.
+func (c *Change) String() string {
+	kind := "compatible"
+	if c.Breaking {
+		kind = "breaking"
+	}
+	return fmt.Sprintf("%s: %s: %s", kind, c.Location, c.Message)
+}
+
+// Breaking returns the breaking changes listed in changes.
+func Breaking(changes []*Change) []*Change {
+	var res []*Change
+	for _, c := range changes {
+		if c.Breaking {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// Compare returns the changes that turn the base design into the target design. A change is
+// breaking if clients built against the base design may fail when talking to a service
+// implementing the target design, for example because an attribute was removed from a response,
+// a request attribute became required, an enum lost values or a route was removed.
+func Compare(base, target *gendesign.Design) []*Change {
+	c := &comparer{
+		base:   newSide(base),
+		target: newSide(target),
+		seen:   make(map[string]bool),
+	}
+	c.api()
+	c.resources()
+	return c.changes
+}
+
+// newSide indexes the types and media types of d.
+func newSide(d *gendesign.Design) *side {
+	s := &side{
+		design:     d,
+		types:      make(map[string]*gendesign.Attribute),
+		mediaTypes: make(map[string]*gendesign.MediaType),
+	}
+	for _, ut := range d.Types {
+		s.types[ut.Name] = ut.Attribute
+	}
+	for _, mt := range d.MediaTypes {
+		s.mediaTypes[design.CanonicalIdentifier(mt.Identifier)] = mt
+	}
+	return s
+}
+
+// api compares the API level properties.
+func (c *comparer) api() {
+	b, t := c.base.design.API, c.target.design.API
+	loc := fmt.Sprintf("API %q", t.Name)
+	if b.Host != t.Host {
+		c.add(true, loc, "host changed from %q to %q", b.Host, t.Host)
+	}
+	removed, added := diffStrings(b.Schemes, t.Schemes)
+	for _, s := range removed {
+		c.add(true, loc, "scheme %q removed", s)
+	}
+	for _, s := range added {
+		c.add(false, loc, "scheme %q added", s)
+	}
+	schemes := make(map[string]*gendesign.SecurityScheme)
+	for _, s := range b.SecuritySchemes {
+		schemes[s.Scheme] = s
+	}
+	for _, ts := range t.SecuritySchemes {
+		bs, ok := schemes[ts.Scheme]
+		if !ok {
+			c.add(false, loc, "security scheme %q added", ts.Scheme)
+			continue
+		}
+		delete(schemes, ts.Scheme)
+		sloc := fmt.Sprintf("%s security scheme %q", loc, ts.Scheme)
+		if bs.Kind != ts.Kind {
+			c.add(true, sloc, "kind changed from %q to %q", bs.Kind, ts.Kind)
+			continue
+		}
+		if bs.In != ts.In || bs.Name != ts.Name {
+			c.add(true, sloc, "credentials location changed from %s %q to %s %q", bs.In, bs.Name, ts.In, ts.Name)
+		}
+		if bs.Flow != ts.Flow {
+			c.add(true, sloc, "flow changed from %q to %q", bs.Flow, ts.Flow)
+		}
+		if bs.TokenURL != ts.TokenURL || bs.AuthorizationURL != ts.AuthorizationURL {
+			c.add(true, sloc, "token or authorization URL changed")
+		}
+	}
+	for _, n := range sortedKeys(schemes) {
+		c.add(false, loc, "security scheme %q removed", n)
+	}
+}
+
+// resources compares the resources and their actions.
+func (c *comparer) resources() {
+	resources := make(map[string]*gendesign.Resource)
+	for _, r := range c.base.design.Resources {
+		resources[r.Name] = r
+	}
+	for _, tr := range c.target.design.Resources {
+		loc := fmt.Sprintf("resource %q", tr.Name)
+		br, ok := resources[tr.Name]
+		if !ok {
+			c.add(false, loc, "resource added")
+			continue
+		}
+		delete(resources, tr.Name)
+		c.fileServers(loc, br, tr)
+		actions := make(map[string]*gendesign.Action)
+		for _, a := range br.Actions {
+			actions[a.Name] = a
+		}
+		for _, ta := range tr.Actions {
+			aloc := fmt.Sprintf("%s action %q", loc, ta.Name)
+			ba, ok := actions[ta.Name]
+			if !ok {
+				c.add(false, aloc, "action added")
+				continue
+			}
+			delete(actions, ta.Name)
+			c.action(aloc, br, tr, ba, ta)
+		}
+		for _, n := range sortedKeys(actions) {
+			c.add(true, fmt.Sprintf("%s action %q", loc, n), "action removed")
+		}
+	}
+	for _, n := range sortedKeys(resources) {
+		c.add(true, fmt.Sprintf("resource %q", n), "resource removed")
+	}
+}
+
+// fileServers compares the file servers of a resource.
+func (c *comparer) fileServers(loc string, b, t *gendesign.Resource) {
+	var bpaths, tpaths []string
+	for _, fs := range b.FileServers {
+		bpaths = append(bpaths, fs.RequestPath)
+	}
+	for _, fs := range t.FileServers {
+		tpaths = append(tpaths, fs.RequestPath)
+	}
+	removed, added := diffStrings(bpaths, tpaths)
+	for _, p := range removed {
+		c.add(true, loc, "file server %q removed", p)
+	}
+	for _, p := range added {
+		c.add(false, loc, "file server %q added", p)
+	}
+}
+
+// action compares two versions of an action.
+func (c *comparer) action(loc string, bres, tres *gendesign.Resource, b, t *gendesign.Action) {
+	c.routes(loc, b, t)
+	c.security(loc, c.effectiveSecurity(c.base, bres, b), c.effectiveSecurity(c.target, tres, t))
+	c.object(loc+" params", request, params(b, t), t.Params)
+	c.object(loc+" headers", request, b.Headers, t.Headers)
+	c.payload(loc+" payload", b, t)
+	c.responses(loc, b, t)
+	switch {
+	case b.EventStream == nil && t.EventStream != nil:
+		c.add(true, loc, "action now streams events")
+	case b.EventStream != nil && t.EventStream == nil:
+		c.add(true, loc, "action no longer streams events")
+	case b.EventStream != nil:
+		c.attribute(loc+" events", response, b.EventStream, t.EventStream)
+	}
+}
+
+// routes compares the routes of two versions of an action. Renaming a wildcard does not change
+// the route.
+func (c *comparer) routes(loc string, b, t *gendesign.Action) {
+	display := make(map[string]string)
+	keys := func(a *gendesign.Action) []string {
+		res := make([]string, len(a.Routes))
+		for i, r := range a.Routes {
+			res[i] = routeKey(r)
+			display[res[i]] = r.Verb + " " + r.FullPath
+		}
+		return res
+	}
+	removed, added := diffStrings(keys(b), keys(t))
+	for _, r := range removed {
+		c.add(true, loc, "route %s removed", display[r])
+	}
+	for _, r := range added {
+		c.add(false, loc, "route %s added", display[r])
+	}
+}
+
+// params returns the params of b with the wildcards renamed after the wildcards of the same
+// routes in t so that renaming a wildcard is not reported as a change.
+func params(b, t *gendesign.Action) *gendesign.Attribute {
+	if b.Params == nil || b.Params.Type == nil || b.Params.Type.Kind != "object" {
+		return b.Params
+	}
+	names := make(map[string]string)
+	for _, br := range b.Routes {
+		for _, tr := range t.Routes {
+			if routeKey(br) != routeKey(tr) {
+				continue
+			}
+			tw := design.ExtractWildcards(tr.FullPath)
+			for i, n := range design.ExtractWildcards(br.FullPath) {
+				if n != tw[i] {
+					names[n] = tw[i]
+				}
+			}
+		}
+	}
+	if len(names) == 0 {
+		return b.Params
+	}
+	rename := func(n string) string {
+		if r, ok := names[n]; ok {
+			return r
+		}
+		return n
+	}
+	p := *b.Params
+	typ := *p.Type
+	typ.Attributes = make(map[string]*gendesign.Attribute, len(p.Type.Attributes))
+	for n, att := range p.Type.Attributes {
+		typ.Attributes[rename(n)] = att
+	}
+	p.Type = &typ
+	if p.Validation != nil {
+		val := *p.Validation
+		val.Required = make([]string, len(p.Validation.Required))
+		for i, n := range p.Validation.Required {
+			val.Required[i] = rename(n)
+		}
+		p.Validation = &val
+	}
+	return &p
+}
+
+// effectiveSecurity returns the security requirements that apply to the given action, nil if
+// the action is not secured.
+func (c *comparer) effectiveSecurity(s *side, res *gendesign.Resource, a *gendesign.Action) *gendesign.Security {
+	sec := a.Security
+	if sec == nil {
+		sec = res.Security
+	}
+	if sec == nil {
+		sec = s.design.API.Security
+	}
+	if sec == nil || sec.Scheme == "" {
+		return nil
+	}
+	return sec
+}
+
+// security compares the security requirements of two versions of an action.
+func (c *comparer) security(loc string, b, t *gendesign.Security) {
+	switch {
+	case b == nil && t == nil:
+		return
+	case b == nil:
+		c.add(true, loc, "security scheme %q now required", t.Scheme)
+		return
+	case t == nil:
+		c.add(false, loc, "security scheme %q no longer required", b.Scheme)
+		return
+	case b.Scheme != t.Scheme:
+		c.add(true, loc, "security scheme changed from %q to %q", b.Scheme, t.Scheme)
+		return
+	}
+	removed, added := diffStrings(b.Scopes, t.Scopes)
+	for _, s := range added {
+		c.add(true, loc, "scope %q now required", s)
+	}
+	for _, s := range removed {
+		c.add(false, loc, "scope %q no longer required", s)
+	}
+}
+
+// payload compares the payloads of two versions of an action.
+func (c *comparer) payload(loc string, b, t *gendesign.Action) {
+	switch {
+	case b.Payload == nil && t.Payload == nil:
+		return
+	case b.Payload == nil:
+		c.add(!t.PayloadOptional, loc, "payload added")
+		return
+	case t.Payload == nil:
+		c.add(true, loc, "payload removed")
+		return
+	}
+	if b.PayloadOptional && !t.PayloadOptional {
+		c.add(true, loc, "payload is now required")
+	} else if !b.PayloadOptional && t.PayloadOptional {
+		c.add(false, loc, "payload is now optional")
+	}
+	if b.PayloadMultipart != t.PayloadMultipart {
+		c.add(true, loc, "multipart encoding changed from %v to %v", b.PayloadMultipart, t.PayloadMultipart)
+	}
+	c.attribute(loc, request, b.Payload, t.Payload)
+}
+
+// responses compares the responses of two versions of an action. Removing a success response
+// is breaking, removing an error response is not.
+func (c *comparer) responses(loc string, b, t *gendesign.Action) {
+	responses := make(map[int]*gendesign.Response)
+	for _, r := range b.Responses {
+		responses[r.Status] = r
+	}
+	for _, tr := range t.Responses {
+		rloc := fmt.Sprintf("%s response %d", loc, tr.Status)
+		br, ok := responses[tr.Status]
+		if !ok {
+			c.add(false, rloc, "response added")
+			continue
+		}
+		delete(responses, tr.Status)
+		c.object(rloc+" headers", response, br.Headers, tr.Headers)
+		bid, tid := design.CanonicalIdentifier(br.MediaType), design.CanonicalIdentifier(tr.MediaType)
+		if bid != tid {
+			c.add(true, rloc, "media type changed from %q to %q", br.MediaType, tr.MediaType)
+			continue
+		}
+		c.attribute(rloc+" body", response, c.responseBody(c.base, br), c.responseBody(c.target, tr))
+	}
+	var removed []int
+	for s := range responses {
+		removed = append(removed, s)
+	}
+	sort.Ints(removed)
+	for _, s := range removed {
+		c.add(s >= 200 && s < 300, fmt.Sprintf("%s response %d", loc, s), "response removed")
+	}
+}
+
+// responseBody returns an attribute describing the body of the given response, nil if the
+// response has no body.
+func (c *comparer) responseBody(s *side, r *gendesign.Response) *gendesign.Attribute {
+	if r.Type != nil {
+		return &gendesign.Attribute{Type: r.Type, View: r.View}
+	}
+	if mt, ok := s.mediaTypes[design.CanonicalIdentifier(r.MediaType)]; ok {
+		return &gendesign.Attribute{
+			Type: &gendesign.DataType{Kind: "media_type", Name: mt.Name, Identifier: mt.Identifier},
+			View: r.View,
+		}
+	}
+	return nil
+}
+
+// object compares two attributes that hold objects, nil attributes are handled as empty objects.
+func (c *comparer) object(loc string, dir direction, b, t *gendesign.Attribute) {
+	if b == nil && t == nil {
+		return
+	}
+	if b == nil {
+		b = &gendesign.Attribute{Type: &gendesign.DataType{Kind: "object"}}
+	}
+	if t == nil {
+		t = &gendesign.Attribute{Type: &gendesign.DataType{Kind: "object"}}
+	}
+	c.attribute(loc, dir, b, t)
+}
+
+// attribute compares two versions of an attribute.
+func (c *comparer) attribute(loc string, dir direction, b, t *gendesign.Attribute) {
+	switch {
+	case b == nil && t == nil:
+		return
+	case b == nil:
+		c.add(dir == request, loc, "body added")
+		return
+	case t == nil:
+		c.add(true, loc, "body removed")
+		return
+	}
+	c.validation(loc, dir, b.Validation, t.Validation)
+	if dir == request && b.Default != nil && !reflect.DeepEqual(b.Default, t.Default) {
+		c.add(false, loc, "default value changed from %v to %v", b.Default, t.Default)
+	}
+
+	bt, bv := c.resolve(c.base, b, dir)
+	tt, tv := c.resolve(c.target, t, dir)
+	if bt == nil || tt == nil {
+		return
+	}
+	if isRef(b.Type) || isRef(t.Type) {
+		key := fmt.Sprintf("%d|%s|%s|%s|%s", dir, refName(b), refName(t), b.View, t.View)
+		if c.seen[key] {
+			return
+		}
+		c.seen[key] = true
+		if isRef(b.Type) && isRef(t.Type) {
+			c.validation(loc, dir, bv, tv)
+		}
+	}
+	if bt.Kind != tt.Kind {
+		if bt.Kind == "integer" && tt.Kind == "number" {
+			c.add(dir == response, loc, "type changed from %s to %s", bt.Kind, tt.Kind)
+		} else {
+			c.add(true, loc, "type changed from %s to %s", bt.Kind, tt.Kind)
+		}
+		return
+	}
+	switch bt.Kind {
+	case "object":
+		c.attributes(loc, dir, bt, tt, required(bv, b.Validation), required(tv, t.Validation))
+	case "array":
+		c.attribute(loc+"[]", dir, bt.Elem, tt.Elem)
+	case "hash":
+		c.attribute(loc+" keys", dir, bt.Key, tt.Key)
+		c.attribute(loc+" values", dir, bt.Elem, tt.Elem)
+	case "union":
+		c.union(loc, dir, bt, tt)
+	}
+}
+
+// attributes compares the attributes of two versions of an object given the names of the
+// required attributes.
+func (c *comparer) attributes(loc string, dir direction, b, t *gendesign.DataType, breq, treq map[string]bool) {
+	names := make(map[string]bool)
+	for n := range b.Attributes {
+		names[n] = true
+	}
+	for n := range t.Attributes {
+		names[n] = true
+	}
+	for _, n := range sortedKeys(names) {
+		aloc := loc + "." + n
+		ba, ta := b.Attributes[n], t.Attributes[n]
+		switch {
+		case ba == nil:
+			if treq[n] {
+				c.add(dir == request, aloc, "required attribute added")
+			} else {
+				c.add(false, aloc, "attribute added")
+			}
+			continue
+		case ta == nil:
+			c.add(true, aloc, "attribute removed")
+			continue
+		}
+		if !breq[n] && treq[n] {
+			c.add(dir == request, aloc, "attribute is now required")
+		} else if breq[n] && !treq[n] {
+			c.add(dir == response, aloc, "attribute is no longer required")
+		}
+		c.attribute(aloc, dir, ba, ta)
+	}
+}
+
+// union compares the values of two versions of a union.
+func (c *comparer) union(loc string, dir direction, b, t *gendesign.DataType) {
+	if b.Discriminator != t.Discriminator {
+		c.add(true, loc, "discriminator changed from %q to %q", b.Discriminator, t.Discriminator)
+	}
+	values := make(map[string]*gendesign.Attribute)
+	for _, v := range b.Values {
+		values[v.Name] = v.Attribute
+	}
+	for _, v := range t.Values {
+		vloc := fmt.Sprintf("%s value %q", loc, v.Name)
+		bv, ok := values[v.Name]
+		if !ok {
+			c.add(dir == response, vloc, "union value added")
+			continue
+		}
+		delete(values, v.Name)
+		c.attribute(vloc, dir, bv, v.Attribute)
+	}
+	for _, n := range sortedKeys(values) {
+		c.add(dir == request, fmt.Sprintf("%s value %q", loc, n), "union value removed")
+	}
+}
+
+// validation compares two versions of attribute validations. Narrowing the set of valid values
+// breaks requests, widening it breaks responses. Required attributes are compared by attributes.
+func (c *comparer) validation(loc string, dir direction, b, t *gendesign.Validation) {
+	if b == nil {
+		b = &gendesign.Validation{}
+	}
+	if t == nil {
+		t = &gendesign.Validation{}
+	}
+	narrowed := func(format string, args ...interface{}) { c.add(dir == request, loc, format, args...) }
+	widened := func(format string, args ...interface{}) { c.add(dir == response, loc, format, args...) }
+
+	switch {
+	case len(b.Enum) == 0 && len(t.Enum) > 0:
+		narrowed("enum %s added", formatValues(t.Enum))
+	case len(b.Enum) > 0 && len(t.Enum) == 0:
+		widened("enum removed")
+	default:
+		removed, added := diffStrings(enumKeys(b.Enum), enumKeys(t.Enum))
+		for _, v := range removed {
+			narrowed("enum value %s removed", v)
+		}
+		for _, v := range added {
+			widened("enum value %s added", v)
+		}
+	}
+
+	compareFloat := func(name string, bv, tv *float64, narrower func(b, t float64) bool) {
+		switch {
+		case bv == nil && tv == nil:
+		case bv == nil:
+			narrowed("%s %v added", name, *tv)
+		case tv == nil:
+			widened("%s %v removed", name, *bv)
+		case *bv != *tv && narrower(*bv, *tv):
+			narrowed("%s changed from %v to %v", name, *bv, *tv)
+		case *bv != *tv:
+			widened("%s changed from %v to %v", name, *bv, *tv)
+		}
+	}
+	greater := func(b, t float64) bool { return t > b }
+	lower := func(b, t float64) bool { return t < b }
+	compareFloat("minimum", b.Minimum, t.Minimum, greater)
+	compareFloat("maximum", b.Maximum, t.Maximum, lower)
+	compareFloat("minimum length", toFloat(b.MinLength), toFloat(t.MinLength), greater)
+	compareFloat("maximum length", toFloat(b.MaxLength), toFloat(t.MaxLength), lower)
+
+	compareString := func(name, bv, tv string) {
+		switch {
+		case bv == tv:
+		case bv == "":
+			narrowed("%s %q added", name, tv)
+		case tv == "":
+			widened("%s %q removed", name, bv)
+		default:
+			c.add(true, loc, "%s changed from %q to %q", name, bv, tv)
+		}
+	}
+	compareString("format", b.Format, t.Format)
+	compareString("pattern", b.Pattern, t.Pattern)
+}
+
+// add records a change.
+func (c *comparer) add(breaking bool, loc, format string, args ...interface{}) {
+	c.changes = append(c.changes, &Change{
+		Breaking: breaking,
+		Location: loc,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// resolve returns the data type and the type level validations of the given attribute,
+// following user type and media type references. Media types used in responses are restricted
+// to the attributes rendered by the attribute view.
+func (c *comparer) resolve(s *side, att *gendesign.Attribute, dir direction) (*gendesign.DataType, *gendesign.Validation) {
+	t := att.Type
+	if t == nil {
+		return nil, nil
+	}
+	switch t.Kind {
+	case "user_type":
+		ut, ok := s.types[t.Name]
+		if !ok || ut == nil {
+			return nil, nil
+		}
+		return ut.Type, ut.Validation
+	case "media_type":
+		mt, ok := s.mediaTypes[design.CanonicalIdentifier(t.Identifier)]
+		if !ok || mt.Attribute == nil {
+			return nil, nil
+		}
+		if dir == request {
+			return mt.Type, mt.Validation
+		}
+		view := att.View
+		if view == "" {
+			view = "default"
+		}
+		return project(mt, view), mt.Validation
+	}
+	return t, nil
+}
+
+// project returns the data type of the media type rendered with the given view. It returns the
+// full media type if the view does not exist.
+func project(mt *gendesign.MediaType, view string) *gendesign.DataType {
+	t := mt.Type
+	if t.Kind == "array" && t.Elem != nil {
+		elem := *t.Elem
+		elem.View = view
+		return &gendesign.DataType{Kind: "array", Elem: &elem}
+	}
+	var v *gendesign.View
+	for _, mv := range mt.Views {
+		if mv.Name == view {
+			v = mv
+			break
+		}
+	}
+	if v == nil || t.Kind != "object" {
+		return t
+	}
+	res := &gendesign.DataType{Kind: "object", Attributes: make(map[string]*gendesign.Attribute)}
+	for _, va := range v.Attributes {
+		att, ok := t.Attributes[va.Name]
+		if !ok {
+			continue
+		}
+		if va.View != "" {
+			cp := *att
+			cp.View = va.View
+			att = &cp
+		}
+		res.Attributes[va.Name] = att
+	}
+	return res
+}
+
+// routeKey returns a key identifying the given route regardless of the names of its wildcards.
+func routeKey(r *gendesign.Route) string {
+	return r.Verb + " " + wildcardRegex.ReplaceAllString(r.FullPath, "/$1")
+}
+
+// isRef returns true if t refers to a user type or media type.
+func isRef(t *gendesign.DataType) bool {
+	return t != nil && (t.Kind == "user_type" || t.Kind == "media_type")
+}
+
+// refName returns the name of the type referred to by att if any.
+func refName(att *gendesign.Attribute) string {
+	if isRef(att.Type) {
+		return att.Type.Name
+	}
+	return ""
+}
+
+// required returns the names of the required attributes listed in the given validations.
+func required(vals ...*gendesign.Validation) map[string]bool {
+	res := make(map[string]bool)
+	for _, v := range vals {
+		if v == nil {
+			continue
+		}
+		for _, n := range v.Required {
+			res[n] = true
+		}
+	}
+	return res
+}
+
+// diffStrings returns the elements of b missing from t and the elements of t missing from b.
+func diffStrings(b, t []string) (removed, added []string) {
+	bs := make(map[string]bool, len(b))
+	for _, s := range b {
+		bs[s] = true
+	}
+	ts := make(map[string]bool, len(t))
+	for _, s := range t {
+		ts[s] = true
+		if !bs[s] {
+			added = append(added, s)
+		}
+	}
+	for _, s := range b {
+		if !ts[s] {
+			removed = append(removed, s)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+	return
+}
+
+// enumKeys returns the string representations of the given enum values.
+func enumKeys(vals []interface{}) []string {
+	keys := make([]string, len(vals))
+	for i, v := range vals {
+		keys[i] = fmt.Sprintf("%#v", v)
+	}
+	return keys
+}
+
+// formatValues returns a string representation of the given enum values.
+func formatValues(vals []interface{}) string {
+	return "[" + strings.Join(enumKeys(vals), ", ") + "]"
+}
+
+// toFloat converts the given length validation into a float.
+func toFloat(i *int) *float64 {
+	if i == nil {
+		return nil
+	}
+	f := float64(*i)
+	return &f
+}
+
+// sortedKeys returns the keys of the given map sorted alphabetically.
+func sortedKeys(m interface{}) []string {
+	keys := reflect.ValueOf(m).MapKeys()
+	res := make([]string, len(keys))
+	for i, k := range keys {
+		res[i] = k.String()
+	}
+	sort.Strings(res)
+	return res
+}
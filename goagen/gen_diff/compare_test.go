@@ -0,0 +1,233 @@
+package gendiff_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_design"
+	"github.com/goadesign/goa/goagen/gen_diff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compare", func() {
+	var baseDSL, targetDSL func()
+	var changes []*gendiff.Change
+
+	build := func(dsl func()) *gendesign.Design {
+		dslengine.Reset()
+		API("cellar", func() {})
+		dsl()
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		return gendesign.Build(Design)
+	}
+
+	JustBeforeEach(func() {
+		base := build(baseDSL)
+		target := build(targetDSL)
+		changes = gendiff.Compare(base, target)
+	})
+
+	messages := func(breaking bool) []string {
+		var res []string
+		for _, c := range changes {
+			if c.Breaking == breaking {
+				res = append(res, c.Location+": "+c.Message)
+			}
+		}
+		return res
+	}
+
+	Context("with identical designs", func() {
+		BeforeEach(func() {
+			baseDSL = bottleDSL(bottleOptions{})
+			targetDSL = bottleDSL(bottleOptions{})
+		})
+
+		It("reports no change", func() {
+			Ω(changes).Should(BeEmpty())
+		})
+	})
+
+	Context("with a renamed route wildcard", func() {
+		BeforeEach(func() {
+			baseDSL = bottleDSL(bottleOptions{})
+			targetDSL = bottleDSL(bottleOptions{wildcard: "bottleID"})
+		})
+
+		It("reports no change", func() {
+			Ω(changes).Should(BeEmpty())
+		})
+	})
+
+	Context("with breaking changes", func() {
+		BeforeEach(func() {
+			baseDSL = bottleDSL(bottleOptions{})
+			targetDSL = bottleDSL(bottleOptions{
+				route:        "/bottles/:id/show",
+				requireColor: true,
+				colors:       []interface{}{"red"},
+				dropVintage:  true,
+				minRating:    2,
+				addRating:    true,
+			})
+		})
+
+		It("reports them as breaking", func() {
+			Ω(messages(true)).Should(ConsistOf(
+				`resource "bottle" action "show": route GET /bottles/:id removed`,
+				`resource "bottle" action "show" response 200 body.vintage: attribute removed`,
+				`resource "bottle" action "create" payload.color: attribute is now required`,
+				`resource "bottle" action "create" payload.color: enum value "white" removed`,
+				`resource "bottle" action "create" payload.rating: minimum changed from 1 to 2`,
+				`resource "bottle" action "show" response 200 body.rating: enum value 6 added`,
+			))
+			Ω(messages(false)).Should(ConsistOf(
+				`resource "bottle" action "show": route GET /bottles/:id/show added`,
+			))
+		})
+	})
+
+	Context("with compatible changes", func() {
+		BeforeEach(func() {
+			baseDSL = bottleDSL(bottleOptions{requireColor: true, colors: []interface{}{"red"}, minRating: 2})
+			targetDSL = bottleDSL(bottleOptions{newAction: true, optionalTag: true})
+		})
+
+		It("reports them as compatible", func() {
+			Ω(messages(true)).Should(BeEmpty())
+			Ω(messages(false)).Should(ConsistOf(
+				`resource "bottle" action "create" payload.color: attribute is no longer required`,
+				`resource "bottle" action "create" payload.color: enum value "white" added`,
+				`resource "bottle" action "create" payload.rating: minimum changed from 2 to 1`,
+				`resource "bottle" action "create" payload.tag: attribute added`,
+				`resource "bottle" action "delete": action added`,
+			))
+		})
+	})
+
+	Context("with removed actions and responses", func() {
+		BeforeEach(func() {
+			baseDSL = bottleDSL(bottleOptions{newAction: true})
+			targetDSL = bottleDSL(bottleOptions{noNotFound: true})
+		})
+
+		It("classifies the removals", func() {
+			Ω(messages(true)).Should(ConsistOf(`resource "bottle" action "delete": action removed`))
+			Ω(messages(false)).Should(ConsistOf(`resource "bottle" action "show" response 404: response removed`))
+		})
+	})
+
+	Context("with new security requirements", func() {
+		BeforeEach(func() {
+			baseDSL = bottleDSL(bottleOptions{})
+			targetDSL = bottleDSL(bottleOptions{secured: true})
+		})
+
+		It("reports a breaking change", func() {
+			Ω(messages(true)).Should(ContainElement(`resource "bottle" action "show": security scheme "key" now required`))
+		})
+	})
+})
+
+// bottleOptions describes the variations of the test design.
+type bottleOptions struct {
+	wildcard     string
+	route        string
+	requireColor bool
+	colors       []interface{}
+	dropVintage  bool
+	minRating    int
+	addRating    bool
+	newAction    bool
+	optionalTag  bool
+	noNotFound   bool
+	secured      bool
+}
+
+// bottleDSL returns the DSL of the test design with the given variations.
+func bottleDSL(o bottleOptions) func() {
+	return func() {
+		var scheme *SecuritySchemeDefinition
+		if o.secured {
+			scheme = APIKeySecurity("key", func() { Header("X-Key") })
+		}
+		colors := o.colors
+		if colors == nil {
+			colors = []interface{}{"red", "white"}
+		}
+		minRating := o.minRating
+		if minRating == 0 {
+			minRating = 1
+		}
+		Type("BottlePayload", func() {
+			Attribute("name", String)
+			Attribute("color", String, func() { Enum(colors...) })
+			Attribute("rating", Integer, func() { Minimum(minRating) })
+			if o.optionalTag {
+				Attribute("tag", String)
+			}
+			Required("name")
+			if o.requireColor {
+				Required("color")
+			}
+		})
+		ratings := []interface{}{1, 2, 3, 4, 5}
+		if o.addRating {
+			ratings = append(ratings, 6)
+		}
+		bottle := MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				Attribute("id", Integer)
+				Attribute("name", String)
+				Attribute("rating", Integer, func() { Enum(ratings...) })
+				if !o.dropVintage {
+					Attribute("vintage", Integer)
+				}
+				Required("id", "name")
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("name")
+				Attribute("rating")
+				if !o.dropVintage {
+					Attribute("vintage")
+				}
+			})
+		})
+		Resource("bottle", func() {
+			if scheme != nil {
+				Security(scheme)
+			}
+			Action("show", func() {
+				wildcard := o.wildcard
+				if wildcard == "" {
+					wildcard = "id"
+				}
+				route := o.route
+				if route == "" {
+					route = "/bottles/:" + wildcard
+				}
+				Routing(GET(route))
+				Params(func() {
+					Param(wildcard, Integer)
+				})
+				Response(OK, bottle)
+				if !o.noNotFound {
+					Response(NotFound)
+				}
+			})
+			Action("create", func() {
+				Routing(POST("/bottles"))
+				Payload("BottlePayload")
+				Response(Created)
+			})
+			if o.newAction {
+				Action("delete", func() {
+					Routing(DELETE("/bottles/:id"))
+					Response(NoContent)
+				})
+			}
+		})
+	}
+}
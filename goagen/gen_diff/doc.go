@@ -0,0 +1,14 @@
+/*
+Package gendiff provides a generator that compares two versions of a design and reports the
+changes that may break existing clients.
+
+The base version is read from a design.json file produced by "goagen design-json" (see package
+gendesign). The target version is either the design package being generated or another
+design.json file. Each change is classified as breaking or compatible taking into account
+whether the affected attributes are sent by clients or by the service: for example adding an
+enum value is compatible for a request attribute but breaking for a response attribute.
+
+The generator writes the list of changes to the design_diff.txt file and fails if any of the
+changes is breaking so that goagen exits with a non-zero status.
+*/
+package gendiff
@@ -0,0 +1,162 @@
+package gendiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_design"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of a design diff generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the design diff generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Base     string                // Path to the JSON representation of the base design
+	Target   string                // Path to the JSON representation of the target design
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, base, target, ver string
+	set := flag.NewFlagSet("diff", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&base, "base", "", "")
+	set.StringVar(&target, "target", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.String("base-design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, API: design.Design, Base: base, Target: target}
+
+	return g.Generate()
+}
+
+// Generate compares the designs, writes the list of changes to the design_diff.txt file and
+// returns an error if any change is breaking. The report is kept in that case.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.Base == "" {
+		return nil, fmt.Errorf("missing base design, use --base or --base-design")
+	}
+	if g.API == nil && g.Target == "" {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	base, err := Load(g.Base)
+	if err != nil {
+		return
+	}
+	var target *gendesign.Design
+	if g.Target != "" {
+		target, err = Load(g.Target)
+	} else {
+		target, err = normalize(gendesign.Build(g.API))
+	}
+	if err != nil {
+		return
+	}
+
+	changes := Compare(base, target)
+	breaking := Breaking(changes)
+
+	if err = os.MkdirAll(g.OutDir, 0755); err != nil {
+		return
+	}
+	content := report(changes, len(breaking))
+	reportFile := filepath.Join(g.OutDir, "design_diff.txt")
+	if err = ioutil.WriteFile(reportFile, content, 0644); err != nil {
+		return
+	}
+	// Keep the report out of genfiles until the check passes so that the cleanup triggered by
+	// the breaking changes error does not delete it.
+	if len(breaking) > 0 {
+		return nil, fmt.Errorf("design has breaking changes\n%s", content)
+	}
+	g.genfiles = append(g.genfiles, reportFile)
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// Load reads the JSON representation of a design produced by "goagen design-json".
+func Load(path string) (*gendesign.Design, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var d gendesign.Design
+	if err := json.Unmarshal(content, &d); err != nil {
+		return nil, fmt.Errorf("invalid design JSON %s: %s", path, err)
+	}
+	if d.Format != gendesign.FormatVersion {
+		return nil, fmt.Errorf("unsupported design JSON format %d in %s, expected %d", d.Format, path, gendesign.FormatVersion)
+	}
+	if d.API == nil {
+		return nil, fmt.Errorf("invalid design JSON %s: missing API", path)
+	}
+	return &d, nil
+}
+
+// normalize round-trips d through its JSON representation so that values such as enums and
+// defaults have the same Go types as the values of a loaded design.
+func normalize(d *gendesign.Design) (*gendesign.Design, error) {
+	js, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	var res gendesign.Design
+	if err := json.Unmarshal(js, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// report returns the content of the report listing the given changes.
+func report(changes []*Change, breaking int) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%d breaking change(s), %d compatible change(s)\n", breaking, len(changes)-breaking)
+	for _, c := range changes {
+		buf.WriteString(c.String())
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
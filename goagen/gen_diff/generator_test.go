@@ -0,0 +1,134 @@
+package gendiff_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_design"
+	"github.com/goadesign/goa/goagen/gen_diff"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var files []string
+	var genErr error
+	var workspace *codegen.Workspace
+	var testPkg *codegen.Package
+	var baseFile string
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		testPkg, err = workspace.NewPackage("difftest")
+		Ω(err).ShouldNot(HaveOccurred())
+		baseFile = filepath.Join(testPkg.Abs(), "base.json")
+		os.Args = []string{"goagen", "--out=" + testPkg.Abs(), "--design=foo", "--base=" + baseFile, "--version=" + version.String()}
+
+		dslengine.Reset()
+		apidsl.API("test api", func() {})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Response(design.NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		js, err := json.Marshal(gendesign.Build(design.Design))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ioutil.WriteFile(baseFile, js, 0644)).ShouldNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = gendiff.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with compatible changes", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", func() {})
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/bottles/:id"), apidsl.GET("/wines/:id"))
+					apidsl.Response(design.NoContent)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("writes the report", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(files).Should(HaveLen(1))
+			content, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "design_diff.txt"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(Equal("0 breaking change(s), 1 compatible change(s)\n" +
+				`compatible: resource "bottle" action "show": route GET /wines/:id added` + "\n"))
+		})
+	})
+
+	Context("with breaking changes", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", func() {})
+			apidsl.Resource("bottle", func() {})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("returns an error listing the changes and keeps the report", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(ContainSubstring(`breaking: resource "bottle" action "show": action removed`))
+			content, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "design_diff.txt"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(HavePrefix("1 breaking change(s), 0 compatible change(s)\n"))
+		})
+	})
+})
+
+var _ = Describe("NewGenerator", func() {
+	var generator *gendiff.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+		base   string
+		target string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+		base:   "base.json",
+		target: "target.json",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = gendiff.NewGenerator(
+				gendiff.API(args.api),
+				gendiff.OutDir(args.outDir),
+				gendiff.Base(args.base),
+				gendiff.Target(args.target),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Base).Should(Equal(args.base))
+			Ω(generator.Target).Should(Equal(args.target))
+		})
+	})
+})
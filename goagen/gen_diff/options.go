@@ -0,0 +1,34 @@
+package gendiff
+
+import "github.com/goadesign/goa/design"
+
+// Option a generator option definition
+type Option func(*Generator)
+
+// API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+// OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+// Base Path to the JSON representation of the base design
+func Base(base string) Option {
+	return func(g *Generator) {
+		g.Base = base
+	}
+}
+
+// Target Path to the JSON representation of the target design, overrides API
+func Target(target string) Option {
+	return func(g *Generator) {
+		g.Target = target
+	}
+}
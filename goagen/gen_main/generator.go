@@ -290,9 +290,10 @@ func (g *Generator) createMainFile(mainFile string, funcs template.FuncMap) erro
 		}
 	}
 	data := map[string]interface{}{
-		"Name": g.API.Name,
-		"API":  g.API,
-		"TLS":  tls,
+		"Name":     g.API.Name,
+		"API":      g.API,
+		"TLS":      tls,
+		"Problems": g.API.ErrorMedia() == design.ProblemMedia,
 	}
 	if err = file.ExecuteTemplate("main", mainT, funcs, data); err != nil {
 		return err
@@ -439,7 +440,10 @@ const mainT = `
 func main() {
 	// Create service
 	service := goa.New({{ printf "%q" .Name }})
-
+{{ if .Problems }}
+	// Render errors as RFC 7807 problem details
+	service.ErrorFormatter = goa.ProblemErrorFormatter("")
+{{ end }}
 	// Mount middleware
 	service.Use(middleware.RequestID())
 	service.Use(middleware.LogRequest(true))
@@ -451,6 +455,9 @@ func main() {
 	{{ targetPkg }}.Mount{{ $name }}Controller(service, {{ $tmp }})
 {{ end }}
 
+	// Shut down gracefully on SIGINT or SIGTERM, in-flight requests have 30 seconds to complete
+	service.ShutdownOnSignal(30 * time.Second)
+
 {{ if .TLS }}
 	// Start service
 	if err := service.ListenAndServeTLS(":{{ getPort .API.Host }}", "cert.pem", "key.pem"); err != nil {
@@ -54,6 +54,7 @@ var _ = Describe("Generate", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(len(strings.Split(string(content), "\n"))).Should(BeNumerically(">=", 16))
 			Ω(string(content)).Should(ContainSubstring(listenAndServeCode))
+			Ω(string(content)).Should(ContainSubstring("service.ShutdownOnSignal(30 * time.Second)"))
 			_, err = gexec.Build(testgenPackagePath)
 			Ω(err).ShouldNot(HaveOccurred())
 		})
@@ -75,6 +76,19 @@ var _ = Describe("Generate", func() {
 			})
 
 		})
+
+		Context("with problem details errors", func() {
+			BeforeEach(func() {
+				design.Design.ErrorMediaType = design.ProblemMedia
+			})
+
+			It("configures the service error formatter", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "main.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(`service.ErrorFormatter = goa.ProblemErrorFormatter("")`))
+			})
+		})
 	})
 
 	Context("with resources", func() {
@@ -0,0 +1,23 @@
+/*
+Package genmock provides a goa generator for mock servers.
+The generated mock server is a standalone goa service that mounts the controllers of the package
+generated with "goagen app". Requests are thus decoded and validated exactly like in the real
+application. The mock controller actions respond with the first success response of the action
+and with a body built from the examples defined in the design, or randomly generated using the
+API random generator when the design does not define one.
+
+The response of an action may be overridden with a fixture file located at
+"<fixtures>/<resource>/<action>.json" where <fixtures> is the directory given with the -fixtures
+flag of the mock server and <resource> and <action> are the names used in the design. Fixture
+files are read on each request so that they may be edited while the server runs. A fixture file
+contains a JSON object with the following optional fields:
+
+	{
+		"status": 404,
+		"headers": {"X-Request-Id": "abc"},
+		"body": {"id": "abc", "message": "not found"}
+	}
+
+The mock server accepts all requests made to secured actions and does not mock websocket actions.
+*/
+package genmock
@@ -0,0 +1,514 @@
+package genmock
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of a mock server generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the mock server generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Destination directory
+	AppPkg   string                // Import path of the app package, may be relative to OutDir
+	MockDir  string                // Name of the mock server directory, relative to OutDir
+	genfiles []string              // Generated files
+}
+
+type (
+	// mockController is the data used to render the mock controller of a resource.
+	mockController struct {
+		Name     string
+		Resource string
+		Actions  []*mockAction
+	}
+
+	// mockAction is the data used to render a mock controller action.
+	mockAction struct {
+		Name        string
+		Action      string
+		Context     string
+		Status      int
+		ContentType string
+		Example     string
+		Stream      bool
+		WebSocket   bool
+	}
+)
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, appPkg, mockDir, ver string
+
+	set := flag.NewFlagSet("mock", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.String("design", "", "")
+	set.StringVar(&appPkg, "app-pkg", "app", "")
+	set.StringVar(&mockDir, "mockdir", "mock", "")
+	set.StringVar(&ver, "version", "", "")
+	set.Parse(os.Args[1:])
+
+	// First check compatibility
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	// Now proceed
+	g := &Generator{OutDir: outDir, AppPkg: appPkg, MockDir: mockDir, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the mock server main and controllers.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.AppPkg == "" {
+		g.AppPkg = "app"
+	}
+	if g.MockDir == "" {
+		g.MockDir = "mock"
+	}
+
+	appImp, err := g.appImport()
+	if err != nil {
+		return nil, err
+	}
+	ctrls, err := g.controllers()
+	if err != nil {
+		return nil, err
+	}
+
+	mockDir := filepath.Join(g.OutDir, g.MockDir)
+	if err = os.MkdirAll(mockDir, 0755); err != nil {
+		return nil, err
+	}
+	if err = g.generateMain(filepath.Join(mockDir, "main.go"), appImp, ctrls); err != nil {
+		return
+	}
+	if err = g.generateMocks(filepath.Join(mockDir, "mocks.go"), appImp, ctrls); err != nil {
+		return
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// appImport returns the import path of the app package. AppPkg may be a complete import path or
+// a path relative to the output directory.
+func (g *Generator) appImport() (string, error) {
+	if _, err := codegen.PackageSourcePath(g.AppPkg); err == nil {
+		return g.AppPkg, nil
+	}
+	imp, err := codegen.PackagePath(g.OutDir)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(filepath.ToSlash(imp), g.AppPkg), nil
+}
+
+// controllers computes the mock controllers of the API resources.
+func (g *Generator) controllers() ([]*mockController, error) {
+	var ctrls []*mockController
+	err := g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		ctrl := &mockController{
+			Name:     codegen.Goify(res.Name, true),
+			Resource: res.Name,
+		}
+		err := res.IterateActions(func(a *design.ActionDefinition) error {
+			action, err := g.action(a)
+			if err != nil {
+				return fmt.Errorf("%s: %s", a.Context(), err)
+			}
+			ctrl.Actions = append(ctrl.Actions, action)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		ctrls = append(ctrls, ctrl)
+		return nil
+	})
+	return ctrls, err
+}
+
+// action computes the mock of the given action. The mock responds with the first success response
+// sorted by status and renders the example of the response media type using the response view.
+// Actions that stream server-sent events send the example of the event data as a single event.
+func (g *Generator) action(a *design.ActionDefinition) (*mockAction, error) {
+	action := &mockAction{
+		Name:      codegen.Goify(a.Name, true),
+		Action:    a.Name,
+		Context:   codegen.Goify(a.Name, true) + codegen.Goify(a.Parent.Name, true) + "Context",
+		Status:    204,
+		Stream:    a.ServerSentEvents(),
+		WebSocket: a.WebSocket(),
+	}
+	if action.WebSocket {
+		return action, nil
+	}
+	if action.Stream {
+		example, err := g.example(a.EventStream)
+		if err != nil {
+			return nil, err
+		}
+		action.Example = example
+		return action, nil
+	}
+
+	resp := successResponse(a)
+	if resp == nil {
+		return action, nil
+	}
+	action.Status = resp.Status
+	action.ContentType = resp.MediaType
+	mt := g.API.MediaTypeWithIdentifier(resp.MediaType)
+	if mt == nil {
+		return action, nil
+	}
+	view := resp.ViewName
+	if view == "" {
+		view = design.DefaultView
+	}
+	pmt, _, err := mt.Project(view)
+	if err != nil {
+		return nil, err
+	}
+	example, err := g.example(pmt.AttributeDefinition)
+	if err != nil {
+		return nil, err
+	}
+	action.ContentType = mt.ContentType
+	if action.ContentType == "" {
+		action.ContentType = mt.Identifier
+	}
+	action.Example = example
+	return action, nil
+}
+
+// example returns the JSON representation of the example of the given attribute.
+func (g *Generator) example(att *design.AttributeDefinition) (string, error) {
+	ex := att.GenerateExample(g.API.RandomGenerator(), nil)
+	if ex == nil {
+		return "", nil
+	}
+	b, err := json.Marshal(toStringMap(ex))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// successResponse returns the success response of the action with the lowest status if any,
+// the response with the lowest status otherwise.
+func successResponse(a *design.ActionDefinition) *design.ResponseDefinition {
+	var resps []*design.ResponseDefinition
+	a.IterateResponses(func(r *design.ResponseDefinition) error {
+		resps = append(resps, r)
+		return nil
+	})
+	if len(resps) == 0 {
+		return nil
+	}
+	sort.SliceStable(resps, func(i, j int) bool { return resps[i].Status < resps[j].Status })
+	for _, r := range resps {
+		if r.Status >= 200 && r.Status < 300 {
+			return r
+		}
+	}
+	return resps[0]
+}
+
+func (g *Generator) generateMain(mainFile, appImp string, ctrls []*mockController) error {
+	file, err := codegen.SourceFileFor(mainFile)
+	if err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, mainFile)
+
+	title := fmt.Sprintf("%s: Mock Server", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("flag"),
+		codegen.SimpleImport("time"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware"),
+		codegen.SimpleImport(appImp),
+	}
+	if err = file.WriteHeader(title, "main", imports); err != nil {
+		return err
+	}
+	port := "8080"
+	if _, p, err := net.SplitHostPort(g.API.Host); err == nil {
+		port = p
+	}
+	data := map[string]interface{}{
+		"Name":        g.API.Name,
+		"AppPkg":      path.Base(appImp),
+		"Addr":        ":" + port,
+		"Problems":    g.API.ErrorMedia() == design.ProblemMedia,
+		"Schemes":     g.API.SecuritySchemes,
+		"Controllers": ctrls,
+	}
+	if err = file.ExecuteTemplate("main", mainT, nil, data); err != nil {
+		return err
+	}
+	return file.FormatCode()
+}
+
+func (g *Generator) generateMocks(mocksFile, appImp string, ctrls []*mockController) error {
+	file, err := codegen.SourceFileFor(mocksFile)
+	if err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, mocksFile)
+
+	title := fmt.Sprintf("%s: Mock Controllers", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("io/ioutil"),
+		codegen.SimpleImport("os"),
+		codegen.SimpleImport("path/filepath"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport(appImp),
+	}
+	if err = file.WriteHeader(title, "main", imports); err != nil {
+		return err
+	}
+	if err = file.ExecuteTemplate("helpers", helpersT, nil, nil); err != nil {
+		return err
+	}
+	appPkg := path.Base(appImp)
+	for _, ctrl := range ctrls {
+		data := map[string]interface{}{"Controller": ctrl, "AppPkg": appPkg}
+		if err = file.ExecuteTemplate("mock", mockT, nil, data); err != nil {
+			return err
+		}
+	}
+	return file.FormatCode()
+}
+
+// toStringMap converts map[interface{}]interface{} to a map[string]interface{} when possible.
+func toStringMap(val interface{}) interface{} {
+	switch actual := val.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{})
+		for k, v := range actual {
+			m[toString(k)] = toStringMap(v)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{})
+		for k, v := range actual {
+			m[k] = toStringMap(v)
+		}
+		return m
+	case []interface{}:
+		mapSlice := make([]interface{}, len(actual))
+		for i, e := range actual {
+			mapSlice[i] = toStringMap(e)
+		}
+		return mapSlice
+	default:
+		return actual
+	}
+}
+
+// toString returns the string representation of the given type.
+func toString(val interface{}) string {
+	switch actual := val.(type) {
+	case string:
+		return actual
+	case int:
+		return strconv.Itoa(actual)
+	case float64:
+		return strconv.FormatFloat(actual, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(actual)
+	default:
+		return fmt.Sprintf("%v", actual)
+	}
+}
+
+const mainT = `
+func main() {
+	addr := flag.String("addr", {{ printf "%q" .Addr }}, "` + "`" + `address` + "`" + ` the mock server listens on")
+{{ if .Controllers }}	fixtures := flag.String("fixtures", "fixtures", "` + "`" + `directory` + "`" + ` containing the fixture files overriding the mock responses")
+{{ end }}	flag.Parse()
+
+	// Create service
+	service := goa.New({{ printf "%q" .Name }})
+{{ if .Problems }}
+	// Render errors as RFC 7807 problem details
+	service.ErrorFormatter = goa.ProblemErrorFormatter("")
+{{ end }}
+	// Mount middleware
+	service.Use(middleware.RequestID())
+	service.Use(middleware.LogRequest(true))
+	service.Use(middleware.ErrorHandler(service, true))
+	service.Use(middleware.Recover())
+{{ if .Schemes }}
+	// Accept all requests made to secured actions
+{{ range .Schemes }}	{{ $.AppPkg }}.Use{{ goify .SchemeName true }}Middleware(service, acceptAll)
+{{ end }}{{ end }}
+{{ range .Controllers }}	// Mount "{{ .Resource }}" mock controller
+	{{ $.AppPkg }}.Mount{{ .Name }}Controller(service, New{{ .Name }}Mock(service, *fixtures))
+{{ end }}
+	// Shut down gracefully on SIGINT or SIGTERM, in-flight requests have 30 seconds to complete
+	service.ShutdownOnSignal(30 * time.Second)
+
+	// Start service
+	if err := service.ListenAndServe(*addr); err != nil {
+		service.LogError("startup", "err", err)
+	}
+}
+{{ if .Schemes }}
+// acceptAll is the security middleware of the mock server, it lets all requests through.
+func acceptAll(h goa.Handler) goa.Handler {
+	return h
+}
+{{ end }}`
+
+const helpersT = `
+// ErrNotMocked is the error returned by the actions that cannot be mocked.
+var ErrNotMocked = goa.NewErrorClass("not_mocked", 501)
+
+// fixture is the content of a fixture file overriding the response of an action.
+type fixture struct {
+	// Status is the response status, the status of the mock response is used if zero.
+	Status int ` + "`" + `json:"status"` + "`" + `
+	// Headers lists the response headers.
+	Headers map[string]string ` + "`" + `json:"headers"` + "`" + `
+	// Body is the response body, the example of the mock response is used if missing.
+	Body json.RawMessage ` + "`" + `json:"body"` + "`" + `
+}
+
+// loadFixture reads the fixture file of the given action if any.
+func loadFixture(fixtures, resource, action string) (*fixture, error) {
+	b, err := ioutil.ReadFile(filepath.Join(fixtures, resource, action+".json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f fixture
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("invalid fixture file %s/%s.json: %s", resource, action, err)
+	}
+	return &f, nil
+}
+
+// respond writes the response of the given action using its fixture file if any, the given
+// status and example otherwise.
+func respond(rw *goa.ResponseData, fixtures, resource, action string, status int, contentType, example string) error {
+	body := []byte(example)
+	f, err := loadFixture(fixtures, resource, action)
+	if err != nil {
+		return err
+	}
+	if f != nil {
+		if f.Status != 0 {
+			status = f.Status
+		}
+		if f.Body != nil {
+			body = f.Body
+		}
+		for name, value := range f.Headers {
+			rw.Header().Set(name, value)
+		}
+	}
+	if len(body) > 0 && contentType != "" && rw.Header().Get("Content-Type") == "" {
+		rw.Header().Set("Content-Type", contentType)
+	}
+	rw.WriteHeader(status)
+	if len(body) == 0 {
+		return nil
+	}
+	_, err = rw.Write(body)
+	return err
+}
+
+// stream sends a single server-sent event whose data is the body of the fixture file of the
+// given action if any, the given example otherwise.
+func stream(ctx context.Context, rw *goa.ResponseData, req *goa.RequestData, fixtures, resource, action, example string) error {
+	data := []byte(example)
+	f, err := loadFixture(fixtures, resource, action)
+	if err != nil {
+		return err
+	}
+	if f != nil && f.Body != nil {
+		data = f.Body
+	}
+	s, err := goa.NewEventStream(ctx, rw, req)
+	if err != nil {
+		return err
+	}
+	return s.Send(&goa.ServerSentEvent{ID: "1", Data: data})
+}
+`
+
+const mockT = `{{ $ctrl := .Controller }}{{ $mock := printf "%sMock" $ctrl.Name }}
+// {{ $mock }} mocks the {{ $ctrl.Resource }} resource.
+type {{ $mock }} struct {
+	*goa.Controller
+	fixtures string
+}
+
+// New{{ $mock }} creates a {{ $ctrl.Resource }} mock controller reading fixture files from the given directory.
+func New{{ $mock }}(service *goa.Service, fixtures string) *{{ $mock }} {
+	return &{{ $mock }}{Controller: service.NewController("{{ $ctrl.Name }}Controller"), fixtures: fixtures}
+}
+{{ range $ctrl.Actions }}
+{{ if .WebSocket }}// {{ .Name }} does not mock the {{ .Action }} websocket action.
+func (c *{{ $mock }}) {{ .Name }}(ctx *{{ $.AppPkg }}.{{ .Context }}) error {
+	return ErrNotMocked("websocket actions are not mocked")
+}
+{{ else if .Stream }}// {{ .Name }} mocks the {{ .Action }} action.
+func (c *{{ $mock }}) {{ .Name }}(ctx *{{ $.AppPkg }}.{{ .Context }}) error {
+	return stream(ctx, ctx.ResponseData, ctx.RequestData, c.fixtures, {{ printf "%q" $ctrl.Resource }}, {{ printf "%q" .Action }}, {{ printf "%q" .Example }})
+}
+{{ else }}// {{ .Name }} mocks the {{ .Action }} action.
+func (c *{{ $mock }}) {{ .Name }}(ctx *{{ $.AppPkg }}.{{ .Context }}) error {
+	return respond(ctx.ResponseData, c.fixtures, {{ printf "%q" $ctrl.Resource }}, {{ printf "%q" .Action }}, {{ .Status }}, {{ printf "%q" .ContentType }}, {{ printf "%q" .Example }})
+}
+{{ end }}{{ end }}`
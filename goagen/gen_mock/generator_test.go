@@ -0,0 +1,133 @@
+package genmock_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_mock"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var files []string
+	var genErr error
+	var workspace *codegen.Workspace
+	var testPkg *codegen.Package
+	var main, mocks string
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		testPkg, err = workspace.NewPackage("mocktest")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + testPkg.Abs(), "--design=foo", "--version=" + version.String()}
+		dslengine.Reset()
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genmock.Generate()
+		main, mocks = "", ""
+		if genErr == nil {
+			b, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "mock", "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			main = string(b)
+			b, err = ioutil.ReadFile(filepath.Join(testPkg.Abs(), "mock", "mocks.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			mocks = string(b)
+		}
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with a dummy API", func() {
+		BeforeEach(func() {
+			API("test api", func() {
+				Title("dummy API with no resource")
+			})
+		})
+
+		It("generates a mock server with no controller", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(files).Should(HaveLen(2))
+			Ω(main).Should(ContainSubstring("package main"))
+			Ω(main).Should(ContainSubstring(`flag.String("addr", ":8080"`))
+			Ω(main).ShouldNot(ContainSubstring("fixtures"))
+			Ω(mocks).Should(ContainSubstring("func respond("))
+		})
+	})
+
+	Context("with resources", func() {
+		BeforeEach(func() {
+			API("test api", func() {
+				Host("localhost:8081")
+				BasicAuthSecurity("basic")
+			})
+			bottle := MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("id", Integer, func() {
+						Example(42)
+					})
+					Attribute("name", String, func() {
+						Example("muscadet")
+					})
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+				})
+				View("tiny", func() {
+					Attribute("id")
+				})
+			})
+			Resource("bottle", func() {
+				Security("basic")
+				Action("show", func() {
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("id", Integer)
+					})
+					Response(NotFound)
+					Response(OK, func() {
+						Media(bottle, "tiny")
+					})
+				})
+				Action("delete", func() {
+					Routing(DELETE("/:id"))
+					Response(NoContent)
+				})
+				Action("watch", func() {
+					Routing(GET("/events"))
+					ServerSentEvents(bottle)
+				})
+			})
+		})
+
+		It("generates the mock server main", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(main).Should(ContainSubstring(`flag.String("addr", ":8081"`))
+			Ω(main).Should(ContainSubstring(`flag.String("fixtures", "fixtures"`))
+			Ω(main).Should(ContainSubstring("app.UseBasicMiddleware(service, acceptAll)"))
+			Ω(main).Should(ContainSubstring("app.MountBottleController(service, NewBottleMock(service, *fixtures))"))
+		})
+
+		It("generates actions responding with the examples", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(mocks).Should(ContainSubstring("type BottleMock struct"))
+			Ω(mocks).Should(ContainSubstring(`func (c *BottleMock) Show(ctx *app.ShowBottleContext) error {
+	return respond(ctx.ResponseData, c.fixtures, "bottle", "show", 200, "application/vnd.bottle", "{\"id\":42}")`))
+			Ω(mocks).Should(ContainSubstring(`return respond(ctx.ResponseData, c.fixtures, "bottle", "delete", 204, "", "")`))
+			Ω(mocks).Should(ContainSubstring(`return stream(ctx, ctx.ResponseData, ctx.RequestData, c.fixtures, "bottle", "watch", "{\"id\":42,\"name\":\"muscadet\"}")`))
+		})
+	})
+})
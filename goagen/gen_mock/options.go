@@ -0,0 +1,36 @@
+package genmock
+
+import "github.com/goadesign/goa/design"
+
+// Option a generator option definition
+type Option func(*Generator)
+
+// API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+// OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+// AppPkg Import path of the package generated with "goagen app", may be relative to the output
+// directory
+func AppPkg(appPkg string) Option {
+	return func(g *Generator) {
+		g.AppPkg = appPkg
+	}
+}
+
+// MockDir Name of the directory containing the generated mock server, relative to the output
+// directory
+func MockDir(mockDir string) Option {
+	return func(g *Generator) {
+		g.MockDir = mockDir
+	}
+}
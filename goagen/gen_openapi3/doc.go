@@ -0,0 +1,8 @@
+/*
+Package genopenapi3 provides a generator for the OpenAPI 3.0 specification of an API.
+The generator walks the same API definition as the Swagger generator and produces the
+corresponding OpenAPI 3.0 document (https://spec.openapis.org/oas/v3.0.3) in both JSON and YAML
+formats. The "swagger:generate", "swagger:summary", "swagger:tag:*" and "swagger:extension:*"
+metadata are honored identically.
+*/
+package genopenapi3
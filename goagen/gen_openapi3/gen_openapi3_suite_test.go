@@ -0,0 +1,13 @@
+package genopenapi3_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenOpenAPI3(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenOpenAPI3 Suite")
+}
@@ -0,0 +1,1068 @@
+package genopenapi3
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_schema"
+)
+
+type (
+	// OpenAPI represents an instance of an OpenAPI 3.0 document.
+	// See https://spec.openapis.org/oas/v3.0.3
+	OpenAPI struct {
+		OpenAPI      string                 `json:"openapi"`
+		Info         *Info                  `json:"info"`
+		Servers      []*Server              `json:"servers,omitempty"`
+		Paths        map[string]interface{} `json:"paths"`
+		Components   *Components            `json:"components,omitempty"`
+		Security     []map[string][]string  `json:"security,omitempty"`
+		Tags         []*Tag                 `json:"tags,omitempty"`
+		ExternalDocs *ExternalDocs          `json:"externalDocs,omitempty"`
+	}
+
+	// Info provides metadata about the API. The metadata can be used by the clients if needed,
+	// and can be presented in editing or documentation generation tools for convenience.
+	Info struct {
+		Title          string                    `json:"title"`
+		Description    string                    `json:"description,omitempty"`
+		TermsOfService string                    `json:"termsOfService,omitempty"`
+		Contact        *design.ContactDefinition `json:"contact,omitempty"`
+		License        *design.LicenseDefinition `json:"license,omitempty"`
+		Version        string                    `json:"version"`
+		Extensions     map[string]interface{}    `json:"-"`
+	}
+
+	// Server represents a server hosting the API.
+	Server struct {
+		// URL to the target host, may be relative to the location of the document.
+		URL string `json:"url"`
+		// Description of the host designated by the URL.
+		Description string `json:"description,omitempty"`
+	}
+
+	// Path holds the operations available on a single path.
+	Path struct {
+		// Ref allows for an external definition of this path item.
+		Ref string `json:"$ref,omitempty"`
+		// Get defines a GET operation on this path.
+		Get *Operation `json:"get,omitempty"`
+		// Put defines a PUT operation on this path.
+		Put *Operation `json:"put,omitempty"`
+		// Post defines a POST operation on this path.
+		Post *Operation `json:"post,omitempty"`
+		// Delete defines a DELETE operation on this path.
+		Delete *Operation `json:"delete,omitempty"`
+		// Options defines a OPTIONS operation on this path.
+		Options *Operation `json:"options,omitempty"`
+		// Head defines a HEAD operation on this path.
+		Head *Operation `json:"head,omitempty"`
+		// Patch defines a PATCH operation on this path.
+		Patch *Operation `json:"patch,omitempty"`
+		// Trace defines a TRACE operation on this path.
+		Trace *Operation `json:"trace,omitempty"`
+		// Parameters is the list of parameters that are applicable for all the operations
+		// described under this path.
+		Parameters []*Parameter `json:"parameters,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// Operation describes a single API operation on a path.
+	Operation struct {
+		// Tags is a list of tags for API documentation control. Tags can be used for
+		// logical grouping of operations by resources or any other qualifier.
+		Tags []string `json:"tags,omitempty"`
+		// Summary is a short summary of what the operation does.
+		Summary string `json:"summary,omitempty"`
+		// Description is a verbose explanation of the operation behavior.
+		// CommonMark syntax can be used for rich text representation.
+		Description string `json:"description,omitempty"`
+		// ExternalDocs points to additional external documentation for this operation.
+		ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+		// OperationID is a unique string used to identify the operation.
+		OperationID string `json:"operationId,omitempty"`
+		// Parameters is a list of parameters that are applicable for this operation.
+		Parameters []*Parameter `json:"parameters,omitempty"`
+		// RequestBody describes the request body if any.
+		RequestBody *RequestBody `json:"requestBody,omitempty"`
+		// Responses is the list of possible responses as they are returned from executing
+		// this operation.
+		Responses map[string]*Response `json:"responses"`
+		// Deprecated declares this operation to be deprecated.
+		Deprecated bool `json:"deprecated,omitempty"`
+		// Security is a declaration of which security schemes are applied for this operation.
+		Security []map[string][]string `json:"security,omitempty"`
+		// Servers overrides the API servers for this operation.
+		Servers []*Server `json:"servers,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// Parameter describes a single operation parameter.
+	Parameter struct {
+		// Name of the parameter. Parameter names are case sensitive.
+		Name string `json:"name"`
+		// In is the location of the parameter.
+		// Possible values are "query", "header", "path" or "cookie".
+		In string `json:"in"`
+		// Description is a brief description of the parameter.
+		Description string `json:"description,omitempty"`
+		// Required determines whether this parameter is mandatory.
+		Required bool `json:"required"`
+		// Explode generates separate parameters for each value of arrays when true.
+		Explode *bool `json:"explode,omitempty"`
+		// Schema defining the type used for the parameter.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// RequestBody describes a single request body.
+	RequestBody struct {
+		// Description is a brief description of the request body.
+		Description string `json:"description,omitempty"`
+		// Content lists the supported representations of the body indexed by media type.
+		Content map[string]*MediaType `json:"content"`
+		// Required determines if the request body is required in the request.
+		Required bool `json:"required,omitempty"`
+	}
+
+	// MediaType describes the schema and example of a given representation.
+	MediaType struct {
+		// Schema defines the type used for the content.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+	}
+
+	// Response describes an operation response.
+	Response struct {
+		// Description of the response. CommonMark syntax can be used for rich text
+		// representation.
+		Description string `json:"description,omitempty"`
+		// Headers maps header names to their definitions.
+		Headers map[string]*Header `json:"headers,omitempty"`
+		// Content lists the possible representations of the response body indexed by
+		// media type.
+		Content map[string]*MediaType `json:"content,omitempty"`
+		// Ref references a response defined in the document components.
+		// This field is exclusive with the other fields of Response.
+		Ref string `json:"$ref,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// Header represents a response header.
+	Header struct {
+		// Description is a brief description of the header.
+		Description string `json:"description,omitempty"`
+		// Required determines whether this header is mandatory.
+		Required bool `json:"required,omitempty"`
+		// Schema defining the type used for the header.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+	}
+
+	// Components holds the reusable objects of the document.
+	Components struct {
+		// Schemas lists the reusable schemas indexed by name.
+		Schemas map[string]*genschema.JSONSchema `json:"schemas,omitempty"`
+		// Responses lists the reusable responses indexed by name.
+		Responses map[string]*Response `json:"responses,omitempty"`
+		// SecuritySchemes lists the reusable security schemes indexed by name.
+		SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+	}
+
+	// SecurityScheme defines a security scheme that can be used by the operations. Supported
+	// schemes are HTTP authentication, an API key (either as a header or as a query parameter)
+	// and OAuth2's common flows (implicit, password, client credentials and authorization
+	// code).
+	SecurityScheme struct {
+		// Type of the security scheme. Valid values are "apiKey", "http" or "oauth2".
+		Type string `json:"type"`
+		// Description for security scheme.
+		Description string `json:"description,omitempty"`
+		// Name of the header or query parameter to be used when type is "apiKey".
+		Name string `json:"name,omitempty"`
+		// In is the location of the API key when type is "apiKey".
+		// Valid values are "query" or "header".
+		In string `json:"in,omitempty"`
+		// Scheme is the name of the HTTP Authorization scheme when type is "http".
+		Scheme string `json:"scheme,omitempty"`
+		// BearerFormat is a hint to the client to identify how the bearer token is
+		// formatted.
+		BearerFormat string `json:"bearerFormat,omitempty"`
+		// Flows contains configuration information for the OAuth2 flows.
+		Flows *OAuthFlows `json:"flows,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// OAuthFlows lists the configuration of the supported OAuth2 flows.
+	OAuthFlows struct {
+		// Implicit configures the OAuth2 implicit flow.
+		Implicit *OAuthFlow `json:"implicit,omitempty"`
+		// Password configures the OAuth2 resource owner password flow.
+		Password *OAuthFlow `json:"password,omitempty"`
+		// ClientCredentials configures the OAuth2 client credentials flow.
+		ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+		// AuthorizationCode configures the OAuth2 authorization code flow.
+		AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+	}
+
+	// OAuthFlow describes the configuration of a single OAuth2 flow.
+	OAuthFlow struct {
+		// AuthorizationURL is the authorization URL to be used for this flow.
+		AuthorizationURL string `json:"authorizationUrl,omitempty"`
+		// TokenURL is the token URL to be used for this flow.
+		TokenURL string `json:"tokenUrl,omitempty"`
+		// Scopes list the available scopes for the OAuth2 security scheme.
+		Scopes map[string]string `json:"scopes"`
+	}
+
+	// ExternalDocs allows referencing an external resource for extended documentation.
+	ExternalDocs struct {
+		// Description is a short description of the target documentation.
+		Description string `json:"description,omitempty"`
+		// URL for the target documentation.
+		URL string `json:"url"`
+	}
+
+	// Tag allows adding meta data to a single tag that is used by the Operation Object. It is
+	// not mandatory to have a Tag Object per tag used there.
+	Tag struct {
+		// Name of the tag.
+		Name string `json:"name,omitempty"`
+		// Description is a short description of the tag.
+		Description string `json:"description,omitempty"`
+		// ExternalDocs is additional external documentation for this tag.
+		ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// These types are used in marshalJSON() to avoid recursive call of json.Marshal().
+	_Info           Info
+	_Path           Path
+	_Operation      Operation
+	_Parameter      Parameter
+	_Response       Response
+	_SecurityScheme SecurityScheme
+	_Tag            Tag
+)
+
+const (
+	// schemasRef is the prefix of references to schemas defined in the document components.
+	schemasRef = "#/components/schemas/"
+	// responsesRef is the prefix of references to responses defined in the document
+	// components.
+	responsesRef = "#/components/responses/"
+)
+
+func marshalJSON(v interface{}, extensions map[string]interface{}) ([]byte, error) {
+	marshaled, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(extensions) == 0 {
+		return marshaled, nil
+	}
+	var unmarshaled interface{}
+	if err := json.Unmarshal(marshaled, &unmarshaled); err != nil {
+		return nil, err
+	}
+	asserted := unmarshaled.(map[string]interface{})
+	for k, v := range extensions {
+		asserted[k] = v
+	}
+	merged, err := json.Marshal(asserted)
+	if err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// MarshalJSON returns the JSON encoding of i.
+func (i Info) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Info(i), i.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of p.
+func (p Path) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Path(p), p.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of o.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Operation(o), o.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of p.
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Parameter(p), p.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of r.
+func (r Response) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Response(r), r.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of s.
+func (s SecurityScheme) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_SecurityScheme(s), s.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of t.
+func (t Tag) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Tag(t), t.Extensions)
+}
+
+// New creates an OpenAPI 3.0 document from an API definition.
+func New(api *design.APIDefinition) (*OpenAPI, error) {
+	if api == nil {
+		return nil, nil
+	}
+	basePath := api.BasePath
+	if hasAbsoluteRoutes(api) || len(design.ExtractWildcards(basePath)) > 0 {
+		// Server URLs cannot contain path parameters, use full paths instead.
+		basePath = ""
+	}
+	s := &OpenAPI{
+		OpenAPI: "3.0.3",
+		Info: &Info{
+			Title:          api.Title,
+			Description:    api.Description,
+			TermsOfService: api.TermsOfService,
+			Contact:        api.Contact,
+			License:        api.License,
+			Version:        api.Version,
+			Extensions:     extensionsFromDefinition(api.Metadata),
+		},
+		Servers:      serversFromDefinition(api, basePath),
+		Paths:        make(map[string]interface{}),
+		Tags:         tagsFromDefinition(api.Metadata),
+		ExternalDocs: docsFromDefinition(api.Docs),
+		Components: &Components{
+			SecuritySchemes: securitySchemesFromDefinition(api.SecuritySchemes),
+		},
+	}
+
+	err := api.IterateResponses(func(r *design.ResponseDefinition) error {
+		res, err := responseFromDefinition(api, r)
+		if err != nil {
+			return err
+		}
+		if s.Components.Responses == nil {
+			s.Components.Responses = make(map[string]*Response)
+		}
+		s.Components.Responses[r.Name] = res
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.IterateResources(func(res *design.ResourceDefinition) error {
+		for k, v := range extensionsFromDefinition(res.Metadata) {
+			s.Paths[k] = v
+		}
+		err := res.IterateFileServers(func(fs *design.FileServerDefinition) error {
+			if !mustGenerate(fs.Metadata) {
+				return nil
+			}
+			return buildPathFromFileServer(s, api, fs)
+		})
+		if err != nil {
+			return err
+		}
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if !mustGenerate(a.Metadata) {
+				return nil
+			}
+			for _, route := range a.Routes {
+				if err := buildPathFromDefinition(s, api, route, basePath); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(genschema.Definitions) > 0 {
+		s.Components.Schemas = make(map[string]*genschema.JSONSchema)
+		for n, d := range genschema.Definitions {
+			s.Components.Schemas[n] = d
+		}
+	}
+	for _, d := range s.Components.Schemas {
+		rewriteRefs(d)
+	}
+	for _, p := range s.Paths {
+		if path, ok := p.(*Path); ok {
+			rewritePathRefs(path)
+		}
+	}
+	for _, r := range s.Components.Responses {
+		rewriteResponseRefs(r)
+	}
+	return s, nil
+}
+
+// mustGenerate returns true if the metadata indicates that an OpenAPI specification should be
+// generated, false otherwise.
+func mustGenerate(meta dslengine.MetadataDefinition) bool {
+	if m, ok := meta["swagger:generate"]; ok {
+		if len(m) > 0 && m[0] == "false" {
+			return false
+		}
+	}
+	return true
+}
+
+// hasAbsoluteRoutes returns true if any action exposed by the API uses an absolute route or if the
+// API has file servers. In this case the server URLs cannot include the API base path and all
+// paths must be absolute.
+func hasAbsoluteRoutes(api *design.APIDefinition) bool {
+	for _, res := range api.Resources {
+		for _, fs := range res.FileServers {
+			if mustGenerate(fs.Metadata) {
+				return true
+			}
+		}
+		for _, a := range res.Actions {
+			if !mustGenerate(a.Metadata) {
+				continue
+			}
+			for _, ro := range a.Routes {
+				if ro.IsAbsolute() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// serversFromDefinition builds the list of servers from the API host, schemes and base path.
+func serversFromDefinition(api *design.APIDefinition, basePath string) []*Server {
+	if api.Host == "" {
+		if basePath == "" || basePath == "/" {
+			return nil
+		}
+		return []*Server{{URL: basePath}}
+	}
+	schemes := api.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	servers := make([]*Server, len(schemes))
+	for i, scheme := range schemes {
+		servers[i] = &Server{URL: fmt.Sprintf("%s://%s%s", scheme, api.Host, strings.TrimSuffix(basePath, "/"))}
+	}
+	return servers
+}
+
+func securitySchemesFromDefinition(schemes []*design.SecuritySchemeDefinition) map[string]*SecurityScheme {
+	if len(schemes) == 0 {
+		return nil
+	}
+
+	defs := make(map[string]*SecurityScheme)
+	for _, scheme := range schemes {
+		def := &SecurityScheme{
+			Description: scheme.Description,
+			Extensions:  extensionsFromDefinition(scheme.Metadata),
+		}
+		switch scheme.Kind {
+		case design.BasicAuthSecurityKind:
+			def.Type = "http"
+			def.Scheme = "basic"
+		case design.APIKeySecurityKind:
+			def.Type = "apiKey"
+			def.Name = scheme.Name
+			def.In = scheme.In
+		case design.JWTSecurityKind:
+			if scheme.In == "header" && http.CanonicalHeaderKey(scheme.Name) == "Authorization" {
+				def.Type = "http"
+				def.Scheme = "bearer"
+				def.BearerFormat = "JWT"
+			} else {
+				def.Type = "apiKey"
+				def.Name = scheme.Name
+				def.In = scheme.In
+			}
+			if scheme.TokenURL != "" {
+				def.Description += fmt.Sprintf("\n\n**Token URL**: %s", scheme.TokenURL)
+			}
+			if len(scheme.Scopes) != 0 {
+				def.Description += fmt.Sprintf("\n\n**Security Scopes**:\n%s", scopesMapList(scheme.Scopes))
+			}
+		case design.OAuth2SecurityKind:
+			def.Type = "oauth2"
+			scopes := scheme.Scopes
+			if scopes == nil {
+				scopes = make(map[string]string)
+			}
+			flow := &OAuthFlow{Scopes: scopes}
+			def.Flows = &OAuthFlows{}
+			switch scheme.Flow {
+			case "implicit":
+				flow.AuthorizationURL = scheme.AuthorizationURL
+				def.Flows.Implicit = flow
+			case "password":
+				flow.TokenURL = scheme.TokenURL
+				def.Flows.Password = flow
+			case "application":
+				flow.TokenURL = scheme.TokenURL
+				def.Flows.ClientCredentials = flow
+			case "accessCode":
+				flow.AuthorizationURL = scheme.AuthorizationURL
+				flow.TokenURL = scheme.TokenURL
+				def.Flows.AuthorizationCode = flow
+			}
+		}
+		defs[scheme.SchemeName] = def
+	}
+	return defs
+}
+
+func scopesMapList(scopes map[string]string) string {
+	names := []string{}
+	for name := range scopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := []string{}
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("  * `%s`: %s", name, scopes[name]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func tagsFromDefinition(mdata dslengine.MetadataDefinition) (tags []*Tag) {
+	var keys []string
+	for k := range mdata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		chunks := strings.Split(key, ":")
+		if len(chunks) != 3 {
+			continue
+		}
+		if chunks[0] != "swagger" || chunks[1] != "tag" {
+			continue
+		}
+
+		tag := &Tag{Name: chunks[2]}
+
+		if desc := mdata[key+":desc"]; len(desc) != 0 {
+			tag.Description = desc[0]
+		}
+
+		hasDocs := false
+		docs := &ExternalDocs{}
+
+		if u := mdata[key+":url"]; len(u) != 0 {
+			docs.URL = u[0]
+			hasDocs = true
+		}
+
+		if desc := mdata[key+":url:desc"]; len(desc) != 0 {
+			docs.Description = desc[0]
+			hasDocs = true
+		}
+
+		if hasDocs {
+			tag.ExternalDocs = docs
+		}
+
+		tag.Extensions = extensionsFromDefinition(mdata)
+
+		tags = append(tags, tag)
+	}
+
+	return
+}
+
+func tagNamesFromDefinitions(mdatas ...dslengine.MetadataDefinition) (tagNames []string) {
+	for _, mdata := range mdatas {
+		tags := tagsFromDefinition(mdata)
+		for _, tag := range tags {
+			tagNames = append(tagNames, tag.Name)
+		}
+	}
+	return
+}
+
+func summaryFromDefinition(name string, metadata dslengine.MetadataDefinition) string {
+	for n, mdata := range metadata {
+		if n == "swagger:summary" && len(mdata) > 0 {
+			return mdata[0]
+		}
+	}
+	return name
+}
+
+func extensionsFromDefinition(mdata dslengine.MetadataDefinition) map[string]interface{} {
+	extensions := make(map[string]interface{})
+	for key, value := range mdata {
+		chunks := strings.Split(key, ":")
+		if len(chunks) != 3 {
+			continue
+		}
+		if chunks[0] != "swagger" || chunks[1] != "extension" {
+			continue
+		}
+		if !strings.HasPrefix(chunks[2], "x-") {
+			continue
+		}
+		val := value[0]
+		ival := interface{}(val)
+		if err := json.Unmarshal([]byte(val), &ival); err != nil {
+			extensions[chunks[2]] = val
+			continue
+		}
+		extensions[chunks[2]] = ival
+	}
+	if len(extensions) == 0 {
+		return nil
+	}
+	return extensions
+}
+
+func paramsFromDefinition(api *design.APIDefinition, params *design.AttributeDefinition, path string) ([]*Parameter, error) {
+	if params == nil {
+		return nil, nil
+	}
+	obj := params.Type.ToObject()
+	if obj == nil {
+		return nil, fmt.Errorf("invalid parameters definition, not an object")
+	}
+	res := make([]*Parameter, len(obj))
+	i := 0
+	wildcards := design.ExtractWildcards(path)
+	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		in := "query"
+		required := params.IsRequired(n)
+		for _, w := range wildcards {
+			if n == w {
+				in = "path"
+				required = true
+				break
+			}
+		}
+		res[i] = paramFor(api, at, n, in, required)
+		i++
+		return nil
+	})
+	return res, nil
+}
+
+func paramsFromHeaders(api *design.APIDefinition, action *design.ActionDefinition) []*Parameter {
+	params := []*Parameter{}
+	action.IterateHeaders(func(name string, required bool, header *design.AttributeDefinition) error {
+		params = append(params, paramFor(api, header, name, "header", required))
+		return nil
+	})
+	return params
+}
+
+func paramFor(api *design.APIDefinition, at *design.AttributeDefinition, name, in string, required bool) *Parameter {
+	p := &Parameter{
+		In:          in,
+		Name:        name,
+		Description: at.Description,
+		Required:    required,
+		Schema:      genschema.AttributeSchema(api, at),
+		Extensions:  extensionsFromDefinition(at.Metadata),
+	}
+	// The description is carried by the parameter itself.
+	p.Schema.Description = ""
+	if at.Type.IsArray() && in == "query" {
+		explode := true
+		p.Explode = &explode
+	}
+	return p
+}
+
+// contentFromDefinition builds the response content for the given response definition.
+func contentFromDefinition(api *design.APIDefinition, r *design.ResponseDefinition) map[string]*MediaType {
+	if r.MediaType == "" {
+		return nil
+	}
+	mt, ok := api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]
+	if !ok {
+		return nil
+	}
+	view := r.ViewName
+	if view == "" {
+		view = design.DefaultView
+	}
+	schema := genschema.NewJSONSchema()
+	schema.Ref = genschema.MediaTypeRef(api, mt, view)
+	contentType := mt.ContentType
+	if contentType == "" {
+		contentType = mt.Identifier
+	}
+	return map[string]*MediaType{contentType: {Schema: schema}}
+}
+
+func responseFromDefinition(api *design.APIDefinition, r *design.ResponseDefinition) (*Response, error) {
+	headers, err := headersFromDefinition(api, r.Headers)
+	if err != nil {
+		return nil, err
+	}
+	desc := r.Description
+	if desc == "" {
+		desc = http.StatusText(r.Status)
+	}
+	return &Response{
+		Description: desc,
+		Content:     contentFromDefinition(api, r),
+		Headers:     headers,
+		Extensions:  extensionsFromDefinition(r.Metadata),
+	}, nil
+}
+
+func headersFromDefinition(api *design.APIDefinition, headers *design.AttributeDefinition) (map[string]*Header, error) {
+	if headers == nil {
+		return nil, nil
+	}
+	obj := headers.Type.ToObject()
+	if obj == nil {
+		return nil, fmt.Errorf("invalid headers definition, not an object")
+	}
+	res := make(map[string]*Header)
+	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		schema := genschema.AttributeSchema(api, at)
+		schema.Description = ""
+		res[n] = &Header{
+			Description: at.Description,
+			Required:    headers.IsRequired(n),
+			Schema:      schema,
+		}
+		return nil
+	})
+	return res, nil
+}
+
+// requestBodyFromDefinition builds the request body of the given action using the API consumed
+// media types.
+func requestBodyFromDefinition(api *design.APIDefinition, action *design.ActionDefinition) *RequestBody {
+	if action.Payload == nil {
+		return nil
+	}
+	var mimeTypes []string
+	if action.PayloadMultipart {
+		mimeTypes = []string{"multipart/form-data"}
+	} else {
+		for _, c := range api.Consumes {
+			mimeTypes = append(mimeTypes, c.MIMETypes...)
+		}
+	}
+	if len(mimeTypes) == 0 {
+		mimeTypes = []string{"application/json"}
+	}
+	content := make(map[string]*MediaType, len(mimeTypes))
+	for _, m := range mimeTypes {
+		content[m] = &MediaType{Schema: genschema.TypeSchema(api, action.Payload)}
+	}
+	return &RequestBody{
+		Description: action.Payload.Description,
+		Content:     content,
+		Required:    !action.PayloadOptional,
+	}
+}
+
+func buildPathFromFileServer(s *OpenAPI, api *design.APIDefinition, fs *design.FileServerDefinition) error {
+	wcs := design.ExtractWildcards(fs.RequestPath)
+	var param []*Parameter
+	if len(wcs) > 0 {
+		param = []*Parameter{{
+			In:          "path",
+			Name:        wcs[0],
+			Description: "Relative file path",
+			Required:    true,
+			Schema:      &genschema.JSONSchema{Type: genschema.JSONString},
+		}}
+	}
+
+	responses := map[string]*Response{
+		"200": {
+			Description: "File downloaded",
+			Content: map[string]*MediaType{
+				"application/octet-stream": {
+					Schema: &genschema.JSONSchema{Type: genschema.JSONString, Format: "binary"},
+				},
+			},
+		},
+	}
+	if len(wcs) > 0 {
+		errMedia := api.ErrorMedia()
+		schema := genschema.TypeSchema(api, errMedia)
+		responses["404"] = &Response{
+			Description: "File not found",
+			Content:     map[string]*MediaType{errMedia.Identifier: {Schema: schema}},
+		}
+	}
+
+	operation := &Operation{
+		Description:  fs.Description,
+		Summary:      summaryFromDefinition(fmt.Sprintf("Download %s", fs.FilePath), fs.Metadata),
+		ExternalDocs: docsFromDefinition(fs.Docs),
+		OperationID:  fmt.Sprintf("%s#%s", fs.Parent.Name, fs.RequestPath),
+		Parameters:   param,
+		Responses:    responses,
+	}
+
+	applySecurity(operation, fs.Security)
+
+	key := design.WildcardRegex.ReplaceAllStringFunc(
+		fs.RequestPath,
+		func(w string) string {
+			return fmt.Sprintf("/{%s}", w[2:])
+		},
+	)
+	if key == "" {
+		key = "/"
+	}
+	p := pathFor(s, key)
+	p.Get = operation
+	p.Extensions = extensionsFromDefinition(fs.Metadata)
+
+	return nil
+}
+
+func buildPathFromDefinition(s *OpenAPI, api *design.APIDefinition, route *design.RouteDefinition, basePath string) error {
+	action := route.Parent
+
+	tagNames := tagNamesFromDefinitions(action.Parent.Metadata, action.Metadata)
+	if len(tagNames) == 0 {
+		// By default tag with resource name
+		tagNames = []string{route.Parent.Parent.Name}
+	}
+	params, err := paramsFromDefinition(api, action.AllParams(), route.FullPath())
+	if err != nil {
+		return err
+	}
+
+	params = append(params, paramsFromHeaders(api, action)...)
+
+	responses := make(map[string]*Response, len(action.Responses))
+	for _, r := range action.Responses {
+		resp, err := responseFromDefinition(api, r)
+		if err != nil {
+			return err
+		}
+		if r.Standard {
+			if s.Components.Responses == nil {
+				s.Components.Responses = make(map[string]*Response)
+			}
+			if _, ok := s.Components.Responses[r.Name]; !ok {
+				s.Components.Responses[r.Name] = resp
+			}
+		}
+		// Reference the reusable response unless the action customizes it.
+		if c, ok := s.Components.Responses[r.Name]; ok && reflect.DeepEqual(c, resp) {
+			resp = &Response{Ref: responsesRef + r.Name}
+		}
+		responses[strconv.Itoa(r.Status)] = resp
+	}
+	if len(responses) == 0 {
+		responses["default"] = &Response{Description: "Unspecified response"}
+	}
+
+	operationID := fmt.Sprintf("%s#%s", action.Parent.Name, action.Name)
+	index := 0
+	for i, rt := range action.Routes {
+		if rt == route {
+			index = i
+			break
+		}
+	}
+	if index > 0 {
+		operationID = fmt.Sprintf("%s#%d", operationID, index)
+	}
+
+	operation := &Operation{
+		Tags:         tagNames,
+		Description:  action.Description,
+		Summary:      summaryFromDefinition(action.Name+" "+action.Parent.Name, action.Metadata),
+		ExternalDocs: docsFromDefinition(action.Docs),
+		OperationID:  operationID,
+		Parameters:   params,
+		RequestBody:  requestBodyFromDefinition(api, action),
+		Responses:    responses,
+		Extensions:   extensionsFromDefinition(route.Metadata),
+	}
+
+	if len(action.Schemes) > 0 && api.Host != "" {
+		operation.Servers = make([]*Server, len(action.Schemes))
+		for i, scheme := range action.Schemes {
+			operation.Servers[i] = &Server{
+				URL: fmt.Sprintf("%s://%s%s", scheme, api.Host, strings.TrimSuffix(basePath, "/")),
+			}
+		}
+	}
+
+	applySecurity(operation, action.Security)
+
+	key := design.WildcardRegex.ReplaceAllStringFunc(
+		route.FullPath(),
+		func(w string) string {
+			return fmt.Sprintf("/{%s}", w[2:])
+		},
+	)
+	if basePath != "" && basePath != "/" {
+		key = strings.TrimPrefix(key, basePath)
+	}
+	if key == "" {
+		key = "/"
+	}
+	p := pathFor(s, key)
+	switch route.Verb {
+	case "GET":
+		p.Get = operation
+	case "PUT":
+		p.Put = operation
+	case "POST":
+		p.Post = operation
+	case "DELETE":
+		p.Delete = operation
+	case "OPTIONS":
+		p.Options = operation
+	case "HEAD":
+		p.Head = operation
+	case "PATCH":
+		p.Patch = operation
+	case "TRACE":
+		p.Trace = operation
+	}
+	p.Extensions = extensionsFromDefinition(route.Parent.Metadata)
+	return nil
+}
+
+// pathFor returns the path item with the given key creating it if needed.
+func pathFor(s *OpenAPI, key string) *Path {
+	if path, ok := s.Paths[key]; ok {
+		if p, ok := path.(*Path); ok {
+			return p
+		}
+	}
+	p := new(Path)
+	s.Paths[key] = p
+	return p
+}
+
+func applySecurity(operation *Operation, security *design.SecurityDefinition) {
+	if security != nil && security.Scheme.Kind != design.NoSecurityKind {
+		if security.Scheme.Kind == design.JWTSecurityKind && len(security.Scopes) > 0 {
+			if operation.Description != "" {
+				operation.Description += "\n\n"
+			}
+			operation.Description += fmt.Sprintf("Required security scopes:\n%s", scopesList(security.Scopes))
+		}
+		scopes := security.Scopes
+		if scopes == nil || security.Scheme.Kind != design.OAuth2SecurityKind {
+			// OpenAPI 3.0 only allows scopes for OAuth2 schemes.
+			scopes = make([]string, 0)
+		}
+		operation.Security = []map[string][]string{{security.Scheme.SchemeName: scopes}}
+	}
+}
+
+func scopesList(scopes []string) string {
+	sort.Strings(scopes)
+
+	var lines []string
+	for _, scope := range scopes {
+		lines = append(lines, fmt.Sprintf("  * `%s`", scope))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func docsFromDefinition(docs *design.DocsDefinition) *ExternalDocs {
+	if docs == nil {
+		return nil
+	}
+	return &ExternalDocs{
+		Description: docs.Description,
+		URL:         docs.URL,
+	}
+}
+
+// rewriteRefs makes the references produced by the JSON schema generator point to the document
+// components and removes the hyper-schema fields that OpenAPI does not support. It also describes
+// files as binary strings.
+func rewriteRefs(s *genschema.JSONSchema) {
+	if s == nil {
+		return
+	}
+	s.Media = nil
+	s.Links = nil
+	if s.Type == genschema.JSONFile {
+		s.Type = genschema.JSONString
+		s.Format = "binary"
+	}
+	if strings.HasPrefix(s.Ref, "#/definitions/") {
+		s.Ref = schemasRef + strings.TrimPrefix(s.Ref, "#/definitions/")
+	}
+	rewriteRefs(s.Items)
+	for _, p := range s.Properties {
+		rewriteRefs(p)
+	}
+	for _, d := range s.Definitions {
+		rewriteRefs(d)
+	}
+	for _, a := range s.AnyOf {
+		rewriteRefs(a)
+	}
+	for _, o := range s.OneOf {
+		rewriteRefs(o)
+	}
+	if s.Discriminator != nil {
+		for n, ref := range s.Discriminator.Mapping {
+			if strings.HasPrefix(ref, "#/definitions/") {
+				s.Discriminator.Mapping[n] = schemasRef + strings.TrimPrefix(ref, "#/definitions/")
+			}
+		}
+	}
+}
+
+// rewritePathRefs rewrites the schema references of all the operations of the given path.
+func rewritePathRefs(p *Path) {
+	for _, o := range []*Operation{p.Get, p.Put, p.Post, p.Delete, p.Options, p.Head, p.Patch, p.Trace} {
+		if o == nil {
+			continue
+		}
+		for _, param := range o.Parameters {
+			rewriteRefs(param.Schema)
+		}
+		if o.RequestBody != nil {
+			for _, c := range o.RequestBody.Content {
+				rewriteRefs(c.Schema)
+			}
+		}
+		for _, r := range o.Responses {
+			rewriteResponseRefs(r)
+		}
+	}
+}
+
+// rewriteResponseRefs rewrites the schema references of the given response.
+func rewriteResponseRefs(r *Response) {
+	for _, c := range r.Content {
+		rewriteRefs(c.Schema)
+	}
+	for _, h := range r.Headers {
+		rewriteRefs(h.Schema)
+	}
+}
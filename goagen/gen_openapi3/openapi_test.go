@@ -0,0 +1,231 @@
+package genopenapi3_test
+
+import (
+	"encoding/json"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_openapi3"
+	"github.com/goadesign/goa/goagen/gen_schema"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var spec *genopenapi3.OpenAPI
+	var newErr error
+
+	BeforeEach(func() {
+		spec = nil
+		newErr = nil
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		spec, newErr = genopenapi3.New(Design)
+	})
+
+	Context("with a valid API definition", func() {
+		const (
+			title       = "title"
+			description = "description"
+			host        = "goa.design"
+			basePath    = "/base"
+			tag         = "tag"
+		)
+
+		BeforeEach(func() {
+			API("test", func() {
+				Title(title)
+				Description(description)
+				Host(host)
+				Scheme("http", "https")
+				BasePath(basePath)
+				Version("2.0")
+				Metadata("swagger:tag:" + tag)
+				Metadata("swagger:tag:"+tag+":desc", "Tag desc.")
+				Metadata("swagger:extension:x-api", `{"foo":"bar"}`)
+			})
+		})
+
+		It("sets the info", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(spec.OpenAPI).Should(Equal("3.0.3"))
+			Ω(spec.Info.Title).Should(Equal(title))
+			Ω(spec.Info.Description).Should(Equal(description))
+			Ω(spec.Info.Version).Should(Equal("2.0"))
+			Ω(spec.Info.Extensions).Should(HaveKeyWithValue("x-api", map[string]interface{}{"foo": "bar"}))
+		})
+
+		It("sets the servers", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(spec.Servers).Should(HaveLen(2))
+			Ω(spec.Servers[0].URL).Should(Equal("http://goa.design/base"))
+			Ω(spec.Servers[1].URL).Should(Equal("https://goa.design/base"))
+		})
+
+		It("sets the tags", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(spec.Tags).Should(HaveLen(1))
+			Ω(spec.Tags[0].Name).Should(Equal(tag))
+			Ω(spec.Tags[0].Description).Should(Equal("Tag desc."))
+		})
+
+		It("serializes the extensions", func() {
+			b, err := json.Marshal(spec)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(ContainSubstring(`"x-api":{"foo":"bar"}`))
+		})
+
+		Context("with resources", func() {
+			BeforeEach(func() {
+				Bottle := MediaType("application/vnd.goa.example.bottle", func() {
+					Attributes(func() {
+						Attribute("id", Integer)
+						Attribute("name", String)
+					})
+					View("default", func() {
+						Attribute("id")
+						Attribute("name")
+					})
+				})
+				BottlePayload := Type("BottlePayload", func() {
+					Attribute("name", String)
+					Required("name")
+				})
+				Resource("bottle", func() {
+					Action("show", func() {
+						Routing(GET("/:id"))
+						Params(func() {
+							Param("id", Integer, "Bottle ID")
+						})
+						Headers(func() {
+							Header("X-Request-Id")
+						})
+						Response(OK, Bottle)
+						Response(NotFound)
+					})
+					Action("create", func() {
+						Routing(POST(""))
+						Payload(BottlePayload)
+						Response(Created)
+					})
+					Action("hidden", func() {
+						Routing(GET("/hidden"))
+						Metadata("swagger:generate", "false")
+					})
+				})
+			})
+
+			It("builds the paths", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				Ω(spec.Paths).Should(HaveLen(2))
+				Ω(spec.Paths).Should(HaveKey("/{id}"))
+				Ω(spec.Paths).Should(HaveKey("/"))
+				Ω(spec.Paths).ShouldNot(HaveKey("/hidden"))
+			})
+
+			It("builds the parameters", func() {
+				show := spec.Paths["/{id}"].(*genopenapi3.Path).Get
+				Ω(show).ShouldNot(BeNil())
+				Ω(show.OperationID).Should(Equal("bottle#show"))
+				Ω(show.Parameters).Should(HaveLen(2))
+				Ω(show.Parameters[0].Name).Should(Equal("id"))
+				Ω(show.Parameters[0].In).Should(Equal("path"))
+				Ω(show.Parameters[0].Required).Should(BeTrue())
+				Ω(show.Parameters[0].Schema.Type).Should(BeEquivalentTo(genschema.JSONInteger))
+				Ω(show.Parameters[1].Name).Should(Equal("X-Request-Id"))
+				Ω(show.Parameters[1].In).Should(Equal("header"))
+			})
+
+			It("builds the responses", func() {
+				show := spec.Paths["/{id}"].(*genopenapi3.Path).Get
+				Ω(show.Responses).Should(HaveLen(2))
+				Ω(show.Responses).Should(HaveKey("200"))
+				Ω(show.Responses).Should(HaveKey("404"))
+				ok := show.Responses["200"]
+				Ω(ok.Content).Should(HaveKey("application/vnd.goa.example.bottle"))
+				Ω(ok.Content["application/vnd.goa.example.bottle"].Schema.Ref).Should(Equal("#/components/schemas/GoaExampleBottle"))
+				Ω(spec.Components.Schemas).Should(HaveKey("GoaExampleBottle"))
+			})
+
+			It("references the reusable responses", func() {
+				show := spec.Paths["/{id}"].(*genopenapi3.Path).Get
+				Ω(show.Responses["404"].Ref).Should(Equal("#/components/responses/NotFound"))
+				Ω(spec.Components.Responses).Should(HaveKey("NotFound"))
+				Ω(spec.Components.Responses["NotFound"].Description).ShouldNot(BeEmpty())
+				create := spec.Paths["/"].(*genopenapi3.Path).Post
+				Ω(create.Responses["201"].Ref).Should(Equal("#/components/responses/Created"))
+			})
+
+			It("builds the request body", func() {
+				create := spec.Paths["/"].(*genopenapi3.Path).Post
+				Ω(create).ShouldNot(BeNil())
+				Ω(create.RequestBody).ShouldNot(BeNil())
+				Ω(create.RequestBody.Required).Should(BeTrue())
+				Ω(create.RequestBody.Content).Should(HaveKey("application/json"))
+				Ω(create.RequestBody.Content["application/json"].Schema.Ref).Should(Equal("#/components/schemas/BottlePayload"))
+				Ω(spec.Components.Schemas).Should(HaveKey("BottlePayload"))
+			})
+
+			It("does not use swagger references", func() {
+				b, err := json.Marshal(spec)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(b)).ShouldNot(ContainSubstring("#/definitions/"))
+			})
+		})
+
+		Context("with security schemes", func() {
+			BeforeEach(func() {
+				basic := BasicAuthSecurity("basic")
+				JWTSecurity("jwt", func() {
+					Header("Authorization")
+					Scope("read", "Read access")
+				})
+				oauth2 := OAuth2Security("oauth2", func() {
+					AccessCodeFlow("http://example.com/auth", "http://example.com/token")
+					Scope("write", "Write access")
+				})
+				Resource("res", func() {
+					Security(basic)
+					Action("list", func() {
+						Routing(GET(""))
+						Response(OK)
+					})
+					Action("update", func() {
+						Routing(PUT(""))
+						Security(oauth2, func() {
+							Scope("write")
+						})
+						Response(NoContent)
+					})
+				})
+			})
+
+			It("builds the security schemes", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				schemes := spec.Components.SecuritySchemes
+				Ω(schemes).Should(HaveLen(3))
+				Ω(schemes["basic"].Type).Should(Equal("http"))
+				Ω(schemes["basic"].Scheme).Should(Equal("basic"))
+				Ω(schemes["jwt"].Type).Should(Equal("http"))
+				Ω(schemes["jwt"].Scheme).Should(Equal("bearer"))
+				Ω(schemes["jwt"].BearerFormat).Should(Equal("JWT"))
+				Ω(schemes["oauth2"].Type).Should(Equal("oauth2"))
+				Ω(schemes["oauth2"].Flows.AuthorizationCode).ShouldNot(BeNil())
+				Ω(schemes["oauth2"].Flows.AuthorizationCode.TokenURL).Should(Equal("http://example.com/token"))
+				Ω(schemes["oauth2"].Flows.AuthorizationCode.Scopes).Should(HaveKey("write"))
+			})
+
+			It("applies the security requirements", func() {
+				p := spec.Paths["/"].(*genopenapi3.Path)
+				Ω(p.Get.Security).Should(Equal([]map[string][]string{{"basic": {}}}))
+				Ω(p.Put.Security).Should(Equal([]map[string][]string{{"oauth2": {"write"}}}))
+			})
+		})
+	})
+})
@@ -0,0 +1,213 @@
+package genproto
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// adapters returns the Go functions that convert between the app type represented by the given
+// message and the message Go type generated by protoc-gen-go. appPkg is the name of the package
+// generated by "goagen app".
+func adapters(m *protoMessage, appPkg string) string {
+	var (
+		b       bytes.Buffer
+		ref     = appRef(m.Type, appPkg)
+		isArray = m.Type.Type.IsArray()
+	)
+
+	fmt.Fprintf(&b, "// %sToProto builds a %s message from the given app type.\n", m.Name, m.Name)
+	fmt.Fprintf(&b, "func %sToProto(v %s) (m *%s, err error) {\n", m.Name, ref, m.Name)
+	if !isArray {
+		// The conversion of collections checks for nil already.
+		b.WriteString("\tif v == nil {\n\t\treturn nil, nil\n\t}\n")
+	}
+	fmt.Fprintf(&b, "\tm = &%s{}\n", m.Name)
+	for _, f := range m.Fields {
+		src := "v." + codegen.GoifyAtt(f.Att, f.AttName, true)
+		if isArray {
+			src = "v"
+		}
+		b.WriteString(codegen.Indent(toProto(f, src, "m."+f.GoName), "\t"))
+	}
+	b.WriteString("\treturn m, nil\n}\n\n")
+
+	fmt.Fprintf(&b, "// %sFromProto builds the app type from the given %s message.\n", m.Name, m.Name)
+	fmt.Fprintf(&b, "func %sFromProto(m *%s) (v %s, err error) {\n", m.Name, m.Name, ref)
+	b.WriteString("\tif m == nil {\n\t\treturn nil, nil\n\t}\n")
+	if !isArray {
+		fmt.Fprintf(&b, "\tv = &%s{}\n", ref[1:])
+	}
+	for _, f := range m.Fields {
+		dst := "v." + codegen.GoifyAtt(f.Att, f.AttName, true)
+		if isArray {
+			dst = "v"
+		}
+		b.WriteString(codegen.Indent(fromProto(f, "m."+f.GoName, dst, appPkg), "\t"))
+	}
+	b.WriteString("\treturn v, nil\n}\n")
+
+	return b.String()
+}
+
+// appRef returns the Go type reference of the given user type in the app package.
+func appRef(ut *design.UserTypeDefinition, appPkg string) string {
+	ref := appPkg + "." + codegen.GoTypeName(ut, nil, 0, false)
+	if ut.Type.IsObject() {
+		ref = "*" + ref
+	}
+	return ref
+}
+
+// toProto returns the Go statements that convert the app value src to the field value dst.
+func toProto(f *protoField, src, dst string) string {
+	switch {
+	case f.JSON:
+		return fmt.Sprintf("if %s, err = json.Marshal(%s); err != nil {\n\treturn nil, err\n}\n", dst, src)
+	case f.Key != nil:
+		return fmt.Sprintf("if %s != nil {\n\t%s = make(map[%s]%s, len(%s))\n\tfor k, e := range %s {\n%s\t}\n}\n",
+			src, dst, mapKeyType(f.Key.Type), protoGoType(f.Elem.Type), src, src,
+			codegen.Indent(valueToProto(f.Elem.Type, "e", dst+"["+keyToProto(f.Key.Type, "k")+"]", false), "\t\t"))
+	case f.Elem != nil:
+		return fmt.Sprintf("if %s != nil {\n\t%s = make([]%s, len(%s))\n\tfor i, e := range %s {\n%s\t}\n}\n",
+			src, dst, protoGoType(f.Elem.Type), src, src,
+			codegen.Indent(valueToProto(f.Elem.Type, "e", dst+"[i]", false), "\t\t"))
+	}
+	return valueToProto(f.Att.Type, src, dst, f.Pointer)
+}
+
+// fromProto returns the Go statements that convert the field value src to the app value dst.
+func fromProto(f *protoField, src, dst, appPkg string) string {
+	switch {
+	case f.JSON:
+		return fmt.Sprintf("if len(%s) > 0 {\n\tif err = json.Unmarshal(%s, &%s); err != nil {\n\t\treturn nil, err\n\t}\n}\n", src, src, dst)
+	case f.Key != nil:
+		return fmt.Sprintf("if %s != nil {\n\t%s = make(map[%s]%s, len(%s))\n\tfor k, e := range %s {\n%s\t}\n}\n",
+			src, dst, codegen.GoNativeType(f.Key.Type), appGoType(f.Elem.Type, appPkg), src, src,
+			codegen.Indent(valueFromProto(f.Elem.Type, "e", dst+"["+keyFromProto(f.Key.Type, "k")+"]", false), "\t\t"))
+	case f.Elem != nil:
+		return fmt.Sprintf("if %s != nil {\n\t%s = make([]%s, len(%s))\n\tfor i, e := range %s {\n%s\t}\n}\n",
+			src, dst, appGoType(f.Elem.Type, appPkg), src, src,
+			codegen.Indent(valueFromProto(f.Elem.Type, "e", dst+"[i]", false), "\t\t"))
+	}
+	return valueFromProto(f.Att.Type, src, dst, f.Pointer)
+}
+
+// valueToProto returns the Go statements that convert the app scalar or user type value src to
+// the message value dst. pointer is true if both src and dst are pointers to scalars.
+func valueToProto(t design.DataType, src, dst string, pointer bool) string {
+	if name := messageName(t); name != "" {
+		return fmt.Sprintf("if %s, err = %sToProto(%s); err != nil {\n\treturn nil, err\n}\n", dst, name, src)
+	}
+	conv := func(v string) string {
+		switch t.Kind() {
+		case design.IntegerKind:
+			return "int64(" + v + ")"
+		case design.DateTimeKind:
+			return v + ".Format(time.RFC3339Nano)"
+		case design.UUIDKind:
+			return v + ".String()"
+		}
+		return v
+	}
+	if !pointer {
+		return fmt.Sprintf("%s = %s\n", dst, conv(src))
+	}
+	if conv(src) == src {
+		return fmt.Sprintf("%s = %s\n", dst, src)
+	}
+	// Methods of time.Time and uuid.UUID can be called on pointers directly.
+	v := src
+	if t.Kind() == design.IntegerKind {
+		v = "*" + src
+	}
+	return fmt.Sprintf("if %s != nil {\n\ttmp := %s\n\t%s = &tmp\n}\n", src, conv(v), dst)
+}
+
+// valueFromProto returns the Go statements that convert the message scalar or message value src
+// to the app value dst. pointer is true if both src and dst are pointers to scalars.
+func valueFromProto(t design.DataType, src, dst string, pointer bool) string {
+	if name := messageName(t); name != "" {
+		return fmt.Sprintf("if %s, err = %sFromProto(%s); err != nil {\n\treturn nil, err\n}\n", dst, name, src)
+	}
+	var parse func(string) string
+	switch t.Kind() {
+	case design.DateTimeKind:
+		parse = func(v string) string { return "time.Parse(time.RFC3339Nano, " + v + ")" }
+	case design.UUIDKind:
+		parse = func(v string) string { return "uuid.FromString(" + v + ")" }
+	}
+	if parse != nil {
+		if !pointer {
+			return fmt.Sprintf("if %s, err = %s; err != nil {\n\treturn nil, err\n}\n", dst, parse(src))
+		}
+		return fmt.Sprintf("if %s != nil {\n\ttmp, err := %s\n\tif err != nil {\n\t\treturn nil, err\n\t}\n\t%s = &tmp\n}\n",
+			src, parse("*"+src), dst)
+	}
+	if t.Kind() != design.IntegerKind {
+		return fmt.Sprintf("%s = %s\n", dst, src)
+	}
+	if !pointer {
+		return fmt.Sprintf("%s = int(%s)\n", dst, src)
+	}
+	return fmt.Sprintf("if %s != nil {\n\ttmp := int(*%s)\n\t%s = &tmp\n}\n", src, src, dst)
+}
+
+// keyToProto returns the expression that converts the app map key k to the message map key.
+func keyToProto(t design.DataType, k string) string {
+	if t.Kind() == design.IntegerKind {
+		return "int64(" + k + ")"
+	}
+	return k
+}
+
+// keyFromProto returns the expression that converts the message map key k to the app map key.
+func keyFromProto(t design.DataType, k string) string {
+	if t.Kind() == design.IntegerKind {
+		return "int(" + k + ")"
+	}
+	return k
+}
+
+// messageName returns the name of the message that represents values of type t, an empty string
+// if t is not represented with a message.
+func messageName(t design.DataType) string {
+	switch actual := t.(type) {
+	case *design.MediaTypeDefinition:
+		return codegen.GoTypeName(actual, nil, 0, false)
+	case *design.UserTypeDefinition:
+		return codegen.GoTypeName(actual, nil, 0, false)
+	}
+	return ""
+}
+
+// protoGoType returns the Go type generated by protoc-gen-go for the repeated field elements and
+// map values of type t.
+func protoGoType(t design.DataType) string {
+	if name := messageName(t); name != "" {
+		return "*" + name
+	}
+	switch t.Kind() {
+	case design.BooleanKind:
+		return "bool"
+	case design.IntegerKind:
+		return "int64"
+	case design.NumberKind:
+		return "float64"
+	}
+	return "string"
+}
+
+// appGoType returns the app Go type of the array elements and map values of type t. appPkg is
+// the name of the app package.
+func appGoType(t design.DataType, appPkg string) string {
+	switch actual := t.(type) {
+	case *design.MediaTypeDefinition:
+		return appRef(actual.UserTypeDefinition, appPkg)
+	case *design.UserTypeDefinition:
+		return appRef(actual, appPkg)
+	}
+	return codegen.GoNativeType(t)
+}
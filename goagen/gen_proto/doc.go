@@ -0,0 +1,17 @@
+/*
+Package genproto provides a goa generator for Protocol Buffers definitions and Go adapters.
+The generated .proto file declares a service per resource with one RPC per action and a message
+per user type and per view of each media type. The request message of an RPC holds the action
+parameters and payload, its response is the media type of the first success response or the
+events streamed by the action.
+
+Field numbers default to the position of the attribute in the alphabetical order of the attribute
+names, use the "proto:field" metadata to keep numbers stable as the design evolves. Values that
+cannot be described with protocol buffer types (Any, inline objects, unions, nested arrays) are
+JSON encoded in bytes fields.
+
+The generator also produces Go functions converting between the types of the package generated
+with "goagen app" and the types generated by protoc-gen-go from the .proto file. The functions
+are generated in the same package so that both files may be compiled together.
+*/
+package genproto
@@ -0,0 +1,399 @@
+package genproto
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+	"github.com/goadesign/goa/version"
+)
+
+// emptyMessage is the name of the well-known message returned by RPCs that have no response body.
+const emptyMessage = "google.protobuf.Empty"
+
+// NewGenerator returns an initialized instance of a protocol buffers generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the protocol buffers definitions and adapters generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Destination directory
+	AppPkg   string                // Import path of the app package, may be relative to OutDir
+	Pkg      string                // Name of the generated Go package
+	genfiles []string              // Generated files
+}
+
+type (
+	// protoService is the data used to render the service of a resource.
+	protoService struct {
+		Name        string
+		Description string
+		RPCs        []*protoRPC
+	}
+
+	// protoRPC is the data used to render the RPC of an action.
+	protoRPC struct {
+		Name        string
+		Description string
+		Request     string
+		Response    string
+		Stream      bool
+	}
+)
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, appPkg, pkg, ver string
+
+	set := flag.NewFlagSet("proto", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.String("design", "", "")
+	set.StringVar(&appPkg, "app-pkg", "app", "")
+	set.StringVar(&pkg, "pkg", "pb", "")
+	set.StringVar(&ver, "version", "", "")
+	set.Parse(os.Args[1:])
+
+	// First check compatibility
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	// Now proceed
+	g := &Generator{OutDir: outDir, AppPkg: appPkg, Pkg: pkg, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the protocol buffers definitions file and the Go adapters.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.AppPkg == "" {
+		g.AppPkg = "app"
+	}
+	if g.Pkg == "" {
+		g.Pkg = "pb"
+	}
+
+	appImp, err := g.appImport()
+	if err != nil {
+		return nil, err
+	}
+
+	b := newBuilder()
+	services, err := g.services(b)
+	if err != nil {
+		return nil, err
+	}
+	if err = g.types(b); err != nil {
+		return nil, err
+	}
+	messages := b.sorted()
+
+	pkgDir := filepath.Join(g.OutDir, g.Pkg)
+	if err = os.RemoveAll(pkgDir); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(pkgDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, pkgDir)
+
+	protoFile := filepath.Join(pkgDir, protoPackage(g.API.Name)+".proto")
+	if err = g.generateProto(protoFile, services, messages); err != nil {
+		return
+	}
+	if err = g.generateAdapters(filepath.Join(pkgDir, "adapters.go"), appImp, messages); err != nil {
+		return
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// appImport returns the import path of the app package. AppPkg may be a complete import path or
+// a path relative to the output directory.
+func (g *Generator) appImport() (string, error) {
+	if _, err := codegen.PackageSourcePath(g.AppPkg); err == nil {
+		return g.AppPkg, nil
+	}
+	imp, err := codegen.PackagePath(g.OutDir)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(filepath.ToSlash(imp), g.AppPkg), nil
+}
+
+// goPackage returns the value of the go_package option of the generated file.
+func (g *Generator) goPackage() string {
+	imp, err := codegen.PackagePath(filepath.Join(g.OutDir, g.Pkg))
+	if err != nil {
+		return g.Pkg
+	}
+	return filepath.ToSlash(imp) + ";" + g.Pkg
+}
+
+// services computes the service of each resource and records the request and response messages
+// of their RPCs.
+func (g *Generator) services(b *builder) ([]*protoService, error) {
+	var services []*protoService
+	err := g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		s := &protoService{
+			Name:        codegen.Goify(res.Name, true) + "Service",
+			Description: res.Description,
+		}
+		err := res.IterateActions(func(a *design.ActionDefinition) error {
+			rpc, err := g.rpc(b, a)
+			if err != nil {
+				return fmt.Errorf("%s: %s", a.Context(), err)
+			}
+			s.RPCs = append(s.RPCs, rpc)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		services = append(services, s)
+		return nil
+	})
+	return services, err
+}
+
+// rpc computes the RPC of the given action. The request message is made of the action parameters
+// and of a "payload" field holding the request payload if any. The response message is the media
+// type of the first success response rendered with the response view, the RPC streams the events
+// if the action streams server-sent events.
+func (g *Generator) rpc(b *builder, a *design.ActionDefinition) (*protoRPC, error) {
+	prefix := codegen.Goify(a.Name, true) + codegen.Goify(a.Parent.Name, true)
+	rpc := &protoRPC{
+		Name:        codegen.Goify(a.Name, true),
+		Description: a.Description,
+		Request:     prefix + "Request",
+	}
+
+	// Request
+	req := &design.AttributeDefinition{Type: design.Object{}}
+	if params := a.AllParams(); params != nil {
+		for n, p := range params.Type.ToObject() {
+			req.Type.ToObject()[n] = p
+		}
+		req.Validation = params.Validation
+	}
+	if a.Payload != nil {
+		req.Type.ToObject()["payload"] = &design.AttributeDefinition{Type: a.Payload, Description: a.Payload.Description}
+	}
+	desc := fmt.Sprintf("%s is the request of the %s RPC of the %s service.", rpc.Request, rpc.Name, codegen.Goify(a.Parent.Name, true)+"Service")
+	if err := b.addMessage(rpc.Request, desc, req); err != nil {
+		return nil, err
+	}
+
+	// Server-sent events
+	if a.EventStream != nil {
+		rpc.Stream = true
+		name, err := b.protoType(a.EventStream.Type)
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			name = prefix + "Event"
+			ev := a.EventStream
+			if !ev.Type.IsObject() {
+				ev = &design.AttributeDefinition{Type: design.Object{"data": a.EventStream}}
+			}
+			if err := b.addMessage(name, a.EventStream.Description, ev); err != nil {
+				return nil, err
+			}
+		}
+		rpc.Response = name
+		return rpc, nil
+	}
+
+	// Response
+	rpc.Response = emptyMessage
+	var ok *design.ResponseDefinition
+	for _, r := range a.Responses {
+		if r.Status < 200 || r.Status > 299 || g.API.MediaTypeWithIdentifier(r.MediaType) == nil {
+			continue
+		}
+		if ok == nil || r.Status < ok.Status {
+			ok = r
+		}
+	}
+	if ok == nil {
+		return rpc, nil
+	}
+	view := ok.ViewName
+	if view == "" {
+		view = design.DefaultView
+	}
+	p, _, err := g.API.MediaTypeWithIdentifier(ok.MediaType).Project(view)
+	if err != nil {
+		return nil, err
+	}
+	name, err := b.protoType(p)
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		rpc.Response = name
+	}
+	return rpc, nil
+}
+
+// types records the messages of all the user types, of the projections of all the media types and
+// of the types they refer to.
+func (g *Generator) types(b *builder) error {
+	err := g.API.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() {
+			return nil
+		}
+		return mt.IterateViews(func(view *design.ViewDefinition) error {
+			p, links, err := mt.Project(view.Name)
+			if err != nil {
+				return err
+			}
+			if err := b.addType(p.UserTypeDefinition); err != nil {
+				return err
+			}
+			if links != nil {
+				return b.addType(links)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return g.API.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		return b.addType(ut)
+	})
+}
+
+func (g *Generator) generateProto(protoFile string, services []*protoService, messages []*protoMessage) error {
+	file, err := codegen.SourceFileFor(protoFile)
+	if err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, protoFile)
+
+	empty := false
+	for _, s := range services {
+		for _, rpc := range s.RPCs {
+			empty = empty || rpc.Response == emptyMessage
+		}
+	}
+	data := map[string]interface{}{
+		"API":         g.API,
+		"ToolVersion": version.String(),
+		"Package":     protoPackage(g.API.Name),
+		"GoPackage":   g.goPackage(),
+		"Empty":       empty,
+		"Services":    services,
+		"Messages":    messages,
+	}
+	return file.ExecuteTemplate("proto", protoT, template.FuncMap{"protoDoc": protoDoc}, data)
+}
+
+func (g *Generator) generateAdapters(adaptersFile, appImp string, messages []*protoMessage) error {
+	file, err := codegen.SourceFileFor(adaptersFile)
+	if err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, adaptersFile)
+
+	title := fmt.Sprintf("%s: Protocol Buffers Adapters", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("time"),
+		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
+		codegen.SimpleImport(appImp),
+	}
+	if err = file.WriteHeader(title, g.Pkg, imports); err != nil {
+		return err
+	}
+	appPkg := path.Base(appImp)
+	for _, m := range messages {
+		if m.Type == nil {
+			continue
+		}
+		if _, err = file.Write([]byte(adapters(m, appPkg) + "\n")); err != nil {
+			return err
+		}
+	}
+	return file.FormatCode()
+}
+
+// protoDoc renders the given description as a protocol buffers comment indented with the given
+// prefix.
+func protoDoc(desc, indent string) string {
+	desc = strings.TrimSpace(desc)
+	if desc == "" {
+		return ""
+	}
+	var b bytes.Buffer
+	for _, l := range strings.Split(desc, "\n") {
+		b.WriteString(strings.TrimRight(indent+"// "+l, " ") + "\n")
+	}
+	return b.String()
+}
+
+const protoT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .API.Name }} Protocol Buffers definitions
+//
+// Command:
+{{ comment commandLine }}
+
+syntax = "proto3";
+
+package {{ .Package }};
+{{ if .Empty }}
+import "google/protobuf/empty.proto";
+{{ end }}
+option go_package = "{{ .GoPackage }}";
+{{ range .Services }}
+{{ protoDoc .Description "" }}service {{ .Name }} {
+{{- range .RPCs }}
+{{ protoDoc .Description "  " }}  rpc {{ .Name }}({{ .Request }}) returns ({{ if .Stream }}stream {{ end }}{{ .Response }});
+{{- end }}
+}
+{{ end }}{{ range .Messages }}
+{{ protoDoc .Description "" }}message {{ .Name }} {
+{{- range .Fields }}
+{{ protoDoc .Description "  " }}  {{ if .Label }}{{ .Label }} {{ end }}{{ .Type }} {{ .Name }} = {{ .Number }};
+{{- end }}
+}
+{{ end }}`
@@ -0,0 +1,328 @@
+package genproto_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_proto"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var files []string
+	var genErr error
+	var workspace *codegen.Workspace
+	var testPkg *codegen.Package
+	var proto, adapters string
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		testPkg, err = workspace.NewPackage("prototest")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + testPkg.Abs(), "--design=foo", "--version=" + version.String()}
+		dslengine.Reset()
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genproto.Generate()
+		proto, adapters = "", ""
+		if genErr == nil {
+			b, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "pb", "test_api.proto"))
+			Ω(err).ShouldNot(HaveOccurred())
+			proto = string(b)
+			b, err = ioutil.ReadFile(filepath.Join(testPkg.Abs(), "pb", "adapters.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			adapters = string(b)
+		}
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with a dummy API", func() {
+		BeforeEach(func() {
+			API("test api", func() {
+				Title("dummy API with no resource")
+			})
+		})
+
+		It("generates an empty definitions file", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(files).Should(HaveLen(3))
+			Ω(proto).Should(ContainSubstring(`syntax = "proto3";`))
+			Ω(proto).Should(ContainSubstring("package test_api;"))
+			Ω(proto).Should(ContainSubstring(`option go_package = "prototest/pb;pb";`))
+			Ω(proto).ShouldNot(ContainSubstring("service "))
+			Ω(adapters).Should(ContainSubstring("package pb"))
+		})
+	})
+
+	Context("with resources and types", func() {
+		BeforeEach(func() {
+			API("test api", func() {})
+			bottle := MediaType("application/vnd.bottle", func() {
+				Description("A bottle of wine")
+				Attributes(func() {
+					Attribute("id", Integer, "ID of bottle")
+					Attribute("name", String, func() {
+						Metadata("proto:field", "1")
+					})
+					Attribute("vintage", Integer)
+					Attribute("created_at", DateTime)
+					Attribute("tags", ArrayOf(String))
+					Attribute("meta", HashOf(String, Any))
+					Required("id", "name")
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+					Attribute("vintage")
+					Attribute("created_at")
+					Attribute("tags")
+					Attribute("meta")
+				})
+				View("tiny", func() {
+					Attribute("id")
+				})
+			})
+			Resource("bottle", func() {
+				Description("Bottle resource")
+				Action("show", func() {
+					Description("Show a bottle")
+					Routing(GET("/bottles/:id"))
+					Params(func() {
+						Param("id", Integer)
+					})
+					Response(OK, bottle)
+					Response(NotFound)
+				})
+				Action("list", func() {
+					Routing(GET("/bottles"))
+					Response(OK, func() {
+						Media(CollectionOf(bottle), "tiny")
+					})
+				})
+				Action("create", func() {
+					Routing(POST("/bottles"))
+					Payload(func() {
+						Member("name", String)
+						Member("x-y", Integer)
+						Required("name")
+					})
+					Response(NoContent)
+				})
+				Action("watch", func() {
+					Routing(GET("/bottles/:id/watch"))
+					ServerSentEvents(func() {
+						Attribute("at", DateTime)
+					})
+				})
+			})
+		})
+
+		It("generates a service per resource", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(proto).Should(ContainSubstring(`import "google/protobuf/empty.proto";`))
+			Ω(proto).Should(ContainSubstring(service))
+		})
+
+		It("generates the messages", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(proto).Should(ContainSubstring(bottleMessage))
+			Ω(proto).Should(ContainSubstring(collectionMessage))
+			Ω(proto).Should(ContainSubstring(createMessage))
+			Ω(proto).Should(ContainSubstring(eventMessage))
+		})
+
+		It("generates the adapters", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(adapters).Should(ContainSubstring(`"prototest/app"`))
+			Ω(adapters).Should(ContainSubstring(toProto))
+			Ω(adapters).Should(ContainSubstring(fromProto))
+			Ω(adapters).Should(ContainSubstring(collectionFromProto))
+			Ω(adapters).Should(ContainSubstring("func WatchBottleEventToProto(v *app.WatchBottleEvent) (m *WatchBottleEvent, err error) {"))
+		})
+	})
+
+	Context("with duplicate field numbers", func() {
+		BeforeEach(func() {
+			API("test api", func() {})
+			Type("Dup", func() {
+				Attribute("a", String, func() { Metadata("proto:field", "2") })
+				Attribute("b", String, func() { Metadata("proto:field", "2") })
+			})
+		})
+
+		It("returns an error", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(ContainSubstring(`attributes "a" and "b" use the same field number 2`))
+		})
+	})
+
+	Context("with an invalid field number", func() {
+		BeforeEach(func() {
+			API("test api", func() {})
+			Type("Reserved", func() {
+				Attribute("a", String, func() { Metadata("proto:field", "19000") })
+			})
+		})
+
+		It("returns an error", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(ContainSubstring(`invalid field number "19000" for attribute "a"`))
+		})
+	})
+})
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genproto.Generator
+
+	var args = struct {
+		api    *APIDefinition
+		outDir string
+		appPkg string
+		pkg    string
+	}{
+		api: &APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+		appPkg: "app_pkg",
+		pkg:    "pkg",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genproto.NewGenerator(
+				genproto.API(args.api),
+				genproto.OutDir(args.outDir),
+				genproto.AppPkg(args.appPkg),
+				genproto.Pkg(args.pkg),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.AppPkg).Should(Equal(args.appPkg))
+			Ω(generator.Pkg).Should(Equal(args.pkg))
+		})
+	})
+})
+
+const service = `// Bottle resource
+service BottleService {
+  rpc Create(CreateBottleRequest) returns (google.protobuf.Empty);
+  rpc List(ListBottleRequest) returns (BottleTinyCollection);
+  // Show a bottle
+  rpc Show(ShowBottleRequest) returns (Bottle);
+  rpc Watch(WatchBottleRequest) returns (stream WatchBottleEvent);
+}
+`
+
+const bottleMessage = `// A bottle of wine (default view)
+message Bottle {
+  string name = 1;
+  optional string created_at = 2;
+  // ID of bottle
+  int64 id = 3;
+  bytes meta = 4;
+  repeated string tags = 5;
+  optional int64 vintage = 6;
+}
+`
+
+const collectionMessage = `message BottleTinyCollection {
+  repeated BottleTiny items = 1;
+}
+`
+
+const createMessage = `// CreateBottleRequest is the request of the Create RPC of the BottleService service.
+message CreateBottleRequest {
+  CreateBottlePayload payload = 1;
+}
+`
+
+const eventMessage = `message WatchBottleEvent {
+  optional string at = 1;
+}
+`
+
+const toProto = `// BottleToProto builds a Bottle message from the given app type.
+func BottleToProto(v *app.Bottle) (m *Bottle, err error) {
+	if v == nil {
+		return nil, nil
+	}
+	m = &Bottle{}
+	m.Name = v.Name
+	if v.CreatedAt != nil {
+		tmp := v.CreatedAt.Format(time.RFC3339Nano)
+		m.CreatedAt = &tmp
+	}
+	m.Id = int64(v.ID)
+	if m.Meta, err = json.Marshal(v.Meta); err != nil {
+		return nil, err
+	}
+	if v.Tags != nil {
+		m.Tags = make([]string, len(v.Tags))
+		for i, e := range v.Tags {
+			m.Tags[i] = e
+		}
+	}
+	if v.Vintage != nil {
+		tmp := int64(*v.Vintage)
+		m.Vintage = &tmp
+	}
+	return m, nil
+}
+`
+
+const fromProto = `// BottleFromProto builds the app type from the given Bottle message.
+func BottleFromProto(m *Bottle) (v *app.Bottle, err error) {
+	if m == nil {
+		return nil, nil
+	}
+	v = &app.Bottle{}
+	v.Name = m.Name
+	if m.CreatedAt != nil {
+		tmp, err := time.Parse(time.RFC3339Nano, *m.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		v.CreatedAt = &tmp
+	}
+	v.ID = int(m.Id)
+	if len(m.Meta) > 0 {
+		if err = json.Unmarshal(m.Meta, &v.Meta); err != nil {
+			return nil, err
+		}
+	}
+`
+
+const collectionFromProto = `func BottleTinyCollectionFromProto(m *BottleTinyCollection) (v app.BottleTinyCollection, err error) {
+	if m == nil {
+		return nil, nil
+	}
+	if m.Items != nil {
+		v = make([]*app.BottleTiny, len(m.Items))
+		for i, e := range m.Items {
+			if v[i], err = BottleTinyFromProto(e); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
+`
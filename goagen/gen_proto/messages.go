@@ -0,0 +1,420 @@
+package genproto
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// FieldNumberMetadata is the name of the metadata used to set the number of the protocol buffer
+// field generated for an attribute, for example:
+//
+//	Attribute("name", String, func() {
+//		Metadata("proto:field", "2")
+//	})
+//
+// Fields that do not define a number are numbered using the first numbers left available taken
+// in the alphabetical order of the attribute names. Setting the number explicitly guarantees that
+// the wire format stays compatible when attributes are added or removed.
+const FieldNumberMetadata = "proto:field"
+
+const (
+	// maxFieldNumber is the largest field number allowed by protocol buffers.
+	maxFieldNumber = 1<<29 - 1
+	// firstReservedNumber and lastReservedNumber delimit the range of field numbers reserved
+	// for the protocol buffers implementation.
+	firstReservedNumber = 19000
+	lastReservedNumber  = 19999
+)
+
+type (
+	// protoMessage is the data used to render a protocol buffer message.
+	protoMessage struct {
+		// Name is the message name.
+		Name string
+		// Description is the message description.
+		Description string
+		// Fields lists the message fields sorted by number.
+		Fields []*protoField
+		// Type is the user type or media type the message represents, nil for the
+		// request and event messages that do not have a counterpart in the app package.
+		Type *design.UserTypeDefinition
+	}
+
+	// protoField is the data used to render a protocol buffer message field.
+	protoField struct {
+		// Name is the field name.
+		Name string
+		// GoName is the name of the struct field generated by protoc-gen-go.
+		GoName string
+		// Number is the field number.
+		Number int
+		// Type is the field protocol buffer type.
+		Type string
+		// Label is "repeated", "optional" or empty.
+		Label string
+		// Description is the field description.
+		Description string
+		// AttName is the name of the corresponding design attribute.
+		AttName string
+		// Att is the corresponding design attribute.
+		Att *design.AttributeDefinition
+		// Pointer is true if the app struct field is a pointer.
+		Pointer bool
+		// JSON is true if the value is JSON encoded in a bytes field because it cannot be
+		// described with protocol buffer types (Any, inline objects, unions etc.).
+		JSON bool
+		// Key is the map key attribute for map fields.
+		Key *design.AttributeDefinition
+		// Elem is the element attribute for repeated and map fields.
+		Elem *design.AttributeDefinition
+	}
+
+	// builder computes the messages of a protocol buffer file.
+	builder struct {
+		messages map[string]*protoMessage
+	}
+)
+
+// newBuilder returns a builder with no message.
+func newBuilder() *builder {
+	return &builder{messages: make(map[string]*protoMessage)}
+}
+
+// sorted returns the messages built so far sorted by name.
+func (b *builder) sorted() []*protoMessage {
+	names := make([]string, len(b.messages))
+	i := 0
+	for n := range b.messages {
+		names[i] = n
+		i++
+	}
+	sort.Strings(names)
+	res := make([]*protoMessage, len(names))
+	for i, n := range names {
+		res[i] = b.messages[n]
+	}
+	return res
+}
+
+// isMessage returns true if the given user type is represented with a protocol buffer message.
+func isMessage(ut *design.UserTypeDefinition) bool {
+	if ut.IsUnion() {
+		return false
+	}
+	return ut.Type.IsObject() || ut.Type.IsArray()
+}
+
+// addType records the message that represents the given user type, and the messages of the
+// types it refers to. Collection types are represented with a message with a single "items"
+// repeated field.
+func (b *builder) addType(ut *design.UserTypeDefinition) error {
+	if !isMessage(ut) {
+		return nil
+	}
+	name := codegen.GoTypeName(ut, nil, 0, false)
+	if m, ok := b.messages[name]; ok {
+		if m.Type == nil {
+			return fmt.Errorf("proto: more than one type maps to message %s", name)
+		}
+		return nil
+	}
+	m := &protoMessage{Name: name, Description: ut.Description, Type: ut}
+	b.messages[name] = m
+	var err error
+	if ut.Type.IsArray() {
+		items := &design.AttributeDefinition{Type: ut.Type}
+		parent := &design.AttributeDefinition{Type: design.Object{"items": items}}
+		var f *protoField
+		if f, err = b.field("items", items, parent); err != nil {
+			return err
+		}
+		if f != nil {
+			f.Number = 1
+			m.Fields = []*protoField{f}
+		}
+		return nil
+	}
+	m.Fields, err = b.fields(ut.AttributeDefinition)
+	return err
+}
+
+// addMessage records a message with the given name and the fields of the given object attribute.
+func (b *builder) addMessage(name, desc string, att *design.AttributeDefinition) error {
+	if _, ok := b.messages[name]; ok {
+		return fmt.Errorf("proto: more than one type maps to message %s", name)
+	}
+	m := &protoMessage{Name: name, Description: desc}
+	b.messages[name] = m
+	var err error
+	m.Fields, err = b.fields(att)
+	return err
+}
+
+// fields computes the numbered fields of the given object attribute.
+func (b *builder) fields(att *design.AttributeDefinition) ([]*protoField, error) {
+	obj := att.Type.ToObject()
+	names := make([]string, len(obj))
+	i := 0
+	for n := range obj {
+		names[i] = n
+		i++
+	}
+	sort.Strings(names)
+	var fields []*protoField
+	seen := make(map[string]string)
+	for _, n := range names {
+		f, err := b.field(n, obj[n], att)
+		if err != nil {
+			return nil, err
+		}
+		if f == nil {
+			continue
+		}
+		if other, ok := seen[f.Name]; ok {
+			return nil, fmt.Errorf("proto: attributes %q and %q both map to field %s", other, n, f.Name)
+		}
+		seen[f.Name] = n
+		fields = append(fields, f)
+	}
+	if err := numberFields(fields); err != nil {
+		return nil, err
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Number < fields[j].Number })
+	return fields, nil
+}
+
+// field computes the field that represents the attribute with the given name of the parent
+// object. It returns nil if the attribute cannot be represented (files).
+func (b *builder) field(name string, att, parent *design.AttributeDefinition) (*protoField, error) {
+	if isFile(att.Type) {
+		return nil, nil
+	}
+	f := &protoField{
+		Name:        fieldName(name),
+		Description: att.Description,
+		AttName:     name,
+		Att:         att,
+		Pointer:     parent.IsPrimitivePointer(name),
+	}
+	f.GoName = goFieldName(f.Name)
+	typ, err := b.protoType(att.Type)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case typ != "":
+		f.Type = typ
+		if f.Pointer {
+			f.Label = "optional"
+		}
+	case att.Type.IsArray():
+		elem := att.Type.ToArray().ElemType
+		if isFile(elem.Type) {
+			return nil, nil
+		}
+		if typ, err = b.protoType(elem.Type); err != nil {
+			return nil, err
+		}
+		if typ != "" {
+			f.Type = typ
+			f.Label = "repeated"
+			f.Elem = elem
+		}
+	case att.Type.IsHash():
+		h := att.Type.ToHash()
+		if typ, err = b.protoType(h.ElemType.Type); err != nil {
+			return nil, err
+		}
+		if key := mapKeyType(h.KeyType.Type); key != "" && typ != "" {
+			f.Type = fmt.Sprintf("map<%s, %s>", key, typ)
+			f.Key = h.KeyType
+			f.Elem = h.ElemType
+		}
+	}
+	if f.Type == "" {
+		f.Type = "bytes"
+		f.JSON = true
+		f.Label = ""
+	}
+	return f, nil
+}
+
+// protoType returns the protocol buffer scalar type or message name used to represent values of
+// the given type. It returns an empty string if the type requires a repeated, map or JSON encoded
+// field.
+func (b *builder) protoType(t design.DataType) (string, error) {
+	switch actual := t.(type) {
+	case design.Primitive:
+		return scalarType(actual), nil
+	case *design.MediaTypeDefinition:
+		if actual.IsError() || !isMessage(actual.UserTypeDefinition) {
+			return "", nil
+		}
+		if err := b.addType(actual.UserTypeDefinition); err != nil {
+			return "", err
+		}
+		return codegen.GoTypeName(actual, nil, 0, false), nil
+	case *design.UserTypeDefinition:
+		if !isMessage(actual) {
+			return "", nil
+		}
+		if err := b.addType(actual); err != nil {
+			return "", err
+		}
+		return codegen.GoTypeName(actual, nil, 0, false), nil
+	}
+	return "", nil
+}
+
+// scalarType returns the protocol buffer scalar type used to represent values of the given
+// primitive type, an empty string if there is none.
+func scalarType(p design.Primitive) string {
+	switch p.Kind() {
+	case design.BooleanKind:
+		return "bool"
+	case design.IntegerKind:
+		return "int64"
+	case design.NumberKind:
+		return "double"
+	case design.StringKind, design.DateTimeKind, design.UUIDKind:
+		return "string"
+	}
+	return ""
+}
+
+// mapKeyType returns the protocol buffer type used to represent map keys of the given type, an
+// empty string if the type cannot be used as map key.
+func mapKeyType(t design.DataType) string {
+	switch t.Kind() {
+	case design.BooleanKind:
+		return "bool"
+	case design.IntegerKind:
+		return "int64"
+	case design.StringKind:
+		return "string"
+	}
+	return ""
+}
+
+// isFile returns true if t is the File primitive type.
+func isFile(t design.DataType) bool {
+	p, ok := t.(design.Primitive)
+	return ok && p.Kind() == design.FileKind
+}
+
+// numberFields sets the number of the given fields, first using the numbers set via the
+// FieldNumberMetadata metadata then assigning the lowest numbers left available in order.
+func numberFields(fields []*protoField) error {
+	used := make(map[int]string)
+	for _, f := range fields {
+		vals, ok := f.Att.Metadata[FieldNumberMetadata]
+		if !ok || len(vals) == 0 {
+			continue
+		}
+		n, err := strconv.Atoi(vals[0])
+		if err != nil || n < 1 || n > maxFieldNumber || (n >= firstReservedNumber && n <= lastReservedNumber) {
+			return fmt.Errorf("proto: invalid field number %q for attribute %q", vals[0], f.AttName)
+		}
+		if other, ok := used[n]; ok {
+			return fmt.Errorf("proto: attributes %q and %q use the same field number %d", other, f.AttName, n)
+		}
+		used[n] = f.AttName
+		f.Number = n
+	}
+	next := 1
+	for _, f := range fields {
+		if f.Number != 0 {
+			continue
+		}
+		for {
+			if next == firstReservedNumber {
+				next = lastReservedNumber + 1
+			}
+			if _, ok := used[next]; !ok {
+				break
+			}
+			next++
+		}
+		f.Number = next
+		used[next] = f.AttName
+	}
+	return nil
+}
+
+// fieldName returns a valid protocol buffer field name in lower snake case for the given
+// attribute name.
+func fieldName(name string) string {
+	var b bytes.Buffer
+	underscore := false
+	for _, r := range codegen.SnakeCase(name) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(unicode.ToLower(r))
+			underscore = false
+			continue
+		}
+		if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	n := strings.TrimSuffix(b.String(), "_")
+	if n == "" || unicode.IsDigit(rune(n[0])) {
+		n = "field_" + n
+	}
+	return n
+}
+
+// reservedGoNames lists the names of the methods of the Go types generated by protoc-gen-go,
+// fields with these names get an extra "_" suffix.
+var reservedGoNames = map[string]bool{
+	"Reset":        true,
+	"String":       true,
+	"ProtoMessage": true,
+	"ProtoReflect": true,
+	"Descriptor":   true,
+}
+
+// goFieldName returns the name of the Go struct field generated by protoc-gen-go for the protocol
+// buffer field with the given name.
+func goFieldName(name string) string {
+	var b []byte
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '_' && i == 0:
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(name) && isLower(name[i+1]):
+			// Skip the underscore, the next letter is capitalized below.
+		case isDigit(c):
+			b = append(b, c)
+		default:
+			if isLower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(name) && isLower(name[i+1]); i++ {
+				b = append(b, name[i+1])
+			}
+		}
+	}
+	n := string(b)
+	if reservedGoNames[n] {
+		n += "_"
+	}
+	return n
+}
+
+func isLower(c byte) bool { return 'a' <= c && c <= 'z' }
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+// protoPackage returns a valid protocol buffer package name for the given API name.
+func protoPackage(name string) string {
+	return fieldName(name)
+}
@@ -46,7 +46,9 @@ type (
 		AdditionalProperties bool          `json:"additionalProperties,omitempty"`
 
 		// Union
-		AnyOf []*JSONSchema `json:"anyOf,omitempty"`
+		AnyOf         []*JSONSchema      `json:"anyOf,omitempty"`
+		OneOf         []*JSONSchema      `json:"oneOf,omitempty"`
+		Discriminator *JSONDiscriminator `json:"discriminator,omitempty"`
 	}
 
 	// JSONType is the JSON type enum.
@@ -58,6 +60,13 @@ type (
 		Type           string `json:"type,omitempty"`
 	}
 
+	// JSONDiscriminator describes the attribute used to identify the type of the values of a
+	// union ("oneOf") schema.
+	JSONDiscriminator struct {
+		PropertyName string            `json:"propertyName"`
+		Mapping      map[string]string `json:"mapping,omitempty"`
+	}
+
 	// JSONLink represents a "link" field in a JSON hyper schema.
 	JSONLink struct {
 		Title        string      `json:"title,omitempty"`
@@ -309,6 +318,23 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 	case *design.Hash:
 		s.Type = JSONObject
 		s.AdditionalProperties = true
+	case *design.Union:
+		for _, v := range actual.Values {
+			vs := NewJSONSchema()
+			buildAttributeSchema(api, vs, v.AttributeDefinition)
+			s.OneOf = append(s.OneOf, vs)
+			if actual.Discriminator != "" {
+				if s.Discriminator == nil {
+					s.Discriminator = &JSONDiscriminator{
+						PropertyName: actual.Discriminator,
+						Mapping:      make(map[string]string),
+					}
+				}
+				if vs.Ref != "" {
+					s.Discriminator.Mapping[v.Name] = vs.Ref
+				}
+			}
+		}
 	case *design.UserTypeDefinition:
 		s.Ref = TypeRef(api, actual)
 	case *design.MediaTypeDefinition:
@@ -338,6 +364,8 @@ func (s *JSONSchema) createMergeItems(other *JSONSchema) mergeItems {
 		{&s.Format, other.Format, s.Format == ""},
 		{&s.Pattern, other.Pattern, s.Pattern == ""},
 		{&s.AdditionalProperties, other.AdditionalProperties, s.AdditionalProperties == false},
+		{&s.OneOf, other.OneOf, s.OneOf == nil},
+		{&s.Discriminator, other.Discriminator, s.Discriminator == nil},
 		{
 			a: s.Minimum, b: other.Minimum,
 			needed: (s.Minimum == nil && s.Minimum != nil) ||
@@ -417,6 +445,8 @@ func (s *JSONSchema) Dup() *JSONSchema {
 		MaxLength:            s.MaxLength,
 		Required:             s.Required,
 		AdditionalProperties: s.AdditionalProperties,
+		OneOf:                s.OneOf,
+		Discriminator:        s.Discriminator,
 	}
 	for n, p := range s.Properties {
 		js.Properties[n] = p.Dup()
@@ -430,6 +460,11 @@ func (s *JSONSchema) Dup() *JSONSchema {
 	return &js
 }
 
+// AttributeSchema produces the JSON schema corresponding to the given attribute.
+func AttributeSchema(api *design.APIDefinition, at *design.AttributeDefinition) *JSONSchema {
+	return buildAttributeSchema(api, NewJSONSchema(), at)
+}
+
 // buildAttributeSchema initializes the given JSON schema that corresponds to the given attribute.
 func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.AttributeDefinition) *JSONSchema {
 	if at.View != "" {
@@ -458,6 +458,9 @@ func New(api *design.APIDefinition) (*Swagger, error) {
 			// sad but swagger doesn't support these
 			d.Media = nil
 			d.Links = nil
+			// swagger discriminators name a property of the schema, they do not
+			// apply to union (oneOf) schemas.
+			d.Discriminator = nil
 			s.Definitions[n] = d
 		}
 	}
@@ -708,6 +711,17 @@ func paramFor(at *design.AttributeDefinition, name, in string, required bool) *P
 	return p
 }
 
+// paramsFromMultipartPayload returns the "formData" parameters corresponding to the attributes of
+// the given multipart payload.
+func paramsFromMultipartPayload(payload *design.UserTypeDefinition) []*Parameter {
+	var params []*Parameter
+	payload.ToObject().IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		params = append(params, paramFor(at, n, "formData", payload.IsRequired(n)))
+		return nil
+	})
+	return params
+}
+
 // toStringMap converts map[interface{}]interface{} to a map[string]interface{} when possible.
 func toStringMap(val interface{}) interface{} {
 	switch actual := val.(type) {
@@ -843,7 +857,7 @@ func buildPathFromFileServer(s *Swagger, api *design.APIDefinition, fs *design.F
 		},
 	}
 	if len(wcs) > 0 {
-		schema := genschema.TypeSchema(api, design.ErrorMedia)
+		schema := genschema.TypeSchema(api, api.ErrorMedia())
 		responses["404"] = &Response{Description: "File not found", Schema: schema}
 	}
 
@@ -908,7 +922,9 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		responses[strconv.Itoa(r.Status)] = resp
 	}
 
-	if action.Payload != nil {
+	if action.Payload != nil && action.PayloadMultipart {
+		params = append(params, paramsFromMultipartPayload(action.Payload)...)
+	} else if action.Payload != nil {
 		payloadSchema := genschema.TypeSchema(api, action.Payload)
 		pp := &Parameter{
 			Name:        "payload",
@@ -949,9 +965,14 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		Deprecated:   false,
 		Extensions:   extensionsFromDefinition(route.Metadata),
 	}
+	if action.Payload != nil && action.PayloadMultipart {
+		operation.Consumes = []string{"multipart/form-data"}
+	}
 
 	computeProduces(operation, s, action)
 	applySecurity(operation, action.Security)
+	applyRateLimit(operation, api, action.RateLimit)
+	applyConditional(operation, action)
 
 	key := design.WildcardRegex.ReplaceAllStringFunc(
 		route.FullPath(),
@@ -1049,6 +1070,90 @@ func applySecurity(operation *Operation, security *design.SecurityDefinition) {
 	}
 }
 
+// applyRateLimit documents the RateLimit-* headers in the operation responses, adds the 429 (Too
+// Many Requests) response and records the limit in the "x-ratelimit" extension.
+func applyRateLimit(operation *Operation, api *design.APIDefinition, limit *design.RateLimitDefinition) {
+	if limit == nil {
+		return
+	}
+	rateLimitHeaders := func() map[string]*Header {
+		return map[string]*Header{
+			"RateLimit-Limit": {
+				Description: "Maximum number of requests allowed in the current window",
+				Type:        "integer",
+			},
+			"RateLimit-Remaining": {
+				Description: "Number of requests remaining in the current window",
+				Type:        "integer",
+			},
+			"RateLimit-Reset": {
+				Description: "Number of seconds until the rate limit resets",
+				Type:        "integer",
+			},
+		}
+	}
+	for _, resp := range operation.Responses {
+		if resp.Ref != "" {
+			continue
+		}
+		if resp.Headers == nil {
+			resp.Headers = make(map[string]*Header)
+		}
+		for n, h := range rateLimitHeaders() {
+			resp.Headers[n] = h
+		}
+	}
+	if _, ok := operation.Responses["429"]; !ok {
+		headers := rateLimitHeaders()
+		headers["Retry-After"] = &Header{
+			Description: "Number of seconds to wait before retrying",
+			Type:        "integer",
+		}
+		operation.Responses["429"] = &Response{
+			Description: fmt.Sprintf("Too Many Requests: limited to %d requests per %s", limit.Requests, limit.Period),
+			Schema:      genschema.TypeSchema(api, api.ErrorMedia()),
+			Headers:     headers,
+		}
+	}
+	ext := map[string]interface{}{
+		"requests": limit.Requests,
+		"period":   limit.Period.String(),
+	}
+	if limit.Burst > 0 {
+		ext["burst"] = limit.Burst
+	}
+	if operation.Extensions == nil {
+		operation.Extensions = make(map[string]interface{})
+	}
+	operation.Extensions["x-ratelimit"] = ext
+}
+
+// applyConditional documents the ETag and Last-Modified headers of the successful responses of
+// cacheable actions and actions that use entity tags.
+func applyConditional(operation *Operation, action *design.ActionDefinition) {
+	if !action.Cacheable && !action.ETag {
+		return
+	}
+	for code, resp := range operation.Responses {
+		if resp.Ref != "" || len(code) != 3 || code[0] != '2' {
+			continue
+		}
+		if resp.Headers == nil {
+			resp.Headers = make(map[string]*Header)
+		}
+		resp.Headers["ETag"] = &Header{
+			Description: "Entity tag of the returned representation",
+			Type:        "string",
+		}
+		if action.Cacheable {
+			resp.Headers["Last-Modified"] = &Header{
+				Description: "Date the returned representation was last modified",
+				Type:        "string",
+			}
+		}
+	}
+}
+
 func scopesList(scopes []string) string {
 	sort.Strings(scopes)
 
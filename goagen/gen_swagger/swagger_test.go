@@ -3,6 +3,7 @@ package genswagger_test
 import (
 	"bytes"
 	"encoding/json"
+	"time"
 
 	"github.com/go-openapi/loads"
 	_ "github.com/goadesign/goa-cellar/design"
@@ -281,6 +282,61 @@ var _ = Describe("New", func() {
 
 		})
 
+		Context("with a cacheable action", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(GET("/"))
+						Cacheable()
+						Response(OK)
+					})
+				})
+			})
+
+			It("documents the conditional requests", func() {
+				p := swagger.Paths["/"].(*genswagger.Path)
+				Ω(p.Get.Responses["200"].Headers).Should(HaveKey("ETag"))
+				Ω(p.Get.Responses["200"].Headers).Should(HaveKey("Last-Modified"))
+				Ω(p.Get.Responses).Should(HaveKey("304"))
+				var names []string
+				for _, param := range p.Get.Parameters {
+					names = append(names, param.Name)
+				}
+				Ω(names).Should(ContainElement("If-None-Match"))
+				Ω(names).Should(ContainElement("If-Modified-Since"))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
+		Context("with a rate limit", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					RateLimit(100, time.Minute, 10)
+					Action("act", func() {
+						Routing(GET("/"))
+						Response(NoContent)
+					})
+				})
+			})
+
+			It("documents the rate limit", func() {
+				p := swagger.Paths["/"].(*genswagger.Path)
+				Ω(p.Get.Responses["204"].Headers).Should(HaveKey("RateLimit-Limit"))
+				Ω(p.Get.Responses["204"].Headers).Should(HaveKey("RateLimit-Remaining"))
+				Ω(p.Get.Responses["204"].Headers).Should(HaveKey("RateLimit-Reset"))
+				Ω(p.Get.Responses).Should(HaveKey("429"))
+				Ω(p.Get.Responses["429"].Headers).Should(HaveKey("Retry-After"))
+				Ω(p.Get.Extensions["x-ratelimit"]).Should(Equal(map[string]interface{}{
+					"requests": 100,
+					"period":   "1m0s",
+					"burst":    10,
+				}))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
 		Context("with zero value validations", func() {
 			const (
 				intParam = "intParam"
@@ -0,0 +1,8 @@
+/*
+Package gents provides a goa generator for a TypeScript client module.
+The module declares an interface or type alias for each user type and each view of each media
+type, a response type per action that lists the possible responses by status code and a Client
+class with one typed method per action. The client relies on the standard fetch API to perform
+the actual HTTP requests and has no other dependency.
+*/
+package gents
@@ -0,0 +1,546 @@
+package gents
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+	"github.com/goadesign/goa/version"
+)
+
+// NewGenerator returns an initialized instance of a TypeScript Client Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the TypeScript client code generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Destination directory
+	Timeout  time.Duration         // Timeout used by TypeScript client when making requests
+	Scheme   string                // Scheme used by TypeScript client
+	Host     string                // Host addressed by TypeScript client
+	genfiles []string              // Generated files
+}
+
+type (
+	// tsAction is the data used to render the client method of an action.
+	tsAction struct {
+		Action       *design.ActionDefinition
+		Method       string
+		Verb         string
+		FullPath     string
+		Path         string
+		Params       string
+		Query        bool
+		Payload      bool
+		Body         string
+		ContentType  string
+		ResponseType string
+		Responses    []*tsResponse
+	}
+
+	// tsResponse is the data used to render a response of an action.
+	tsResponse struct {
+		Status int
+		Type   string
+		JSON   bool
+	}
+
+	// tsParam is a client method parameter.
+	tsParam struct {
+		Name     string
+		Type     string
+		Optional bool
+	}
+)
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, ver  string
+		timeout      time.Duration
+		scheme, host string
+	)
+
+	set := flag.NewFlagSet("ts", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.String("design", "", "")
+	set.DurationVar(&timeout, "timeout", time.Duration(20)*time.Second, "")
+	set.StringVar(&scheme, "scheme", "", "")
+	set.StringVar(&host, "host", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.Parse(os.Args[1:])
+
+	// First check compatibility
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	// Now proceed
+	g := &Generator{OutDir: outDir, Timeout: timeout, Scheme: scheme, Host: host, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the TypeScript client module.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.Timeout == 0 {
+		g.Timeout = 20 * time.Second
+	}
+	if g.Scheme == "" && len(g.API.Schemes) > 0 {
+		g.Scheme = g.API.Schemes[0]
+	}
+	if g.Scheme == "" {
+		g.Scheme = "http"
+	}
+	if g.Host == "" {
+		g.Host = g.API.Host
+	}
+	if g.Host == "" {
+		return nil, fmt.Errorf("missing host value, set it with --host")
+	}
+
+	g.OutDir = filepath.Join(g.OutDir, "ts")
+	if err := os.RemoveAll(g.OutDir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(g.OutDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, g.OutDir)
+
+	// Generate client.ts
+	if err = g.generateTS(filepath.Join(g.OutDir, "client.ts")); err != nil {
+		return
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+func (g *Generator) generateTS(tsFile string) error {
+	file, err := codegen.SourceFileFor(tsFile)
+	if err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, tsFile)
+
+	types, err := g.userTypes()
+	if err != nil {
+		return err
+	}
+	var (
+		actions   []*tsAction
+		multipart bool
+	)
+	err = g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			ta, err := g.action(a)
+			if err != nil {
+				return err
+			}
+			multipart = multipart || a.PayloadMultipart
+			actions = append(actions, ta)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"API":         g.API,
+		"Host":        g.Host,
+		"Scheme":      g.Scheme,
+		"Timeout":     int64(g.Timeout / time.Millisecond),
+		"ToolVersion": version.String(),
+		"Types":       types,
+		"Actions":     actions,
+		"Multipart":   multipart,
+	}
+	funcs := template.FuncMap{"typeDecl": typeDecl, "tsDoc": tsDoc}
+	return file.ExecuteTemplate("client", clientT, funcs, data)
+}
+
+// userTypes returns the user types that need a TypeScript declaration sorted by name: all the
+// user types, the projections of all the media types and the action payloads as well as any type
+// they refer to.
+func (g *Generator) userTypes() ([]*design.UserTypeDefinition, error) {
+	types := make(map[string]*design.UserTypeDefinition)
+	var add func(*design.UserTypeDefinition)
+	add = func(ut *design.UserTypeDefinition) {
+		name := tsTypeName(ut)
+		if _, ok := types[name]; ok {
+			return
+		}
+		types[name] = ut
+		for _, child := range design.UserTypes(ut.Type) {
+			add(child)
+		}
+	}
+	err := g.API.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		return mt.IterateViews(func(view *design.ViewDefinition) error {
+			p, links, err := mt.Project(view.Name)
+			if err != nil {
+				return err
+			}
+			add(p.UserTypeDefinition)
+			if links != nil {
+				add(links)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.API.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		add(ut)
+		return nil
+	})
+	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload != nil {
+				add(a.Payload)
+			}
+			return nil
+		})
+	})
+
+	names := make([]string, len(types))
+	i := 0
+	for n := range types {
+		names[i] = n
+		i++
+	}
+	sort.Strings(names)
+	res := make([]*design.UserTypeDefinition, len(names))
+	for i, n := range names {
+		res[i] = types[n]
+	}
+	return res, nil
+}
+
+// action computes the data used to render the client method of the given action. The method
+// builds the request path using the first route of the action.
+func (g *Generator) action(a *design.ActionDefinition) (*tsAction, error) {
+	if len(a.Routes) == 0 {
+		return nil, fmt.Errorf("action %s of resource %s has no route", a.Name, a.Parent.Name)
+	}
+	var (
+		route  = a.Routes[0]
+		all    = a.AllParams()
+		params []*tsParam
+	)
+	ta := &tsAction{
+		Action:       a,
+		Method:       codegen.Goify(a.Name+"_"+a.Parent.Name, false),
+		Verb:         route.Verb,
+		FullPath:     route.FullPath(),
+		ResponseType: codegen.Goify(a.Name, true) + codegen.Goify(a.Parent.Name, true) + "Response",
+	}
+
+	// Path parameters
+	ta.Path = design.WildcardRegex.ReplaceAllStringFunc(strings.Replace(ta.FullPath, "`", "\\`", -1), func(w string) string {
+		name := design.WildcardRegex.FindStringSubmatch(w)[1]
+		encode := "encodeURIComponent"
+		if w[1] == '*' {
+			encode = "encodeURI"
+		}
+		return "/${" + encode + "(String(" + codegen.Goify(name, false) + "))}"
+	})
+	for _, n := range route.Params() {
+		typ := "string"
+		if all != nil {
+			if att, ok := all.Type.ToObject()[n]; ok {
+				typ = tsTypeRef(att)
+			}
+		}
+		params = append(params, &tsParam{Name: codegen.Goify(n, false), Type: typ})
+	}
+
+	// Payload
+	if a.Payload != nil {
+		ta.Payload = true
+		params = append(params, &tsParam{Name: "payload", Type: tsTypeName(a.Payload), Optional: a.PayloadOptional})
+		switch {
+		case a.PayloadMultipart:
+			ta.Body = "formData(payload)"
+			ta.ContentType = "undefined"
+		case a.PayloadOptional:
+			ta.Body = "payload === undefined ? undefined : JSON.stringify(payload)"
+			ta.ContentType = "'application/json'"
+		default:
+			ta.Body = "JSON.stringify(payload)"
+			ta.ContentType = "'application/json'"
+		}
+	}
+
+	// Query string
+	if a.QueryParams != nil && len(a.QueryParams.Type.ToObject()) > 0 {
+		ta.Query = true
+		qatt := &design.AttributeDefinition{Type: a.QueryParams.Type, Validation: a.QueryParams.Validation}
+		if all != nil {
+			qatt.Validation = all.Validation
+		}
+		required := false
+		for n := range a.QueryParams.Type.ToObject() {
+			if qatt.IsRequired(n) {
+				required = true
+				break
+			}
+		}
+		params = append(params, &tsParam{Name: "query", Type: tsTypeRef(qatt), Optional: !required})
+	}
+
+	// Optional parameters must come last, make the ones followed by a required parameter
+	// accept undefined instead.
+	args := []string{"init?: RequestInit"}
+	trailing := true
+	for i := len(params) - 1; i >= 0; i-- {
+		p := params[i]
+		switch {
+		case !p.Optional:
+			trailing = false
+			args = append([]string{p.Name + ": " + p.Type}, args...)
+		case trailing:
+			args = append([]string{p.Name + "?: " + p.Type}, args...)
+		default:
+			args = append([]string{p.Name + ": " + p.Type + " | undefined"}, args...)
+		}
+	}
+	ta.Params = strings.Join(args, ", ")
+
+	// Responses
+	seen := make(map[int]bool)
+	a.IterateResponses(func(r *design.ResponseDefinition) error {
+		if seen[r.Status] {
+			return nil
+		}
+		seen[r.Status] = true
+		ta.Responses = append(ta.Responses, g.response(r))
+		return nil
+	})
+	sort.Slice(ta.Responses, func(i, j int) bool { return ta.Responses[i].Status < ta.Responses[j].Status })
+
+	return ta, nil
+}
+
+// response computes the type of the body of the given response. The body of responses that use a
+// media type defined in the design is decoded from JSON, the body of responses that use any other
+// media type is returned as text. The body of responses that do not specify a view may use any
+// view of the media type.
+func (g *Generator) response(r *design.ResponseDefinition) *tsResponse {
+	res := &tsResponse{Status: r.Status}
+	var mt *design.MediaTypeDefinition
+	if r.Type != nil {
+		var ok bool
+		if mt, ok = r.Type.(*design.MediaTypeDefinition); !ok {
+			res.Type = tsTypeRef(&design.AttributeDefinition{Type: r.Type})
+			res.JSON = true
+			return res
+		}
+	} else if r.MediaType != "" {
+		mt = g.API.MediaTypeWithIdentifier(r.MediaType)
+		if mt == nil {
+			res.Type = "string"
+			return res
+		}
+	}
+	if mt == nil {
+		return res
+	}
+	var views []string
+	if r.ViewName != "" {
+		views = []string{r.ViewName}
+	} else {
+		mt.IterateViews(func(v *design.ViewDefinition) error {
+			views = append(views, v.Name)
+			return nil
+		})
+	}
+	var types []string
+	for _, view := range views {
+		if p, _, err := mt.Project(view); err == nil {
+			types = append(types, tsTypeName(p.UserTypeDefinition))
+		}
+	}
+	if len(types) == 0 {
+		types = []string{tsTypeName(mt.UserTypeDefinition)}
+	}
+	res.Type = strings.Join(types, " | ")
+	res.JSON = true
+	return res
+}
+
+const clientT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .API.Name }} TypeScript client
+//
+// Command:
+{{ comment commandLine }}
+
+// This module exports the types and a client for the {{ .API.Name }} API hosted at {{ .Host }}.
+// It uses the standard fetch API for making the actual HTTP requests.
+{{ range .Types }}
+{{ typeDecl . }}{{ end }}{{ range .Actions }}
+/** {{ .ResponseType }} lists the responses of the {{ .Action.Name }} action of the {{ .Action.Parent.Name }} resource. */
+export type {{ .ResponseType }} ={{ if .Responses }}{{ range $i, $r := .Responses }}
+  | { status: {{ $r.Status }}{{ if $r.Type }}; body: {{ $r.Type }}{{ end }} }{{ end }};
+{{ else }} { status: number };
+{{ end }}{{ end }}
+/** ClientOptions configures a Client. */
+export interface ClientOptions {
+  /** scheme is the URL scheme used to make requests, defaults to "{{ .Scheme }}". */
+  scheme?: string;
+  /** host is the API hostname, defaults to "{{ .Host }}". */
+  host?: string;
+  /** timeout is the duration in milliseconds before requests time out, defaults to {{ .Timeout }}. Use 0 to disable. */
+  timeout?: number;
+  /** headers are added to all requests. */
+  headers?: { [name: string]: string };
+  /** fetch is the function used to make requests, defaults to the global fetch function. */
+  fetch?: (input: string, init?: RequestInit) => Promise<Response>;
+}
+
+/** ClientError is thrown when the API responds with a status code not described in the design. */
+export class ClientError extends Error {
+  readonly status: number;
+  readonly body: string;
+
+  constructor(status: number, body: string) {
+    super(` + "`unexpected response status ${status}`" + `);
+    this.name = 'ClientError';
+    this.status = status;
+    this.body = body;
+  }
+}
+{{ if .Multipart }}
+// formData encodes the given payload into multipart form data.
+function formData(payload: object | undefined): FormData | undefined {
+  if (payload === undefined) {
+    return undefined;
+  }
+  const form = new FormData();
+  const fields = payload as { [name: string]: unknown };
+  for (const name of Object.keys(fields)) {
+    const value = fields[name];
+    for (const v of Array.isArray(value) ? value : [value]) {
+      if (v === undefined || v === null) {
+        continue;
+      }
+      form.append(name, v instanceof Blob ? v : typeof v === 'object' ? JSON.stringify(v) : String(v));
+    }
+  }
+  return form;
+}
+{{ end }}
+/** Client gives access to the {{ .API.Name }} API. */
+export class Client {
+  private readonly urlPrefix: string;
+  private readonly timeout: number;
+  private readonly headers: { [name: string]: string };
+  private readonly fetchFn: (input: string, init?: RequestInit) => Promise<Response>;
+
+  constructor(options: ClientOptions = {}) {
+    this.urlPrefix = (options.scheme || '{{ .Scheme }}') + '://' + (options.host || '{{ .Host }}');
+    this.timeout = options.timeout === undefined ? {{ .Timeout }} : options.timeout;
+    this.headers = options.headers || {};
+    this.fetchFn = options.fetch || ((input, init) => fetch(input, init));
+  }
+{{ range .Actions }}
+{{ if .Action.Description }}{{ tsDoc .Action.Description "  " }}{{ else }}  /** {{ .Method }} calls the {{ .Action.Name }} action of the {{ .Action.Parent.Name }} resource. */
+{{ end }}  async {{ .Method }}({{ .Params }}): Promise<{{ .ResponseType }}> {
+    const resp = await this.request('{{ .Verb }}', ` + "`{{ .Path }}`" + `, {{ if .Query }}query{{ else }}undefined{{ end }}, {{ if .Payload }}{{ .Body }}, {{ .ContentType }}{{ else }}undefined, undefined{{ end }}, init);
+{{ if .Responses }}    switch (resp.status) {
+{{ range .Responses }}      case {{ .Status }}:
+        return { status: {{ .Status }}{{ if .JSON }}, body: (await resp.json()) as {{ .Type }}{{ else if .Type }}, body: await resp.text(){{ end }} };
+{{ end }}    }
+{{ else }}    if (resp.ok) {
+      return { status: resp.status };
+    }
+{{ end }}    throw new ClientError(resp.status, await resp.text());
+  }
+{{ end }}
+  // request makes a request to the API and returns the response.
+  private async request(
+    method: string,
+    path: string,
+    query: object | undefined,
+    body: BodyInit | undefined,
+    contentType: string | undefined,
+    init: RequestInit | undefined,
+  ): Promise<Response> {
+    let url = this.urlPrefix + path;
+    if (query !== undefined) {
+      const params = new URLSearchParams();
+      const values = query as { [name: string]: unknown };
+      for (const name of Object.keys(values)) {
+        const value = values[name];
+        for (const v of Array.isArray(value) ? value : [value]) {
+          if (v !== undefined && v !== null) {
+            params.append(name, String(v));
+          }
+        }
+      }
+      const qs = params.toString();
+      if (qs !== '') {
+        url += '?' + qs;
+      }
+    }
+    const headers = new Headers(this.headers);
+    new Headers(init && init.headers).forEach((value, name) => headers.set(name, value));
+    if (contentType !== undefined) {
+      headers.set('Content-Type', contentType);
+    }
+    let signal = init && init.signal;
+    let timer: ReturnType<typeof setTimeout> | undefined;
+    if (!signal && this.timeout > 0) {
+      const controller = new AbortController();
+      timer = setTimeout(() => controller.abort(), this.timeout);
+      signal = controller.signal;
+    }
+    try {
+      return await this.fetchFn(url, { ...init, method, headers, body, signal });
+    } finally {
+      if (timer !== undefined) {
+        clearTimeout(timer);
+      }
+    }
+  }
+}
+`
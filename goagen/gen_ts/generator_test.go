@@ -0,0 +1,230 @@
+package gents_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_ts"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	const testgenPackagePath = "github.com/goadesign/goa/goagen/gen_ts/test_"
+
+	var outDir string
+	var files []string
+	var genErr error
+	var content string
+
+	BeforeEach(func() {
+		gopath := filepath.SplitList(os.Getenv("GOPATH"))[0]
+		outDir = filepath.Join(gopath, "src", testgenPackagePath)
+		err := os.MkdirAll(outDir, 0777)
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + outDir, "--design=foo", "--host=baz", "--version=" + version.String()}
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = gents.Generate()
+		content = ""
+		if genErr == nil {
+			b, err := ioutil.ReadFile(filepath.Join(outDir, "ts", "client.ts"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content = string(b)
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+	})
+
+	Context("with a dummy API", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name:        "testapi",
+				Title:       "dummy API with no resource",
+				Description: "I told you it's dummy",
+			}
+		})
+
+		It("generates a client", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(HaveLen(2))
+			Ω(content).Should(ContainSubstring("export class Client {"))
+			Ω(content).Should(ContainSubstring("this.urlPrefix = (options.scheme || 'http') + '://' + (options.host || 'baz');"))
+			Ω(content).ShouldNot(ContainSubstring("function formData("))
+		})
+	})
+
+	Context("with user types and actions", func() {
+		BeforeEach(func() {
+			circle := &design.UserTypeDefinition{
+				TypeName: "Circle",
+				AttributeDefinition: &design.AttributeDefinition{
+					Description: "Circle is a circle",
+					Type: design.Object{
+						"radius": {Type: design.Integer, Description: "Radius of circle"},
+						"x-y":    {Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.Number}}},
+					},
+					Validation: &dslengine.ValidationDefinition{Required: []string{"radius"}},
+				},
+			}
+			shape := &design.UserTypeDefinition{
+				TypeName: "Shape",
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: &design.Union{
+						Discriminator: "kind",
+						Values: []*design.UnionValue{
+							{Name: "circle", AttributeDefinition: &design.AttributeDefinition{Type: circle}},
+						},
+					},
+				},
+			}
+			payload := &design.UserTypeDefinition{
+				TypeName: "CreateShapePayload",
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{
+						"shape": {Type: shape},
+						"data":  {Type: &design.Hash{KeyType: &design.AttributeDefinition{Type: design.String}, ElemType: &design.AttributeDefinition{Type: design.Any}}},
+					},
+				},
+			}
+			action := &design.ActionDefinition{
+				Name: "create",
+				Routes: []*design.RouteDefinition{{
+					Verb: "POST",
+					Path: "/accounts/:accountID/shapes",
+				}},
+				Params: &design.AttributeDefinition{
+					Type: design.Object{
+						"accountID": {Type: design.Integer},
+						"dry":       {Type: design.Boolean},
+					},
+				},
+				QueryParams: &design.AttributeDefinition{
+					Type: design.Object{
+						"dry": {Type: design.Boolean},
+					},
+				},
+				Payload: payload,
+				Responses: map[string]*design.ResponseDefinition{
+					"NoContent": {Name: "NoContent", Status: 204},
+					"Text":      {Name: "Text", Status: 200, MediaType: "text/plain"},
+				},
+			}
+			design.Design = &design.APIDefinition{
+				Name:  "testapi",
+				Types: map[string]*design.UserTypeDefinition{"Circle": circle, "Shape": shape},
+				Resources: map[string]*design.ResourceDefinition{
+					"shape": {
+						Name: "shape",
+						Actions: map[string]*design.ActionDefinition{
+							"create": action,
+						},
+					},
+				},
+			}
+			action.Parent = design.Design.Resources["shape"]
+			action.Routes[0].Parent = action
+		})
+
+		It("generates the type declarations", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(content).Should(ContainSubstring(circleDecl))
+			Ω(content).Should(ContainSubstring(shapeDecl))
+			Ω(content).Should(ContainSubstring(payloadDecl))
+		})
+
+		It("generates the response type", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(content).Should(ContainSubstring(responseDecl))
+		})
+
+		It("generates the client method", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(content).Should(ContainSubstring(methodCode))
+		})
+	})
+})
+
+var _ = Describe("NewGenerator", func() {
+	var generator *gents.Generator
+
+	var args = struct {
+		api     *design.APIDefinition
+		outDir  string
+		timeout time.Duration
+		scheme  string
+		host    string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir:  "out_dir",
+		timeout: time.Millisecond * 500,
+		scheme:  "http",
+		host:    "localhost",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = gents.NewGenerator(
+				gents.API(args.api),
+				gents.OutDir(args.outDir),
+				gents.Timeout(args.timeout),
+				gents.Scheme(args.scheme),
+				gents.Host(args.host),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Timeout).Should(Equal(args.timeout))
+			Ω(generator.Scheme).Should(Equal(args.scheme))
+			Ω(generator.Host).Should(Equal(args.host))
+		})
+	})
+})
+
+const circleDecl = `/** Circle is a circle */
+export interface Circle {
+  /** Radius of circle */
+  radius: number;
+  "x-y"?: number[];
+}
+`
+
+const shapeDecl = `export type Shape =
+  | ({ kind: "circle" } & Circle);
+`
+
+const payloadDecl = `export interface CreateShapePayload {
+  data?: { [key: string]: any };
+  shape?: Shape;
+}
+`
+
+const responseDecl = `export type CreateShapeResponse =
+  | { status: 200; body: string }
+  | { status: 204 };
+`
+
+const methodCode = `  async createShape(accountID: number, payload: CreateShapePayload, query?: { dry?: boolean }, init?: RequestInit): Promise<CreateShapeResponse> {
+    const resp = await this.request('POST', ` + "`/accounts/${encodeURIComponent(String(accountID))}/shapes`" + `, query, JSON.stringify(payload), 'application/json', init);
+    switch (resp.status) {
+      case 200:
+        return { status: 200, body: await resp.text() };
+      case 204:
+        return { status: 204 };
+    }
+    throw new ClientError(resp.status, await resp.text());
+  }
+`
@@ -0,0 +1,192 @@
+package gents
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// identRegex matches the field names that need not be quoted in TypeScript.
+var identRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsField describes a field of a TypeScript interface or object type.
+type tsField struct {
+	Name        string
+	Optional    bool
+	Type        string
+	Description string
+}
+
+// reserved lists the names that generated types may not use because they would shadow a global
+// TypeScript declaration used by the client or a type declared by the client module itself.
+var reserved = map[string]bool{
+	"AbortController": true, "Array": true, "Blob": true, "Boolean": true, "Client": true,
+	"ClientError": true, "ClientOptions": true, "Date": true, "Error": true, "FormData": true,
+	"Headers": true, "JSON": true, "Number": true, "Object": true, "Promise": true,
+	"Request": true, "RequestInit": true, "Response": true, "String": true, "URLSearchParams": true,
+}
+
+// tsTypeName returns the name of the TypeScript type generated for the given user type.
+func tsTypeName(ut *design.UserTypeDefinition) string {
+	name := codegen.Goify(ut.TypeName, true)
+	if reserved[name] {
+		name += "Type"
+	}
+	return name
+}
+
+// tsTypeRef returns the TypeScript type expression that corresponds to the JSON representation
+// of the given attribute.
+func tsTypeRef(att *design.AttributeDefinition) string {
+	switch actual := att.Type.(type) {
+	case design.Primitive:
+		return tsPrimitive(actual)
+	case *design.Array:
+		elem := tsTypeRef(actual.ElemType)
+		if strings.ContainsAny(elem, " |&") {
+			return "Array<" + elem + ">"
+		}
+		return elem + "[]"
+	case *design.Hash:
+		return "{ [key: string]: " + tsTypeRef(actual.ElemType) + " }"
+	case design.Object:
+		fields := tsFields(att)
+		if len(fields) == 0 {
+			return "{}"
+		}
+		parts := make([]string, len(fields))
+		for i, f := range fields {
+			parts[i] = f.Name + optional(f.Optional) + ": " + f.Type
+		}
+		return "{ " + strings.Join(parts, "; ") + " }"
+	case *design.UserTypeDefinition:
+		return tsTypeName(actual)
+	case *design.MediaTypeDefinition:
+		return tsTypeName(actual.UserTypeDefinition)
+	case *design.Union:
+		return strings.Join(tsUnionValues(actual), " | ")
+	}
+	return "any"
+}
+
+// tsPrimitive returns the TypeScript type used to represent values of the given primitive type.
+func tsPrimitive(p design.Primitive) string {
+	switch p.Kind() {
+	case design.BooleanKind:
+		return "boolean"
+	case design.IntegerKind, design.NumberKind:
+		return "number"
+	case design.StringKind, design.DateTimeKind, design.UUIDKind:
+		return "string"
+	case design.FileKind:
+		return "Blob"
+	default:
+		return "any"
+	}
+}
+
+// tsUnionValues returns the TypeScript type expressions of each value of a union. Values of unions
+// that define a discriminator are intersected with an object type holding the discriminator.
+func tsUnionValues(u *design.Union) []string {
+	values := make([]string, len(u.Values))
+	for i, v := range u.Values {
+		if u.Discriminator == "" {
+			values[i] = tsTypeRef(v.AttributeDefinition)
+			continue
+		}
+		values[i] = fmt.Sprintf("({ %s: %q } & %s)", fieldName(u.Discriminator), v.Name, tsTypeRef(v.AttributeDefinition))
+	}
+	return values
+}
+
+// tsFields returns the fields of the given object attribute sorted by name.
+func tsFields(att *design.AttributeDefinition) []*tsField {
+	o := att.Type.ToObject()
+	names := make([]string, len(o))
+	i := 0
+	for n := range o {
+		names[i] = n
+		i++
+	}
+	sort.Strings(names)
+	fields := make([]*tsField, len(names))
+	for i, n := range names {
+		fields[i] = &tsField{
+			Name:        fieldName(n),
+			Optional:    !att.IsRequired(n),
+			Type:        tsTypeRef(o[n]),
+			Description: o[n].Description,
+		}
+	}
+	return fields
+}
+
+// fieldName quotes the given attribute name if it is not a valid TypeScript identifier.
+func fieldName(n string) string {
+	if identRegex.MatchString(n) {
+		return n
+	}
+	return strconv.Quote(n)
+}
+
+// optional returns the TypeScript optional marker if opt is true.
+func optional(opt bool) string {
+	if opt {
+		return "?"
+	}
+	return ""
+}
+
+// tsDoc renders the given description as a TSDoc comment indented with the given prefix.
+func tsDoc(desc, indent string) string {
+	desc = strings.TrimSpace(strings.Replace(desc, "*/", "*\\/", -1))
+	if desc == "" {
+		return ""
+	}
+	lines := strings.Split(desc, "\n")
+	if len(lines) == 1 {
+		return indent + "/** " + desc + " */\n"
+	}
+	var b bytes.Buffer
+	b.WriteString(indent + "/**\n")
+	for _, l := range lines {
+		b.WriteString(strings.TrimRight(indent+" * "+l, " ") + "\n")
+	}
+	b.WriteString(indent + " */\n")
+	return b.String()
+}
+
+// typeDecl returns the TypeScript declaration of the given user type.
+func typeDecl(ut *design.UserTypeDefinition) string {
+	var b bytes.Buffer
+	b.WriteString(tsDoc(ut.Description, ""))
+	name := tsTypeName(ut)
+	switch actual := ut.Type.(type) {
+	case design.Object:
+		fmt.Fprintf(&b, "export interface %s {\n", name)
+		for _, f := range tsFields(ut.AttributeDefinition) {
+			b.WriteString(tsDoc(f.Description, "  "))
+			fmt.Fprintf(&b, "  %s%s: %s;\n", f.Name, optional(f.Optional), f.Type)
+		}
+		b.WriteString("}\n")
+	case *design.Union:
+		fmt.Fprintf(&b, "export type %s =\n", name)
+		values := tsUnionValues(actual)
+		for i, v := range values {
+			end := ""
+			if i == len(values)-1 {
+				end = ";"
+			}
+			fmt.Fprintf(&b, "  | %s%s\n", v, end)
+		}
+	default:
+		fmt.Fprintf(&b, "export type %s = %s;\n", name, tsTypeRef(ut.AttributeDefinition))
+	}
+	return b.String()
+}
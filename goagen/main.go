@@ -3,12 +3,14 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_diff"
 	"github.com/goadesign/goa/goagen/meta"
 	"github.com/goadesign/goa/goagen/utils"
 	"github.com/goadesign/goa/version"
@@ -110,6 +112,14 @@ package and tool and the Swagger specification for the API.
 	}
 	rootCmd.AddCommand(swaggerCmd)
 
+	// openapi3Cmd implements the "openapi3" command.
+	openapi3Cmd := &cobra.Command{
+		Use:   "openapi3",
+		Short: "Generate OpenAPI 3.0 specification",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genopenapi3", c) },
+	}
+	rootCmd.AddCommand(openapi3Cmd)
+
 	// jsCmd implements the "js" command.
 	var (
 		timeout      = time.Duration(20) * time.Second
@@ -127,6 +137,17 @@ package and tool and the Swagger specification for the API.
 	jsCmd.Flags().BoolVar(&noexample, "noexample", false, `Skip generation of example HTML and controller`)
 	rootCmd.AddCommand(jsCmd)
 
+	// tsCmd implements the "ts" command.
+	tsCmd := &cobra.Command{
+		Use:   "ts",
+		Short: "Generate TypeScript client",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("gents", c) },
+	}
+	tsCmd.Flags().DurationVar(&timeout, "timeout", timeout, `the duration before the request times out.`)
+	tsCmd.Flags().StringVar(&scheme, "scheme", "", `the URL scheme used to make requests to the API, defaults to the scheme defined in the API design if any.`)
+	tsCmd.Flags().StringVar(&host, "host", "", `the API hostname, defaults to the hostname defined in the API design if any`)
+	rootCmd.AddCommand(tsCmd)
+
 	// schemaCmd implements the "schema" command.
 	schemaCmd := &cobra.Command{
 		Use:   "schema",
@@ -135,6 +156,14 @@ package and tool and the Swagger specification for the API.
 	}
 	rootCmd.AddCommand(schemaCmd)
 
+	// designJSONCmd implements the "design-json" command.
+	designJSONCmd := &cobra.Command{
+		Use:   "design-json",
+		Short: "Generate JSON representation of design",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("gendesign", c) },
+	}
+	rootCmd.AddCommand(designJSONCmd)
+
 	// genCmd implements the "gen" command.
 	var (
 		pkgPath string
@@ -199,6 +228,47 @@ package and tool and the Swagger specification for the API.
 	controllerCmd.Flags().StringVar(&appPkg, "app-pkg", "app", "`import path` of Go package generated with 'goagen app', may be relative to output")
 	rootCmd.AddCommand(controllerCmd)
 
+	// protoCmd implements the "proto" command.
+	protoCmd := &cobra.Command{
+		Use:   "proto",
+		Short: "Generate Protocol Buffers definitions and Go adapters",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genproto", c) },
+	}
+	protoCmd.Flags().StringVar(&pkg, "pkg", "pb", "name of the generated Go `package` containing the adapters")
+	protoCmd.Flags().StringVar(&appPkg, "app-pkg", "app", "`import path` of Go package generated with 'goagen app', may be relative to output")
+	rootCmd.AddCommand(protoCmd)
+
+	// mockCmd implements the "mock" command.
+	var (
+		mockDir string
+	)
+	mockCmd := &cobra.Command{
+		Use:   "mock",
+		Short: "Generate mock server returning example responses",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genmock", c) },
+	}
+	mockCmd.Flags().StringVar(&appPkg, "app-pkg", "app", "`import path` of Go package generated with 'goagen app', may be relative to output")
+	mockCmd.Flags().StringVar(&mockDir, "mockdir", "mock", "name of the generated mock server `directory`")
+	rootCmd.AddCommand(mockCmd)
+
+	// diffCmd implements the "diff" command.
+	var (
+		base, baseDesign, target string
+	)
+	diffCmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Detect breaking changes between two versions of a design",
+		Long: `Compare a base version of a design with the design given with --design (or with the
+serialized design given with --target) and report the changes. The base version is either a
+design.json file produced by "goagen design-json" (--base) or a design package (--base-design).
+The command exits with a non-zero status if any change may break existing clients.`,
+		Run: func(c *cobra.Command, _ []string) { files, err = runDiff(c, base, baseDesign, target) },
+	}
+	diffCmd.Flags().StringVar(&base, "base", "", "`path` to the design.json file describing the base design")
+	diffCmd.Flags().StringVar(&baseDesign, "base-design", "", "`import path` of the base design package")
+	diffCmd.Flags().StringVar(&target, "target", "", "`path` to the design.json file describing the target design, compare with --design if not specified")
+	rootCmd.AddCommand(diffCmd)
+
 	// cmdsCmd implements the commands command
 	// It lists all the commands and flags in JSON to enable shell integrations.
 	cmdsCmd := &cobra.Command{
@@ -258,6 +328,47 @@ func run(pkg string, c *cobra.Command) ([]string, error) {
 	return generate(pkgName, pkgPath, c, nil)
 }
 
+func runDiff(c *cobra.Command, base, baseDesign, target string) ([]string, error) {
+	if (base == "") == (baseDesign == "") {
+		return nil, fmt.Errorf("exactly one of --base or --base-design must be specified")
+	}
+	if baseDesign != "" {
+		tmpDir, err := ioutil.TempDir("", "goagen-diff")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmpDir)
+		gen, err := meta.NewGenerator(
+			"gendesign.Generate",
+			[]*codegen.ImportSpec{codegen.SimpleImport("github.com/goadesign/goa/goagen/gen_design")},
+			map[string]string{"design": baseDesign, "out": tmpDir},
+			nil,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := gen.Generate(); err != nil {
+			return nil, fmt.Errorf("failed to serialize base design: %s", err)
+		}
+		base = filepath.Join(tmpDir, "design.json")
+	}
+	base, err := filepath.Abs(base)
+	if err != nil {
+		return nil, err
+	}
+	if target != "" {
+		out, err := filepath.Abs(c.Flag("out").Value.String())
+		if err != nil {
+			return nil, err
+		}
+		return gendiff.NewGenerator(gendiff.Base(base), gendiff.Target(target), gendiff.OutDir(out)).Generate()
+	}
+	if err := c.Flags().Set("base", base); err != nil {
+		return nil, err
+	}
+	return run("gendiff", c)
+}
+
 func runGen(c *cobra.Command, args []string) ([]string, error) {
 	pkgPath := c.Flag("pkg-path").Value.String()
 	pkgSrcPath, err := codegen.PackageSourcePath(pkgPath)
@@ -0,0 +1,198 @@
+package goatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+)
+
+type (
+	// ContractTest describes a request made to an action and the responses declared in the
+	// design for the action. Contract tests are generated by "goagen app" from the examples of
+	// the action parameters and payload.
+	ContractTest struct {
+		// Name identifies the test in error messages.
+		Name string
+		// Method is the request HTTP method.
+		Method string
+		// Path is the request path including the query string.
+		Path string
+		// Header contains the request headers.
+		Header http.Header
+		// Body is the request body if any.
+		Body []byte
+		// Responses lists the responses declared in the design.
+		Responses []*ContractResponse
+	}
+
+	// ContractResponse describes a response declared in the design.
+	ContractResponse struct {
+		// Status is the response status code.
+		Status int
+		// Headers lists the names of the headers the response must set.
+		Headers []string
+		// ContentType is the response media type identifier, empty if the design does not
+		// define one.
+		ContentType string
+		// Views lists the views of the response media type the body may be rendered with.
+		Views []*ContractView
+	}
+
+	// ContractView describes a view of a response media type.
+	ContractView struct {
+		// Name is the view name.
+		Name string
+		// Attributes lists the names of the attributes rendered by the view. For collections
+		// Attributes lists the names of the attributes of the elements.
+		Attributes []string
+		// Validate decodes the JSON response body into the view type and validates the
+		// result, nil if the view type has no validation.
+		Validate func(body []byte) error
+	}
+)
+
+// RunContract sends the request described by c to handler and checks that the response conforms
+// to one of the responses declared in the design. handler is typically the mux of a service
+// whose controllers are mounted so that the request goes through routing, decoding and
+// middleware. RunContract returns the recorded response.
+func RunContract(t TInterface, handler http.Handler, c *ContractTest) *httptest.ResponseRecorder {
+	var body io.Reader
+	if c.Body != nil {
+		body = bytes.NewReader(c.Body)
+	}
+	req, err := http.NewRequest(c.Method, c.Path, body)
+	if err != nil {
+		t.Fatalf("%s: invalid request: %s", c.Name, err)
+		return nil
+	}
+	for name, values := range c.Header {
+		req.Header[name] = values
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+	if c.Body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+
+	if err := c.Check(rw.Code, rw.Header(), rw.Body.Bytes()); err != nil {
+		t.Errorf("%s: %s", c.Name, err)
+	}
+	return rw
+}
+
+// Check returns an error if the response with the given status, headers and body does not
+// conform to any of the responses declared for the action.
+func (c *ContractTest) Check(status int, header http.Header, body []byte) error {
+	var err error
+	for _, r := range c.Responses {
+		if r.Status != status {
+			continue
+		}
+		if err = r.Check(header, body); err == nil {
+			return nil
+		}
+	}
+	if err == nil {
+		return fmt.Errorf("undeclared response status %d, body: %s", status, body)
+	}
+	return err
+}
+
+// Check returns an error if the response with the given headers and body does not conform to r.
+func (r *ContractResponse) Check(header http.Header, body []byte) error {
+	for _, name := range r.Headers {
+		if header.Get(name) == "" {
+			return fmt.Errorf("%d response is missing header %s", r.Status, name)
+		}
+	}
+	if r.ContentType != "" && len(body) > 0 {
+		ct := header.Get("Content-Type")
+		if !matchMediaType(ct, r.ContentType) {
+			return fmt.Errorf("%d response has Content-Type %q, expected %q", r.Status, ct, r.ContentType)
+		}
+	}
+	if len(r.Views) == 0 {
+		return nil
+	}
+	var err error
+	for _, v := range r.Views {
+		if err = v.Check(body); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%d response body does not match media type: %s", r.Status, err)
+}
+
+// Check returns an error if the given JSON body is not a valid rendering of the view.
+func (v *ContractView) Check(body []byte) error {
+	if v.Attributes != nil {
+		var raw interface{}
+		if err := json.Unmarshal(body, &raw); err != nil {
+			return fmt.Errorf("view %s: %s", v.Name, err)
+		}
+		elems, ok := raw.([]interface{})
+		if !ok {
+			elems = []interface{}{raw}
+		}
+		for _, e := range elems {
+			obj, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if extra := v.extraAttributes(obj); len(extra) > 0 {
+				return fmt.Errorf("view %s does not render %s", v.Name, strings.Join(extra, ", "))
+			}
+		}
+	}
+	if v.Validate != nil {
+		if err := v.Validate(body); err != nil {
+			return fmt.Errorf("view %s: %s", v.Name, err)
+		}
+	}
+	return nil
+}
+
+// extraAttributes returns the sorted names of the keys of obj that are not attributes of the view.
+func (v *ContractView) extraAttributes(obj map[string]interface{}) []string {
+	var extra []string
+	for k := range obj {
+		found := false
+		for _, a := range v.Attributes {
+			if a == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			extra = append(extra, k)
+		}
+	}
+	sort.Strings(extra)
+	return extra
+}
+
+// matchMediaType returns true if the given Content-Type header value identifies the expected
+// media type. Parameters and structured syntax suffixes are ignored.
+func matchMediaType(contentType, expected string) bool {
+	canonical := func(id string) string {
+		mt, _, err := mime.ParseMediaType(id)
+		if err != nil {
+			mt = id
+		}
+		if i := strings.Index(mt, "+"); i > -1 {
+			mt = mt[:i]
+		}
+		return strings.ToLower(mt)
+	}
+	return canonical(contentType) == canonical(expected)
+}
@@ -0,0 +1,160 @@
+package goatest_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/goadesign/goa/goatest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// recordingT is a goatest.TInterface that records the reported errors.
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Fatalf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+var _ = Describe("RunContract", func() {
+	var handler http.HandlerFunc
+	var contract *goatest.ContractTest
+	var t *recordingT
+	var req *http.Request
+	var reqBody []byte
+
+	BeforeEach(func() {
+		t = &recordingT{}
+		req, reqBody = nil, nil
+		contract = &goatest.ContractTest{
+			Name:   "bottle show",
+			Method: "POST",
+			Path:   "/bottles/1?limit=2",
+			Header: http.Header{"X-Foo": []string{"bar"}},
+			Body:   []byte(`{"name":"x"}`),
+			Responses: []*goatest.ContractResponse{
+				{Status: 404},
+				{
+					Status:      200,
+					Headers:     []string{"Location"},
+					ContentType: "application/vnd.bottle+json",
+					Views: []*goatest.ContractView{
+						{Name: "default", Attributes: []string{"id", "name"}},
+						{
+							Name:       "tiny",
+							Attributes: []string{"id"},
+							Validate: func(body []byte) error {
+								if string(body) == `{"id":0}` {
+									return errors.New("invalid id")
+								}
+								return nil
+							},
+						},
+					},
+				},
+			},
+		}
+	})
+
+	respond := func(status int, header http.Header, body string) {
+		handler = func(rw http.ResponseWriter, r *http.Request) {
+			req = r
+			reqBody, _ = ioutil.ReadAll(r.Body)
+			for n, v := range header {
+				rw.Header()[n] = v
+			}
+			rw.WriteHeader(status)
+			rw.Write([]byte(body))
+		}
+	}
+
+	JustBeforeEach(func() {
+		goatest.RunContract(t, handler, contract)
+	})
+
+	Context("with a conforming response", func() {
+		BeforeEach(func() {
+			respond(200, http.Header{"Location": {"/bottles/1"}, "Content-Type": {"application/vnd.bottle; type=collection"}}, `[{"id":1}]`)
+		})
+
+		It("sends the request and reports no error", func() {
+			Ω(t.errors).Should(BeEmpty())
+			Ω(req.Method).Should(Equal("POST"))
+			Ω(req.URL.String()).Should(Equal("/bottles/1?limit=2"))
+			Ω(req.Header.Get("X-Foo")).Should(Equal("bar"))
+			Ω(req.Header.Get("Accept")).Should(Equal("application/json"))
+			Ω(req.Header.Get("Content-Type")).Should(Equal("application/json"))
+			Ω(string(reqBody)).Should(Equal(`{"name":"x"}`))
+		})
+	})
+
+	Context("with a response with an undeclared status", func() {
+		BeforeEach(func() {
+			respond(500, nil, "oops")
+		})
+
+		It("reports an error", func() {
+			Ω(t.errors).Should(ConsistOf("bottle show: undeclared response status 500, body: oops"))
+		})
+	})
+
+	Context("with a response missing a header", func() {
+		BeforeEach(func() {
+			respond(200, http.Header{"Content-Type": {"application/vnd.bottle+json"}}, `{"id":1}`)
+		})
+
+		It("reports an error", func() {
+			Ω(t.errors).Should(ConsistOf("bottle show: 200 response is missing header Location"))
+		})
+	})
+
+	Context("with a response with the wrong content type", func() {
+		BeforeEach(func() {
+			respond(200, http.Header{"Location": {"/"}, "Content-Type": {"text/plain"}}, `{"id":1}`)
+		})
+
+		It("reports an error", func() {
+			Ω(t.errors).Should(HaveLen(1))
+			Ω(t.errors[0]).Should(ContainSubstring(`Content-Type "text/plain"`))
+		})
+	})
+
+	Context("with a body rendering attributes outside of the views", func() {
+		BeforeEach(func() {
+			respond(200, http.Header{"Location": {"/"}, "Content-Type": {"application/vnd.bottle+json"}}, `{"id":1,"vintage":2012}`)
+		})
+
+		It("reports an error", func() {
+			Ω(t.errors).Should(HaveLen(1))
+			Ω(t.errors[0]).Should(ContainSubstring("does not render vintage"))
+		})
+	})
+
+	Context("with a body failing validation", func() {
+		BeforeEach(func() {
+			respond(200, http.Header{"Location": {"/"}, "Content-Type": {"application/vnd.bottle+json"}}, `{"id":0}`)
+		})
+
+		It("accepts the body if it matches another view", func() {
+			Ω(t.errors).Should(BeEmpty())
+		})
+	})
+
+	Context("with a response with no body", func() {
+		BeforeEach(func() {
+			respond(404, nil, "")
+		})
+
+		It("reports no error", func() {
+			Ω(t.errors).Should(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package conditional_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestConditional(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Conditional Suite")
+}
@@ -0,0 +1,26 @@
+/*
+Package conditional contains a middleware that evaluates conditional requests as defined in
+RFC 7232.
+
+Handlers of safe requests (GET and HEAD) set the validators of the representation they return
+using the SetETag, SetWeakETag and SetLastModified methods of goa.ResponseData. The middleware
+compares the validators with the If-None-Match and If-Modified-Since request headers and responds
+with 304 (Not Modified) and no body when the client representation is current.
+
+Unsafe requests (POST, PUT, PATCH, DELETE...) must be evaluated before the handler modifies the
+resource: the middleware uses a LookupFunc to retrieve the validators of the current
+representation and responds with 412 (Precondition Failed) when the If-Match,
+If-Unmodified-Since or If-None-Match preconditions do not hold. Example:
+
+	service.Use(conditional.New(func(ctx context.Context, req *http.Request) (*conditional.Validators, error) {
+		b, err := db.LoadBottle(path.Base(req.URL.Path))
+		if err != nil || b == nil {
+			return nil, err
+		}
+		return &conditional.Validators{ETag: `"` + b.Version + `"`, LastModified: b.UpdatedAt}, nil
+	}))
+
+The Cacheable and ETag design DSL functions add the conditional request headers and the 304 and
+412 responses to the actions so that they appear in the generated code and documentation.
+*/
+package conditional
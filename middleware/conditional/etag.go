@@ -0,0 +1,67 @@
+package conditional
+
+import "strings"
+
+// matchETag returns true if the list of entity tags given in the value of a If-Match or
+// If-None-Match header contains etag. The comparison is weak if weak is true, strong otherwise.
+// The special value "*" matches any entity tag.
+func matchETag(header, etag string, weak bool) bool {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return true
+	}
+	if etag == "" {
+		return false
+	}
+	for header != "" {
+		if header[0] == ',' || header[0] == ' ' || header[0] == '\t' {
+			header = header[1:]
+			continue
+		}
+		tag, remain := scanETag(header)
+		if tag == "" {
+			return false
+		}
+		if weak && weakMatch(tag, etag) || !weak && strongMatch(tag, etag) {
+			return true
+		}
+		header = remain
+	}
+	return false
+}
+
+// scanETag returns the entity tag found at the beginning of s and the remaining text. It returns
+// empty strings if s does not start with a syntactically valid entity tag.
+func scanETag(s string) (etag string, remain string) {
+	start := 0
+	if strings.HasPrefix(s, "W/") {
+		start = 2
+	}
+	if len(s[start:]) < 2 || s[start] != '"' {
+		return "", ""
+	}
+	// ETag is either W/"text" or "text", see RFC 7232 section 2.3.
+	for i := start + 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == 0x21 || c >= 0x23 && c <= 0x7E || c >= 0x80:
+		case c == '"':
+			return s[:i+1], s[i+1:]
+		default:
+			return "", ""
+		}
+	}
+	return "", ""
+}
+
+// strongMatch reports whether a and b match using strong comparison: both tags must be strong
+// and identical.
+func strongMatch(a, b string) bool {
+	return a == b && a != "" && a[0] == '"'
+}
+
+// weakMatch reports whether a and b match using weak comparison: the opaque tags must be
+// identical regardless of whether the tags are weak.
+func weakMatch(a, b string) bool {
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
@@ -0,0 +1,186 @@
+package conditional
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+const (
+	headerETag              = "ETag"
+	headerLastModified      = "Last-Modified"
+	headerIfMatch           = "If-Match"
+	headerIfNoneMatch       = "If-None-Match"
+	headerIfModifiedSince   = "If-Modified-Since"
+	headerIfUnmodifiedSince = "If-Unmodified-Since"
+)
+
+// ErrPreconditionFailed is the error returned by the middleware when a precondition given in the
+// request headers does not hold.
+var ErrPreconditionFailed = goa.NewErrorClass("precondition_failed", http.StatusPreconditionFailed)
+
+type (
+	// Validators describe the current representation of a resource.
+	Validators struct {
+		// ETag is the entity tag of the representation including the double quotes and
+		// the W/ prefix for weak tags, e.g. `"v42"` or `W/"v42"`. Empty if none.
+		ETag string
+		// LastModified is the date the representation was last modified, zero if unknown.
+		LastModified time.Time
+	}
+
+	// LookupFunc returns the validators of the current representation of the resource
+	// targeted by the request. It returns nil if the resource does not exist.
+	LookupFunc func(ctx context.Context, req *http.Request) (*Validators, error)
+
+	// conditionalWriter evaluates the conditional headers of safe requests against the
+	// validators set by the handler before writing the response status.
+	conditionalWriter struct {
+		http.ResponseWriter
+		req     *http.Request
+		resp    *goa.ResponseData
+		discard bool
+	}
+)
+
+// New returns a middleware that evaluates conditional requests. Safe requests (GET and HEAD)
+// that result in a 200 (OK) response are answered with 304 (Not Modified) if the ETag or
+// Last-Modified headers set by the handler match the If-None-Match or If-Modified-Since request
+// headers. If lookup is not nil it is used to evaluate the preconditions before calling the
+// handler: unsafe requests whose If-Match, If-Unmodified-Since or If-None-Match preconditions do
+// not hold result in a ErrPreconditionFailed error and safe requests may be answered without
+// calling the handler. Unsafe requests are not evaluated if lookup is nil.
+func New(lookup LookupFunc) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if !isConditional(req) {
+				return h(ctx, rw, req)
+			}
+			safe := isSafe(req.Method)
+			if lookup != nil {
+				v, err := lookup(ctx, req)
+				if err != nil {
+					return err
+				}
+				switch evaluate(req, v) {
+				case http.StatusNotModified:
+					setValidators(rw.Header(), v)
+					rw.WriteHeader(http.StatusNotModified)
+					return nil
+				case http.StatusPreconditionFailed:
+					return ErrPreconditionFailed("precondition failed", "method", req.Method)
+				}
+			}
+			if !safe {
+				return h(ctx, rw, req)
+			}
+			resp := goa.ContextResponse(ctx)
+			if resp == nil {
+				return h(ctx, rw, req)
+			}
+			w := resp.SwitchWriter(nil)
+			resp.SwitchWriter(&conditionalWriter{ResponseWriter: w, req: req, resp: resp})
+			defer resp.SwitchWriter(w)
+			return h(ctx, rw, req)
+		}
+	}
+}
+
+// WriteHeader replaces a 200 (OK) status with 304 (Not Modified) or 412 (Precondition Failed)
+// if the request preconditions evaluated against the response validators say so.
+func (w *conditionalWriter) WriteHeader(status int) {
+	if status == http.StatusOK {
+		header := w.Header()
+		v := &Validators{ETag: header.Get(headerETag)}
+		if lm := header.Get(headerLastModified); lm != "" {
+			if t, err := http.ParseTime(lm); err == nil {
+				v.LastModified = t
+			}
+		}
+		if s := evaluate(w.req, v); s != 0 {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			w.discard = true
+			w.resp.Status = s
+			status = s
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write discards the body of responses whose status was changed by WriteHeader.
+func (w *conditionalWriter) Write(b []byte) (int, error) {
+	if w.discard {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends the buffered data to the client so that streaming responses keep working when the
+// middleware is mounted.
+func (w *conditionalWriter) Flush() {
+	if w.discard {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// evaluate evaluates the preconditions of the request against the given validators following
+// the order defined in RFC 7232 section 6. v is nil if the resource does not exist. evaluate
+// returns 304 (Not Modified) or 412 (Precondition Failed) if the request should not be handled,
+// 0 otherwise.
+func evaluate(req *http.Request, v *Validators) int {
+	safe := isSafe(req.Method)
+	if im := req.Header.Get(headerIfMatch); im != "" {
+		if v == nil || !matchETag(im, v.ETag, false) {
+			return http.StatusPreconditionFailed
+		}
+	} else if ius := req.Header.Get(headerIfUnmodifiedSince); ius != "" && v != nil && !v.LastModified.IsZero() {
+		if t, err := http.ParseTime(ius); err == nil && v.LastModified.Truncate(time.Second).After(t) {
+			return http.StatusPreconditionFailed
+		}
+	}
+	if inm := req.Header.Get(headerIfNoneMatch); inm != "" {
+		if v != nil && matchETag(inm, v.ETag, true) {
+			if safe {
+				return http.StatusNotModified
+			}
+			return http.StatusPreconditionFailed
+		}
+	} else if ims := req.Header.Get(headerIfModifiedSince); ims != "" && safe && v != nil && !v.LastModified.IsZero() {
+		if t, err := http.ParseTime(ims); err == nil && !v.LastModified.Truncate(time.Second).After(t) {
+			return http.StatusNotModified
+		}
+	}
+	return 0
+}
+
+// setValidators sets the ETag and Last-Modified headers from v.
+func setValidators(header http.Header, v *Validators) {
+	if v.ETag != "" {
+		header.Set(headerETag, v.ETag)
+	}
+	if !v.LastModified.IsZero() {
+		header.Set(headerLastModified, v.LastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// isConditional returns true if the request contains any conditional header.
+func isConditional(req *http.Request) bool {
+	for _, h := range []string{headerIfMatch, headerIfNoneMatch, headerIfModifiedSince, headerIfUnmodifiedSince} {
+		if req.Header.Get(h) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// isSafe returns true if the method is GET or HEAD, the methods for which RFC 7232 defines the
+// 304 (Not Modified) response.
+func isSafe(method string) bool {
+	return method == "GET" || method == "HEAD"
+}
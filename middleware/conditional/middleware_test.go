@@ -0,0 +1,226 @@
+package conditional_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/conditional"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var lookup conditional.LookupFunc
+	var rw *httptest.ResponseRecorder
+	var req *http.Request
+	var ctx context.Context
+	var handled int
+	var handler goa.Handler
+	var err error
+
+	modified := time.Date(2017, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		lookup = nil
+		rw = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/bottles/1", nil)
+		handled = 0
+		handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			handled++
+			resp := goa.ContextResponse(ctx)
+			resp.SetETag("v1")
+			resp.SetLastModified(modified)
+			resp.Header().Set("Content-Type", "application/json")
+			resp.WriteHeader(http.StatusOK)
+			resp.Write([]byte(`{"id":1}`))
+			return nil
+		}
+	})
+
+	JustBeforeEach(func() {
+		ctx = goa.NewContext(context.Background(), rw, req, nil)
+		err = conditional.New(lookup)(handler)(ctx, goa.ContextResponse(ctx), req)
+	})
+
+	Context("with an unconditional request", func() {
+		It("writes the response", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(rw.Code).Should(Equal(http.StatusOK))
+			Ω(rw.Body.String()).Should(Equal(`{"id":1}`))
+		})
+	})
+
+	Context("with a matching If-None-Match header", func() {
+		BeforeEach(func() {
+			req.Header.Set("If-None-Match", `"v0", W/"v1"`)
+		})
+
+		It("responds with 304", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(handled).Should(Equal(1))
+			Ω(rw.Code).Should(Equal(http.StatusNotModified))
+			Ω(rw.Body.Len()).Should(Equal(0))
+			Ω(rw.Header().Get("ETag")).Should(Equal(`"v1"`))
+			Ω(rw.Header().Get("Content-Type")).Should(BeEmpty())
+			Ω(goa.ContextResponse(ctx).Status).Should(Equal(http.StatusNotModified))
+		})
+	})
+
+	Context("with a non matching If-None-Match header", func() {
+		BeforeEach(func() {
+			req.Header.Set("If-None-Match", `"v0"`)
+			req.Header.Set("If-Modified-Since", modified.Format(http.TimeFormat))
+		})
+
+		Context("and a streaming handler", func() {
+			BeforeEach(func() {
+				handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					resp := goa.ContextResponse(ctx)
+					resp.WriteHeader(http.StatusOK)
+					resp.Write([]byte("data: 1\n\n"))
+					flusher, ok := resp.ResponseWriter.(http.Flusher)
+					Ω(ok).Should(BeTrue())
+					flusher.Flush()
+					return nil
+				}
+			})
+
+			It("flushes the response", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(rw.Flushed).Should(BeTrue())
+			})
+		})
+
+		It("writes the response and ignores If-Modified-Since", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(rw.Code).Should(Equal(http.StatusOK))
+			Ω(rw.Body.String()).Should(Equal(`{"id":1}`))
+		})
+	})
+
+	Context("with If-Modified-Since", func() {
+		BeforeEach(func() {
+			req.Header.Set("If-Modified-Since", modified.Add(time.Hour).Format(http.TimeFormat))
+		})
+
+		It("responds with 304 if the resource was not modified", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(rw.Code).Should(Equal(http.StatusNotModified))
+		})
+	})
+
+	Context("with a lookup function", func() {
+		var current *conditional.Validators
+
+		BeforeEach(func() {
+			current = &conditional.Validators{ETag: `"v1"`, LastModified: modified}
+			lookup = func(context.Context, *http.Request) (*conditional.Validators, error) {
+				return current, nil
+			}
+		})
+
+		Context("and a safe request", func() {
+			BeforeEach(func() {
+				req.Header.Set("If-None-Match", `"v1"`)
+			})
+
+			It("responds with 304 without calling the handler", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(handled).Should(Equal(0))
+				Ω(rw.Code).Should(Equal(http.StatusNotModified))
+				Ω(rw.Header().Get("ETag")).Should(Equal(`"v1"`))
+				Ω(rw.Header().Get("Last-Modified")).Should(Equal("Wed, 01 Mar 2017 10:00:00 GMT"))
+			})
+		})
+
+		Context("and an unsafe request with a matching If-Match header", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("PUT", "/bottles/1", nil)
+				req.Header.Set("If-Match", `"v1"`)
+			})
+
+			It("calls the handler", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(handled).Should(Equal(1))
+			})
+		})
+
+		Context("and an unsafe request with a stale If-Match header", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("PUT", "/bottles/1", nil)
+				req.Header.Set("If-Match", `"v0"`)
+			})
+
+			It("fails with a precondition error", func() {
+				Ω(handled).Should(Equal(0))
+				Ω(err).Should(HaveOccurred())
+				Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusPreconditionFailed))
+			})
+		})
+
+		Context("and an unsafe request with a weak If-Match tag", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("PUT", "/bottles/1", nil)
+				req.Header.Set("If-Match", `W/"v1"`)
+			})
+
+			It("uses the strong comparison", func() {
+				Ω(handled).Should(Equal(0))
+				Ω(err).Should(HaveOccurred())
+			})
+		})
+
+		Context("and an unsafe request with a stale If-Unmodified-Since header", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("DELETE", "/bottles/1", nil)
+				req.Header.Set("If-Unmodified-Since", modified.Add(-time.Hour).Format(http.TimeFormat))
+			})
+
+			It("fails with a precondition error", func() {
+				Ω(handled).Should(Equal(0))
+				Ω(err).Should(HaveOccurred())
+			})
+		})
+
+		Context("and a create request with If-None-Match: *", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("PUT", "/bottles/1", nil)
+				req.Header.Set("If-None-Match", "*")
+			})
+
+			It("fails if the resource exists", func() {
+				Ω(handled).Should(Equal(0))
+				Ω(err).Should(HaveOccurred())
+			})
+
+			Context("when the resource does not exist", func() {
+				BeforeEach(func() {
+					current = nil
+				})
+
+				It("calls the handler", func() {
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(handled).Should(Equal(1))
+				})
+			})
+		})
+
+		Context("failing", func() {
+			BeforeEach(func() {
+				req.Header.Set("If-None-Match", `"v1"`)
+				lookup = func(context.Context, *http.Request) (*conditional.Validators, error) {
+					return nil, errors.New("boom")
+				}
+			})
+
+			It("returns the error", func() {
+				Ω(err).Should(MatchError("boom"))
+				Ω(handled).Should(Equal(0))
+			})
+		})
+	})
+})
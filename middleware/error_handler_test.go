@@ -2,6 +2,7 @@ package middleware_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -163,6 +164,34 @@ var _ = Describe("ErrorHandler", func() {
 		})
 	})
 
+	Context("with a problem details error formatter", func() {
+		var gerr error
+
+		BeforeEach(func() {
+			service = newService(nil)
+			service.ErrorFormatter = goa.ProblemErrorFormatter("https://example.com/errors/")
+			gerr = goa.NewErrorClass("code", 418)("teapot", "foobar", 42)
+			h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				return gerr
+			}
+		})
+
+		It("maps goa errors to problem details responses", func() {
+			var decoded map[string]interface{}
+			Ω(rw.Status).Should(Equal(418))
+			Ω(rw.ParentHeader["Content-Type"]).Should(Equal([]string{goa.ProblemMediaIdentifier}))
+			err := json.Unmarshal(rw.Body, &decoded)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(decoded).Should(HaveKeyWithValue("type", "https://example.com/errors/code"))
+			Ω(decoded).Should(HaveKeyWithValue("title", "I'm a teapot"))
+			Ω(decoded).Should(HaveKeyWithValue("status", BeNumerically("==", 418)))
+			Ω(decoded).Should(HaveKeyWithValue("detail", "teapot"))
+			Ω(decoded).Should(HaveKeyWithValue("instance", gerr.(goa.ServiceError).Token()))
+			Ω(decoded).Should(HaveKeyWithValue("code", "code"))
+			Ω(decoded).Should(HaveKeyWithValue("foobar", BeNumerically("==", 42)))
+		})
+	})
+
 	Context("with a handler returning a pkg errors wrapped error", func() {
 		var wrappedError error
 		var logger *testLogger
@@ -0,0 +1,35 @@
+/*
+Package idempotency contains a middleware that makes the retries of unsafe requests safe.
+
+Clients that retry POST and PATCH requests on network errors risk creating duplicate resources.
+With the middleware mounted such clients send a unique Idempotency-Key header with the request and
+reuse it when retrying: the response of the first request is recorded in a Store and replayed for
+the repeated requests without calling the handler again. Replayed responses carry the
+Idempotent-Replayed header.
+
+A request made while the first request with the same key is still being handled is rejected with
+status 409 (Conflict). A request that reuses a key with a different method, path or payload is
+rejected with status 422 (Unprocessable Entity). Responses are not recorded when the handler
+returns an error or writes a 5xx response so that clients may retry these requests. Example:
+
+	service.Use(idempotency.New(idempotency.NewMemoryStore(), 24*time.Hour))
+
+Keys are scoped to the caller so that a client cannot replay the response recorded for another
+client that happened to use the same key. The default scope is a hash of the Authorization
+header, the KeyFunc option sets a function that computes the scope from the request, e.g. from
+the security principal:
+
+	idempotency.New(store, 24*time.Hour, idempotency.KeyFunc(func(req *http.Request) string {
+		return principal(req.Context())
+	}))
+
+The Idempotent design DSL function documents the Idempotency-Key header and the error responses
+of the idempotent actions. The generated code runs the middleware given to the generated
+UseIdempotencyMiddleware function for these actions only:
+
+	app.UseIdempotencyMiddleware(service, idempotency.New(store, 24*time.Hour))
+
+The default store keeps the responses in memory and may be replaced with a store shared by
+multiple service instances.
+*/
+package idempotency
@@ -0,0 +1,222 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+const (
+	headerKey      = "Idempotency-Key"
+	headerReplayed = "Idempotent-Replayed"
+
+	// maxKeyLength is the maximum length of the Idempotency-Key header.
+	maxKeyLength = 255
+)
+
+// DefaultTTL is the duration responses are recorded for when New is given a zero TTL.
+const DefaultTTL = 24 * time.Hour
+
+var (
+	// ErrInvalidKey is the error returned by the middleware when the Idempotency-Key header
+	// is longer than 255 characters.
+	ErrInvalidKey = goa.NewErrorClass("invalid_idempotency_key", http.StatusBadRequest)
+
+	// ErrInProgress is the error returned by the middleware when a request is made while the
+	// request that first used the same key is still being handled.
+	ErrInProgress = goa.NewErrorClass("idempotency_conflict", http.StatusConflict)
+
+	// ErrKeyReused is the error returned by the middleware when a key is reused with a
+	// request whose method, path or payload differ from the request that first used the key.
+	ErrKeyReused = goa.NewErrorClass("idempotency_key_reused", http.StatusUnprocessableEntity)
+)
+
+type (
+	// Option is a constructor option that configures the middleware.
+	Option func(*options) *options
+
+	// options is the struct storing all the middleware options.
+	options struct {
+		keyFunc func(*http.Request) string
+	}
+)
+
+// KeyFunc is a constructor option that sets the function used to compute the scope of the
+// Idempotency-Key header values, typically the identity of the caller. Keys sent by callers with
+// different scopes never collide. The default scope is a hash of the Authorization header.
+func KeyFunc(f func(*http.Request) string) Option {
+	return func(o *options) *options {
+		o.keyFunc = f
+		return o
+	}
+}
+
+// recorder captures the response written by the handler while writing it to the underlying
+// writer.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+// New returns a middleware that handles the unsafe requests (i.e. requests made with methods
+// other than GET, HEAD, OPTIONS and TRACE) that carry an Idempotency-Key header at most once.
+// The response of the first request is recorded in store for ttl and replayed for the requests
+// made with the same key. Requests made while the first request is being handled result in a
+// ErrInProgress error and requests that reuse a key with a different method, path or payload
+// result in a ErrKeyReused error. Responses are not recorded if the handler returns an error or
+// writes a 5xx response. New uses a memory store if store is nil and DefaultTTL if ttl is zero.
+// Keys are scoped to the caller as computed by the KeyFunc option.
+func New(store Store, ttl time.Duration, opts ...Option) goa.Middleware {
+	o := &options{keyFunc: authorizationScope}
+	for _, opt := range opts {
+		o = opt(o)
+	}
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			key := req.Header.Get(headerKey)
+			if key == "" || isSafe(req.Method) {
+				return h(ctx, rw, req)
+			}
+			if len(key) > maxKeyLength {
+				return ErrInvalidKey("idempotency key is too long", "max", maxKeyLength)
+			}
+			resp := goa.ContextResponse(ctx)
+			if resp == nil {
+				return h(ctx, rw, req)
+			}
+			fp, err := fingerprint(ctx, req)
+			if err != nil {
+				return err
+			}
+			if scope := o.keyFunc(req); scope != "" {
+				key = scope + ":" + key
+			}
+			e, err := store.Lock(key, fp, ttl)
+			if err != nil {
+				return err
+			}
+			if e != nil {
+				if e.Fingerprint != fp {
+					return ErrKeyReused("idempotency key was used with a different request", "key", key)
+				}
+				if e.Response == nil {
+					return ErrInProgress("a request with the same idempotency key is being handled", "key", key)
+				}
+				return replay(resp, e.Response)
+			}
+
+			rec := &recorder{ResponseWriter: resp.SwitchWriter(nil)}
+			resp.SwitchWriter(rec)
+			saved := false
+			defer func() {
+				resp.SwitchWriter(rec.ResponseWriter)
+				if !saved {
+					store.Unlock(key)
+				}
+			}()
+			if err := h(ctx, rw, req); err != nil {
+				return err
+			}
+			if rec.status == 0 || rec.status >= 500 {
+				return nil
+			}
+			saved = true
+			return store.Save(key, &Response{Status: rec.status, Header: rec.header, Body: rec.body.Bytes()}, ttl)
+		}
+	}
+}
+
+// WriteHeader records the status and the headers of the response.
+func (w *recorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+		w.header = copyHeader(w.Header())
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the response body.
+func (w *recorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+		w.header = copyHeader(w.Header())
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// replay writes the recorded response.
+func replay(resp *goa.ResponseData, r *Response) error {
+	header := resp.Header()
+	for k, v := range copyHeader(r.Header) {
+		header[k] = v
+	}
+	header.Set(headerReplayed, "true")
+	resp.WriteHeader(r.Status)
+	_, err := resp.Write(r.Body)
+	return err
+}
+
+// fingerprint computes a hash of the request method, URI and payload. The payload is the
+// decoded payload if the request body was loaded by the controller, the raw body otherwise.
+func fingerprint(ctx context.Context, req *http.Request) (string, error) {
+	h := sha256.New()
+	io.WriteString(h, req.Method+" "+req.URL.RequestURI()+"\n")
+	if r := goa.ContextRequest(ctx); r != nil && r.Payload != nil {
+		b, err := json.Marshal(r.Payload)
+		if err != nil {
+			return "", err
+		}
+		h.Write(b)
+	} else if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return "", err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(b))
+		h.Write(b)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// authorizationScope returns a hash of the Authorization header of the request, the empty string
+// if there is none.
+func authorizationScope(req *http.Request) string {
+	auth := req.Header.Get("Authorization")
+	if auth == "" {
+		return ""
+	}
+	h := sha256.Sum256([]byte(auth))
+	return hex.EncodeToString(h[:])
+}
+
+// copyHeader returns a deep copy of the given header.
+func copyHeader(header http.Header) http.Header {
+	c := make(http.Header, len(header))
+	for k, v := range header {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
+
+// isSafe returns true if the method is one of the safe methods defined in RFC 7231.
+func isSafe(method string) bool {
+	return method == "GET" || method == "HEAD" || method == "OPTIONS" || method == "TRACE"
+}
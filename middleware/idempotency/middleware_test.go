@@ -0,0 +1,145 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/idempotency"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var store *idempotency.MemoryStore
+	var middleware goa.Middleware
+	var handled int
+	var handler goa.Handler
+
+	newRequest := func(method, body, key string) *http.Request {
+		req, _ := http.NewRequest(method, "/orders", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		return req
+	}
+
+	serve := func(req *http.Request) (*httptest.ResponseRecorder, error) {
+		rw := httptest.NewRecorder()
+		ctx := goa.NewContext(context.Background(), rw, req, nil)
+		err := middleware(handler)(ctx, goa.ContextResponse(ctx), req)
+		return rw, err
+	}
+
+	BeforeEach(func() {
+		store = idempotency.NewMemoryStore()
+		middleware = idempotency.New(store, 0)
+		handled = 0
+		handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			handled++
+			resp := goa.ContextResponse(ctx)
+			resp.Header().Set("Location", "/orders/1")
+			resp.WriteHeader(http.StatusCreated)
+			resp.Write([]byte(`{"id":1}`))
+			return nil
+		}
+	})
+
+	It("replays the response of repeated requests", func() {
+		rw, err := serve(newRequest("POST", `{"item":"wine"}`, "k1"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rw.Code).Should(Equal(http.StatusCreated))
+		Ω(rw.Header().Get("Idempotent-Replayed")).Should(BeEmpty())
+
+		rw, err = serve(newRequest("POST", `{"item":"wine"}`, "k1"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(handled).Should(Equal(1))
+		Ω(rw.Code).Should(Equal(http.StatusCreated))
+		Ω(rw.Body.String()).Should(Equal(`{"id":1}`))
+		Ω(rw.Header().Get("Location")).Should(Equal("/orders/1"))
+		Ω(rw.Header().Get("Idempotent-Replayed")).Should(Equal("true"))
+	})
+
+	It("handles requests without key or with a safe method", func() {
+		serve(newRequest("POST", `{}`, ""))
+		serve(newRequest("POST", `{}`, ""))
+		serve(newRequest("GET", "", "k1"))
+		serve(newRequest("GET", "", "k1"))
+		Ω(handled).Should(Equal(4))
+		Ω(store.Len()).Should(BeZero())
+	})
+
+	It("scopes the keys to the caller", func() {
+		req := newRequest("POST", `{"item":"wine"}`, "k1")
+		req.Header.Set("Authorization", "Bearer alice")
+		serve(req)
+		req = newRequest("POST", `{"item":"wine"}`, "k1")
+		req.Header.Set("Authorization", "Bearer bob")
+		rw, err := serve(req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(handled).Should(Equal(2))
+		Ω(rw.Header().Get("Idempotent-Replayed")).Should(BeEmpty())
+		Ω(store.Len()).Should(Equal(2))
+	})
+
+	It("scopes the keys with the KeyFunc option", func() {
+		middleware = idempotency.New(store, 0, idempotency.KeyFunc(func(req *http.Request) string {
+			return req.Header.Get("X-Tenant")
+		}))
+		req := newRequest("POST", `{"item":"wine"}`, "k1")
+		req.Header.Set("X-Tenant", "acme")
+		serve(req)
+		req = newRequest("POST", `{"item":"wine"}`, "k1")
+		req.Header.Set("X-Tenant", "acme")
+		serve(req)
+		req = newRequest("POST", `{"item":"wine"}`, "k1")
+		req.Header.Set("X-Tenant", "other")
+		serve(req)
+		Ω(handled).Should(Equal(2))
+	})
+
+	It("rejects keys reused with a different payload", func() {
+		serve(newRequest("POST", `{"item":"wine"}`, "k1"))
+		_, err := serve(newRequest("POST", `{"item":"beer"}`, "k1"))
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusUnprocessableEntity))
+		Ω(handled).Should(Equal(1))
+	})
+
+	It("rejects concurrent duplicates", func() {
+		var inner error
+		handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			handled++
+			_, inner = serve(newRequest("POST", `{"item":"wine"}`, "k1"))
+			goa.ContextResponse(ctx).WriteHeader(http.StatusCreated)
+			return nil
+		}
+		serve(newRequest("POST", `{"item":"wine"}`, "k1"))
+		Ω(handled).Should(Equal(1))
+		Ω(inner).Should(HaveOccurred())
+		Ω(inner.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusConflict))
+	})
+
+	It("does not record failed requests", func() {
+		handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			handled++
+			return errors.New("boom")
+		}
+		_, err := serve(newRequest("POST", `{}`, "k1"))
+		Ω(err).Should(HaveOccurred())
+		_, err = serve(newRequest("POST", `{}`, "k1"))
+		Ω(err).Should(HaveOccurred())
+		Ω(handled).Should(Equal(2))
+		Ω(store.Len()).Should(BeZero())
+	})
+
+	It("rejects keys that are too long", func() {
+		_, err := serve(newRequest("POST", `{}`, strings.Repeat("k", 256)))
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusBadRequest))
+		Ω(handled).Should(BeZero())
+	})
+})
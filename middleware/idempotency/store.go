@@ -0,0 +1,117 @@
+package idempotency
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	// Store persists the responses of the requests indexed by idempotency key. Implementations
+	// must be safe for concurrent use. Use a store shared by all the service instances (e.g.
+	// backed by a database) to make retries safe across a cluster.
+	Store interface {
+		// Lock reserves key for the request with the given fingerprint for at least ttl.
+		// It returns nil if the key was reserved or the entry recorded under key if the
+		// key is already in use. Lock must be atomic for a given key.
+		Lock(key, fingerprint string, ttl time.Duration) (*Entry, error)
+		// Save records the response of the request that reserved key for at least ttl.
+		Save(key string, resp *Response, ttl time.Duration) error
+		// Unlock releases a key reserved with Lock without recording a response so that
+		// the request may be retried.
+		Unlock(key string) error
+	}
+
+	// Entry is the state of an idempotency key.
+	Entry struct {
+		// Fingerprint identifies the method, path and payload of the request that
+		// reserved the key.
+		Fingerprint string
+		// Response is the recorded response, nil while the request is being handled.
+		Response *Response
+	}
+
+	// Response is a recorded HTTP response.
+	Response struct {
+		// Status is the response HTTP status code.
+		Status int
+		// Header contains the response headers.
+		Header http.Header
+		// Body is the response body.
+		Body []byte
+	}
+
+	// MemoryStore is a Store that keeps the entries in memory.
+	MemoryStore struct {
+		mu      sync.Mutex
+		entries map[string]*memoryEntry
+		sweep   time.Time
+	}
+
+	memoryEntry struct {
+		entry   Entry
+		expires time.Time
+	}
+)
+
+// sweepInterval is the minimum interval between two purges of the expired memory store entries.
+const sweepInterval = time.Minute
+
+// NewMemoryStore returns a store that keeps the entries in memory. Expired entries are purged
+// periodically as keys are reserved.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*memoryEntry)}
+}
+
+// Lock implements Store.
+func (s *MemoryStore) Lock(key, fingerprint string, ttl time.Duration) (*Entry, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.sweep) > sweepInterval {
+		for k, e := range s.entries {
+			if now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.sweep = now
+	}
+	if e, ok := s.entries[key]; ok && !now.After(e.expires) {
+		entry := e.entry
+		return &entry, nil
+	}
+	s.entries[key] = &memoryEntry{
+		entry:   Entry{Fingerprint: fingerprint},
+		expires: now.Add(ttl),
+	}
+	return nil, nil
+}
+
+// Save implements Store.
+func (s *MemoryStore) Save(key string, resp *Response, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		e = &memoryEntry{}
+		s.entries[key] = e
+	}
+	e.entry.Response = resp
+	e.expires = time.Now().Add(ttl)
+	return nil
+}
+
+// Unlock implements Store.
+func (s *MemoryStore) Unlock(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// Len returns the number of entries held by the store.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
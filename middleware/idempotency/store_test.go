@@ -0,0 +1,47 @@
+package idempotency_test
+
+import (
+	"time"
+
+	"github.com/goadesign/goa/middleware/idempotency"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MemoryStore", func() {
+	var store *idempotency.MemoryStore
+
+	BeforeEach(func() {
+		store = idempotency.NewMemoryStore()
+	})
+
+	It("reserves keys and records responses", func() {
+		e, err := store.Lock("foo", "fp", time.Minute)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(e).Should(BeNil())
+		e, err = store.Lock("foo", "fp", time.Minute)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(e.Fingerprint).Should(Equal("fp"))
+		Ω(e.Response).Should(BeNil())
+		err = store.Save("foo", &idempotency.Response{Status: 201}, time.Minute)
+		Ω(err).ShouldNot(HaveOccurred())
+		e, _ = store.Lock("foo", "fp", time.Minute)
+		Ω(e.Response.Status).Should(Equal(201))
+		Ω(store.Len()).Should(Equal(1))
+	})
+
+	It("releases unlocked keys", func() {
+		store.Lock("foo", "fp", time.Minute)
+		Ω(store.Unlock("foo")).ShouldNot(HaveOccurred())
+		e, _ := store.Lock("foo", "fp2", time.Minute)
+		Ω(e).Should(BeNil())
+	})
+
+	It("forgets expired entries", func() {
+		store.Lock("foo", "fp", time.Minute)
+		store.Save("foo", &idempotency.Response{Status: 201}, time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+		e, _ := store.Lock("foo", "fp", time.Minute)
+		Ω(e).Should(BeNil())
+	})
+})
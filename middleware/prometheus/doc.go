@@ -0,0 +1,18 @@
+/*
+Package prometheus records service metrics and exposes them using the Prometheus text exposition
+format so that they can be scraped by a Prometheus server. It does not depend on the Prometheus
+client libraries nor on a running Prometheus server.
+
+The metrics are held in a Registry. The middleware created with New records the number of
+requests, the request latency, the number of in-flight requests and the response sizes labeled
+with the controller, action and response status. The Sink type implements the go-metrics sink
+interface so that the metrics recorded with goa.IncrCounter, goa.MeasureSince etc. are also
+exposed. Example:
+
+	reg := prometheus.NewRegistry()
+	goa.SetMetrics(metrics.New(metrics.DefaultConfig("cellar"), prometheus.NewSink(reg)))
+	service.Use(prometheus.New(reg))
+	service.Mux.Handle("GET", "/metrics", reg.MuxHandler())
+
+*/
+package prometheus
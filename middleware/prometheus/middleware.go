@@ -0,0 +1,65 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+// Names of the metrics recorded by the middleware.
+const (
+	RequestsTotalMetric   = "goa_http_requests_total"
+	RequestDurationMetric = "goa_http_request_duration_seconds"
+	InFlightMetric        = "goa_http_requests_in_flight"
+	ResponseSizeMetric    = "goa_http_response_size_bytes"
+)
+
+// New returns a middleware that records the following metrics in reg:
+//
+//	goa_http_requests_total{controller,action,status}           counter
+//	goa_http_request_duration_seconds{controller,action,status} histogram
+//	goa_http_requests_in_flight{controller,action}              gauge
+//	goa_http_response_size_bytes{controller,action,status}      histogram
+//
+// The middleware should be mounted before the ErrorHandler middleware so that the status of the
+// error responses is known when the metrics are recorded. The status of errors that have not been
+// written is computed from the error itself.
+func New(reg *Registry) goa.Middleware {
+	requests := reg.Counter(RequestsTotalMetric, "Number of HTTP requests handled.", "controller", "action", "status")
+	duration := reg.Histogram(RequestDurationMetric, "Duration of the HTTP requests in seconds.", DefaultBuckets, "controller", "action", "status")
+	inFlight := reg.Gauge(InFlightMetric, "Number of HTTP requests being handled.", "controller", "action")
+	size := reg.Histogram(ResponseSizeMetric, "Size of the HTTP response bodies in bytes.", SizeBuckets, "controller", "action", "status")
+
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			ctrl, action := goa.ContextController(ctx), goa.ContextAction(ctx)
+			inFlight.Add(1, ctrl, action)
+			started := time.Now()
+
+			err := h(ctx, rw, req)
+
+			inFlight.Add(-1, ctrl, action)
+			var status, length int
+			if resp := goa.ContextResponse(ctx); resp != nil {
+				status, length = resp.Status, resp.Length
+			}
+			if status == 0 {
+				status = http.StatusOK
+				if err != nil {
+					status = http.StatusInternalServerError
+					if serr, ok := err.(goa.ServiceError); ok {
+						status = serr.ResponseStatus()
+					}
+				}
+			}
+			code := strconv.Itoa(status)
+			requests.Inc(ctrl, action, code)
+			duration.Observe(time.Since(started).Seconds(), ctrl, action, code)
+			size.Observe(float64(length), ctrl, action, code)
+			return err
+		}
+	}
+}
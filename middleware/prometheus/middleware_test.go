@@ -0,0 +1,57 @@
+package prometheus_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/prometheus"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var reg *prometheus.Registry
+	var ctx context.Context
+	var rw *httptest.ResponseRecorder
+	var req *http.Request
+
+	BeforeEach(func() {
+		reg = prometheus.NewRegistry()
+		rw = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/bottles", nil)
+		ctrl := goa.New("test").NewController("BottleController")
+		ctx = goa.NewContext(goa.WithAction(ctrl.Context, "list"), rw, req, nil)
+	})
+
+	It("records the request metrics", func() {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			resp := goa.ContextResponse(ctx)
+			resp.WriteHeader(http.StatusCreated)
+			resp.Write([]byte("hello"))
+			return nil
+		}
+		err := prometheus.New(reg)(h)(ctx, rw, req)
+		Ω(err).ShouldNot(HaveOccurred())
+		var buf bytes.Buffer
+		reg.WriteTo(&buf)
+		out := buf.String()
+		Ω(out).Should(ContainSubstring(`goa_http_requests_total{controller="BottleController",action="list",status="201"} 1`))
+		Ω(out).Should(ContainSubstring(`goa_http_request_duration_seconds_count{controller="BottleController",action="list",status="201"} 1`))
+		Ω(out).Should(ContainSubstring(`goa_http_requests_in_flight{controller="BottleController",action="list"} 0`))
+		Ω(out).Should(ContainSubstring(`goa_http_response_size_bytes_sum{controller="BottleController",action="list",status="201"} 5`))
+	})
+
+	It("uses the status of unhandled errors", func() {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return goa.ErrNotFound("not found")
+		}
+		err := prometheus.New(reg)(h)(ctx, rw, req)
+		Ω(err).Should(HaveOccurred())
+		var buf bytes.Buffer
+		reg.WriteTo(&buf)
+		Ω(buf.String()).Should(ContainSubstring(`goa_http_requests_total{controller="BottleController",action="list",status="404"} 1`))
+	})
+})
@@ -0,0 +1,341 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goadesign/goa"
+)
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	// DefaultBuckets are the default histogram buckets, suitable to measure latencies in
+	// seconds.
+	DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+	// SizeBuckets are histogram buckets suitable to measure sizes in bytes.
+	SizeBuckets = []float64{100, 1000, 10000, 100000, 1e6, 1e7, 1e8}
+)
+
+type (
+	// Registry holds metric families and renders them using the Prometheus text exposition
+	// format. It is safe for concurrent use.
+	Registry struct {
+		mu       sync.Mutex
+		families map[string]*family
+	}
+
+	// CounterVec is a counter partitioned by label values.
+	CounterVec struct {
+		r *Registry
+		f *family
+	}
+
+	// GaugeVec is a gauge partitioned by label values.
+	GaugeVec struct {
+		r *Registry
+		f *family
+	}
+
+	// HistogramVec is a histogram partitioned by label values.
+	HistogramVec struct {
+		r *Registry
+		f *family
+	}
+
+	// SummaryVec is a summary that tracks the count and sum of observations partitioned by
+	// label values. It does not compute quantiles.
+	SummaryVec struct {
+		r *Registry
+		f *family
+	}
+
+	// family is a metric family.
+	family struct {
+		name    string
+		help    string
+		typ     string
+		labels  []string
+		buckets []float64
+		series  map[string]*series
+	}
+
+	// series holds the values of a metric family for a given set of label values.
+	series struct {
+		labelValues []string
+		value       float64
+		count       uint64
+		sum         float64
+		counts      []uint64
+	}
+)
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// Counter returns the counter with the given name and label names, creating it if needed.
+// Counter panics if a metric with the same name but a different type or label names exists.
+func (r *Registry) Counter(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{r: r, f: r.family(name, help, "counter", labels, nil)}
+}
+
+// Gauge returns the gauge with the given name and label names, creating it if needed. Gauge
+// panics if a metric with the same name but a different type or label names exists.
+func (r *Registry) Gauge(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{r: r, f: r.family(name, help, "gauge", labels, nil)}
+}
+
+// Histogram returns the histogram with the given name, buckets and label names, creating it if
+// needed. DefaultBuckets are used if buckets is empty. Histogram panics if a metric with the
+// same name but a different type or label names exists.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	b := make([]float64, len(buckets))
+	copy(b, buckets)
+	sort.Float64s(b)
+	return &HistogramVec{r: r, f: r.family(name, help, "histogram", labels, b)}
+}
+
+// Summary returns the summary with the given name and label names, creating it if needed.
+// Summary panics if a metric with the same name but a different type or label names exists.
+func (r *Registry) Summary(name, help string, labels ...string) *SummaryVec {
+	return &SummaryVec{r: r, f: r.family(name, help, "summary", labels, nil)}
+}
+
+// Add adds v to the counter identified by the label values. v must not be negative.
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic("prometheus: counters cannot decrease") // bug
+	}
+	c.r.update(c.f, labelValues, func(s *series) { s.value += v })
+}
+
+// Inc increments the counter identified by the label values.
+func (c *CounterVec) Inc(labelValues ...string) { c.Add(1, labelValues...) }
+
+// Set sets the value of the gauge identified by the label values.
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	g.r.update(g.f, labelValues, func(s *series) { s.value = v })
+}
+
+// Add adds v to the gauge identified by the label values, v may be negative.
+func (g *GaugeVec) Add(v float64, labelValues ...string) {
+	g.r.update(g.f, labelValues, func(s *series) { s.value += v })
+}
+
+// Observe records an observation in the histogram identified by the label values.
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	h.r.update(h.f, labelValues, func(s *series) {
+		if s.counts == nil {
+			s.counts = make([]uint64, len(h.f.buckets))
+		}
+		for i, b := range h.f.buckets {
+			if v <= b {
+				s.counts[i]++
+			}
+		}
+		s.count++
+		s.sum += v
+	})
+}
+
+// Observe records an observation in the summary identified by the label values.
+func (s *SummaryVec) Observe(v float64, labelValues ...string) {
+	s.r.update(s.f, labelValues, func(s *series) {
+		s.count++
+		s.sum += v
+	})
+}
+
+// WriteTo writes the metrics to w using the Prometheus text exposition format. The metric
+// families are sorted by name and the series by label values.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	r.mu.Lock()
+	names := make([]string, 0, len(r.families))
+	for n := range r.families {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		r.families[n].write(&buf)
+	}
+	r.mu.Unlock()
+	return buf.WriteTo(w)
+}
+
+// ServeHTTP writes the metrics in the response using the Prometheus text exposition format.
+func (r *Registry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", ContentType)
+	r.WriteTo(rw)
+}
+
+// MuxHandler returns a handler that serves the metrics. Mount it with e.g.:
+//
+//	service.Mux.Handle("GET", "/metrics", reg.MuxHandler())
+//
+func (r *Registry) MuxHandler() goa.MuxHandler {
+	return func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+		r.ServeHTTP(rw, req)
+	}
+}
+
+// family returns the metric family with the given name creating it if needed.
+func (r *Registry) family(name, help, typ string, labels []string, buckets []float64) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok := r.lookup(name, help, typ, labels, buckets)
+	if !ok {
+		panic(fmt.Sprintf("prometheus: metric %s already registered with a different type or labels", name)) // bug
+	}
+	return f
+}
+
+// sinkFamily returns the unlabeled metric family used by the sink to record the metric with the
+// given name and type. The type is appended to the name if a metric with the same name but a
+// different type exists. sinkFamily returns nil if both names are taken.
+func (r *Registry) sinkFamily(name, typ string) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f, ok := r.lookup(name, "", typ, nil, nil); ok {
+		return f
+	}
+	f, _ := r.lookup(name+"_"+typ, "", typ, nil, nil)
+	return f
+}
+
+// lookup returns the metric family with the given name creating it if needed. It returns false
+// if a metric with the same name but a different type or label names exists. The registry lock
+// must be held.
+func (r *Registry) lookup(name, help, typ string, labels []string, buckets []float64) (*family, bool) {
+	if f, ok := r.families[name]; ok {
+		if f.typ != typ || strings.Join(f.labels, ",") != strings.Join(labels, ",") {
+			return nil, false
+		}
+		return f, true
+	}
+	f := &family{
+		name:    name,
+		help:    help,
+		typ:     typ,
+		labels:  labels,
+		buckets: buckets,
+		series:  make(map[string]*series),
+	}
+	r.families[name] = f
+	return f, true
+}
+
+// update calls fn with the series of f identified by the label values creating it if needed.
+func (r *Registry) update(f *family, labelValues []string, fn func(*series)) {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("prometheus: metric %s expects %d label values, got %d", f.name, len(f.labels), len(labelValues))) // bug
+	}
+	key := strings.Join(labelValues, "\xff")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		f.series[key] = s
+	}
+	fn(s)
+}
+
+// write writes the family using the Prometheus text exposition format.
+func (f *family) write(buf *bytes.Buffer) {
+	if len(f.series) == 0 {
+		return
+	}
+	if f.help != "" {
+		fmt.Fprintf(buf, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	}
+	fmt.Fprintf(buf, "# TYPE %s %s\n", f.name, f.typ)
+	keys := make([]string, 0, len(f.series))
+	for k := range f.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := f.series[k]
+		labels := f.labelPairs(s.labelValues)
+		switch f.typ {
+		case "histogram":
+			for i, b := range f.buckets {
+				writeSample(buf, f.name+"_bucket", joinLabels(labels, `le="`+formatFloat(b)+`"`), float64(s.counts[i]))
+			}
+			writeSample(buf, f.name+"_bucket", joinLabels(labels, `le="+Inf"`), float64(s.count))
+			writeSample(buf, f.name+"_sum", labels, s.sum)
+			writeSample(buf, f.name+"_count", labels, float64(s.count))
+		case "summary":
+			writeSample(buf, f.name+"_sum", labels, s.sum)
+			writeSample(buf, f.name+"_count", labels, float64(s.count))
+		default:
+			writeSample(buf, f.name, labels, s.value)
+		}
+	}
+}
+
+// labelPairs renders the label name and value pairs.
+func (f *family) labelPairs(values []string) string {
+	pairs := make([]string, len(f.labels))
+	for i, l := range f.labels {
+		pairs[i] = l + `="` + escapeLabel(values[i]) + `"`
+	}
+	return strings.Join(pairs, ",")
+}
+
+// joinLabels appends the extra label pair to the rendered label pairs.
+func joinLabels(labels, extra string) string {
+	if labels == "" {
+		return extra
+	}
+	return labels + "," + extra
+}
+
+// writeSample writes a single sample line.
+func writeSample(buf *bytes.Buffer, name, labels string, v float64) {
+	buf.WriteString(name)
+	if labels != "" {
+		buf.WriteString("{" + labels + "}")
+	}
+	buf.WriteString(" " + formatFloat(v) + "\n")
+}
+
+// formatFloat formats a sample value.
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// escapeHelp escapes the backslashes and new lines of help strings.
+func escapeHelp(s string) string { return helpReplacer.Replace(s) }
+
+// escapeLabel escapes the backslashes, double quotes and new lines of label values.
+func escapeLabel(s string) string { return labelReplacer.Replace(s) }
@@ -0,0 +1,70 @@
+package prometheus_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa/middleware/prometheus"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registry", func() {
+	var reg *prometheus.Registry
+
+	BeforeEach(func() {
+		reg = prometheus.NewRegistry()
+	})
+
+	It("renders counters and gauges", func() {
+		reg.Counter("requests_total", "Number of requests.", "method").Add(2, "GET")
+		reg.Gauge("temperature", "").Set(21.5)
+		var buf bytes.Buffer
+		_, err := reg.WriteTo(&buf)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(buf.String()).Should(Equal(`# HELP requests_total Number of requests.
+# TYPE requests_total counter
+requests_total{method="GET"} 2
+# TYPE temperature gauge
+temperature 21.5
+`))
+	})
+
+	It("renders histograms", func() {
+		h := reg.Histogram("latency", "", []float64{1, 2})
+		h.Observe(0.5)
+		h.Observe(1.5)
+		h.Observe(3)
+		var buf bytes.Buffer
+		reg.WriteTo(&buf)
+		Ω(buf.String()).Should(Equal(`# TYPE latency histogram
+latency_bucket{le="1"} 1
+latency_bucket{le="2"} 2
+latency_bucket{le="+Inf"} 3
+latency_sum 5
+latency_count 3
+`))
+	})
+
+	It("escapes label values", func() {
+		reg.Counter("c", "", "l").Inc("a\"b\\c\nd")
+		var buf bytes.Buffer
+		reg.WriteTo(&buf)
+		Ω(buf.String()).Should(ContainSubstring(`c{l="a\"b\\c\nd"} 1`))
+	})
+
+	It("panics on conflicting registrations", func() {
+		reg.Counter("c", "")
+		Ω(func() { reg.Gauge("c", "") }).Should(Panic())
+	})
+
+	It("serves the metrics", func() {
+		reg.Counter("c", "").Inc()
+		rw := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/metrics", nil)
+		reg.MuxHandler()(rw, req, nil)
+		Ω(rw.Header().Get("Content-Type")).Should(Equal(prometheus.ContentType))
+		Ω(rw.Body.String()).Should(Equal("# TYPE c counter\nc 1\n"))
+	})
+})
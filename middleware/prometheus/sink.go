@@ -0,0 +1,66 @@
+package prometheus
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// invalidNameCharsRE matches the characters that are not allowed in Prometheus metric names.
+var invalidNameCharsRE = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+// Sink is a go-metrics sink that records the metrics in a registry. Counters are exposed as
+// Prometheus counters, gauges and emitted keys as gauges and samples as summaries. The metric
+// name is the key elements joined with underscores. The metric type is appended to the name of
+// a metric whose key is already used by a metric of a different type, e.g. recording a sample
+// with the key of an existing counter "hits" produces the summary "hits_summary".
+type Sink struct {
+	reg *Registry
+}
+
+// NewSink returns a go-metrics sink that records the metrics in reg.
+func NewSink(reg *Registry) *Sink {
+	return &Sink{reg: reg}
+}
+
+// SetGauge implements metrics.MetricSink.
+func (s *Sink) SetGauge(key []string, val float32) {
+	s.record(key, "gauge", func(f *family) { (&GaugeVec{r: s.reg, f: f}).Set(float64(val)) })
+}
+
+// EmitKey implements metrics.MetricSink.
+func (s *Sink) EmitKey(key []string, val float32) {
+	s.SetGauge(key, val)
+}
+
+// IncrCounter implements metrics.MetricSink.
+func (s *Sink) IncrCounter(key []string, val float32) {
+	s.record(key, "counter", func(f *family) { (&CounterVec{r: s.reg, f: f}).Add(float64(val)) })
+}
+
+// AddSample implements metrics.MetricSink.
+func (s *Sink) AddSample(key []string, val float32) {
+	s.record(key, "summary", func(f *family) { (&SummaryVec{r: s.reg, f: f}).Observe(float64(val)) })
+}
+
+// MeasureSince records the duration since start in milliseconds as a sample.
+func (s *Sink) MeasureSince(key []string, start time.Time) {
+	s.AddSample(key, float32(time.Since(start).Seconds()*1000))
+}
+
+// record calls fn with the family of the metric with the given key and type. The value is
+// dropped if no family can be registered for the metric.
+func (s *Sink) record(key []string, typ string, fn func(*family)) {
+	if f := s.reg.sinkFamily(metricName(key), typ); f != nil {
+		fn(f)
+	}
+}
+
+// metricName computes a valid Prometheus metric name from a go-metrics key.
+func metricName(key []string) string {
+	name := invalidNameCharsRE.ReplaceAllString(strings.Join(key, "_"), "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
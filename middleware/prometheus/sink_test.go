@@ -0,0 +1,52 @@
+package prometheus_test
+
+import (
+	"bytes"
+
+	"github.com/goadesign/goa/middleware/prometheus"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sink", func() {
+	var reg *prometheus.Registry
+	var sink *prometheus.Sink
+
+	BeforeEach(func() {
+		reg = prometheus.NewRegistry()
+		sink = prometheus.NewSink(reg)
+	})
+
+	It("records go-metrics metrics", func() {
+		sink.IncrCounter([]string{"service", "hits"}, 2)
+		sink.SetGauge([]string{"service", "queue.size"}, 3)
+		sink.AddSample([]string{"service", "latency"}, 4)
+		var buf bytes.Buffer
+		reg.WriteTo(&buf)
+		Ω(buf.String()).Should(Equal(`# TYPE service_hits counter
+service_hits 2
+# TYPE service_latency summary
+service_latency_sum 4
+service_latency_count 1
+# TYPE service_queue_size gauge
+service_queue_size 3
+`))
+	})
+
+	It("records metrics of different types using the same key", func() {
+		sink.IncrCounter([]string{"hits"}, 1)
+		sink.AddSample([]string{"hits"}, 2)
+		sink.SetGauge([]string{"hits"}, 3)
+		sink.IncrCounter([]string{"hits"}, 1)
+		var buf bytes.Buffer
+		reg.WriteTo(&buf)
+		Ω(buf.String()).Should(Equal(`# TYPE hits counter
+hits 2
+# TYPE hits_gauge gauge
+hits_gauge 3
+# TYPE hits_summary summary
+hits_summary_sum 2
+hits_summary_count 1
+`))
+	})
+})
@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// TraceFormat identifies a trace context propagation format.
+type TraceFormat int
+
+const (
+	// GoaTraceFormat propagates the trace context via the TraceIDHeader and
+	// ParentSpanIDHeader headers.
+	GoaTraceFormat TraceFormat = iota
+	// W3CTraceFormat propagates the trace context via the W3C Trace Context
+	// traceparent and tracestate headers, see https://www.w3.org/TR/trace-context/.
+	W3CTraceFormat
+	// B3TraceFormat propagates the trace context via the Zipkin B3 X-B3-* headers.
+	B3TraceFormat
+	// B3SingleTraceFormat propagates the trace context via the Zipkin B3 single
+	// b3 header.
+	B3SingleTraceFormat
+)
+
+const (
+	traceparentHeader  = "traceparent"
+	tracestateHeader   = "tracestate"
+	b3TraceIDHeader    = "X-B3-TraceId"
+	b3SpanIDHeader     = "X-B3-SpanId"
+	b3ParentSpanHeader = "X-B3-ParentSpanId"
+	b3SampledHeader    = "X-B3-Sampled"
+	b3FlagsHeader      = "X-B3-Flags"
+	b3SingleHeader     = "b3"
+)
+
+// traceContext is the trace context extracted from request headers.
+type traceContext struct {
+	traceID  string
+	parentID string
+	state    string
+	sampled  bool
+}
+
+// String returns the name of the format.
+func (f TraceFormat) String() string {
+	switch f {
+	case GoaTraceFormat:
+		return "goa"
+	case W3CTraceFormat:
+		return "w3c"
+	case B3TraceFormat:
+		return "b3"
+	case B3SingleTraceFormat:
+		return "b3-single"
+	}
+	return "unknown"
+}
+
+// extract reads the trace context from the request headers. It returns nil if the
+// headers do not contain a valid trace context. The returned context has an empty
+// trace ID if the headers only indicate that the request must not be traced.
+func (f TraceFormat) extract(h http.Header) *traceContext {
+	switch f {
+	case GoaTraceFormat:
+		if traceID := h.Get(TraceIDHeader); traceID != "" {
+			return &traceContext{traceID: traceID, parentID: h.Get(ParentSpanIDHeader), sampled: true}
+		}
+	case W3CTraceFormat:
+		parts := strings.Split(strings.TrimSpace(h.Get(traceparentHeader)), "-")
+		if len(parts) < 4 || !isHex(parts[0], 2) || parts[0] == "ff" || parts[0] == "00" && len(parts) != 4 {
+			return nil
+		}
+		if !isHex(parts[1], 32) || isZero(parts[1]) || !isHex(parts[2], 16) || isZero(parts[2]) || !isHex(parts[3], 2) {
+			return nil
+		}
+		flags, _ := hex.DecodeString(parts[3])
+		return &traceContext{
+			traceID:  parts[1],
+			parentID: parts[2],
+			state:    strings.Join(h[http.CanonicalHeaderKey(tracestateHeader)], ","),
+			sampled:  flags[0]&1 == 1,
+		}
+	case B3TraceFormat:
+		s := h.Get(b3SampledHeader)
+		sampled := s != "0" && s != "false" || h.Get(b3FlagsHeader) == "1"
+		traceID, spanID := h.Get(b3TraceIDHeader), h.Get(b3SpanIDHeader)
+		if !isB3TraceID(traceID) || !isHex(spanID, 16) {
+			if !sampled {
+				return &traceContext{}
+			}
+			return nil
+		}
+		return &traceContext{traceID: traceID, parentID: spanID, sampled: sampled}
+	case B3SingleTraceFormat:
+		parts := strings.Split(strings.TrimSpace(h.Get(b3SingleHeader)), "-")
+		if len(parts) == 1 && parts[0] == "0" {
+			return &traceContext{}
+		}
+		if len(parts) < 2 || !isB3TraceID(parts[0]) || !isHex(parts[1], 16) {
+			return nil
+		}
+		return &traceContext{
+			traceID:  parts[0],
+			parentID: parts[1],
+			sampled:  len(parts) < 3 || parts[2] != "0",
+		}
+	}
+	return nil
+}
+
+// inject writes the trace context to the request headers so that the downstream
+// service uses spanID as parent span ID. Formats that cannot represent the IDs are
+// skipped.
+func (f TraceFormat) inject(h http.Header, traceID, spanID, state string) {
+	switch f {
+	case GoaTraceFormat:
+		h.Set(TraceIDHeader, traceID)
+		h.Set(ParentSpanIDHeader, spanID)
+	case W3CTraceFormat:
+		traceID = w3cTraceID(traceID)
+		if traceID == "" || !isHex(spanID, 16) {
+			return
+		}
+		h.Set(traceparentHeader, "00-"+traceID+"-"+spanID+"-01")
+		if state != "" {
+			h.Set(tracestateHeader, state)
+		}
+	case B3TraceFormat:
+		if !isB3TraceID(traceID) || !isHex(spanID, 16) {
+			return
+		}
+		h.Set(b3TraceIDHeader, traceID)
+		h.Set(b3SpanIDHeader, spanID)
+		h.Set(b3SampledHeader, "1")
+	case B3SingleTraceFormat:
+		if !isB3TraceID(traceID) || !isHex(spanID, 16) {
+			return
+		}
+		h.Set(b3SingleHeader, traceID+"-"+spanID+"-1")
+	}
+}
+
+// w3cTraceID returns the W3C representation of the given trace ID. AWS X-Ray trace
+// IDs (e.g. 1-5759e988-bd862e3fe1be46a994272793) are converted, the empty string is
+// returned if the ID cannot be represented.
+func w3cTraceID(traceID string) string {
+	if isHex(traceID, 32) {
+		return traceID
+	}
+	parts := strings.Split(traceID, "-")
+	if len(parts) == 3 && parts[0] == "1" && isHex(parts[1], 8) && isHex(parts[2], 24) {
+		return parts[1] + parts[2]
+	}
+	return ""
+}
+
+// isB3TraceID returns true if id is a valid 64 or 128-bit B3 trace ID.
+func isB3TraceID(id string) bool {
+	return isHex(id, 16) || isHex(id, 32)
+}
+
+// isHex returns true if s is made of n lowercase hexadecimal digits.
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// isZero returns true if s is only made of zeros.
+func isZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
+
+// hexID returns a random identifier made of n bytes encoded in hexadecimal.
+func hexID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// HexTraceID produces 128-bit trace IDs encoded in hexadecimal that are compatible
+// with the W3C Trace Context and B3 formats.
+func HexTraceID() string { return hexID(16) }
+
+// HexSpanID produces 64-bit span IDs encoded in hexadecimal that are compatible
+// with the W3C Trace Context and B3 formats.
+func HexSpanID() string { return hexID(8) }
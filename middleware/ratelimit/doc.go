@@ -0,0 +1,22 @@
+/*
+Package ratelimit contains a middleware that limits the rate of requests made to a goa service.
+
+Requests are identified by a key computed by a KeyFunc, for example the client IP address, the
+value of an API key header or the subject of the JWT validated by the jwt security middleware. The
+number of requests allowed for each key is controlled by a Limiter. The package provides two
+limiter implementations: a token bucket (NewTokenBucket) that allows bursts and a sliding window
+counter (NewSlidingWindow) that smooths the count of requests over a fixed window. Limiters keep
+their state in a Store, the default store keeps the state in memory and may be replaced with a
+store shared by multiple service instances.
+
+The middleware sets the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset response headers
+and responds with status 429 (Too Many Requests) and a Retry-After header when the limit is
+exceeded. Example:
+
+	limiter := ratelimit.NewTokenBucket(100, time.Minute, 20, nil)
+	service.Use(ratelimit.New(limiter, ratelimit.IPKey()))
+
+The RateLimit design DSL may be used to document the limits in the generated Swagger
+specification.
+*/
+package ratelimit
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa/middleware/security/jwt"
+)
+
+// KeyFunc computes the key that identifies the client making a request. Requests with the same
+// key share the same limit. Requests for which the key is empty are not limited.
+type KeyFunc func(ctx context.Context, req *http.Request) string
+
+// IPKey returns a key func that identifies clients by the IP address of the request remote
+// address.
+func IPKey() KeyFunc {
+	return func(ctx context.Context, req *http.Request) string {
+		host, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			host = req.RemoteAddr
+		}
+		if host == "" {
+			return ""
+		}
+		return "ip:" + host
+	}
+}
+
+// ForwardedIPKey returns a key func that identifies clients by the first IP address listed in
+// the X-Forwarded-For header or by the X-Real-IP header. It falls back to the request remote
+// address if neither header is set. Only use it behind a proxy that sets these headers as they
+// are under the control of the clients otherwise.
+func ForwardedIPKey() KeyFunc {
+	ipKey := IPKey()
+	return func(ctx context.Context, req *http.Request) string {
+		if fwd := req.Header.Get("X-Forwarded-For"); fwd != "" {
+			if ip := strings.TrimSpace(strings.Split(fwd, ",")[0]); ip != "" {
+				return "ip:" + ip
+			}
+		}
+		if ip := strings.TrimSpace(req.Header.Get("X-Real-IP")); ip != "" {
+			return "ip:" + ip
+		}
+		return ipKey(ctx, req)
+	}
+}
+
+// HeaderKey returns a key func that identifies clients by the value of the given request header,
+// typically the header containing an API key.
+func HeaderKey(name string) KeyFunc {
+	prefix := "header:" + strings.ToLower(name) + ":"
+	return func(ctx context.Context, req *http.Request) string {
+		if val := req.Header.Get(name); val != "" {
+			return prefix + val
+		}
+		return ""
+	}
+}
+
+// JWTSubjectKey returns a key func that identifies clients by the "sub" claim of the JWT
+// validated by the jwt security middleware, see jwt.ContextJWT. The rate limit middleware must
+// be mounted after the jwt middleware for the token to be available.
+func JWTSubjectKey() KeyFunc {
+	return func(ctx context.Context, req *http.Request) string {
+		token := jwt.ContextJWT(ctx)
+		if token == nil {
+			return ""
+		}
+		var sub string
+		switch claims := token.Claims.(type) {
+		case jwtgo.MapClaims:
+			sub, _ = claims["sub"].(string)
+		case *jwtgo.StandardClaims:
+			sub = claims.Subject
+		}
+		if sub == "" {
+			return ""
+		}
+		return "jwt:" + sub
+	}
+}
+
+// FirstKey returns a key func that returns the first non-empty key computed by the given key
+// funcs. It makes it possible to limit authenticated and anonymous clients differently, e.g.:
+//
+//	ratelimit.FirstKey(ratelimit.JWTSubjectKey(), ratelimit.IPKey())
+//
+func FirstKey(funcs ...KeyFunc) KeyFunc {
+	return func(ctx context.Context, req *http.Request) string {
+		for _, f := range funcs {
+			if key := f(ctx, req); key != "" {
+				return key
+			}
+		}
+		return ""
+	}
+}
@@ -0,0 +1,49 @@
+package ratelimit_test
+
+import (
+	"context"
+	"net/http"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa/middleware/ratelimit"
+	"github.com/goadesign/goa/middleware/security/jwt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("KeyFunc", func() {
+	var req *http.Request
+	var ctx context.Context
+
+	BeforeEach(func() {
+		req, _ = http.NewRequest("GET", "/foo", nil)
+		req.RemoteAddr = "10.0.0.1:4242"
+		ctx = context.Background()
+	})
+
+	It("identifies clients by IP", func() {
+		Ω(ratelimit.IPKey()(ctx, req)).Should(Equal("ip:10.0.0.1"))
+	})
+
+	It("identifies clients by forwarded IP", func() {
+		req.Header.Set("X-Forwarded-For", "192.168.1.1, 10.0.0.2")
+		Ω(ratelimit.ForwardedIPKey()(ctx, req)).Should(Equal("ip:192.168.1.1"))
+	})
+
+	It("identifies clients by header", func() {
+		req.Header.Set("X-Api-Key", "secret")
+		Ω(ratelimit.HeaderKey("X-Api-Key")(ctx, req)).Should(Equal("header:x-api-key:secret"))
+	})
+
+	It("identifies clients by JWT subject", func() {
+		key := ratelimit.JWTSubjectKey()
+		Ω(key(ctx, req)).Should(BeEmpty())
+		ctx = jwt.WithJWT(ctx, &jwtgo.Token{Claims: jwtgo.MapClaims{"sub": "foo"}})
+		Ω(key(ctx, req)).Should(Equal("jwt:foo"))
+	})
+
+	It("falls back to the next key func", func() {
+		key := ratelimit.FirstKey(ratelimit.JWTSubjectKey(), ratelimit.IPKey())
+		Ω(key(ctx, req)).Should(Equal("ip:10.0.0.1"))
+	})
+})
@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+type (
+	// Limiter decides whether the request identified by a key may proceed.
+	Limiter interface {
+		// Allow records a request for key and returns whether it is allowed.
+		Allow(key string) (*Result, error)
+	}
+
+	// Result is the outcome of a rate limit check.
+	Result struct {
+		// Allowed is true if the request may proceed.
+		Allowed bool
+		// Limit is the maximum number of requests allowed by the limiter.
+		Limit int
+		// Remaining is the number of requests that may still be made.
+		Remaining int
+		// Reset is the duration until the limit is fully reset.
+		Reset time.Duration
+		// RetryAfter is the duration after which a denied request may be retried.
+		RetryAfter time.Duration
+	}
+
+	// tokenBucket implements the token bucket algorithm.
+	tokenBucket struct {
+		store    Store
+		capacity float64
+		rate     float64 // tokens per second
+	}
+
+	// slidingWindow implements the sliding window counter algorithm.
+	slidingWindow struct {
+		store  Store
+		limit  float64
+		window time.Duration
+	}
+)
+
+// NewTokenBucket returns a limiter that implements the token bucket algorithm. Each key gets a
+// bucket holding up to burst tokens that is refilled at the rate of requests tokens per period.
+// A request consumes one token and is denied if the bucket is empty. burst defaults to requests
+// if zero. The state is kept in store, a memory store is used if store is nil.
+func NewTokenBucket(requests int, period time.Duration, burst int, store Store) Limiter {
+	if burst <= 0 {
+		burst = requests
+	}
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &tokenBucket{
+		store:    store,
+		capacity: float64(burst),
+		rate:     float64(requests) / period.Seconds(),
+	}
+}
+
+// NewSlidingWindow returns a limiter that allows up to requests requests per window. The count of
+// requests is estimated by weighting the count of the previous window by the portion of the
+// window that overlaps with the current sliding window. The state is kept in store, a memory
+// store is used if store is nil.
+func NewSlidingWindow(requests int, window time.Duration, store Store) Limiter {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &slidingWindow{store: store, limit: float64(requests), window: window}
+}
+
+// Allow implements Limiter.
+func (l *tokenBucket) Allow(key string) (*Result, error) {
+	now := time.Now()
+	res := &Result{Limit: int(l.capacity)}
+	ttl := seconds(l.capacity / l.rate)
+	err := l.store.Update(key, ttl, func(s *State) {
+		tokens := l.capacity
+		if !s.Timestamp.IsZero() {
+			tokens = math.Min(l.capacity, s.Value+now.Sub(s.Timestamp).Seconds()*l.rate)
+		}
+		if tokens >= 1 {
+			tokens--
+			res.Allowed = true
+		} else {
+			res.RetryAfter = seconds((1 - tokens) / l.rate)
+		}
+		s.Value = tokens
+		s.Timestamp = now
+		res.Remaining = int(tokens)
+		res.Reset = seconds((l.capacity - tokens) / l.rate)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Allow implements Limiter.
+func (l *slidingWindow) Allow(key string) (*Result, error) {
+	now := time.Now()
+	res := &Result{Limit: int(l.limit)}
+	err := l.store.Update(key, 2*l.window, func(s *State) {
+		start := now.Truncate(l.window)
+		switch {
+		case s.Timestamp.IsZero() || !start.Before(s.Timestamp.Add(2*l.window)):
+			s.Previous, s.Value = 0, 0
+		case !start.Before(s.Timestamp.Add(l.window)):
+			s.Previous, s.Value = s.Value, 0
+		}
+		s.Timestamp = start
+		elapsed := now.Sub(start)
+		weight := 1 - float64(elapsed)/float64(l.window)
+		count := s.Previous*weight + s.Value
+		res.Reset = l.window - elapsed
+		if count+1 > l.limit {
+			res.RetryAfter = res.Reset
+			if s.Previous > 0 && s.Value+1 <= l.limit {
+				// Wait for the weight of the previous window to decrease enough.
+				w := (l.limit - 1 - s.Value) / s.Previous
+				res.RetryAfter = time.Duration((1-w)*float64(l.window)) - elapsed
+			}
+			return
+		}
+		s.Value++
+		res.Allowed = true
+		res.Remaining = int(l.limit - count - 1)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// seconds converts a number of seconds into a duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,86 @@
+package ratelimit_test
+
+import (
+	"time"
+
+	"github.com/goadesign/goa/middleware/ratelimit"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewTokenBucket", func() {
+	var limiter ratelimit.Limiter
+
+	BeforeEach(func() {
+		limiter = ratelimit.NewTokenBucket(10, time.Hour, 2, nil)
+	})
+
+	It("allows bursts up to the bucket capacity", func() {
+		res, err := limiter.Allow("foo")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(res.Allowed).Should(BeTrue())
+		Ω(res.Limit).Should(Equal(2))
+		Ω(res.Remaining).Should(Equal(1))
+
+		res, err = limiter.Allow("foo")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(res.Allowed).Should(BeTrue())
+		Ω(res.Remaining).Should(Equal(0))
+
+		res, err = limiter.Allow("foo")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(res.Allowed).Should(BeFalse())
+		Ω(res.RetryAfter).Should(BeNumerically("~", 6*time.Minute, time.Second))
+	})
+
+	It("limits each key separately", func() {
+		limiter.Allow("foo")
+		limiter.Allow("foo")
+		res, err := limiter.Allow("bar")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(res.Allowed).Should(BeTrue())
+	})
+
+	Context("with a fast refill rate", func() {
+		BeforeEach(func() {
+			limiter = ratelimit.NewTokenBucket(1, 10*time.Millisecond, 1, nil)
+		})
+
+		It("refills the bucket", func() {
+			res, _ := limiter.Allow("foo")
+			Ω(res.Allowed).Should(BeTrue())
+			res, _ = limiter.Allow("foo")
+			Ω(res.Allowed).Should(BeFalse())
+			time.Sleep(20 * time.Millisecond)
+			res, _ = limiter.Allow("foo")
+			Ω(res.Allowed).Should(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("NewSlidingWindow", func() {
+	var limiter ratelimit.Limiter
+
+	BeforeEach(func() {
+		limiter = ratelimit.NewSlidingWindow(2, time.Hour, nil)
+	})
+
+	It("allows up to the limit per window", func() {
+		res, err := limiter.Allow("foo")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(res.Allowed).Should(BeTrue())
+		Ω(res.Limit).Should(Equal(2))
+		Ω(res.Remaining).Should(Equal(1))
+
+		res, err = limiter.Allow("foo")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(res.Allowed).Should(BeTrue())
+		Ω(res.Remaining).Should(Equal(0))
+
+		res, err = limiter.Allow("foo")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(res.Allowed).Should(BeFalse())
+		Ω(res.RetryAfter).Should(BeNumerically(">", 0))
+		Ω(res.RetryAfter).Should(BeNumerically("<=", time.Hour))
+	})
+})
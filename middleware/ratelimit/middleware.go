@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+const (
+	headerLimit      = "RateLimit-Limit"
+	headerRemaining  = "RateLimit-Remaining"
+	headerReset      = "RateLimit-Reset"
+	headerRetryAfter = "Retry-After"
+)
+
+// ErrRateLimited is the error returned by the middleware when a client exceeds the rate limit.
+var ErrRateLimited = goa.NewErrorClass("rate_limited", http.StatusTooManyRequests)
+
+// New returns a middleware that limits the rate of requests made by each client as identified by
+// key. The limiter decides whether a request may proceed. The middleware sets the
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers on all responses. Requests
+// that exceed the limit are not handled and result in a ErrRateLimited error with the
+// Retry-After header set.
+func New(limiter Limiter, key KeyFunc) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			k := key(ctx, req)
+			if k == "" {
+				return h(ctx, rw, req)
+			}
+			res, err := limiter.Allow(k)
+			if err != nil {
+				return err
+			}
+			header := rw.Header()
+			header.Set(headerLimit, strconv.Itoa(res.Limit))
+			header.Set(headerRemaining, strconv.Itoa(res.Remaining))
+			header.Set(headerReset, formatSeconds(res.Reset))
+			if !res.Allowed {
+				header.Set(headerRetryAfter, formatSeconds(res.RetryAfter))
+				return ErrRateLimited("rate limit exceeded, retry later", "limit", res.Limit)
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
+
+// formatSeconds formats the duration as a number of seconds rounded up, durations shorter
+// than a second are rounded up to 1.
+func formatSeconds(d time.Duration) string {
+	s := int(math.Ceil(d.Seconds()))
+	if s < 1 {
+		s = 1
+	}
+	return strconv.Itoa(s)
+}
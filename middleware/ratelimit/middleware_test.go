@@ -0,0 +1,63 @@
+package ratelimit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/ratelimit"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var limiter ratelimit.Limiter
+	var key ratelimit.KeyFunc
+	var rw *httptest.ResponseRecorder
+	var req *http.Request
+	var handled int
+	var handler goa.Handler
+
+	BeforeEach(func() {
+		limiter = ratelimit.NewTokenBucket(1, time.Minute, 1, nil)
+		key = ratelimit.IPKey()
+		rw = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/foo", nil)
+		req.RemoteAddr = "10.0.0.1:4242"
+		handled = 0
+		handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			handled++
+			return nil
+		}
+	})
+
+	It("sets the rate limit headers", func() {
+		err := ratelimit.New(limiter, key)(handler)(context.Background(), rw, req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(handled).Should(Equal(1))
+		Ω(rw.Header().Get("RateLimit-Limit")).Should(Equal("1"))
+		Ω(rw.Header().Get("RateLimit-Remaining")).Should(Equal("0"))
+		Ω(rw.Header().Get("RateLimit-Reset")).Should(Equal("60"))
+	})
+
+	It("rejects requests exceeding the limit", func() {
+		h := ratelimit.New(limiter, key)(handler)
+		h(context.Background(), rw, req)
+		err := h(context.Background(), rw, req)
+		Ω(err).Should(HaveOccurred())
+		Ω(handled).Should(Equal(1))
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusTooManyRequests))
+		Ω(rw.Header().Get("Retry-After")).Should(Equal("60"))
+	})
+
+	It("does not limit requests without key", func() {
+		h := ratelimit.New(limiter, ratelimit.HeaderKey("X-Api-Key"))(handler)
+		h(context.Background(), rw, req)
+		err := h(context.Background(), rw, req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(handled).Should(Equal(2))
+		Ω(rw.Header().Get("RateLimit-Limit")).Should(BeEmpty())
+	})
+})
@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// Store persists the state of the rate limits indexed by key. Implementations must be
+	// safe for concurrent use. Use a store shared by all the service instances (e.g. backed
+	// by a database) to enforce limits across a cluster.
+	Store interface {
+		// Update calls fn with the state stored under key and stores the modified state
+		// for at least ttl. The state given to fn is the zero value if there is no state
+		// for the key or if it expired. Update must be atomic for a given key.
+		Update(key string, ttl time.Duration, fn func(*State)) error
+	}
+
+	// State is the state of a rate limit for a given key. Its meaning depends on the limiter:
+	// the token bucket stores the number of tokens left and the time they were computed, the
+	// sliding window stores the count of requests in the current and previous windows and the
+	// start of the current window.
+	State struct {
+		// Value is the number of tokens left (token bucket) or the number of requests
+		// made in the current window (sliding window).
+		Value float64
+		// Previous is the number of requests made in the previous window (sliding window
+		// only).
+		Previous float64
+		// Timestamp is the time the tokens were last computed (token bucket) or the start
+		// of the current window (sliding window).
+		Timestamp time.Time
+	}
+
+	// MemoryStore is a Store that keeps the states in memory.
+	MemoryStore struct {
+		mu      sync.Mutex
+		entries map[string]*memoryEntry
+		sweep   time.Time
+	}
+
+	memoryEntry struct {
+		state   State
+		expires time.Time
+	}
+)
+
+// sweepInterval is the minimum interval between two purges of the expired memory store entries.
+const sweepInterval = time.Minute
+
+// NewMemoryStore returns a store that keeps the states in memory. Expired states are purged
+// periodically as the store is updated.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*memoryEntry)}
+}
+
+// Update implements Store.
+func (s *MemoryStore) Update(key string, ttl time.Duration, fn func(*State)) error {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.sweep) > sweepInterval {
+		for k, e := range s.entries {
+			if now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.sweep = now
+	}
+	e, ok := s.entries[key]
+	if !ok || now.After(e.expires) {
+		e = &memoryEntry{}
+		s.entries[key] = e
+	}
+	fn(&e.state)
+	e.expires = now.Add(ttl)
+	return nil
+}
+
+// Len returns the number of states held by the store.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
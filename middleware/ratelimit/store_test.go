@@ -0,0 +1,35 @@
+package ratelimit_test
+
+import (
+	"time"
+
+	"github.com/goadesign/goa/middleware/ratelimit"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MemoryStore", func() {
+	var store *ratelimit.MemoryStore
+
+	BeforeEach(func() {
+		store = ratelimit.NewMemoryStore()
+	})
+
+	It("stores the state of each key", func() {
+		err := store.Update("foo", time.Minute, func(s *ratelimit.State) { s.Value = 42 })
+		Ω(err).ShouldNot(HaveOccurred())
+		var val float64
+		err = store.Update("foo", time.Minute, func(s *ratelimit.State) { val = s.Value })
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(val).Should(Equal(42.0))
+		Ω(store.Len()).Should(Equal(1))
+	})
+
+	It("resets expired states", func() {
+		store.Update("foo", time.Millisecond, func(s *ratelimit.State) { s.Value = 42 })
+		time.Sleep(5 * time.Millisecond)
+		var val float64
+		store.Update("foo", time.Minute, func(s *ratelimit.State) { val = s.Value })
+		Ω(val).Should(BeZero())
+	})
+})
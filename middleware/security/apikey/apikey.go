@@ -0,0 +1,70 @@
+package apikey
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	"github.com/goadesign/goa"
+)
+
+// New returns a middleware to be used with the APIKeySecurity DSL definitions of goa. The
+// middleware reads the API key from the header or query string parameter defined by scheme and
+// looks up its hash in store. The key must grant the scopes required by the action. The key
+// principal and scopes are stored in the request context and can be retrieved with ContextKey.
+//
+// Example:
+//
+//    store := apikey.NewMemoryStore()
+//    store.Add(os.Getenv("ADMIN_API_KEY"), "admin", "api:read", "api:write")
+//    app.UseAPIKey(apikey.New(store, app.NewAPIKeySecurity()))
+//
+func New(store Store, scheme *goa.APIKeySecurity) goa.Middleware {
+	return func(nextHandler goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			var val string
+			switch scheme.In {
+			case goa.LocHeader:
+				val = req.Header.Get(scheme.Name)
+			case goa.LocQuery:
+				val = req.URL.Query().Get(scheme.Name)
+			default:
+				return fmt.Errorf("whoops, security scheme with location (in) %q not supported", scheme.In)
+			}
+			if val == "" {
+				return ErrInvalidKey(fmt.Sprintf("missing %s %q", scheme.In, scheme.Name))
+			}
+
+			hash := Hash(val)
+			key, err := store.Lookup(ctx, hash)
+			if err != nil {
+				goa.LogError(ctx, "API key lookup failed", "err", err)
+				return goa.ErrInternal(err)
+			}
+			if key == nil || subtle.ConstantTimeCompare(key.Hash, hash) != 1 {
+				return ErrInvalidKey("invalid API key")
+			}
+
+			requiredScopes := goa.ContextRequiredScopes(ctx)
+			for _, scope := range requiredScopes {
+				if !contains(key.Scopes, scope) {
+					return ErrInsufficientScope("required scopes not granted",
+						"required", requiredScopes, "scopes", key.Scopes)
+				}
+			}
+
+			return nextHandler(WithKey(ctx, key), rw, req)
+		}
+	}
+}
+
+// contains returns true if s contains v.
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
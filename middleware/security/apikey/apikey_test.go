@@ -0,0 +1,128 @@
+package apikey_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/apikey"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type failingStore struct{}
+
+func (failingStore) Lookup(context.Context, []byte) (*apikey.Key, error) {
+	return nil, errors.New("boom")
+}
+
+var _ = Describe("Middleware", func() {
+	var (
+		store  apikey.Store
+		scheme *goa.APIKeySecurity
+		ctx    context.Context
+		req    *http.Request
+		key    *apikey.Key
+		err    error
+	)
+
+	BeforeEach(func() {
+		s := apikey.NewMemoryStore()
+		s.Add("secret", "alice", "read", "write")
+		s.Add("other", "bob")
+		store = s
+		scheme = &goa.APIKeySecurity{In: goa.LocHeader, Name: "X-API-Key"}
+		ctx = goa.WithRequiredScopes(context.Background(), []string{"read"})
+		req, _ = http.NewRequest("GET", "http://example.com/", nil)
+		req.Header.Set("X-API-Key", "secret")
+		key = nil
+	})
+
+	JustBeforeEach(func() {
+		handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			key = apikey.ContextKey(ctx)
+			return nil
+		}
+		err = apikey.New(store, scheme)(handler)(ctx, httptest.NewRecorder(), req)
+	})
+
+	// status returns the HTTP status of err.
+	status := func() int {
+		Ω(err).Should(HaveOccurred())
+		serr, ok := err.(*goa.ErrorResponse)
+		Ω(ok).Should(BeTrue())
+		return serr.Status
+	}
+
+	It("stores the key principal and scopes in the context", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(key).ShouldNot(BeNil())
+		Ω(key.Principal).Should(Equal("alice"))
+		Ω(key.Scopes).Should(Equal([]string{"read", "write"}))
+	})
+
+	Context("with an unknown key", func() {
+		BeforeEach(func() {
+			req.Header.Set("X-API-Key", "unknown")
+		})
+
+		It("rejects the request", func() {
+			Ω(status()).Should(Equal(401))
+			Ω(key).Should(BeNil())
+		})
+	})
+
+	Context("with a missing key", func() {
+		BeforeEach(func() {
+			req.Header.Del("X-API-Key")
+		})
+
+		It("rejects the request", func() {
+			Ω(status()).Should(Equal(401))
+		})
+	})
+
+	Context("with insufficient scopes", func() {
+		BeforeEach(func() {
+			req.Header.Set("X-API-Key", "other")
+		})
+
+		It("rejects the request", func() {
+			Ω(status()).Should(Equal(403))
+		})
+	})
+
+	Context("with a key in the query string", func() {
+		BeforeEach(func() {
+			scheme = &goa.APIKeySecurity{In: goa.LocQuery, Name: "api_key"}
+			req, _ = http.NewRequest("GET", "http://example.com/?api_key=secret", nil)
+		})
+
+		It("reads the key from the query string", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(key.Principal).Should(Equal("alice"))
+		})
+	})
+
+	Context("with a failing store", func() {
+		BeforeEach(func() {
+			store = failingStore{}
+		})
+
+		It("returns an internal error", func() {
+			Ω(status()).Should(Equal(500))
+		})
+	})
+})
+
+var _ = Describe("MemoryStore", func() {
+	It("stores the key hashes only", func() {
+		s := apikey.NewMemoryStore(&apikey.Key{Hash: apikey.Hash("secret"), Principal: "alice"})
+		key, err := s.Lookup(context.Background(), apikey.Hash("secret"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(key.Principal).Should(Equal("alice"))
+		Ω(key.Hash).ShouldNot(Equal([]byte("secret")))
+	})
+})
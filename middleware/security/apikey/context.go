@@ -0,0 +1,23 @@
+package apikey
+
+import "context"
+
+type contextKey int
+
+const (
+	keyKey contextKey = iota + 1
+)
+
+// WithKey creates a child context containing the given API key.
+func WithKey(ctx context.Context, k *Key) context.Context {
+	return context.WithValue(ctx, keyKey, k)
+}
+
+// ContextKey retrieves the API key from a `context` that went through the security middleware.
+func ContextKey(ctx context.Context) *Key {
+	k, ok := ctx.Value(keyKey).(*Key)
+	if !ok {
+		return nil
+	}
+	return k
+}
@@ -0,0 +1,13 @@
+package apikey
+
+import "github.com/goadesign/goa"
+
+var (
+	// ErrInvalidKey is the error returned by the middleware when the request does not carry an
+	// API key or when the key is unknown.
+	ErrInvalidKey = goa.NewErrorClass("invalid_api_key", 401)
+
+	// ErrInsufficientScope is the error returned by the middleware when the API key does not
+	// grant the scopes required by the action.
+	ErrInsufficientScope = goa.NewErrorClass("insufficient_scope", 403)
+)
@@ -0,0 +1,64 @@
+package apikey
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"sync"
+)
+
+type (
+	// Key describes an API key known to a store. The key itself is not stored, only its hash.
+	Key struct {
+		// Hash is the SHA-256 hash of the key as computed by Hash.
+		Hash []byte
+		// Principal identifies the owner of the key.
+		Principal string
+		// Scopes lists the scopes granted to the key.
+		Scopes []string
+	}
+
+	// Store retrieves the API keys. Implementations may be backed by a database or a secret
+	// manager, they should only store the hashes of the keys.
+	Store interface {
+		// Lookup returns the key with the given hash, nil if there is no such key.
+		Lookup(ctx context.Context, hash []byte) (*Key, error)
+	}
+
+	// MemoryStore is a Store that keeps the keys in memory.
+	MemoryStore struct {
+		sync.RWMutex
+		keys []*Key
+	}
+)
+
+// Hash returns the hash of the given API key.
+func Hash(key string) []byte {
+	h := sha256.Sum256([]byte(key))
+	return h[:]
+}
+
+// NewMemoryStore returns a store holding the given keys.
+func NewMemoryStore(keys ...*Key) *MemoryStore {
+	return &MemoryStore{keys: keys}
+}
+
+// Add hashes the given API key and adds it to the store.
+func (s *MemoryStore) Add(key, principal string, scopes ...string) {
+	s.Lock()
+	defer s.Unlock()
+	s.keys = append(s.keys, &Key{Hash: Hash(key), Principal: principal, Scopes: scopes})
+}
+
+// Lookup compares the given hash with the hashes of all the keys in constant time.
+func (s *MemoryStore) Lookup(_ context.Context, hash []byte) (*Key, error) {
+	s.RLock()
+	defer s.RUnlock()
+	var found *Key
+	for _, k := range s.keys {
+		if subtle.ConstantTimeCompare(k.Hash, hash) == 1 {
+			found = k
+		}
+	}
+	return found, nil
+}
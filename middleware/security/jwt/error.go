@@ -23,4 +23,28 @@ var (
 	// ErrJWTError is the error returned by this middleware when any sort of validation or
 	// assertion fails during processing.
 	ErrJWTError = goa.NewErrorClass("jwt_security_error", 401)
+
+	// ErrInvalidAlgorithm is the error returned by this middleware when the token is signed with
+	// an algorithm that is not accepted.
+	ErrInvalidAlgorithm = goa.NewErrorClass("jwt_invalid_algorithm", 401)
+
+	// ErrTokenExpired is the error returned by this middleware when the "exp" claim of the token
+	// is in the past.
+	ErrTokenExpired = goa.NewErrorClass("jwt_token_expired", 401)
+
+	// ErrTokenNotValidYet is the error returned by this middleware when the "nbf" or "iat" claim
+	// of the token is in the future.
+	ErrTokenNotValidYet = goa.NewErrorClass("jwt_token_not_valid_yet", 401)
+
+	// ErrInvalidIssuer is the error returned by this middleware when the "iss" claim of the token
+	// does not match the expected issuer.
+	ErrInvalidIssuer = goa.NewErrorClass("jwt_invalid_issuer", 401)
+
+	// ErrInvalidAudience is the error returned by this middleware when the "aud" claim of the
+	// token does not contain any of the accepted audiences.
+	ErrInvalidAudience = goa.NewErrorClass("jwt_invalid_audience", 401)
+
+	// ErrMissingClaim is the error returned by this middleware when the token does not define a
+	// required claim.
+	ErrMissingClaim = goa.NewErrorClass("jwt_missing_claim", 401)
 )
@@ -0,0 +1,338 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultJWKSTTL is the duration the keys loaded from a JWKS document are cached for by
+	// default.
+	DefaultJWKSTTL = time.Hour
+
+	// DefaultJWKSMinRefreshInterval is the minimum duration between two refreshes triggered by
+	// tokens with an unknown key ID by default.
+	DefaultJWKSMinRefreshInterval = time.Minute
+)
+
+type (
+	// JWKSResolver is a key resolver that loads the keys from a JSON Web Key Set document read
+	// from a file or a URL. It selects the key identified by the "kid" header of the incoming
+	// token or all the keys if the token has no "kid" header. The keys are reloaded in the
+	// background once their TTL elapses and when a token refers to an unknown key ID.
+	JWKSResolver struct {
+		source  string
+		options *jwksOptions
+
+		sync.RWMutex
+		keys      map[string]Key
+		all       []Key
+		lastFetch time.Time
+		stop      chan struct{}
+		closeOnce sync.Once
+	}
+
+	// JWKSOption is a constructor option that makes it possible to customize the JWKS resolver.
+	JWKSOption func(*jwksOptions) *jwksOptions
+
+	// jwksOptions is the struct storing all the options.
+	jwksOptions struct {
+		client             *http.Client
+		ttl                time.Duration
+		minRefreshInterval time.Duration
+		header             string
+	}
+
+	// jwks is a JSON Web Key Set document.
+	jwks struct {
+		Keys []*jwk `json:"keys"`
+	}
+
+	// jwk is a JSON Web Key.
+	jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+		K   string `json:"k"`
+	}
+)
+
+// JWKSClient is a constructor option that sets the HTTP client used to retrieve the JWKS document.
+func JWKSClient(c *http.Client) JWKSOption {
+	return func(o *jwksOptions) *jwksOptions {
+		o.client = c
+		return o
+	}
+}
+
+// JWKSTTL is a constructor option that sets the duration the keys are cached for. The keys are
+// reloaded in the background every ttl. A zero or negative value disables the background refresh.
+func JWKSTTL(ttl time.Duration) JWKSOption {
+	return func(o *jwksOptions) *jwksOptions {
+		o.ttl = ttl
+		return o
+	}
+}
+
+// JWKSMinRefreshInterval is a constructor option that sets the minimum duration between two
+// reloads triggered by tokens with an unknown key ID. This prevents clients from flooding the
+// JWKS endpoint by sending tokens with random key IDs.
+func JWKSMinRefreshInterval(d time.Duration) JWKSOption {
+	return func(o *jwksOptions) *jwksOptions {
+		o.minRefreshInterval = d
+		return o
+	}
+}
+
+// JWKSHeader is a constructor option that sets the name of the request header containing the
+// bearer token, "Authorization" by default.
+func JWKSHeader(name string) JWKSOption {
+	return func(o *jwksOptions) *jwksOptions {
+		o.header = name
+		return o
+	}
+}
+
+// NewJWKSResolver returns a resolver that loads the keys from the JWKS document at source. source
+// is either a http or https URL or the path to a file optionally prefixed with "file://".
+// NewJWKSResolver returns an error if the document cannot be loaded. Call Close to stop the
+// background refresh.
+func NewJWKSResolver(source string, opts ...JWKSOption) (*JWKSResolver, error) {
+	o := &jwksOptions{
+		client:             http.DefaultClient,
+		ttl:                DefaultJWKSTTL,
+		minRefreshInterval: DefaultJWKSMinRefreshInterval,
+		header:             "Authorization",
+	}
+	for _, opt := range opts {
+		o = opt(o)
+	}
+	r := &JWKSResolver{source: source, options: o, stop: make(chan struct{})}
+	if err := r.Refresh(); err != nil {
+		return nil, err
+	}
+	if o.ttl > 0 {
+		go r.refreshLoop()
+	}
+	return r, nil
+}
+
+// SelectKeys returns the key identified by the "kid" header of the request token. The JWKS
+// document is reloaded if the key ID is unknown, SelectKeys returns no key if the key ID is still
+// unknown after that. SelectKeys returns all the keys if the token has no "kid" header.
+func (r *JWKSResolver) SelectKeys(req *http.Request) []Key {
+	kid := tokenKeyID(req.Header.Get(r.options.header))
+	r.RLock()
+	if kid == "" {
+		defer r.RUnlock()
+		return r.all
+	}
+	key, ok := r.keys[kid]
+	r.RUnlock()
+	if ok {
+		return []Key{key}
+	}
+
+	r.Lock()
+	stale := time.Since(r.lastFetch) >= r.options.minRefreshInterval
+	if stale {
+		r.lastFetch = time.Now()
+	}
+	r.Unlock()
+	if stale {
+		r.load()
+	}
+	r.RLock()
+	defer r.RUnlock()
+	if key, ok := r.keys[kid]; ok {
+		return []Key{key}
+	}
+	return nil
+}
+
+// Refresh reloads the JWKS document. The previously loaded keys are kept if the document cannot
+// be loaded.
+func (r *JWKSResolver) Refresh() error {
+	r.Lock()
+	r.lastFetch = time.Now()
+	r.Unlock()
+	return r.load()
+}
+
+// load reads and parses the JWKS document and replaces the keys with the result.
+func (r *JWKSResolver) load() error {
+	b, err := r.read()
+	if err != nil {
+		return err
+	}
+	keys, all, err := parseJWKS(b)
+	if err != nil {
+		return err
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.keys = keys
+	r.all = all
+	return nil
+}
+
+// Close stops the background refresh of the keys.
+func (r *JWKSResolver) Close() {
+	r.closeOnce.Do(func() { close(r.stop) })
+}
+
+// refreshLoop reloads the JWKS document every TTL until the resolver is closed.
+func (r *JWKSResolver) refreshLoop() {
+	ticker := time.NewTicker(r.options.ttl)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.Refresh()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// read returns the content of the JWKS document.
+func (r *JWKSResolver) read() ([]byte, error) {
+	if !strings.HasPrefix(r.source, "http://") && !strings.HasPrefix(r.source, "https://") {
+		return ioutil.ReadFile(strings.TrimPrefix(r.source, "file://"))
+	}
+	resp, err := r.options.client.Get(r.source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to retrieve JWKS from %s: %s", r.source, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// parseJWKS parses the given JWKS document and returns the signature verification keys indexed
+// by key ID and the list of all the keys. Keys with an unsupported type or used for encryption
+// are ignored.
+func parseJWKS(b []byte) (map[string]Key, []Key, error) {
+	var set jwks
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, nil, fmt.Errorf("invalid JWKS document: %s", err)
+	}
+	keys := make(map[string]Key)
+	var all []Key
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.key()
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid JWK %q: %s", k.Kid, err)
+		}
+		if key == nil {
+			continue
+		}
+		if k.Kid != "" {
+			keys[k.Kid] = key
+		}
+		all = append(all, key)
+	}
+	return keys, all, nil
+}
+
+// key returns the public key or secret described by the JWK, nil if the key type is not
+// supported.
+func (k *jwk) key() (Key, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(k.K, "="))
+	}
+	return nil, nil
+}
+
+// decodeBigInt decodes the given base64url encoded big-endian integer.
+func decodeBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, fmt.Errorf("missing parameter")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// tokenKeyID returns the "kid" header of the bearer token in the given header value if any.
+func tokenKeyID(val string) string {
+	fields := strings.Fields(val)
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "bearer") {
+		return ""
+	}
+	parts := strings.Split(fields[1], ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[0], "="))
+	if err != nil {
+		return ""
+	}
+	var header struct {
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(b, &header); err != nil {
+		return ""
+	}
+	return header.Kid
+}
@@ -0,0 +1,197 @@
+package jwt_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"time"
+
+	jwtpkg "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/jwt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JWKSResolver", func() {
+	var (
+		rsaKey   *rsa.PrivateKey
+		ecKey    *ecdsa.PrivateKey
+		document atomic.Value
+		fetches  int32
+		server   *httptest.Server
+		source   string
+		options  []jwt.JWKSOption
+		resolver *jwt.JWKSResolver
+		err      error
+	)
+
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+	rsaJWK := func(kid string, k *rsa.PublicKey) string {
+		return fmt.Sprintf(`{"kty":"RSA","kid":%q,"use":"sig","n":%q,"e":%q}`,
+			kid, b64(k.N.Bytes()), b64(big.NewInt(int64(k.E)).Bytes()))
+	}
+
+	ecJWK := func(kid string, k *ecdsa.PublicKey) string {
+		return fmt.Sprintf(`{"kty":"EC","kid":%q,"crv":"P-256","x":%q,"y":%q}`,
+			kid, b64(k.X.Bytes()), b64(k.Y.Bytes()))
+	}
+
+	sign := func(method jwtpkg.SigningMethod, kid string, key interface{}) string {
+		token := jwtpkg.NewWithClaims(method, jwtpkg.MapClaims{"scopes": "scope1"})
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		s, err := token.SignedString(key)
+		Ω(err).ShouldNot(HaveOccurred())
+		return s
+	}
+
+	selectKeys := func(token string) []jwt.Key {
+		req, _ := http.NewRequest("GET", "http://example.com/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return resolver.SelectKeys(req)
+	}
+
+	BeforeEach(func() {
+		rsaKey, err = rsa.GenerateKey(rand.Reader, 1024)
+		Ω(err).ShouldNot(HaveOccurred())
+		ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Ω(err).ShouldNot(HaveOccurred())
+		document.Store(fmt.Sprintf(`{"keys":[%s,%s,{"kty":"oct","kid":"hmac","k":%q},{"kty":"RSA","kid":"enc","use":"enc","n":"AQAB","e":"AQAB"}]}`,
+			rsaJWK("rsa", &rsaKey.PublicKey), ecJWK("ec", &ecKey.PublicKey), b64([]byte("secret"))))
+		atomic.StoreInt32(&fetches, 0)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&fetches, 1)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, document.Load().(string))
+		}))
+		source = server.URL
+		options = []jwt.JWKSOption{jwt.JWKSMinRefreshInterval(0)}
+	})
+
+	JustBeforeEach(func() {
+		resolver, err = jwt.NewJWKSResolver(source, options...)
+	})
+
+	AfterEach(func() {
+		if resolver != nil {
+			resolver.Close()
+		}
+		server.Close()
+	})
+
+	It("loads the signature keys", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		keys := selectKeys(sign(jwtpkg.SigningMethodHS256, "", []byte("secret")))
+		Ω(keys).Should(HaveLen(3))
+	})
+
+	It("selects the key using the token key ID", func() {
+		keys := selectKeys(sign(jwtpkg.SigningMethodRS256, "rsa", rsaKey))
+		Ω(keys).Should(HaveLen(1))
+		Ω(keys[0].(*rsa.PublicKey).N).Should(Equal(rsaKey.N))
+
+		keys = selectKeys(sign(jwtpkg.SigningMethodES256, "ec", ecKey))
+		Ω(keys).Should(HaveLen(1))
+		Ω(keys[0].(*ecdsa.PublicKey).X).Should(Equal(ecKey.X))
+
+		keys = selectKeys(sign(jwtpkg.SigningMethodHS256, "hmac", []byte("secret")))
+		Ω(keys).Should(Equal([]jwt.Key{[]byte("secret")}))
+
+		Ω(selectKeys(sign(jwtpkg.SigningMethodHS256, "enc", []byte("secret")))).Should(BeEmpty())
+	})
+
+	It("reloads the document when the key ID is unknown", func() {
+		newKey, err := rsa.GenerateKey(rand.Reader, 1024)
+		Ω(err).ShouldNot(HaveOccurred())
+		document.Store(fmt.Sprintf(`{"keys":[%s]}`, rsaJWK("new", &newKey.PublicKey)))
+		keys := selectKeys(sign(jwtpkg.SigningMethodRS256, "new", newKey))
+		Ω(keys).Should(HaveLen(1))
+		Ω(atomic.LoadInt32(&fetches)).Should(Equal(int32(2)))
+	})
+
+	Context("with a minimum refresh interval", func() {
+		BeforeEach(func() {
+			options = []jwt.JWKSOption{jwt.JWKSMinRefreshInterval(time.Hour)}
+		})
+
+		It("does not reload the document for each unknown key ID", func() {
+			selectKeys(sign(jwtpkg.SigningMethodHS256, "unknown1", []byte("secret")))
+			selectKeys(sign(jwtpkg.SigningMethodHS256, "unknown2", []byte("secret")))
+			Ω(atomic.LoadInt32(&fetches)).Should(Equal(int32(1)))
+		})
+	})
+
+	Context("with a TTL", func() {
+		BeforeEach(func() {
+			options = append(options, jwt.JWKSTTL(10*time.Millisecond))
+		})
+
+		It("reloads the document in the background", func() {
+			Eventually(func() int32 { return atomic.LoadInt32(&fetches) }).Should(BeNumerically(">=", 3))
+		})
+	})
+
+	Context("with a file", func() {
+		BeforeEach(func() {
+			f, err := ioutil.TempFile("", "jwks")
+			Ω(err).ShouldNot(HaveOccurred())
+			_, err = f.WriteString(document.Load().(string))
+			Ω(err).ShouldNot(HaveOccurred())
+			f.Close()
+			source = "file://" + f.Name()
+		})
+
+		AfterEach(func() {
+			os.Remove(source[len("file://"):])
+		})
+
+		It("loads the keys from the file", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(selectKeys(sign(jwtpkg.SigningMethodRS256, "rsa", rsaKey))).Should(HaveLen(1))
+			Ω(atomic.LoadInt32(&fetches)).Should(Equal(int32(0)))
+		})
+	})
+
+	Context("with an invalid document", func() {
+		BeforeEach(func() {
+			document.Store(`{"keys":[{"kty":"EC","kid":"bad","crv":"P-256","x":"AQAB","y":"AQAB"}]}`)
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("used by the middleware", func() {
+		It("validates tokens signed with the JWKS keys", func() {
+			scheme := &goa.JWTSecurity{In: goa.LocHeader, Name: "Authorization"}
+			var token *jwtpkg.Token
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				token = jwt.ContextJWT(ctx)
+				return nil
+			}
+			req, _ := http.NewRequest("GET", "http://example.com/", nil)
+			req.Header.Set("Authorization", "Bearer "+sign(jwtpkg.SigningMethodES256, "ec", ecKey))
+			err := jwt.New(resolver, nil, scheme)(handler)(context.Background(), httptest.NewRecorder(), req)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(token).ShouldNot(BeNil())
+
+			req.Header.Set("Authorization", "Bearer "+sign(jwtpkg.SigningMethodHS256, "rsa", []byte("secret")))
+			err = jwt.New(resolver, nil, scheme)(handler)(context.Background(), httptest.NewRecorder(), req)
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
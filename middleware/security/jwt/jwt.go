@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"time"
 
 	"context"
 
@@ -24,7 +25,8 @@ import (
 //     2. If scopes are defined in the design for the action validate them against the "scopes" JWT
 //        claim
 //
-// The `exp` (expiration) and `nbf` (not before) date checks are validated by the JWT library.
+// The `exp` (expiration), `nbf` (not before) and `iat` (issued at) date checks are validated by the
+// middleware taking the configured leeway into account.
 //
 // validationKeys can be one of these:
 //
@@ -47,13 +49,28 @@ import (
 //        }
 //    })
 //
+// Use NewJWKSResolver to load the keys from the JSON Web Key Set document published by the token
+// issuer.
+//
+// The "iss", "aud" and required claims, the clock skew tolerated when validating the "exp", "nbf"
+// and "iat" claims and the accepted signing algorithms are configured with the corresponding
+// JWTSecurity DSL functions. The generated NewXXSecurity functions initialize scheme accordingly,
+// opts may be used to override these settings, e.g.:
+//
+//    app.UseJWT(jwt.New(jwtResolver, nil, app.NewJWTSecurity(), jwt.Leeway(time.Minute)))
+//
+// Failed checks result in errors of the classes ErrInvalidAlgorithm, ErrTokenExpired,
+// ErrTokenNotValidYet, ErrInvalidIssuer, ErrInvalidAudience or ErrMissingClaim so that clients
+// may tell which check failed.
+//
 // Mount the middleware with the generated UseXX function where XX is the name of the scheme as
 // defined in the design, e.g.:
 //
 //    jwtResolver, _ := jwt.NewSimpleResolver("secret")
 //    app.UseJWT(jwt.New(jwtResolver, validationHandler, app.NewJWTSecurity()))
 //
-func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSecurity) goa.Middleware {
+func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSecurity, opts ...ValidationOption) goa.Middleware {
+	options := newValidationOptions(scheme, opts)
 	return func(nextHandler goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 			// TODO: implement the QUERY string handler too
@@ -71,6 +88,10 @@ func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSec
 
 			incomingToken := strings.Split(val, " ")[1]
 
+			if err := options.validateAlgorithm(incomingToken); err != nil {
+				return err
+			}
+
 			rsaKeys, ecdsaKeys, hmacKeys := partitionKeys(resolver.SelectKeys(req))
 
 			var (
@@ -104,6 +125,10 @@ func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSec
 				return ErrJWTError("JWT validation failed")
 			}
 
+			if err := options.validateClaims(token, time.Now()); err != nil {
+				return err
+			}
+
 			scopesInClaim, scopesInClaimList, err := parseClaimScopes(token)
 			if err != nil {
 				goa.LogError(ctx, err.Error())
@@ -188,7 +213,7 @@ func parseClaimScopes(token *jwt.Token) (map[string]bool, []string, error) {
 
 func validateRSAKeys(rsaKeys []*rsa.PublicKey, algo, incomingToken string) (token *jwt.Token, err error) {
 	for _, pubkey := range rsaKeys {
-		token, err = jwt.Parse(incomingToken, func(token *jwt.Token) (interface{}, error) {
+		token, err = parser.Parse(incomingToken, func(token *jwt.Token) (interface{}, error) {
 			if !strings.HasPrefix(token.Method.Alg(), algo) {
 				return nil, ErrJWTError(fmt.Sprintf("Unexpected signing method: %v", token.Header["alg"]))
 			}
@@ -203,7 +228,7 @@ func validateRSAKeys(rsaKeys []*rsa.PublicKey, algo, incomingToken string) (toke
 
 func validateECDSAKeys(ecdsaKeys []*ecdsa.PublicKey, algo, incomingToken string) (token *jwt.Token, err error) {
 	for _, pubkey := range ecdsaKeys {
-		token, err = jwt.Parse(incomingToken, func(token *jwt.Token) (interface{}, error) {
+		token, err = parser.Parse(incomingToken, func(token *jwt.Token) (interface{}, error) {
 			if !strings.HasPrefix(token.Method.Alg(), algo) {
 				return nil, ErrJWTError(fmt.Sprintf("Unexpected signing method: %v", token.Header["alg"]))
 			}
@@ -218,7 +243,7 @@ func validateECDSAKeys(ecdsaKeys []*ecdsa.PublicKey, algo, incomingToken string)
 
 func validateHMACKeys(hmacKeys [][]byte, algo, incomingToken string) (token *jwt.Token, err error) {
 	for _, key := range hmacKeys {
-		token, err = jwt.Parse(incomingToken, func(token *jwt.Token) (interface{}, error) {
+		token, err = parser.Parse(incomingToken, func(token *jwt.Token) (interface{}, error) {
 			if !strings.HasPrefix(token.Method.Alg(), algo) {
 				return nil, ErrJWTError(fmt.Sprintf("Unexpected signing method: %v", token.Header["alg"]))
 			}
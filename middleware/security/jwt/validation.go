@@ -0,0 +1,198 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa"
+)
+
+type (
+	// ValidationOption is a constructor option that configures the validation of the token
+	// claims done by the middleware. Options override the settings of the security scheme.
+	ValidationOption func(*validationOptions) *validationOptions
+
+	// validationOptions is the struct storing all the validation options.
+	validationOptions struct {
+		issuer         string
+		audience       []string
+		leeway         time.Duration
+		algorithms     []string
+		requiredClaims []string
+	}
+)
+
+// parser parses and verifies the incoming tokens. The time based claims are validated by the
+// middleware so that the leeway can be applied.
+var parser = &jwt.Parser{SkipClaimsValidation: true}
+
+// Issuer is a constructor option that sets the expected value of the "iss" claim.
+func Issuer(iss string) ValidationOption {
+	return func(o *validationOptions) *validationOptions {
+		o.issuer = iss
+		return o
+	}
+}
+
+// Audience is a constructor option that sets the accepted values of the "aud" claim. The token
+// audience must contain at least one of the values.
+func Audience(aud ...string) ValidationOption {
+	return func(o *validationOptions) *validationOptions {
+		o.audience = aud
+		return o
+	}
+}
+
+// Leeway is a constructor option that sets the clock skew tolerated when validating the "exp",
+// "nbf" and "iat" claims.
+func Leeway(d time.Duration) ValidationOption {
+	if d < 0 {
+		panic("leeway must not be negative")
+	}
+	return func(o *validationOptions) *validationOptions {
+		o.leeway = d
+		return o
+	}
+}
+
+// Algorithms is a constructor option that sets the accepted signing algorithms, e.g. "RS256".
+func Algorithms(algs ...string) ValidationOption {
+	return func(o *validationOptions) *validationOptions {
+		o.algorithms = algs
+		return o
+	}
+}
+
+// RequiredClaims is a constructor option that sets the names of the claims the token must define.
+func RequiredClaims(names ...string) ValidationOption {
+	return func(o *validationOptions) *validationOptions {
+		o.requiredClaims = names
+		return o
+	}
+}
+
+// newValidationOptions initializes the validation options from the scheme and applies opts.
+func newValidationOptions(scheme *goa.JWTSecurity, opts []ValidationOption) *validationOptions {
+	o := &validationOptions{
+		issuer:         scheme.Issuer,
+		audience:       scheme.Audience,
+		leeway:         scheme.Leeway,
+		algorithms:     scheme.Algorithms,
+		requiredClaims: scheme.RequiredClaims,
+	}
+	for _, opt := range opts {
+		o = opt(o)
+	}
+	return o
+}
+
+// validateAlgorithm returns an error if the incoming token is signed with an algorithm that is not
+// accepted. The signature is not verified.
+func (o *validationOptions) validateAlgorithm(incomingToken string) error {
+	if len(o.algorithms) == 0 {
+		return nil
+	}
+	token, _, err := parser.ParseUnverified(incomingToken, jwt.MapClaims{})
+	if err != nil {
+		return ErrJWTError(fmt.Sprintf("malformed token: %s", err))
+	}
+	alg := token.Method.Alg()
+	for _, a := range o.algorithms {
+		if a == alg {
+			return nil
+		}
+	}
+	return ErrInvalidAlgorithm("token signing algorithm is not accepted", "alg", alg, "accepted", o.algorithms)
+}
+
+// validateClaims returns an error if the claims of the token are not valid at time now.
+func (o *validationOptions) validateClaims(token *jwt.Token, now time.Time) error {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ErrJWTError("unsupported claims shape")
+	}
+
+	exp, err := timeClaim(claims, "exp")
+	if err != nil {
+		return err
+	}
+	if exp != nil && now.After(exp.Add(o.leeway)) {
+		return ErrTokenExpired("token is expired", "exp", exp.UTC().Format(time.RFC3339))
+	}
+	for _, name := range []string{"nbf", "iat"} {
+		t, err := timeClaim(claims, name)
+		if err != nil {
+			return err
+		}
+		if t != nil && now.Before(t.Add(-o.leeway)) {
+			return ErrTokenNotValidYet("token is not valid yet", name, t.UTC().Format(time.RFC3339))
+		}
+	}
+
+	if o.issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != o.issuer {
+			return ErrInvalidIssuer("invalid token issuer", "iss", iss, "expected", o.issuer)
+		}
+	}
+
+	if len(o.audience) > 0 {
+		var aud []string
+		switch a := claims["aud"].(type) {
+		case string:
+			aud = []string{a}
+		case []interface{}:
+			for _, v := range a {
+				if s, ok := v.(string); ok {
+					aud = append(aud, s)
+				}
+			}
+		}
+		if !intersects(aud, o.audience) {
+			return ErrInvalidAudience("invalid token audience", "aud", aud, "expected", o.audience)
+		}
+	}
+
+	for _, name := range o.requiredClaims {
+		if claims[name] == nil {
+			return ErrMissingClaim("missing required claim", "claim", name)
+		}
+	}
+
+	return nil
+}
+
+// timeClaim returns the value of the numeric date claim with the given name, nil if the claim is
+// not set.
+func timeClaim(claims jwt.MapClaims, name string) (*time.Time, error) {
+	var secs float64
+	switch v := claims[name].(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		secs = v
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return nil, ErrJWTError(fmt.Sprintf("invalid %q claim", name))
+		}
+		secs = f
+	default:
+		return nil, ErrJWTError(fmt.Sprintf("invalid %q claim", name))
+	}
+	t := time.Unix(0, int64(secs*float64(time.Second)))
+	return &t, nil
+}
+
+// intersects returns true if a and b have at least one element in common.
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package jwt_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	jwtpkg "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/jwt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Claims validation", func() {
+	var (
+		scheme *goa.JWTSecurity
+		opts   []jwt.ValidationOption
+		method jwtpkg.SigningMethod
+		claims jwtpkg.MapClaims
+		err    error
+	)
+
+	BeforeEach(func() {
+		scheme = &goa.JWTSecurity{In: goa.LocHeader, Name: "Authorization"}
+		opts = nil
+		method = jwtpkg.SigningMethodHS256
+		claims = jwtpkg.MapClaims{
+			"iss": "https://example.com",
+			"aud": []string{"cellar", "other"},
+			"sub": "user",
+			"exp": time.Now().Add(time.Minute).Unix(),
+		}
+	})
+
+	JustBeforeEach(func() {
+		token, serr := jwtpkg.NewWithClaims(method, claims).SignedString([]byte("secret"))
+		Ω(serr).ShouldNot(HaveOccurred())
+		req, _ := http.NewRequest("GET", "http://example.com/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resolver := jwt.NewSimpleResolver([]jwt.Key{"secret"})
+		handler := func(context.Context, http.ResponseWriter, *http.Request) error { return nil }
+		err = jwt.New(resolver, nil, scheme, opts...)(handler)(context.Background(), httptest.NewRecorder(), req)
+	})
+
+	// errorCode returns the goa error code of err.
+	errorCode := func() string {
+		Ω(err).Should(HaveOccurred())
+		serr, ok := err.(*goa.ErrorResponse)
+		Ω(ok).Should(BeTrue())
+		Ω(serr.Status).Should(Equal(401))
+		return serr.Code
+	}
+
+	It("accepts valid claims", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	Context("with the scheme settings", func() {
+		BeforeEach(func() {
+			scheme.Issuer = "https://example.com"
+			scheme.Audience = []string{"cellar"}
+			scheme.Algorithms = []string{"HS256"}
+			scheme.RequiredClaims = []string{"sub"}
+		})
+
+		It("accepts valid claims", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		Context("and an unexpected issuer", func() {
+			BeforeEach(func() {
+				claims["iss"] = "https://evil.com"
+			})
+
+			It("rejects the token", func() {
+				Ω(errorCode()).Should(Equal("jwt_invalid_issuer"))
+			})
+		})
+
+		Context("and an unexpected audience", func() {
+			BeforeEach(func() {
+				claims["aud"] = "other"
+			})
+
+			It("rejects the token", func() {
+				Ω(errorCode()).Should(Equal("jwt_invalid_audience"))
+			})
+		})
+
+		Context("and an unexpected algorithm", func() {
+			BeforeEach(func() {
+				method = jwtpkg.SigningMethodHS512
+			})
+
+			It("rejects the token", func() {
+				Ω(errorCode()).Should(Equal("jwt_invalid_algorithm"))
+			})
+		})
+
+		Context("and a missing required claim", func() {
+			BeforeEach(func() {
+				delete(claims, "sub")
+			})
+
+			It("rejects the token", func() {
+				Ω(errorCode()).Should(Equal("jwt_missing_claim"))
+			})
+		})
+
+		Context("and options overriding them", func() {
+			BeforeEach(func() {
+				claims["iss"] = "https://other.com"
+				opts = []jwt.ValidationOption{jwt.Issuer("https://other.com")}
+			})
+
+			It("uses the options", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with an expired token", func() {
+		BeforeEach(func() {
+			claims["exp"] = time.Now().Add(-10 * time.Second).Unix()
+		})
+
+		It("rejects the token", func() {
+			Ω(errorCode()).Should(Equal("jwt_token_expired"))
+		})
+
+		Context("within the leeway", func() {
+			BeforeEach(func() {
+				opts = []jwt.ValidationOption{jwt.Leeway(time.Minute)}
+			})
+
+			It("accepts the token", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a token not valid yet", func() {
+		BeforeEach(func() {
+			claims["nbf"] = time.Now().Add(10 * time.Second).Unix()
+		})
+
+		It("rejects the token", func() {
+			Ω(errorCode()).Should(Equal("jwt_token_not_valid_yet"))
+		})
+
+		Context("within the leeway", func() {
+			BeforeEach(func() {
+				scheme.Leeway = time.Minute
+			})
+
+			It("accepts the token", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+})
@@ -0,0 +1,17 @@
+package oauth2
+
+import "github.com/goadesign/goa"
+
+var (
+	// ErrInvalidToken is the error returned by the middleware when the request does not carry a
+	// bearer token or when the token is not active.
+	ErrInvalidToken = goa.NewErrorClass("invalid_token", 401)
+
+	// ErrInsufficientScope is the error returned by the middleware when the token does not grant
+	// the scopes required by the action.
+	ErrInsufficientScope = goa.NewErrorClass("insufficient_scope", 403)
+
+	// ErrIntrospectionFailed is the error returned by the middleware when the introspection
+	// endpoint cannot be reached or returns an invalid response.
+	ErrIntrospectionFailed = goa.NewErrorClass("introspection_failed", 503)
+)
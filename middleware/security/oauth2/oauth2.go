@@ -0,0 +1,277 @@
+package oauth2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+type (
+	// Introspection is the response of a RFC 7662 token introspection endpoint.
+	Introspection struct {
+		// Active indicates whether the token is currently active.
+		Active bool `json:"active"`
+		// Scope is the space-separated list of scopes associated with the token.
+		Scope string `json:"scope,omitempty"`
+		// ClientID is the identifier of the client that requested the token.
+		ClientID string `json:"client_id,omitempty"`
+		// Username is the human-readable identifier of the resource owner.
+		Username string `json:"username,omitempty"`
+		// TokenType is the type of the token, e.g. "Bearer".
+		TokenType string `json:"token_type,omitempty"`
+		// Exp is the expiration time of the token in seconds since the epoch.
+		Exp int64 `json:"exp,omitempty"`
+		// Iat is the time the token was issued in seconds since the epoch.
+		Iat int64 `json:"iat,omitempty"`
+		// Nbf is the time before which the token must not be used in seconds since the epoch.
+		Nbf int64 `json:"nbf,omitempty"`
+		// Sub is the subject of the token.
+		Sub string `json:"sub,omitempty"`
+		// Aud is the list of intended audiences of the token.
+		Aud []string `json:"-"`
+		// Iss is the issuer of the token.
+		Iss string `json:"iss,omitempty"`
+		// Jti is the identifier of the token.
+		Jti string `json:"jti,omitempty"`
+		// Claims contains all the members of the introspection response including extensions.
+		Claims map[string]interface{} `json:"-"`
+	}
+
+	// Introspector queries a RFC 7662 token introspection endpoint and caches the active
+	// results until the tokens expire.
+	Introspector struct {
+		// URL is the URL of the introspection endpoint.
+		URL string
+		// ClientID and ClientSecret are the credentials used to authenticate with the
+		// introspection endpoint using HTTP basic authentication, if ClientID is not empty.
+		ClientID     string
+		ClientSecret string
+		// HTTPClient is the client used to make the introspection requests,
+		// http.DefaultClient if nil.
+		HTTPClient *http.Client
+		// MaxTTL caps the duration active results are cached for, including results with no
+		// expiration time. Results are not cached if MaxTTL is zero.
+		MaxTTL time.Duration
+
+		lock  sync.Mutex
+		cache map[[sha256.Size]byte]*cacheEntry
+	}
+
+	// cacheEntry is a cached introspection result.
+	cacheEntry struct {
+		introspection *Introspection
+		expiresAt     time.Time
+	}
+)
+
+// DefaultMaxTTL is the default value of the Introspector MaxTTL field set by NewIntrospector.
+const DefaultMaxTTL = 5 * time.Minute
+
+// NewIntrospector returns an introspector for the endpoint at the given URL that authenticates
+// with the given client credentials and caches results for at most DefaultMaxTTL.
+func NewIntrospector(url, clientID, clientSecret string) *Introspector {
+	return &Introspector{
+		URL:          url,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		MaxTTL:       DefaultMaxTTL,
+	}
+}
+
+// New returns a middleware to be used with the OAuth2Security DSL definitions of goa. The
+// middleware validates the "Bearer" token present in the "Authorization" header by calling the
+// introspection endpoint of the authorization server and ensures that the token grants the scopes
+// required by the action. The introspection result is stored in the request context and can be
+// retrieved with ContextIntrospection.
+//
+// You can define an optional function to do additional validations on the token once it is proven
+// to be active, e.g.:
+//
+//    validationHandler, _ := goa.NewMiddleware(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+//        if oauth2.ContextIntrospection(ctx).ClientID != "trusted" {
+//            return oauth2.ErrInvalidToken("untrusted client")
+//        }
+//        return nil
+//    })
+//
+// Mount the middleware with the generated UseXX function where XX is the name of the scheme as
+// defined in the design, e.g.:
+//
+//    introspector := oauth2.NewIntrospector("https://auth.example.com/introspect", "id", "secret")
+//    app.UseOAuth2Sec(oauth2.New(introspector, validationHandler))
+//
+func New(introspector *Introspector, validationFunc goa.Middleware) goa.Middleware {
+	return func(nextHandler goa.Handler) goa.Handler {
+		if validationFunc != nil {
+			nextHandler = validationFunc(nextHandler)
+		}
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			val := req.Header.Get("Authorization")
+			if len(val) < 7 || !strings.EqualFold(val[:7], "bearer ") {
+				rw.Header().Set("WWW-Authenticate", "Bearer")
+				return ErrInvalidToken("missing or malformed bearer token")
+			}
+			token := strings.TrimSpace(val[7:])
+
+			in, err := introspector.Introspect(ctx, token)
+			if err != nil {
+				goa.LogError(ctx, "token introspection failed", "err", err)
+				return ErrIntrospectionFailed(err)
+			}
+			now := time.Now().Unix()
+			if !in.Active || in.Exp != 0 && now >= in.Exp || in.Nbf != 0 && now < in.Nbf {
+				rw.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				return ErrInvalidToken("token is not active")
+			}
+
+			granted := in.Scopes()
+			requiredScopes := goa.ContextRequiredScopes(ctx)
+			for _, scope := range requiredScopes {
+				if !contains(granted, scope) {
+					rw.Header().Set("WWW-Authenticate",
+						fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, strings.Join(requiredScopes, " ")))
+					return ErrInsufficientScope("required scopes not granted",
+						"required", requiredScopes, "scopes", granted)
+				}
+			}
+
+			return nextHandler(WithIntrospection(ctx, in), rw, req)
+		}
+	}
+}
+
+// Introspect returns the introspection of the given token. Active results are served from the
+// cache until the token expires or MaxTTL elapses.
+func (i *Introspector) Introspect(ctx context.Context, token string) (*Introspection, error) {
+	key := sha256.Sum256([]byte(token))
+	now := time.Now()
+	if in := i.cached(key, now); in != nil {
+		return in, nil
+	}
+
+	in, err := i.introspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if in.Active && i.MaxTTL > 0 {
+		expiresAt := now.Add(i.MaxTTL)
+		if in.Exp != 0 {
+			if exp := time.Unix(in.Exp, 0); exp.Before(expiresAt) {
+				expiresAt = exp
+			}
+		}
+		if expiresAt.After(now) {
+			i.store(key, &cacheEntry{introspection: in, expiresAt: expiresAt}, now)
+		}
+	}
+	return in, nil
+}
+
+// Scopes returns the scopes associated with the token.
+func (in *Introspection) Scopes() []string {
+	return strings.Fields(in.Scope)
+}
+
+// UnmarshalJSON initializes the introspection from the JSON response of the endpoint.
+func (in *Introspection) UnmarshalJSON(data []byte) error {
+	type introspection Introspection
+	var fields struct {
+		*introspection
+		Aud interface{} `json:"aud"`
+	}
+	fields.introspection = (*introspection)(in)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	switch aud := fields.Aud.(type) {
+	case string:
+		in.Aud = []string{aud}
+	case []interface{}:
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				in.Aud = append(in.Aud, s)
+			}
+		}
+	}
+	return json.Unmarshal(data, &in.Claims)
+}
+
+// introspect calls the introspection endpoint.
+func (i *Introspector) introspect(ctx context.Context, token string) (*Introspection, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequest("POST", i.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if i.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(i.ClientID), url.QueryEscape(i.ClientSecret))
+	}
+	client := i.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection endpoint returned status %d", resp.StatusCode)
+	}
+	var in Introspection
+	if err := json.NewDecoder(resp.Body).Decode(&in); err != nil {
+		return nil, fmt.Errorf("invalid introspection response: %s", err)
+	}
+	return &in, nil
+}
+
+// cached returns the cached introspection for the given key if it has not expired.
+func (i *Introspector) cached(key [sha256.Size]byte, now time.Time) *Introspection {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	e, ok := i.cache[key]
+	if !ok {
+		return nil
+	}
+	if !now.Before(e.expiresAt) {
+		delete(i.cache, key)
+		return nil
+	}
+	return e.introspection
+}
+
+// store caches the given entry and evicts the expired ones.
+func (i *Introspector) store(key [sha256.Size]byte, e *cacheEntry, now time.Time) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if i.cache == nil {
+		i.cache = make(map[[sha256.Size]byte]*cacheEntry)
+	}
+	for k, c := range i.cache {
+		if !now.Before(c.expiresAt) {
+			delete(i.cache, k)
+		}
+	}
+	i.cache[key] = e
+}
+
+// contains returns true if s contains v.
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
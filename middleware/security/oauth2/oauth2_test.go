@@ -0,0 +1,201 @@
+package oauth2_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/oauth2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Middleware", func() {
+	var (
+		response      map[string]interface{}
+		calls         int32
+		server        *httptest.Server
+		introspector  *oauth2.Introspector
+		ctx           context.Context
+		rw            *httptest.ResponseRecorder
+		req           *http.Request
+		introspection *oauth2.Introspection
+		err           error
+	)
+
+	handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		introspection = oauth2.ContextIntrospection(ctx)
+		return nil
+	}
+
+	serve := func() error {
+		introspection = nil
+		rw = httptest.NewRecorder()
+		return oauth2.New(introspector, nil)(handler)(ctx, rw, req)
+	}
+
+	BeforeEach(func() {
+		response = map[string]interface{}{
+			"active":    true,
+			"scope":     "read write",
+			"client_id": "client",
+			"sub":       "user",
+			"aud":       "cellar",
+			"exp":       time.Now().Add(time.Hour).Unix(),
+			"tenant":    "acme",
+		}
+		atomic.StoreInt32(&calls, 0)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			id, secret, _ := r.BasicAuth()
+			if id != "id" || secret != "secret" {
+				w.WriteHeader(401)
+				return
+			}
+			if r.FormValue("token") != "token" {
+				json.NewEncoder(w).Encode(map[string]interface{}{"active": false})
+				return
+			}
+			json.NewEncoder(w).Encode(response)
+		}))
+		introspector = oauth2.NewIntrospector(server.URL, "id", "secret")
+		ctx = goa.WithRequiredScopes(context.Background(), []string{"read"})
+		req, _ = http.NewRequest("GET", "http://example.com/", nil)
+		req.Header.Set("Authorization", "Bearer token")
+	})
+
+	JustBeforeEach(func() {
+		err = serve()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("stores the introspection in the context", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(introspection).ShouldNot(BeNil())
+		Ω(introspection.ClientID).Should(Equal("client"))
+		Ω(introspection.Sub).Should(Equal("user"))
+		Ω(introspection.Aud).Should(Equal([]string{"cellar"}))
+		Ω(introspection.Scopes()).Should(Equal([]string{"read", "write"}))
+		Ω(introspection.Claims["tenant"]).Should(Equal("acme"))
+	})
+
+	It("caches the active results", func() {
+		Ω(serve()).ShouldNot(HaveOccurred())
+		Ω(atomic.LoadInt32(&calls)).Should(Equal(int32(1)))
+	})
+
+	Context("with a validation function", func() {
+		var validations int32
+
+		BeforeEach(func() {
+			atomic.StoreInt32(&validations, 0)
+		})
+
+		It("runs the function once per request", func() {
+			validationFunc := func(h goa.Handler) goa.Handler {
+				return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					atomic.AddInt32(&validations, 1)
+					return h(ctx, rw, req)
+				}
+			}
+			noop := func(context.Context, http.ResponseWriter, *http.Request) error { return nil }
+			mw := oauth2.New(introspector, validationFunc)(noop)
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					Ω(mw(ctx, httptest.NewRecorder(), req)).ShouldNot(HaveOccurred())
+				}()
+			}
+			wg.Wait()
+			Ω(atomic.LoadInt32(&validations)).Should(Equal(int32(10)))
+		})
+	})
+
+	Context("with a token about to expire", func() {
+		BeforeEach(func() {
+			response["exp"] = time.Now().Add(time.Second).Unix()
+		})
+
+		It("caches the result until the token expires", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			time.Sleep(time.Until(time.Unix(response["exp"].(int64), 0)))
+			Ω(serve()).Should(HaveOccurred())
+			Ω(atomic.LoadInt32(&calls)).Should(Equal(int32(2)))
+		})
+	})
+
+	Context("with caching disabled", func() {
+		BeforeEach(func() {
+			introspector.MaxTTL = 0
+		})
+
+		It("introspects the token for each request", func() {
+			Ω(serve()).ShouldNot(HaveOccurred())
+			Ω(atomic.LoadInt32(&calls)).Should(Equal(int32(2)))
+		})
+	})
+
+	Context("with an inactive token", func() {
+		BeforeEach(func() {
+			req.Header.Set("Authorization", "Bearer other")
+		})
+
+		It("rejects the request", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(*goa.ErrorResponse).Status).Should(Equal(401))
+			Ω(rw.Header().Get("WWW-Authenticate")).Should(Equal(`Bearer error="invalid_token"`))
+			Ω(introspection).Should(BeNil())
+		})
+
+		It("does not cache the result", func() {
+			Ω(serve()).Should(HaveOccurred())
+			Ω(atomic.LoadInt32(&calls)).Should(Equal(int32(2)))
+		})
+	})
+
+	Context("with a missing token", func() {
+		BeforeEach(func() {
+			req.Header.Del("Authorization")
+		})
+
+		It("rejects the request without introspecting", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(*goa.ErrorResponse).Status).Should(Equal(401))
+			Ω(atomic.LoadInt32(&calls)).Should(Equal(int32(0)))
+		})
+	})
+
+	Context("with insufficient scopes", func() {
+		BeforeEach(func() {
+			ctx = goa.WithRequiredScopes(context.Background(), []string{"read", "admin"})
+		})
+
+		It("rejects the request", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(*goa.ErrorResponse).Status).Should(Equal(403))
+			Ω(rw.Header().Get("WWW-Authenticate")).Should(ContainSubstring(`error="insufficient_scope"`))
+		})
+	})
+
+	Context("with invalid client credentials", func() {
+		BeforeEach(func() {
+			introspector.ClientSecret = "wrong"
+		})
+
+		It("fails", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(*goa.ErrorResponse).Status).Should(Equal(503))
+		})
+	})
+})
@@ -34,12 +34,14 @@ type (
 		samplingPercent int
 		maxSamplingRate int
 		sampleSize      int
+		formats         []TraceFormat
 	}
 
 	// tracedDoer is a goa client Doer that inserts the tracing headers for
 	// each request it makes.
 	tracedDoer struct {
 		client.Doer
+		formats []TraceFormat
 	}
 )
 
@@ -106,13 +108,33 @@ func SampleSize(s int) TracerOption {
 	}
 }
 
+// Propagation sets the formats used to read the trace context from the request
+// headers. The formats are tried in order and the first one that matches is used.
+// The formats are also recorded in the request context so that TraceDoer writes
+// the trace context in the same formats. Defaults to GoaTraceFormat. The default
+// trace and span ID functions produce IDs compatible with the W3C and B3 formats
+// (see HexTraceID and HexSpanID) when a format other than GoaTraceFormat is used.
+//
+// Requests whose trace context indicates that they are not sampled are not traced.
+func Propagation(formats ...TraceFormat) TracerOption {
+	if len(formats) == 0 {
+		panic("at least one trace format must be given")
+	}
+	return func(o *tracerOptions) *tracerOptions {
+		o.formats = formats
+		return o
+	}
+}
+
 // NewTracer returns a trace middleware that initializes the trace information
 // in the request context. The information can be retrieved using any of the
 // ContextXXX functions.
 //
 // samplingPercent must be a value between 0 and 100. It represents the percentage
 // of requests that should be traced. If the incoming request has a Trace ID
-// header then the sampling rate is disregarded and the tracing is enabled.
+// header then the sampling rate is disregarded and the tracing is enabled. The
+// Propagation option controls which headers are read, by default the TraceID and
+// ParentSpanID headers.
 //
 // spanIDFunc and traceIDFunc are the functions used to create Span and Trace
 // IDs respectively. This is configurable so that the created IDs are compatible
@@ -120,14 +142,31 @@ func SampleSize(s int) TracerOption {
 // implementations that produce AWS X-Ray compatible IDs.
 func NewTracer(opts ...TracerOption) goa.Middleware {
 	o := &tracerOptions{
-		traceIDFunc:     shortID,
-		spanIDFunc:      shortID,
 		samplingPercent: 100,
 		sampleSize:      1000, // only applies if maxSamplingRate is set
+		formats:         []TraceFormat{GoaTraceFormat},
 	}
 	for _, opt := range opts {
 		o = opt(o)
 	}
+	hexIDs := false
+	for _, f := range o.formats {
+		if f != GoaTraceFormat {
+			hexIDs = true
+		}
+	}
+	if o.traceIDFunc == nil {
+		o.traceIDFunc = shortID
+		if hexIDs {
+			o.traceIDFunc = HexTraceID
+		}
+	}
+	if o.spanIDFunc == nil {
+		o.spanIDFunc = shortID
+		if hexIDs {
+			o.spanIDFunc = HexSpanID
+		}
+	}
 	var sampler Sampler
 	if o.maxSamplingRate > 0 {
 		sampler = NewAdaptiveSampler(o.maxSamplingRate, o.sampleSize)
@@ -136,21 +175,34 @@ func NewTracer(opts ...TracerOption) goa.Middleware {
 	}
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			var tc *traceContext
+			for _, f := range o.formats {
+				if tc = f.extract(req.Header); tc != nil {
+					break
+				}
+			}
+
 			// insert a new trace ID only if not already being traced.
-			traceID := req.Header.Get(TraceIDHeader)
-			if traceID == "" {
+			var traceID, parentID, state string
+			if tc == nil {
 				// insert tracing only within sample.
-				if sampler.Sample() {
-					traceID = o.traceIDFunc()
-				} else {
+				if !sampler.Sample() {
 					return h(ctx, rw, req)
 				}
+				traceID = o.traceIDFunc()
+			} else if !tc.sampled {
+				return h(ctx, rw, req)
+			} else {
+				traceID, parentID, state = tc.traceID, tc.parentID, tc.state
 			}
 
 			// insert IDs into context to enable tracing.
 			spanID := o.spanIDFunc()
-			parentID := req.Header.Get(ParentSpanIDHeader)
 			ctx = WithTrace(ctx, traceID, spanID, parentID)
+			if state != "" {
+				ctx = WithTraceState(ctx, state)
+			}
+			ctx = context.WithValue(ctx, traceFormatsKey, o.formats)
 			return h(ctx, rw, req)
 		}
 	}
@@ -162,9 +214,12 @@ func Tracer(sampleRate int, spanIDFunc, traceIDFunc IDFunc) goa.Middleware {
 }
 
 // TraceDoer wraps a goa client Doer and sets the trace headers so that the
-// downstream service may properly retrieve the parent span ID and trace ID.
-func TraceDoer(doer client.Doer) client.Doer {
-	return &tracedDoer{doer}
+// downstream service may properly retrieve the parent span ID and trace ID. The
+// headers are written using the given formats, if none are given the formats
+// used by the tracer middleware that handled the request are used and default to
+// GoaTraceFormat.
+func TraceDoer(doer client.Doer, formats ...TraceFormat) client.Doer {
+	return &tracedDoer{Doer: doer, formats: formats}
 }
 
 // ContextTraceID returns the trace ID extracted from the given context if any,
@@ -194,6 +249,21 @@ func ContextParentSpanID(ctx context.Context) string {
 	return ""
 }
 
+// ContextTraceState returns the W3C tracestate value extracted from the given
+// context if any, the empty string otherwise.
+func ContextTraceState(ctx context.Context) string {
+	if s := ctx.Value(traceStateKey); s != nil {
+		return s.(string)
+	}
+	return ""
+}
+
+// WithTraceState returns a context containing the given W3C tracestate value. The
+// value is propagated by TraceDoer when using the W3CTraceFormat.
+func WithTraceState(ctx context.Context, state string) context.Context {
+	return context.WithValue(ctx, traceStateKey, state)
+}
+
 // WithTrace returns a context containing the given trace, span and parent span
 // IDs.
 func WithTrace(ctx context.Context, traceID, spanID, parentID string) context.Context {
@@ -212,8 +282,16 @@ func (d *tracedDoer) Do(ctx context.Context, req *http.Request) (*http.Response,
 		spanID  = ContextSpanID(ctx)
 	)
 	if traceID != "" {
-		req.Header.Set(TraceIDHeader, traceID)
-		req.Header.Set(ParentSpanIDHeader, spanID)
+		formats := d.formats
+		if len(formats) == 0 {
+			formats, _ = ctx.Value(traceFormatsKey).([]TraceFormat)
+		}
+		if len(formats) == 0 {
+			formats = []TraceFormat{GoaTraceFormat}
+		}
+		for _, f := range formats {
+			f.inject(req.Header, traceID, spanID, ContextTraceState(ctx))
+		}
 	}
 
 	return d.Doer.Do(ctx, req)
@@ -169,3 +169,139 @@ func TestTracerMiddleware(t *testing.T) {
 		}
 	}
 }
+
+func TestTracerPropagation(t *testing.T) {
+	var (
+		traceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentID = "00f067aa0ba902b7"
+		spanID   = "b7ad6b7169203331"
+		newID    = func() string { return spanID }
+	)
+
+	cases := map[string]struct {
+		Formats []TraceFormat
+		Headers map[string]string
+		// output
+		CtxTraceID, CtxParentID, CtxState string
+	}{
+		"w3c": {[]TraceFormat{W3CTraceFormat}, map[string]string{
+			"traceparent": "00-" + traceID + "-" + parentID + "-01",
+			"tracestate":  "congo=t61rcWkgMzE",
+		}, traceID, parentID, "congo=t61rcWkgMzE"},
+		"w3c-not-sampled": {[]TraceFormat{W3CTraceFormat}, map[string]string{
+			"traceparent": "00-" + traceID + "-" + parentID + "-00",
+		}, "", "", ""},
+		"w3c-invalid": {[]TraceFormat{W3CTraceFormat}, map[string]string{
+			"traceparent": "00-" + traceID + "-0000000000000000-01",
+		}, spanID, "", ""},
+		"b3": {[]TraceFormat{B3TraceFormat}, map[string]string{
+			"X-B3-TraceId": traceID,
+			"X-B3-SpanId":  parentID,
+			"X-B3-Sampled": "1",
+		}, traceID, parentID, ""},
+		"b3-not-sampled": {[]TraceFormat{B3TraceFormat}, map[string]string{
+			"X-B3-Sampled": "0",
+		}, "", "", ""},
+		"b3-single": {[]TraceFormat{B3SingleTraceFormat}, map[string]string{
+			"b3": traceID + "-" + parentID + "-1",
+		}, traceID, parentID, ""},
+		"fallback": {[]TraceFormat{W3CTraceFormat, GoaTraceFormat}, map[string]string{
+			TraceIDHeader:      "trace",
+			ParentSpanIDHeader: "parent",
+		}, "trace", "parent", ""},
+		"ignored": {nil, map[string]string{
+			"traceparent": "00-" + traceID + "-" + parentID + "-01",
+		}, spanID, "", ""},
+	}
+
+	for k, c := range cases {
+		var (
+			ctxTraceID, ctxParentID, ctxState string
+
+			opts = []TracerOption{SpanIDFunc(newID), TraceIDFunc(newID)}
+			h    = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				ctxTraceID = ContextTraceID(ctx)
+				ctxParentID = ContextParentSpanID(ctx)
+				ctxState = ContextTraceState(ctx)
+				return nil
+			}
+		)
+		if c.Formats != nil {
+			opts = append(opts, Propagation(c.Formats...))
+		}
+		req, _ := http.NewRequest("GET", "/", nil)
+		for n, v := range c.Headers {
+			req.Header.Set(n, v)
+		}
+
+		NewTracer(opts...)(h)(context.Background(), httptest.NewRecorder(), req)
+
+		if ctxTraceID != c.CtxTraceID {
+			t.Errorf("%s: invalid TraceID, expected %v - got %v", k, c.CtxTraceID, ctxTraceID)
+		}
+		if ctxParentID != c.CtxParentID {
+			t.Errorf("%s: invalid ParentSpanID, expected %v - got %v", k, c.CtxParentID, ctxParentID)
+		}
+		if ctxState != c.CtxState {
+			t.Errorf("%s: invalid trace state, expected %v - got %v", k, c.CtxState, ctxState)
+		}
+	}
+}
+
+func TestTraceDoer(t *testing.T) {
+	var (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "b7ad6b7169203331"
+	)
+
+	cases := map[string]struct {
+		Formats  []TraceFormat
+		TraceID  string
+		Expected map[string]string
+	}{
+		"goa": {nil, traceID, map[string]string{
+			TraceIDHeader:      traceID,
+			ParentSpanIDHeader: spanID,
+		}},
+		"w3c": {[]TraceFormat{W3CTraceFormat}, traceID, map[string]string{
+			"traceparent": "00-" + traceID + "-" + spanID + "-01",
+			"tracestate":  "congo=t61rcWkgMzE",
+		}},
+		"w3c-xray": {[]TraceFormat{W3CTraceFormat}, "1-4bf92f35-77b34da6a3ce929d0e0e4736", map[string]string{
+			"traceparent": "00-" + traceID + "-" + spanID + "-01",
+		}},
+		"b3": {[]TraceFormat{B3TraceFormat}, traceID, map[string]string{
+			"X-B3-TraceId": traceID,
+			"X-B3-SpanId":  spanID,
+			"X-B3-Sampled": "1",
+		}},
+		"b3-single": {[]TraceFormat{B3SingleTraceFormat}, traceID, map[string]string{
+			"b3": traceID + "-" + spanID + "-1",
+		}},
+	}
+
+	for k, c := range cases {
+		var (
+			headers http.Header
+			doer    = TraceDoer(doFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+				headers = req.Header
+				return nil, nil
+			}), c.Formats...)
+			ctx = WithTraceState(WithTrace(context.Background(), c.TraceID, spanID, ""), "congo=t61rcWkgMzE")
+		)
+		req, _ := http.NewRequest("GET", "/", nil)
+		doer.Do(ctx, req)
+
+		for n, v := range c.Expected {
+			if headers.Get(n) != v {
+				t.Errorf("%s: invalid %s header, expected %v - got %v", k, n, v, headers.Get(n))
+			}
+		}
+	}
+}
+
+type doFunc func(context.Context, *http.Request) (*http.Response, error)
+
+func (f doFunc) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return f(ctx, req)
+}
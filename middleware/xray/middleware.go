@@ -3,6 +3,7 @@ package xray
 import (
 	"context"
 	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"net/http"
@@ -26,7 +27,19 @@ const (
 //
 // The middleware works by extracting the trace information from the context
 // using the tracing middleware package. The tracing middleware must be mounted
-// first on the service.
+// first on the service. Trace IDs propagated using the W3C Trace Context format
+// (see middleware.W3CTraceFormat) are converted to the X-Ray format by using
+// their first 8 hexadecimal digits as the X-Ray epoch. X-Ray rejects traces whose
+// epoch is not a recent timestamp so the tracing middleware must create trace IDs
+// with NewTraceID when using the W3C format, e.g.:
+//
+//     service.Use(middleware.NewTracer(
+//         middleware.TraceIDFunc(xray.NewTraceID),
+//         middleware.Propagation(middleware.W3CTraceFormat),
+//     ))
+//
+// The upstream services propagating W3C trace IDs must likewise start them with
+// the hexadecimal Unix time of their creation.
 //
 // The middleware stores the request segment in the context. Use ContextSegment
 // to retrieve it. User code can further configure the segment for example to set
@@ -64,7 +77,7 @@ func New(service, daemon string) (goa.Middleware, error) {
 				return h(ctx, rw, req)
 			}
 
-			s := newSegment(ctx, traceID, service, req, connection())
+			s := newSegment(ctx, fromW3CTraceID(traceID), service, req, connection())
 			ctx = WithSegment(ctx, s)
 
 			defer func() {
@@ -101,6 +114,20 @@ func NewTraceID() string {
 	return fmt.Sprintf("%d-%x-%s", 1, time.Now().Unix(), fmt.Sprintf("%x", b))
 }
 
+// fromW3CTraceID converts a W3C Trace Context trace ID (32 hexadecimal digits) to
+// the X-Ray format: the first 8 digits are used as the X-Ray epoch part so that
+// IDs created by NewTraceID convert back to their original value. Other trace IDs
+// are returned unchanged.
+func fromW3CTraceID(traceID string) string {
+	if len(traceID) != 32 {
+		return traceID
+	}
+	if _, err := hex.DecodeString(traceID); err != nil {
+		return traceID
+	}
+	return fmt.Sprintf("1-%s-%s", traceID[:8], traceID[8:])
+}
+
 // WithSegment creates a context containing the given segment. Use ContextSegment
 // to retrieve it.
 func WithSegment(ctx context.Context, s *Segment) context.Context {
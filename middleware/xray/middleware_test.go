@@ -265,6 +265,27 @@ func TestNewTraceID(t *testing.T) {
 	}
 }
 
+func TestFromW3CTraceID(t *testing.T) {
+	cases := map[string]struct{ TraceID, Expected string }{
+		"w3c":     {"5759e988bd862e3fe1be46a994272793", "1-5759e988-bd862e3fe1be46a994272793"},
+		"xray":    {"1-5759e988-bd862e3fe1be46a994272793", "1-5759e988-bd862e3fe1be46a994272793"},
+		"invalid": {"5759e988bd862e3fe1be46a99427279z", "5759e988bd862e3fe1be46a99427279z"},
+	}
+	for k, c := range cases {
+		if id := fromW3CTraceID(c.TraceID); id != c.Expected {
+			t.Errorf("%s: invalid trace ID, expected %s got %s", k, c.Expected, id)
+		}
+	}
+}
+
+func TestFromW3CTraceIDRoundTrip(t *testing.T) {
+	id := NewTraceID()
+	w3c := strings.Replace(strings.TrimPrefix(id, "1-"), "-", "", 1)
+	if converted := fromW3CTraceID(w3c); converted != id {
+		t.Errorf("invalid trace ID, expected %s got %s", id, converted)
+	}
+}
+
 func TestPeriodicallyRedialingConn(t *testing.T) {
 
 	t.Run("dial fails, returns error immediately", func(t *testing.T) {
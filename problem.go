@@ -0,0 +1,167 @@
+package goa
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ProblemMediaIdentifier is the media type identifier used for RFC 7807 problem details
+	// error responses.
+	ProblemMediaIdentifier = "application/problem+json"
+
+	// DefaultErrorFormatter is the error formatter used by services that do not set one. It
+	// produces responses that use the goa error media type (application/vnd.goa.error).
+	DefaultErrorFormatter ErrorFormatter = func(err ServiceError) (string, interface{}) {
+		return ErrorMediaIdentifier, err
+	}
+)
+
+type (
+	// ErrorFormatter computes the content type and body of the responses sent for service
+	// errors. The body is encoded using the service encoder.
+	ErrorFormatter func(ServiceError) (contentType string, body interface{})
+
+	// ProblemDetails is a RFC 7807 problem details object. It implements ServiceError.
+	// See https://tools.ietf.org/html/rfc7807.
+	ProblemDetails struct {
+		// Type is a URI reference that identifies the problem type.
+		Type string `json:"type,omitempty"`
+		// Title is a short, human-readable summary of the problem type.
+		Title string `json:"title,omitempty"`
+		// Status is the HTTP status code of the response.
+		Status int `json:"status,omitempty"`
+		// Detail is a human-readable explanation specific to this occurrence of the problem.
+		Detail string `json:"detail,omitempty"`
+		// Instance is a URI reference that identifies the specific occurrence of the problem.
+		Instance string `json:"instance,omitempty"`
+		// Extensions contains the problem extension members.
+		Extensions map[string]interface{} `json:"-"`
+	}
+)
+
+// ProblemErrorFormatter returns an error formatter that produces RFC 7807 problem details
+// responses (application/problem+json). The problem type is typeBase followed by the error code
+// if typeBase is not empty, "about:blank" otherwise. Example:
+//
+//	service.ErrorFormatter = goa.ProblemErrorFormatter("https://example.com/errors/")
+//
+func ProblemErrorFormatter(typeBase string) ErrorFormatter {
+	return func(err ServiceError) (string, interface{}) {
+		return ProblemMediaIdentifier, NewProblemDetails(err, typeBase)
+	}
+}
+
+// NewProblemDetails converts a service error into a problem details object. The error ID is used
+// as the problem instance, the error code and metadata are set as extension members. See
+// ProblemErrorFormatter for a description of typeBase.
+func NewProblemDetails(err ServiceError, typeBase string) *ProblemDetails {
+	if p, ok := err.(*ProblemDetails); ok {
+		return p
+	}
+	status := err.ResponseStatus()
+	p := &ProblemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   err.Error(),
+		Instance: err.Token(),
+	}
+	if e, ok := err.(*ErrorResponse); ok {
+		p.Detail = e.Detail
+		p.Extensions = make(map[string]interface{}, len(e.Meta)+1)
+		for k, v := range e.Meta {
+			p.Extensions[k] = v
+		}
+		if e.Code != "" {
+			p.Extensions["code"] = e.Code
+			if typeBase != "" {
+				p.Type = typeBase + e.Code
+			}
+		}
+	}
+	return p
+}
+
+// ErrorResponse converts the problem details back into a goa error. The "code" extension member
+// is used as error code and the other extension members as metadata.
+func (p *ProblemDetails) ErrorResponse() *ErrorResponse {
+	e := &ErrorResponse{ID: p.Instance, Status: p.Status, Detail: p.Detail}
+	if e.Detail == "" {
+		e.Detail = p.Title
+	}
+	for k, v := range p.Extensions {
+		if code, ok := v.(string); ok && k == "code" {
+			e.Code = code
+			continue
+		}
+		if e.Meta == nil {
+			e.Meta = make(map[string]interface{})
+		}
+		e.Meta[k] = v
+	}
+	return e
+}
+
+// Error returns the problem details.
+func (p *ProblemDetails) Error() string {
+	msg := fmt.Sprintf("[%s] %d %s: %s", p.Instance, p.Status, p.Type, p.Detail)
+	for k, v := range p.Extensions {
+		msg += ", " + fmt.Sprintf("%s: %v", k, v)
+	}
+	return msg
+}
+
+// ResponseStatus is the status used to build responses.
+func (p *ProblemDetails) ResponseStatus() int { return p.Status }
+
+// Token is the unique problem occurrence identifier.
+func (p *ProblemDetails) Token() string { return p.Instance }
+
+// MarshalJSON encodes the problem details, extension members are encoded alongside the standard
+// members.
+func (p *ProblemDetails) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+	if p.Type != "" {
+		m["type"] = p.Type
+	}
+	if p.Title != "" {
+		m["title"] = p.Title
+	}
+	if p.Status != 0 {
+		m["status"] = p.Status
+	}
+	if p.Detail != "" {
+		m["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		m["instance"] = p.Instance
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON decodes the problem details, members that are not standard are stored in
+// Extensions.
+func (p *ProblemDetails) UnmarshalJSON(b []byte) error {
+	type standard ProblemDetails
+	var s standard
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	for _, k := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(m, k)
+	}
+	*p = ProblemDetails(s)
+	if len(m) > 0 {
+		p.Extensions = m
+	}
+	return nil
+}
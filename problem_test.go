@@ -0,0 +1,67 @@
+package goa_test
+
+import (
+	"encoding/json"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProblemDetails", func() {
+	var gerr *goa.ErrorResponse
+
+	BeforeEach(func() {
+		gerr = &goa.ErrorResponse{
+			ID:     "foo",
+			Code:   "invalid_request",
+			Status: 400,
+			Detail: "bar",
+			Meta:   map[string]interface{}{"param": "baz"},
+		}
+	})
+
+	Describe("NewProblemDetails", func() {
+		It("converts goa errors", func() {
+			p := goa.NewProblemDetails(gerr, "https://example.com/errors/")
+			Ω(p.Type).Should(Equal("https://example.com/errors/invalid_request"))
+			Ω(p.Title).Should(Equal("Bad Request"))
+			Ω(p.Status).Should(Equal(400))
+			Ω(p.Detail).Should(Equal("bar"))
+			Ω(p.Instance).Should(Equal("foo"))
+			Ω(p.Extensions).Should(Equal(map[string]interface{}{"code": "invalid_request", "param": "baz"}))
+		})
+
+		It("defaults the type to about:blank", func() {
+			p := goa.NewProblemDetails(gerr, "")
+			Ω(p.Type).Should(Equal("about:blank"))
+		})
+	})
+
+	It("serializes to JSON with the extension members", func() {
+		b, err := json.Marshal(goa.NewProblemDetails(gerr, ""))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(MatchJSON(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"bar","instance":"foo","code":"invalid_request","param":"baz"}`))
+	})
+
+	It("deserializes from JSON", func() {
+		var p goa.ProblemDetails
+		err := json.Unmarshal([]byte(`{"type":"about:blank","status":400,"detail":"bar","instance":"foo","code":"invalid_request","param":"baz"}`), &p)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(p.Status).Should(Equal(400))
+		Ω(p.Extensions).Should(Equal(map[string]interface{}{"code": "invalid_request", "param": "baz"}))
+	})
+
+	It("converts back to a goa error", func() {
+		Ω(goa.NewProblemDetails(gerr, "").ErrorResponse()).Should(Equal(gerr))
+	})
+})
+
+var _ = Describe("ProblemErrorFormatter", func() {
+	It("produces problem details", func() {
+		gerr := goa.ErrBadRequest("foo")
+		ct, body := goa.ProblemErrorFormatter("")(gerr.(goa.ServiceError))
+		Ω(ct).Should(Equal(goa.ProblemMediaIdentifier))
+		Ω(body).Should(BeAssignableToTypeOf(&goa.ProblemDetails{}))
+	})
+})
@@ -1,6 +1,9 @@
 package goa
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Location is the enum defining where the value of key based security schemes should be read:
 // either a HTTP request header or a URL querystring value
@@ -74,4 +77,15 @@ type JWTSecurity struct {
 	TokenURL string
 	// Scopes defines a list of scopes for the security scheme, along with their description.
 	Scopes map[string]string
+	// Issuer is the expected value of the "iss" claim, not checked if empty.
+	Issuer string
+	// Audience lists the accepted values of the "aud" claim, not checked if empty.
+	Audience []string
+	// Leeway is the clock skew tolerated when validating the "exp", "nbf" and "iat" claims.
+	Leeway time.Duration
+	// Algorithms lists the accepted signing algorithms, e.g. "RS256", all the algorithms
+	// supported by the keys are accepted if empty.
+	Algorithms []string
+	// RequiredClaims lists the names of the claims the token must define.
+	RequiredClaims []string
 }
@@ -8,9 +8,14 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"context"
 )
@@ -35,9 +40,28 @@ type (
 		Decoder *HTTPDecoder
 		// Response body encoder
 		Encoder *HTTPEncoder
+		// ErrorFormatter computes the content type and body of error responses, it defaults
+		// to DefaultErrorFormatter if nil.
+		ErrorFormatter ErrorFormatter
+		// ShutdownDelay is the time Shutdown waits after marking the service as not ready
+		// and before closing the listeners. It gives load balancers probing the service
+		// readiness the time to stop routing requests to it.
+		ShutdownDelay time.Duration
 
 		middleware []Middleware       // Middleware chain
 		cancel     context.CancelFunc // Service context cancel signal trigger
+
+		mu           sync.Mutex     // Protects servers, closing, done and ready updates
+		servers      []*http.Server // Servers started with ListenAndServe, ListenAndServeTLS or Serve
+		closing      bool           // true once Shutdown starts
+		done         chan struct{}  // Closed once Shutdown completes
+		onStart      []Hook         // Start hooks
+		onShutdown   []Hook         // Shutdown hooks
+		startOnce    sync.Once      // Guards start hooks execution
+		startErr     error          // Error returned by start hooks
+		shutdownOnce sync.Once      // Guards shutdown
+		shutdownErr  error          // Error returned by Shutdown
+		ready        int32          // 1 if the service is ready to serve requests
 	}
 
 	// Controller defines the common fields and behavior of generated controllers.
@@ -82,6 +106,9 @@ type (
 
 	// DecodeFunc is the function that initialize the unmarshaled payload from the request body.
 	DecodeFunc func(context.Context, io.ReadCloser, interface{}) error
+
+	// Hook is the signature of the functions called when the service starts or shuts down.
+	Hook func(context.Context) error
 )
 
 // New instantiates a service with the given name.
@@ -159,25 +186,174 @@ func (service *Service) LogError(msg string, keyvals ...interface{}) {
 }
 
 // ListenAndServe starts a HTTP server and sets up a listener on the given host/port.
+// ListenAndServe returns nil once the service has been shut down gracefully with Shutdown.
 func (service *Service) ListenAndServe(addr string) error {
 	service.LogInfo("listen", "transport", "http", "addr", addr)
-	return http.ListenAndServe(addr, service.Mux)
+	srv := &http.Server{Addr: addr, Handler: service.Mux}
+	return service.serve(srv, srv.ListenAndServe)
 }
 
 // ListenAndServeTLS starts a HTTPS server and sets up a listener on the given host/port.
+// ListenAndServeTLS returns nil once the service has been shut down gracefully with Shutdown.
 func (service *Service) ListenAndServeTLS(addr, certFile, keyFile string) error {
 	service.LogInfo("listen", "transport", "https", "addr", addr)
-	return http.ListenAndServeTLS(addr, certFile, keyFile, service.Mux)
+	srv := &http.Server{Addr: addr, Handler: service.Mux}
+	return service.serve(srv, func() error { return srv.ListenAndServeTLS(certFile, keyFile) })
 }
 
 // Serve accepts incoming HTTP connections on the listener l, invoking the service mux handler for each.
+// Serve returns nil once the service has been shut down gracefully with Shutdown.
 func (service *Service) Serve(l net.Listener) error {
-	if err := http.Serve(l, service.Mux); err != nil {
+	srv := &http.Server{Handler: service.Mux}
+	return service.serve(srv, func() error { return srv.Serve(l) })
+}
+
+// OnStart registers a hook that is called with the service root context before the service
+// starts accepting connections. Hooks are called in registration order the first time one of
+// ListenAndServe, ListenAndServeTLS or Serve is called, an error aborts the startup.
+func (service *Service) OnStart(h Hook) {
+	service.onStart = append(service.onStart, h)
+}
+
+// OnShutdown registers a hook that is called by Shutdown once the in-flight requests have
+// completed. Hooks are called in reverse registration order with the context given to Shutdown.
+func (service *Service) OnShutdown(h Hook) {
+	service.onShutdown = append(service.onShutdown, h)
+}
+
+// Ready returns true if the service is serving requests and is not shutting down.
+func (service *Service) Ready() bool {
+	return atomic.LoadInt32(&service.ready) == 1
+}
+
+// ReadinessHandler returns a handler suitable for load balancer readiness probes. The handler
+// responds with status 200 while the service is ready and 503 once it starts shutting down.
+// Mount it with e.g.:
+//
+//	service.Mux.Handle("GET", "/readyz", service.ReadinessHandler())
+//
+func (service *Service) ReadinessHandler() MuxHandler {
+	return func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+		if !service.Ready() {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}
+}
+
+// Shutdown gracefully shuts down the service. It marks the service as not ready, waits for
+// ShutdownDelay, closes the listeners and waits for the in-flight requests to complete. It then
+// cancels the service root context and runs the shutdown hooks. If ctx is done before the
+// requests complete, Shutdown cancels the root context right away so that long running handlers
+// can return and returns the context error once the hooks have run. Calling Shutdown more than
+// once returns the result of the first call.
+func (service *Service) Shutdown(ctx context.Context) error {
+	service.shutdownOnce.Do(func() {
+		service.shutdownErr = service.shutdown(ctx)
+		close(service.doneChan())
+	})
+	return service.shutdownErr
+}
+
+// ShutdownOnSignal shuts down the service gracefully when the process receives one of the given
+// signals, os.Interrupt and SIGTERM if none is given. timeout is the maximum time given to
+// Shutdown, zero means no limit. ShutdownOnSignal returns immediately, a second signal received
+// during shutdown terminates the process.
+func (service *Service) ShutdownOnSignal(timeout time.Duration, sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sigs...)
+	go func() {
+		sig := <-c
+		signal.Stop(c)
+		service.LogInfo("shutdown", "signal", sig.String())
+		ctx := context.Background()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		if err := service.Shutdown(ctx); err != nil {
+			service.LogError("shutdown", "err", err)
+		}
+	}()
+}
+
+// serve runs the start hooks, registers srv so that Shutdown may drain it and runs it.
+func (service *Service) serve(srv *http.Server, run func() error) error {
+	service.startOnce.Do(func() {
+		for _, h := range service.onStart {
+			if err := h(service.Context); err != nil {
+				service.startErr = err
+				return
+			}
+		}
+	})
+	if service.startErr != nil {
+		return service.startErr
+	}
+	service.mu.Lock()
+	if service.closing {
+		service.mu.Unlock()
+		return http.ErrServerClosed
+	}
+	service.servers = append(service.servers, srv)
+	atomic.StoreInt32(&service.ready, 1)
+	service.mu.Unlock()
+	done := service.doneChan()
+	if err := run(); err != http.ErrServerClosed {
 		return err
 	}
+	// Wait for the in-flight requests to complete.
+	<-done
 	return nil
 }
 
+// shutdown implements Shutdown.
+func (service *Service) shutdown(ctx context.Context) error {
+	// Set closing and clear ready together so that a concurrent serve cannot mark the service
+	// ready again.
+	service.mu.Lock()
+	service.closing = true
+	atomic.StoreInt32(&service.ready, 0)
+	servers := service.servers
+	service.mu.Unlock()
+	if service.ShutdownDelay > 0 {
+		select {
+		case <-time.After(service.ShutdownDelay):
+		case <-ctx.Done():
+		}
+	}
+	var err error
+	for _, srv := range servers {
+		if e := srv.Shutdown(ctx); e != nil && err == nil {
+			err = e
+		}
+	}
+	if service.cancel != nil {
+		service.cancel()
+	}
+	for i := len(service.onShutdown) - 1; i >= 0; i-- {
+		if e := service.onShutdown[i](ctx); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// doneChan returns the channel closed once Shutdown completes.
+func (service *Service) doneChan() chan struct{} {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if service.done == nil {
+		service.done = make(chan struct{})
+	}
+	return service.done
+}
+
 // NewController returns a controller for the given resource. This method is mainly intended for
 // use by the generated code. User code shouldn't have to call it directly.
 func (service *Service) NewController(name string) *Controller {
@@ -193,12 +369,22 @@ func (service *Service) NewController(name string) *Controller {
 }
 
 // Send serializes the given body matching the request Accept header against the service
-// encoders. It uses the default service encoder if no match is found.
+// encoders. It uses the default service encoder if no match is found. Bodies that implement
+// ServiceError are formatted with the service error formatter first.
 func (service *Service) Send(ctx context.Context, code int, body interface{}) error {
 	r := ContextResponse(ctx)
 	if r == nil {
 		return fmt.Errorf("no response data in context")
 	}
+	if err, ok := body.(ServiceError); ok {
+		format := service.ErrorFormatter
+		if format == nil {
+			format = DefaultErrorFormatter
+		}
+		var contentType string
+		contentType, body = format(err)
+		r.Header().Set("Content-Type", contentType)
+	}
 	r.WriteHeader(code)
 	return service.EncodeResponse(ctx, body)
 }
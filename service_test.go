@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"time"
 
 	"context"
 
@@ -310,6 +312,113 @@ var _ = Describe("Service", func() {
 		})
 	})
 
+	Describe("Shutdown", func() {
+		var (
+			l        net.Listener
+			calls    []string
+			release  chan struct{}
+			served   chan error
+			inflight chan error
+		)
+
+		unblock := func() {
+			select {
+			case <-release:
+			default:
+				close(release)
+			}
+		}
+
+		BeforeEach(func() {
+			var err error
+			l, err = net.Listen("tcp", "127.0.0.1:0")
+			Ω(err).ShouldNot(HaveOccurred())
+			calls = nil
+			rel := make(chan struct{})
+			release = rel
+			served = make(chan error, 1)
+			inflight = make(chan error, 1)
+			s.Mux.Handle("GET", "/slow", func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+				<-rel
+				rw.WriteHeader(200)
+			})
+			s.Mux.Handle("GET", "/ready", s.ReadinessHandler())
+			s.OnStart(func(context.Context) error {
+				calls = append(calls, "start")
+				return nil
+			})
+			s.OnShutdown(func(context.Context) error {
+				calls = append(calls, "shutdown1")
+				return nil
+			})
+			s.OnShutdown(func(context.Context) error {
+				calls = append(calls, "shutdown2")
+				return nil
+			})
+		})
+
+		AfterEach(func() {
+			unblock()
+			s.Shutdown(context.Background())
+		})
+
+		JustBeforeEach(func() {
+			s, l, served, inflight := s, l, served, inflight
+			go func() { served <- s.Serve(l) }()
+			Eventually(s.Ready).Should(BeTrue())
+			addr := l.Addr().String()
+			go func() {
+				resp, err := http.Get("http://" + addr + "/slow")
+				if err == nil {
+					resp.Body.Close()
+				}
+				inflight <- err
+			}()
+		})
+
+		It("reports the service as ready", func() {
+			resp, err := http.Get("http://" + l.Addr().String() + "/ready")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(resp.StatusCode).Should(Equal(200))
+		})
+
+		It("drains in-flight requests and runs the hooks", func() {
+			shutdown := make(chan error, 1)
+			time.Sleep(50 * time.Millisecond) // let the in-flight request reach the handler
+			go func() { shutdown <- s.Shutdown(context.Background()) }()
+			Eventually(s.Ready).Should(BeFalse())
+			Consistently(shutdown).ShouldNot(Receive())
+			unblock()
+			Eventually(inflight).Should(Receive(BeNil()))
+			Eventually(shutdown).Should(Receive(BeNil()))
+			Eventually(served).Should(Receive(BeNil()))
+			Ω(calls).Should(Equal([]string{"start", "shutdown2", "shutdown1"}))
+			Ω(s.Context.Err()).Should(Equal(context.Canceled))
+		})
+
+		It("does not serve nor become ready again once shutting down", func() {
+			s.ShutdownDelay = 100 * time.Millisecond
+			shutdown := make(chan error, 1)
+			go func() { shutdown <- s.Shutdown(context.Background()) }()
+			Eventually(s.Ready).Should(BeFalse())
+			other, err := net.Listen("tcp", "127.0.0.1:0")
+			Ω(err).ShouldNot(HaveOccurred())
+			defer other.Close()
+			Ω(s.Serve(other)).Should(Equal(http.ErrServerClosed))
+			Ω(s.Ready()).Should(BeFalse())
+			unblock()
+			Eventually(shutdown).Should(Receive(BeNil()))
+		})
+
+		It("returns the context error if requests do not complete in time", func() {
+			time.Sleep(50 * time.Millisecond)
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			Ω(s.Shutdown(ctx)).Should(Equal(context.DeadlineExceeded))
+			Ω(calls).Should(ContainElement("shutdown1"))
+		})
+	})
+
 	Describe("FileHandler", func() {
 		const publicPath = "github.com/goadesign/goa/public"
 
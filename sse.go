@@ -0,0 +1,125 @@
+package goa
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type (
+	// EventStream sends server-sent events to the client that made a request. Each event is
+	// flushed to the client as soon as it is sent. See
+	// https://html.spec.whatwg.org/multipage/server-sent-events.html for details on the
+	// protocol.
+	EventStream struct {
+		ctx         context.Context
+		resp        *ResponseData
+		flusher     http.Flusher
+		lastEventID string
+	}
+
+	// ServerSentEvent is a single event sent on an event stream.
+	ServerSentEvent struct {
+		// ID is the event ID. Clients send the ID of the last event they received in the
+		// Last-Event-ID header when reconnecting.
+		ID string
+		// Event is the event type, clients default to "message" when empty.
+		Event string
+		// Data is the event data.
+		Data []byte
+		// Retry is the reconnection time clients should use, ignored if zero.
+		Retry time.Duration
+	}
+)
+
+// ErrStreamingUnsupported is the error returned by NewEventStream when the underlying response
+// writer cannot flush data to the client.
+var ErrStreamingUnsupported = errors.New("response writer does not support streaming")
+
+// NewEventStream writes the headers of a server-sent events response and returns the stream used
+// to send the events. The stream stops sending events and returns the context error as soon as
+// ctx is done, typically when the client closes the connection.
+func NewEventStream(ctx context.Context, resp *ResponseData, req *RequestData) (*EventStream, error) {
+	flusher, ok := resp.ResponseWriter.(http.Flusher)
+	if !ok {
+		return nil, ErrStreamingUnsupported
+	}
+	h := resp.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	resp.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	var lastEventID string
+	if req != nil && req.Request != nil {
+		lastEventID = req.Header.Get("Last-Event-ID")
+	}
+	return &EventStream{ctx: ctx, resp: resp, flusher: flusher, lastEventID: lastEventID}, nil
+}
+
+// LastEventID returns the value of the Last-Event-ID request header. Clients set the header to the
+// ID of the last event they received when reconnecting so that the stream can resume from there.
+func (s *EventStream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Done returns a channel that is closed when the stream request context is done.
+func (s *EventStream) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Send writes the given event to the client and flushes it. Send returns the context error if the
+// request context is done.
+func (s *EventStream) Send(e *ServerSentEvent) error {
+	select {
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	default:
+	}
+	if strings.ContainsAny(e.ID, "\r\n") || strings.ContainsAny(e.Event, "\r\n") {
+		return fmt.Errorf("invalid event, ID and event type may not contain line breaks")
+	}
+	var b bytes.Buffer
+	if e.ID != "" {
+		b.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Event != "" {
+		b.WriteString("event: " + e.Event + "\n")
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", int64(e.Retry/time.Millisecond))
+	}
+	data := bytes.Replace(e.Data, []byte("\r\n"), []byte("\n"), -1)
+	data = bytes.Replace(data, []byte("\r"), []byte("\n"), -1)
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		b.WriteString("data: ")
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	if _, err := s.resp.Write(b.Bytes()); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// SendComment writes a comment line to the client. Clients ignore comments, they are typically
+// used to keep the connection alive.
+func (s *EventStream) SendComment(comment string) error {
+	select {
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	default:
+	}
+	comment = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(comment)
+	if _, err := s.resp.Write([]byte(": " + comment + "\n\n")); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
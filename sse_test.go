@@ -0,0 +1,77 @@
+package goa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EventStream", func() {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+		rw     *httptest.ResponseRecorder
+		req    *http.Request
+		stream *goa.EventStream
+		err    error
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		rw = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/events", nil)
+		req.Header.Set("Last-Event-ID", "42")
+	})
+
+	JustBeforeEach(func() {
+		stream, err = goa.NewEventStream(ctx, &goa.ResponseData{ResponseWriter: rw}, &goa.RequestData{Request: req})
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("writes the response headers", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rw.Code).Should(Equal(200))
+		Ω(rw.Header().Get("Content-Type")).Should(Equal("text/event-stream"))
+		Ω(rw.Header().Get("Cache-Control")).Should(Equal("no-cache"))
+		Ω(rw.Flushed).Should(BeTrue())
+	})
+
+	It("exposes the last event ID", func() {
+		Ω(stream.LastEventID()).Should(Equal("42"))
+	})
+
+	It("sends events", func() {
+		err := stream.Send(&goa.ServerSentEvent{ID: "43", Event: "update", Data: []byte("foo\nbar"), Retry: time.Second})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rw.Body.String()).Should(Equal("id: 43\nevent: update\nretry: 1000\ndata: foo\ndata: bar\n\n"))
+	})
+
+	It("sends comments", func() {
+		err := stream.SendComment("ping")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rw.Body.String()).Should(Equal(": ping\n\n"))
+	})
+
+	It("rejects event IDs with line breaks", func() {
+		err := stream.Send(&goa.ServerSentEvent{ID: "4\n3"})
+		Ω(err).Should(HaveOccurred())
+	})
+
+	Context("with a canceled context", func() {
+		It("stops sending events", func() {
+			cancel()
+			Eventually(stream.Done()).Should(BeClosed())
+			err := stream.Send(&goa.ServerSentEvent{Data: []byte("foo")})
+			Ω(err).Should(Equal(context.Canceled))
+			Ω(rw.Body.String()).Should(BeEmpty())
+		})
+	})
+})
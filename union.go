@@ -0,0 +1,48 @@
+package goa
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalUnionValue returns the JSON representation of the union value v with an additional
+// discriminator attribute whose value is name. The JSON representation of v must be an object.
+// MarshalUnionValue is used by the code generated for union types that define a discriminator.
+func MarshalUnionValue(v interface{}, discriminator, name string) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil, fmt.Errorf("union value %#v is not an object", name)
+	}
+	if obj == nil {
+		obj = make(map[string]json.RawMessage)
+	}
+	n, err := json.Marshal(name)
+	if err != nil {
+		return nil, err
+	}
+	obj[discriminator] = n
+	return json.Marshal(obj)
+}
+
+// UnionDiscriminator returns the value of the discriminator attribute of the JSON object
+// contained in data. UnionDiscriminator is used by the code generated for union types that
+// define a discriminator.
+func UnionDiscriminator(data []byte, discriminator string) (string, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return "", err
+	}
+	raw, ok := obj[discriminator]
+	if !ok {
+		return "", fmt.Errorf("missing union discriminator %#v", discriminator)
+	}
+	var name string
+	if err := json.Unmarshal(raw, &name); err != nil {
+		return "", fmt.Errorf("invalid union discriminator %#v: %s", discriminator, err)
+	}
+	return name, nil
+}
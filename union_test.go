@@ -0,0 +1,48 @@
+package goa_test
+
+import (
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MarshalUnionValue", func() {
+	type circle struct {
+		Radius int `json:"radius"`
+	}
+
+	It("adds the discriminator", func() {
+		b, err := goa.MarshalUnionValue(&circle{Radius: 2}, "kind", "circle")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(MatchJSON(`{"kind":"circle","radius":2}`))
+	})
+
+	It("handles nil values", func() {
+		b, err := goa.MarshalUnionValue((*circle)(nil), "kind", "circle")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(MatchJSON(`{"kind":"circle"}`))
+	})
+
+	It("fails with values that are not objects", func() {
+		_, err := goa.MarshalUnionValue(42, "kind", "number")
+		Ω(err).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("UnionDiscriminator", func() {
+	It("returns the discriminator value", func() {
+		name, err := goa.UnionDiscriminator([]byte(`{"kind":"circle","radius":2}`), "kind")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(name).Should(Equal("circle"))
+	})
+
+	It("fails if the discriminator is missing", func() {
+		_, err := goa.UnionDiscriminator([]byte(`{"radius":2}`), "kind")
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("fails if the discriminator is not a string", func() {
+		_, err := goa.UnionDiscriminator([]byte(`{"kind":2}`), "kind")
+		Ω(err).Should(HaveOccurred())
+	})
+})